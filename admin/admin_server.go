@@ -0,0 +1,638 @@
+package admin
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/graphql-go/graphql"
+
+	"github.com/bnb-chain/greenfield-relayer/common"
+	"github.com/bnb-chain/greenfield-relayer/config"
+	"github.com/bnb-chain/greenfield-relayer/db/dao"
+	"github.com/bnb-chain/greenfield-relayer/db/model"
+	"github.com/bnb-chain/greenfield-relayer/events"
+	"github.com/bnb-chain/greenfield-relayer/executor"
+	"github.com/bnb-chain/greenfield-relayer/logging"
+	"github.com/bnb-chain/greenfield-relayer/metric"
+	"github.com/bnb-chain/greenfield-relayer/payload"
+	"github.com/bnb-chain/greenfield-relayer/types"
+)
+
+const (
+	DefaultAuditLogLimit = 100
+	MaxAuditLogLimit     = 1000
+
+	DefaultPeerStatsWindow = 24 * time.Hour
+
+	DefaultVoteLatencyStatsWindow = 24 * time.Hour
+
+	DefaultClaimDiagnosticLimit = 20
+	MaxClaimDiagnosticLimit     = 100
+
+	TaskActionPause       = "pause"
+	TaskActionResume      = "resume"
+	TaskActionSetInterval = "set_interval"
+)
+
+// Backfiller re-scans a height range of a chain, implemented by app.App
+type Backfiller interface {
+	Backfill(chain string, from, to uint64) error
+}
+
+type route struct {
+	method     string
+	permission string
+	summary    string
+	params     []param
+	body       *bodySchema
+	handler    http.HandlerFunc
+}
+
+// AdminServer serves the admin API, every request is authenticated by api key or client certificate, authorized
+// against the permissions of the client and recorded in the audit log. Queries are served by readDaoManager, which may
+// be backed by a read replica, while audit logs are written by daoManager.
+type AdminServer struct {
+	config         *config.Config
+	daoManager     *dao.DaoManager
+	readDaoManager *dao.DaoManager
+	backfiller     Backfiller
+	eventBus       *events.Bus
+	auth           *authenticator
+	routes         map[string]route
+	graphQLSchema  graphql.Schema
+}
+
+func NewAdminServer(cfg *config.Config, daoManager, readDaoManager *dao.DaoManager, backfiller Backfiller, eventBus *events.Bus) *AdminServer {
+	s := &AdminServer{
+		config:         cfg,
+		daoManager:     daoManager,
+		readDaoManager: readDaoManager,
+		backfiller:     backfiller,
+		eventBus:       eventBus,
+		auth:           newAuthenticator(cfg.AdminConfig.Clients),
+	}
+	schema, err := s.newGraphQLSchema()
+	if err != nil {
+		panic(fmt.Sprintf("invalid GraphQL schema, err=%s", err.Error()))
+	}
+	s.graphQLSchema = schema
+	s.routes = map[string]route{
+		"/admin/status": {
+			method:     http.MethodGet,
+			permission: config.AdminPermissionRead,
+			summary:    "Latest saved block heights of both chains",
+			handler:    s.handleStatus,
+		},
+		"/admin/audit_logs": {
+			method:     http.MethodGet,
+			permission: config.AdminPermissionRead,
+			summary:    "Latest admin API requests",
+			params: []param{
+				{name: "limit", typ: paramTypeInteger, min: 1, max: MaxAuditLogLimit, description: "number of audit logs to return"},
+			},
+			handler: s.handleAuditLogs,
+		},
+		"/admin/backfill": {
+			method:     http.MethodPost,
+			permission: config.AdminPermissionWrite,
+			summary:    "Re-scan a height range of a chain and save missing packages asynchronously",
+			params: []param{
+				{name: config.FlagBackfillChain, typ: paramTypeString, required: true, enum: []string{config.ChainGreenfield, config.ChainBSC}},
+				{name: config.FlagBackfillFrom, typ: paramTypeInteger, required: true, description: "start height, inclusive"},
+				{name: config.FlagBackfillTo, typ: paramTypeInteger, required: true, description: "end height, inclusive"},
+			},
+			handler: s.handleBackfill,
+		},
+		"/admin/skip_sequence": {
+			method:     http.MethodPost,
+			permission: config.AdminPermissionWrite,
+			summary:    "Mark undelivered packages of a sequence which can never be claimed as skipped, so they are neither voted nor claimed",
+			params: []param{
+				{name: "direction", typ: paramTypeString, required: true, enum: []string{metric.DirectionBSCToGnfd, metric.DirectionGnfdToBSC}},
+				{name: "channel_id", typ: paramTypeInteger, max: 255, description: "required for greenfield_to_bsc"},
+				{name: "sequence", typ: paramTypeInteger, required: true, description: "oracle sequence for bsc_to_greenfield"},
+				{name: "confirm", typ: paramTypeString, required: true, enum: []string{"yes"}},
+			},
+			handler: s.handleSkipSequence,
+		},
+		"/admin/retry_sequence": {
+			method:     http.MethodPost,
+			permission: config.AdminPermissionWrite,
+			summary:    "Claim packages of a sequence which need attention after failing claim_max_attempts times again",
+			params: []param{
+				{name: "direction", typ: paramTypeString, required: true, enum: []string{metric.DirectionBSCToGnfd, metric.DirectionGnfdToBSC}},
+				{name: "channel_id", typ: paramTypeInteger, max: 255, description: "required for greenfield_to_bsc"},
+				{name: "sequence", typ: paramTypeInteger, required: true, description: "oracle sequence for bsc_to_greenfield"},
+			},
+			handler: s.handleRetrySequence,
+		},
+		"/admin/peer_stats": {
+			method:     http.MethodGet,
+			permission: config.AdminPermissionRead,
+			summary:    "Delivery counts and latencies of every relayer, observed from claim txs",
+			params: []param{
+				{name: "direction", typ: paramTypeString, enum: []string{metric.DirectionBSCToGnfd, metric.DirectionGnfdToBSC}},
+				{name: "since", typ: paramTypeInteger, description: "unix timestamp in second, defaults to 24 hours ago"},
+			},
+			handler: s.handlePeerStats,
+		},
+		"/admin/vote_latency_stats": {
+			method:     http.MethodGet,
+			permission: config.AdminPermissionRead,
+			summary:    "Vote latency percentiles of every validator, slowest first",
+			params: []param{
+				{name: "direction", typ: paramTypeString, enum: []string{metric.DirectionBSCToGnfd, metric.DirectionGnfdToBSC}},
+				{name: "since", typ: paramTypeInteger, description: "unix timestamp in second, defaults to 24 hours ago"},
+			},
+			handler: s.handleVoteLatencyStats,
+		},
+		"/admin/claim_diagnostics": {
+			method:     http.MethodGet,
+			permission: config.AdminPermissionRead,
+			summary:    "Latest diagnostic bundles captured on claim failures",
+			params: []param{
+				{name: "direction", typ: paramTypeString, enum: []string{metric.DirectionBSCToGnfd, metric.DirectionGnfdToBSC}},
+				{name: "channel_id", typ: paramTypeInteger, max: 255},
+				{name: "sequence", typ: paramTypeInteger, description: "oracle sequence for bsc_to_greenfield"},
+				{name: "limit", typ: paramTypeInteger, min: 1, max: MaxClaimDiagnosticLimit, description: "number of diagnostics to return"},
+			},
+			handler: s.handleClaimDiagnostics,
+		},
+		"/admin/claim_stream": {
+			method:     http.MethodGet,
+			permission: config.AdminPermissionRead,
+			summary:    "Stream claim attempts and their results as server-sent events",
+			params: []param{
+				{name: "direction", typ: paramTypeString, enum: []string{metric.DirectionBSCToGnfd, metric.DirectionGnfdToBSC}},
+				{name: "channel_id", typ: paramTypeInteger, max: 255},
+			},
+			handler: s.handleClaimStream,
+		},
+		"/admin/grafana_dashboard": {
+			method:     http.MethodGet,
+			permission: config.AdminPermissionRead,
+			summary:    "Grafana dashboard JSON generated from the metrics of this binary",
+			handler:    s.handleGrafanaDashboard,
+		},
+		"/admin/scheduled_tasks": {
+			method:     http.MethodGet,
+			permission: config.AdminPermissionRead,
+			summary:    "Periodic tasks of the relayer with their intervals and latest runs",
+			handler:    s.handleScheduledTasks,
+		},
+		"/admin/scheduled_task": {
+			method:     http.MethodPost,
+			permission: config.AdminPermissionWrite,
+			summary:    "Pause, resume or change the interval of a periodic task until restart",
+			params: []param{
+				{name: "name", typ: paramTypeString, required: true},
+				{name: "action", typ: paramTypeString, required: true, enum: []string{TaskActionPause, TaskActionResume, TaskActionSetInterval}},
+				{name: "interval_in_millisecond", typ: paramTypeInteger, min: 1, description: "required for set_interval"},
+			},
+			handler: s.handleScheduledTask,
+		},
+		"/admin/rpc_trace": {
+			method:     http.MethodPost,
+			permission: config.AdminPermissionWrite,
+			summary:    "Log full RPC requests and responses of an executor for a while, secrets redacted",
+			params: []param{
+				{name: "subsystem", typ: paramTypeString, required: true, enum: []string{common.SubsystemBSCExecutor, common.SubsystemGreenfieldExecutor}},
+				{name: "duration_in_second", typ: paramTypeInteger, required: true, max: uint64(executor.MaxRPCTraceDuration / time.Second), description: "0 stops tracing"},
+			},
+			handler: s.handleRPCTrace,
+		},
+		"/admin/graphql": {
+			method:     http.MethodPost,
+			permission: config.AdminPermissionRead,
+			summary:    "GraphQL query over relay packages, transactions and votes",
+			body: &bodySchema{properties: []param{
+				{name: "query", typ: paramTypeString, required: true},
+				{name: "operationName", typ: paramTypeString},
+				{name: "variables", typ: paramTypeObject},
+			}},
+			handler: s.handleGraphQL,
+		},
+	}
+	return s
+}
+
+func (s *AdminServer) Start() {
+	cfg := s.config.AdminConfig
+	server := &http.Server{
+		Addr:    fmt.Sprintf(":%d", cfg.APIPort),
+		Handler: s.Handler(),
+	}
+	if cfg.TLSCertFile == "" {
+		logging.Logger.Infof("admin API listens on port %d without TLS", cfg.APIPort)
+		if err := server.ListenAndServe(); err != nil {
+			panic(err)
+		}
+		return
+	}
+	tlsConfig, err := newTLSConfig(&cfg)
+	if err != nil {
+		panic(err)
+	}
+	server.TLSConfig = tlsConfig
+	if err := server.ListenAndServeTLS("", ""); err != nil {
+		panic(err)
+	}
+}
+
+// newTLSConfig loads the server certificate, and the client CA if mutual TLS is enabled
+func newTLSConfig(cfg *config.AdminConfig) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if cfg.ClientCAFile != "" {
+		caCert, err := os.ReadFile(cfg.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no valid certificate found in %s", cfg.ClientCAFile)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return tlsConfig, nil
+}
+
+func (s *AdminServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	for path, r := range s.routes {
+		mux.Handle(path, s.wrap(r))
+	}
+	// the spec is served without authentication so that integrators can generate clients
+	mux.HandleFunc(OpenAPIPath, s.handleOpenAPI)
+	return mux
+}
+
+// wrap authenticates and authorizes the request before calling the handler, and records the request in audit log
+func (s *AdminServer) wrap(r route) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		rw := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		identity := anonymousIdentity
+		defer func() {
+			s.audit(identity, req, rw.status)
+		}()
+
+		if err := req.ParseForm(); err != nil {
+			writeError(rw, http.StatusBadRequest, err)
+			return
+		}
+		client := s.auth.authenticate(req)
+		if client == nil {
+			writeError(rw, http.StatusUnauthorized, fmt.Errorf("unauthenticated"))
+			return
+		}
+		identity = client.Name
+		if !hasPermission(client, r.permission) {
+			writeError(rw, http.StatusForbidden, fmt.Errorf("client %s has no %s permission", client.Name, r.permission))
+			return
+		}
+		if req.Method != r.method {
+			writeError(rw, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", req.Method))
+			return
+		}
+		if err := r.validate(req); err != nil {
+			writeError(rw, http.StatusBadRequest, err)
+			return
+		}
+		r.handler(rw, req)
+	})
+}
+
+func (s *AdminServer) audit(identity string, req *http.Request, status int) {
+	err := s.daoManager.AdminDao.SaveAuditLog(&model.AdminAuditLog{
+		Identity:    identity,
+		Method:      req.Method,
+		Path:        req.URL.Path,
+		Params:      req.Form.Encode(),
+		StatusCode:  status,
+		RemoteAddr:  req.RemoteAddr,
+		CreatedTime: time.Now().Unix(),
+	})
+	if err != nil {
+		logging.Logger.Errorf("failed to save admin audit log, identity=%s, path=%s, err=%s", identity, req.URL.Path, err.Error())
+	}
+}
+
+func (s *AdminServer) handleStatus(w http.ResponseWriter, _ *http.Request) {
+	gnfdBlock, err := s.readDaoManager.GreenfieldDao.GetLatestBlock()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	bscBlock, err := s.readDaoManager.BSCDao.GetLatestBlock()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	// names of the monitored channels by the registered payload decoders
+	channels := make(map[string]string, len(s.config.GreenfieldConfig.MonitorChannelList))
+	for _, c := range s.config.GreenfieldConfig.MonitorChannelList {
+		channels[strconv.Itoa(int(c))] = payload.ChannelName(c)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"greenfield_saved_block_height": gnfdBlock.Height,
+		"bsc_saved_block_height":        bscBlock.Height,
+		"channels":                      channels,
+	})
+}
+
+func (s *AdminServer) handleAuditLogs(w http.ResponseWriter, req *http.Request) {
+	limit := DefaultAuditLogLimit
+	if l := req.Form.Get("limit"); l != "" {
+		parsed, err := strconv.Atoi(l)
+		if err != nil || parsed <= 0 || parsed > MaxAuditLogLimit {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("limit should be within (0, %d]", MaxAuditLogLimit))
+			return
+		}
+		limit = parsed
+	}
+	logs, err := s.readDaoManager.AdminDao.GetAuditLogs(limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, logs)
+}
+
+// handleBackfill starts backfilling asynchronously, the progress can be followed in logs
+func (s *AdminServer) handleBackfill(w http.ResponseWriter, req *http.Request) {
+	chain := req.Form.Get(config.FlagBackfillChain)
+	from, err := strconv.ParseUint(req.Form.Get(config.FlagBackfillFrom), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid from height"))
+		return
+	}
+	to, err := strconv.ParseUint(req.Form.Get(config.FlagBackfillTo), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid to height"))
+		return
+	}
+	if chain != config.ChainGreenfield && chain != config.ChainBSC {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unexpected chain %s", chain))
+		return
+	}
+	if from > to {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("from height %d should not be larger than to height %d", from, to))
+		return
+	}
+	go func() {
+		if err := s.backfiller.Backfill(chain, from, to); err != nil {
+			logging.Logger.Errorf("backfill %s from %d to %d failed, err=%s", chain, from, to, err.Error())
+			return
+		}
+		logging.Logger.Infof("backfill %s from %d to %d finished", chain, from, to)
+	}()
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "started"})
+}
+
+// handleSkipSequence marks the packages of the sequence as skipped, delivered ones are kept
+func (s *AdminServer) handleSkipSequence(w http.ResponseWriter, req *http.Request) {
+	direction := req.Form.Get("direction")
+	sequence, err := strconv.ParseUint(req.Form.Get("sequence"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid sequence"))
+		return
+	}
+	if req.Form.Get("confirm") != "yes" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("confirm should be yes"))
+		return
+	}
+	var skipped int64
+	switch direction {
+	case metric.DirectionBSCToGnfd:
+		skipped, err = s.daoManager.BSCDao.SkipPackages(sequence)
+	case metric.DirectionGnfdToBSC:
+		channelId, parseErr := strconv.ParseUint(req.Form.Get("channel_id"), 10, 8)
+		if parseErr != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid channel_id"))
+			return
+		}
+		skipped, err = s.daoManager.GreenfieldDao.SkipTransaction(types.ChannelId(channelId), sequence)
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unexpected direction %s", direction))
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if skipped == 0 {
+		writeError(w, http.StatusNotFound, fmt.Errorf("no undelivered package of sequence %d", sequence))
+		return
+	}
+	logging.Logger.Infof("skipped %d packages of %s sequence %d", skipped, direction, sequence)
+	writeJSON(w, http.StatusOK, map[string]int64{"skipped": skipped})
+}
+
+func (s *AdminServer) handleRetrySequence(w http.ResponseWriter, req *http.Request) {
+	direction := req.Form.Get("direction")
+	sequence, err := strconv.ParseUint(req.Form.Get("sequence"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid sequence"))
+		return
+	}
+	var retried int64
+	switch direction {
+	case metric.DirectionBSCToGnfd:
+		retried, err = s.daoManager.BSCDao.RetryPackages(sequence)
+	case metric.DirectionGnfdToBSC:
+		channelId, parseErr := strconv.ParseUint(req.Form.Get("channel_id"), 10, 8)
+		if parseErr != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid channel_id"))
+			return
+		}
+		retried, err = s.daoManager.GreenfieldDao.RetryTransaction(types.ChannelId(channelId), sequence)
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unexpected direction %s", direction))
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if retried == 0 {
+		writeError(w, http.StatusNotFound, fmt.Errorf("no package of sequence %d needs attention", sequence))
+		return
+	}
+	logging.Logger.Infof("retry %d packages of %s sequence %d", retried, direction, sequence)
+	writeJSON(w, http.StatusOK, map[string]int64{"retried": retried})
+}
+
+func (s *AdminServer) handleScheduledTasks(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, common.GetScheduler().Tasks())
+}
+
+func (s *AdminServer) handleScheduledTask(w http.ResponseWriter, req *http.Request) {
+	name := req.Form.Get("name")
+	task := common.GetScheduler().Task(name)
+	if task == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("task %s is not scheduled", name))
+		return
+	}
+	switch action := req.Form.Get("action"); action {
+	case TaskActionPause:
+		task.Pause()
+	case TaskActionResume:
+		task.Resume()
+	case TaskActionSetInterval:
+		interval, err := strconv.ParseInt(req.Form.Get("interval_in_millisecond"), 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid interval_in_millisecond"))
+			return
+		}
+		if err := task.SetInterval(time.Duration(interval) * time.Millisecond); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unexpected action %s", action))
+		return
+	}
+	logging.Logger.Infof("%s scheduled task %s", req.Form.Get("action"), name)
+	writeJSON(w, http.StatusOK, task.Status())
+}
+
+// handleRPCTrace enables or stops tracing of the subsystem, and returns the traces still enabled
+func (s *AdminServer) handleRPCTrace(w http.ResponseWriter, req *http.Request) {
+	duration, err := strconv.ParseUint(req.Form.Get("duration_in_second"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid duration_in_second"))
+		return
+	}
+	if err := executor.EnableRPCTrace(req.Form.Get("subsystem"), time.Duration(duration)*time.Second); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, executor.RPCTraces())
+}
+
+func (s *AdminServer) handlePeerStats(w http.ResponseWriter, req *http.Request) {
+	since := time.Now().Add(-DefaultPeerStatsWindow).Unix()
+	if v := req.Form.Get("since"); v != "" {
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid since"))
+			return
+		}
+		since = parsed
+	}
+	stats, err := s.readDaoManager.PeerDao.GetRelayerStats(req.Form.Get("direction"), since)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
+
+func (s *AdminServer) handleVoteLatencyStats(w http.ResponseWriter, req *http.Request) {
+	since := time.Now().Add(-DefaultVoteLatencyStatsWindow).Unix()
+	if v := req.Form.Get("since"); v != "" {
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid since"))
+			return
+		}
+		since = parsed
+	}
+	stats, err := s.readDaoManager.VoteDao.GetValidatorVoteLatencyStats(req.Form.Get("direction"), since)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
+
+func (s *AdminServer) handleClaimDiagnostics(w http.ResponseWriter, req *http.Request) {
+	var (
+		channelId *uint8
+		sequence  *uint64
+	)
+	if v := req.Form.Get("channel_id"); v != "" {
+		parsed, err := strconv.ParseUint(v, 10, 8)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid channel_id"))
+			return
+		}
+		id := uint8(parsed)
+		channelId = &id
+	}
+	if v := req.Form.Get("sequence"); v != "" {
+		parsed, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid sequence"))
+			return
+		}
+		sequence = &parsed
+	}
+	limit := DefaultClaimDiagnosticLimit
+	if l := req.Form.Get("limit"); l != "" {
+		parsed, err := strconv.Atoi(l)
+		if err != nil || parsed <= 0 || parsed > MaxClaimDiagnosticLimit {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("limit should be within (0, %d]", MaxClaimDiagnosticLimit))
+			return
+		}
+		limit = parsed
+	}
+	diagnostics, err := s.readDaoManager.DiagnosticDao.GetClaimDiagnostics(req.Form.Get("direction"), channelId, sequence, limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, diagnostics)
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Flush supports streaming responses
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// handleGrafanaDashboard returns a dashboard with a panel of each metric exported at /metrics, to be imported to Grafana
+func (s *AdminServer) handleGrafanaDashboard(w http.ResponseWriter, _ *http.Request) {
+	families := metric.Families()
+	if len(families) == 0 {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("metrics are not registered"))
+		return
+	}
+	writeJSON(w, http.StatusOK, metric.Dashboard(families))
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logging.Logger.Errorf("failed to write admin API response, err=%s", err.Error())
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,68 @@
+package admin
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/bnb-chain/greenfield-relayer/config"
+)
+
+const (
+	APIKeyHeader      = "X-API-Key"
+	anonymousIdentity = "anonymous"
+)
+
+type authenticator struct {
+	clients []config.AdminClient
+}
+
+func newAuthenticator(clients []config.AdminClient) *authenticator {
+	return &authenticator{clients: clients}
+}
+
+// authenticate returns the client of the request, a verified client certificate takes precedence over api key.
+// Returns nil if the request can not be authenticated.
+func (a *authenticator) authenticate(req *http.Request) *config.AdminClient {
+	if req.TLS != nil && len(req.TLS.VerifiedChains) > 0 {
+		return a.authenticateCommonName(req.TLS.VerifiedChains[0][0].Subject.CommonName)
+	}
+	key := req.Header.Get(APIKeyHeader)
+	if key == "" {
+		key = strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	}
+	return a.authenticateKey(key)
+}
+
+func (a *authenticator) authenticateCommonName(cn string) *config.AdminClient {
+	for i := range a.clients {
+		if a.clients[i].Name == cn {
+			return &a.clients[i]
+		}
+	}
+	return nil
+}
+
+func (a *authenticator) authenticateKey(key string) *config.AdminClient {
+	if key == "" {
+		return nil
+	}
+	var matched *config.AdminClient
+	// compare with every key in constant time so that the response time does not leak the key
+	for i := range a.clients {
+		if a.clients[i].APIKey != "" && subtle.ConstantTimeCompare([]byte(a.clients[i].APIKey), []byte(key)) == 1 {
+			matched = &a.clients[i]
+		}
+	}
+	return matched
+}
+
+// hasPermission checks whether the client is granted the permission, write permission implies read permission
+func hasPermission(client *config.AdminClient, permission string) bool {
+	for _, p := range client.Permissions {
+		if p == permission || p == config.AdminPermissionWrite {
+			return true
+		}
+	}
+	return false
+}
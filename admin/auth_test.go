@@ -0,0 +1,36 @@
+package admin
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-relayer/config"
+)
+
+func TestAuthenticateAndAuthorize(t *testing.T) {
+	a := newAuthenticator([]config.AdminClient{
+		{Name: "viewer", APIKey: "viewer-key", Permissions: []string{config.AdminPermissionRead}},
+		{Name: "operator", APIKey: "operator-key", Permissions: []string{config.AdminPermissionWrite}},
+	})
+
+	req := httptest.NewRequest("GET", "/admin/status", nil)
+	require.Nil(t, a.authenticate(req))
+
+	req.Header.Set(APIKeyHeader, "wrong-key")
+	require.Nil(t, a.authenticate(req))
+
+	req.Header.Set(APIKeyHeader, "viewer-key")
+	client := a.authenticate(req)
+	require.Equal(t, "viewer", client.Name)
+	require.True(t, hasPermission(client, config.AdminPermissionRead))
+	require.False(t, hasPermission(client, config.AdminPermissionWrite))
+
+	req = httptest.NewRequest("POST", "/admin/backfill", nil)
+	req.Header.Set("Authorization", "Bearer operator-key")
+	client = a.authenticate(req)
+	require.Equal(t, "operator", client.Name)
+	require.True(t, hasPermission(client, config.AdminPermissionRead))
+	require.True(t, hasPermission(client, config.AdminPermissionWrite))
+}
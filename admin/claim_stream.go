@@ -0,0 +1,85 @@
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bnb-chain/greenfield-relayer/events"
+)
+
+// ClaimStreamKeepAliveInterval is the interval of comments sent on an idle claim stream, so that proxies keep the
+// connection open
+const ClaimStreamKeepAliveInterval = 15 * time.Second
+
+// claimEventTypes are the events streamed by /admin/claim_stream, turn boundaries are included so that gaps between
+// claims can be correlated with them
+var claimEventTypes = map[string]bool{
+	events.EventTypeClaimAttempt:  true,
+	events.EventTypeClaim:         true,
+	events.EventTypeClaimFailure:  true,
+	events.EventTypeInturnEnter:   true,
+	events.EventTypeInturnLeave:   true,
+	events.EventTypeSequenceReset: true,
+}
+
+// handleClaimStream streams claim attempts and their results as server-sent events until the client disconnects. A
+// client which falls more than EventStreamBufferSize events behind receives an error event and should reconnect.
+func (s *AdminServer) handleClaimStream(w http.ResponseWriter, req *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok || s.eventBus == nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("streaming is not supported"))
+		return
+	}
+	filter := &eventFilter{direction: req.Form.Get("direction")}
+	if c := req.Form.Get("channel_id"); c != "" {
+		channelId, err := strconv.ParseUint(c, 10, 8)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid channel_id"))
+			return
+		}
+		id := uint8(channelId)
+		filter.channelId = &id
+	}
+
+	sub := s.eventBus.Subscribe(EventStreamBufferSize)
+	defer s.eventBus.Unsubscribe(sub)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(ClaimStreamKeepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-req.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case e, ok := <-sub.Events():
+			if !ok {
+				if sub.Overflowed() {
+					fmt.Fprint(w, "event: error\ndata: {\"error\":\"client is too slow, events are dropped, please reconnect\"}\n\n")
+					flusher.Flush()
+				}
+				return
+			}
+			if !claimEventTypes[e.Type] || !filter.match(e) {
+				continue
+			}
+			data, err := json.Marshal(e)
+			if err != nil {
+				return
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
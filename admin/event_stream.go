@@ -0,0 +1,191 @@
+package admin
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/bnb-chain/greenfield-relayer/config"
+	"github.com/bnb-chain/greenfield-relayer/events"
+	"github.com/bnb-chain/greenfield-relayer/logging"
+)
+
+const (
+	relayEventServiceName = "greenfield.relayer.RelayEventService"
+
+	// EventStreamBufferSize is the number of events buffered for a subscriber, a subscriber falling further behind is
+	// disconnected with ResourceExhausted and should resubscribe
+	EventStreamBufferSize = 1024
+
+	apiKeyMetadata = "x-api-key"
+)
+
+// EventStreamServer serves the gRPC service defined in relay_event.proto. Requests and events are
+// google.protobuf.Struct so that clients can be generated from the proto file with well-known types only.
+type EventStreamServer struct {
+	config   *config.Config
+	eventBus *events.Bus
+	auth     *authenticator
+}
+
+func NewEventStreamServer(cfg *config.Config, eventBus *events.Bus) *EventStreamServer {
+	return &EventStreamServer{
+		config:   cfg,
+		eventBus: eventBus,
+		auth:     newAuthenticator(cfg.AdminConfig.Clients),
+	}
+}
+
+func (s *EventStreamServer) Start() {
+	cfg := s.config.AdminConfig
+	var opts []grpc.ServerOption
+	if cfg.TLSCertFile != "" {
+		tlsConfig, err := newTLSConfig(&cfg)
+		if err != nil {
+			panic(err)
+		}
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	server := grpc.NewServer(opts...)
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: relayEventServiceName,
+		HandlerType: (*interface{})(nil),
+		Streams: []grpc.StreamDesc{
+			{StreamName: "WatchPackages", Handler: s.watchHandler(events.EventTypePackage), ServerStreams: true},
+			{StreamName: "WatchClaims", Handler: s.watchHandler(events.EventTypeClaim), ServerStreams: true},
+		},
+		Metadata: "relay_event.proto",
+	}, s)
+
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GRPCPort))
+	if err != nil {
+		panic(err)
+	}
+	logging.Logger.Infof("relay event stream listens on port %d", cfg.GRPCPort)
+	if err := server.Serve(lis); err != nil {
+		panic(err)
+	}
+}
+
+// watchHandler streams events of the type to the client, optionally filtered by "direction" and "channel_id" in the
+// request. Sending blocks when the client does not read fast enough, events are buffered meanwhile.
+func (s *EventStreamServer) watchHandler(eventType string) grpc.StreamHandler {
+	return func(_ interface{}, stream grpc.ServerStream) error {
+		client := s.authenticate(stream)
+		if client == nil {
+			return status.Error(codes.Unauthenticated, "unauthenticated")
+		}
+		if !hasPermission(client, config.AdminPermissionRead) {
+			return status.Errorf(codes.PermissionDenied, "client %s has no %s permission", client.Name, config.AdminPermissionRead)
+		}
+		req := &structpb.Struct{}
+		if err := stream.RecvMsg(req); err != nil {
+			return err
+		}
+		filter, err := newEventFilter(req)
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		sub := s.eventBus.Subscribe(EventStreamBufferSize)
+		defer s.eventBus.Unsubscribe(sub)
+		for {
+			select {
+			case <-stream.Context().Done():
+				return nil
+			case e, ok := <-sub.Events():
+				if !ok {
+					if sub.Overflowed() {
+						return status.Error(codes.ResourceExhausted, "client is too slow, events are dropped, please resubscribe")
+					}
+					return nil
+				}
+				if e.Type != eventType || !filter.match(e) {
+					continue
+				}
+				msg, err := structpb.NewStruct(map[string]interface{}{
+					"type":            e.Type,
+					"direction":       e.Direction,
+					"channel_id":      uint32(e.ChannelId),
+					"sequence":        e.Sequence,
+					"oracle_sequence": e.OracleSequence,
+					"package_type":    e.PackageType,
+					"height":          e.Height,
+					"tx_hash":         e.TxHash,
+					"claim_tx_hash":   e.ClaimTxHash,
+					"delivered":       e.Delivered,
+					"time":            e.Time,
+				})
+				if err != nil {
+					return status.Error(codes.Internal, err.Error())
+				}
+				if err := stream.SendMsg(msg); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// authenticate identifies the client by verified client certificate, or by api key in metadata
+func (s *EventStreamServer) authenticate(stream grpc.ServerStream) *config.AdminClient {
+	if p, ok := peer.FromContext(stream.Context()); ok {
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(tlsInfo.State.VerifiedChains) > 0 {
+			return s.auth.authenticateCommonName(tlsInfo.State.VerifiedChains[0][0].Subject.CommonName)
+		}
+	}
+	md, ok := metadata.FromIncomingContext(stream.Context())
+	if !ok {
+		return nil
+	}
+	if keys := md.Get(apiKeyMetadata); len(keys) > 0 {
+		return s.auth.authenticateKey(keys[0])
+	}
+	if values := md.Get("authorization"); len(values) > 0 {
+		return s.auth.authenticateKey(strings.TrimPrefix(values[0], "Bearer "))
+	}
+	return nil
+}
+
+type eventFilter struct {
+	direction string
+	channelId *uint8
+}
+
+func newEventFilter(req *structpb.Struct) (*eventFilter, error) {
+	f := &eventFilter{}
+	for name, v := range req.GetFields() {
+		switch name {
+		case "direction":
+			f.direction = v.GetStringValue()
+		case "channel_id":
+			n := v.GetNumberValue()
+			if n < 0 || n > 255 || n != float64(uint8(n)) {
+				return nil, fmt.Errorf("invalid channel_id")
+			}
+			c := uint8(n)
+			f.channelId = &c
+		default:
+			return nil, fmt.Errorf("unknown filter %s", name)
+		}
+	}
+	return f, nil
+}
+
+func (f *eventFilter) match(e *events.Event) bool {
+	if f.direction != "" && f.direction != e.Direction {
+		return false
+	}
+	if f.channelId != nil && *f.channelId != e.ChannelId {
+		return false
+	}
+	return true
+}
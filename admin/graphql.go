@@ -0,0 +1,280 @@
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+
+	"github.com/bnb-chain/greenfield-relayer/db"
+	"github.com/bnb-chain/greenfield-relayer/db/dao"
+	"github.com/bnb-chain/greenfield-relayer/db/model"
+	"github.com/bnb-chain/greenfield-relayer/listener"
+)
+
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// uint64Scalar is the type of sequences, heights and timestamps, which overflow the 32 bits Int of GraphQL
+var uint64Scalar = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "Uint64",
+	Description: "Unsigned 64 bits integer, given as an integer or a decimal string",
+	Serialize: func(value interface{}) interface{} {
+		v := reflect.ValueOf(value)
+		switch v.Kind() {
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return v.Uint()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if v.Int() >= 0 {
+				return uint64(v.Int())
+			}
+		}
+		return nil
+	},
+	ParseValue: func(value interface{}) interface{} {
+		switch v := value.(type) {
+		case float64: // numbers in variables are decoded as float64
+			if v >= 0 && v == float64(uint64(v)) {
+				return uint64(v)
+			}
+		case string:
+			return parseUint64(v)
+		}
+		return nil
+	},
+	ParseLiteral: func(valueAST ast.Value) interface{} {
+		switch v := valueAST.(type) {
+		case *ast.IntValue:
+			return parseUint64(v.Value)
+		case *ast.StringValue:
+			return parseUint64(v.Value)
+		}
+		return nil
+	},
+})
+
+// parseUint64 returns nil for an invalid value, which GraphQL reports as an argument of the wrong type
+func parseUint64(s string) interface{} {
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return nil
+	}
+	return v
+}
+
+// statusField resolves db.TxStatus, whose named type is not coerced to Int by the default resolver
+func statusField(status func(source interface{}) db.TxStatus) *graphql.Field {
+	return &graphql.Field{
+		Type:        graphql.Int,
+		Description: "0 saved, 1 self voted, 2 all voted, 3 delivered, 4 skipped, 5 needs attention, 6 claimed",
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return int(status(p.Source)), nil
+		},
+	}
+}
+
+// relayFilterArgs are the arguments of relay packages and transactions, see dao.RelayFilter
+var relayFilterArgs = graphql.FieldConfigArgument{
+	"channelId":    {Type: graphql.Int},
+	"fromSequence": {Type: uint64Scalar, Description: "inclusive"},
+	"toSequence":   {Type: uint64Scalar, Description: "inclusive"},
+	"status":       {Type: graphql.Int},
+	"fromTime":     {Type: uint64Scalar, Description: "tx time in unix second, inclusive"},
+	"toTime":       {Type: uint64Scalar, Description: "tx time in unix second, inclusive"},
+	"limit":        {Type: graphql.Int, Description: fmt.Sprintf("at most %d", dao.MaxQueryLimit)},
+}
+
+// newGraphQLSchema builds the read only schema over relay packages, transactions and votes. Fields of the objects are
+// resolved from the model structs by name.
+func (s *AdminServer) newGraphQLSchema() (graphql.Schema, error) {
+	bscPackage := graphql.NewObject(graphql.ObjectConfig{
+		Name: "BscPackage",
+		Fields: graphql.Fields{
+			"id":              {Type: uint64Scalar},
+			"channelId":       {Type: graphql.Int},
+			"oracleSequence":  {Type: uint64Scalar},
+			"packageSequence": {Type: uint64Scalar},
+			"payload":         {Type: graphql.String},
+			"txIndex":         {Type: graphql.Int},
+			"txHash":          {Type: graphql.String},
+			"claimTxHash":     {Type: graphql.String},
+			"height":          {Type: uint64Scalar},
+			"status":          statusField(func(source interface{}) db.TxStatus { return source.(*model.BscRelayPackage).Status }),
+			"txTime":          {Type: uint64Scalar},
+			"updatedTime":     {Type: uint64Scalar},
+			"claimFailures":   {Type: graphql.Int},
+		},
+	})
+	greenfieldTransaction := graphql.NewObject(graphql.ObjectConfig{
+		Name: "GreenfieldTransaction",
+		Fields: graphql.Fields{
+			"id":            {Type: uint64Scalar},
+			"srcChainId":    {Type: graphql.Int},
+			"destChainId":   {Type: graphql.Int},
+			"channelId":     {Type: graphql.Int},
+			"sequence":      {Type: uint64Scalar},
+			"packageType":   {Type: graphql.Int},
+			"height":        {Type: uint64Scalar},
+			"payload":       {Type: graphql.String},
+			"relayerFee":    {Type: graphql.String},
+			"ackRelayerFee": {Type: graphql.String},
+			"claimedTxHash": {Type: graphql.String},
+			"status":        statusField(func(source interface{}) db.TxStatus { return source.(*model.GreenfieldRelayTransaction).Status }),
+			"txTime":        {Type: uint64Scalar},
+			"updatedTime":   {Type: uint64Scalar},
+			"resourceType":  {Type: graphql.String},
+			"resourceId":    {Type: graphql.String},
+			"claimGasUsed":  {Type: uint64Scalar},
+			"claimGasPrice": {Type: graphql.String},
+			"claimFailures": {Type: graphql.Int},
+		},
+	})
+	vote := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Vote",
+		Fields: graphql.Fields{
+			"id":          {Type: uint64Scalar},
+			"channelId":   {Type: graphql.Int},
+			"sequence":    {Type: uint64Scalar},
+			"eventType":   {Type: graphql.Int},
+			"pubKey":      {Type: graphql.String},
+			"signature":   {Type: graphql.String},
+			"createdTime": {Type: uint64Scalar},
+		},
+	})
+
+	greenfieldTransactionArgs := graphql.FieldConfigArgument{
+		"resourceType": {Type: graphql.String, Description: fmt.Sprintf("%s, %s or %s", listener.ResourceTypeBucket, listener.ResourceTypeObject, listener.ResourceTypeGroup)},
+		"resourceId":   {Type: graphql.String},
+	}
+	for name, arg := range relayFilterArgs {
+		greenfieldTransactionArgs[name] = arg
+	}
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"bscPackages": {
+				Type:        graphql.NewList(bscPackage),
+				Description: "Relay packages from BSC, ordered by package sequence",
+				Args:        relayFilterArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					filter, err := relayFilterFromArgs(p.Args)
+					if err != nil {
+						return nil, err
+					}
+					return s.readDaoManager.BSCDao.QueryPackages(filter)
+				},
+			},
+			"greenfieldTransactions": {
+				Type:        graphql.NewList(greenfieldTransaction),
+				Description: "Relay transactions from Greenfield, ordered by sequence",
+				Args:        greenfieldTransactionArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					filter, err := relayFilterFromArgs(p.Args)
+					if err != nil {
+						return nil, err
+					}
+					if v, ok := p.Args["resourceType"].(string); ok {
+						filter.ResourceType = &v
+					}
+					if v, ok := p.Args["resourceId"].(string); ok {
+						filter.ResourceId = &v
+					}
+					return s.readDaoManager.GreenfieldDao.QueryTransactions(filter)
+				},
+			},
+			"votes": {
+				Type:        graphql.NewList(vote),
+				Description: "Votes of a package",
+				Args: graphql.FieldConfigArgument{
+					"channelId": {Type: graphql.NewNonNull(graphql.Int)},
+					"sequence":  {Type: graphql.NewNonNull(uint64Scalar)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					channelId, err := channelIdArg(p.Args)
+					if err != nil {
+						return nil, err
+					}
+					return s.readDaoManager.VoteDao.GetVotesByChannelIdAndSequence(*channelId, p.Args["sequence"].(uint64))
+				},
+			},
+		},
+	})
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query})
+}
+
+// handleGraphQL serves read only queries over relay packages, transactions and votes, e.g.
+//
+//	{ bscPackages(channelId: 1, fromSequence: 10, toSequence: 20, status: 3) { packageSequence txHash claimTxHash } }
+func (s *AdminServer) handleGraphQL(w http.ResponseWriter, req *http.Request) {
+	var gqlReq graphQLRequest
+	if err := json.NewDecoder(req.Body).Decode(&gqlReq); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	result := graphql.Do(graphql.Params{
+		Schema:         s.graphQLSchema,
+		RequestString:  gqlReq.Query,
+		OperationName:  gqlReq.OperationName,
+		VariableValues: gqlReq.Variables,
+		Context:        req.Context(),
+	})
+	writeJSON(w, http.StatusOK, result)
+}
+
+func relayFilterFromArgs(args map[string]interface{}) (*dao.RelayFilter, error) {
+	filter := &dao.RelayFilter{}
+	var err error
+	if filter.ChannelId, err = channelIdArg(args); err != nil {
+		return nil, err
+	}
+	if v, ok := args["fromSequence"].(uint64); ok {
+		filter.FromSequence = &v
+	}
+	if v, ok := args["toSequence"].(uint64); ok {
+		filter.ToSequence = &v
+	}
+	if v, ok := args["status"].(int); ok {
+		if v < 0 || v > 255 {
+			return nil, fmt.Errorf("status %d is out of range", v)
+		}
+		status := db.TxStatus(v)
+		filter.Status = &status
+	}
+	for name, target := range map[string]**int64{"fromTime": &filter.FromTime, "toTime": &filter.ToTime} {
+		if v, ok := args[name].(uint64); ok {
+			if v > math.MaxInt64 {
+				return nil, fmt.Errorf("%s %d is out of range", name, v)
+			}
+			t := int64(v)
+			*target = &t
+		}
+	}
+	if v, ok := args["limit"].(int); ok {
+		if v < 1 || v > dao.MaxQueryLimit {
+			return nil, fmt.Errorf("limit should be between 1 and %d", dao.MaxQueryLimit)
+		}
+		filter.Limit = v
+	}
+	return filter, nil
+}
+
+// channelIdArg reads the channelId argument, nil is returned if it is absent
+func channelIdArg(args map[string]interface{}) (*uint8, error) {
+	v, ok := args["channelId"].(int)
+	if !ok {
+		return nil, nil
+	}
+	if v < 0 || v > 255 {
+		return nil, fmt.Errorf("channelId %d is out of range", v)
+	}
+	channelId := uint8(v)
+	return &channelId, nil
+}
@@ -0,0 +1,54 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-relayer/config"
+	"github.com/bnb-chain/greenfield-relayer/db"
+)
+
+func TestRelayFilterFromArgs(t *testing.T) {
+	filter, err := relayFilterFromArgs(map[string]interface{}{"channelId": 1, "fromSequence": uint64(5), "status": 3, "toTime": uint64(100)})
+	require.NoError(t, err)
+	require.Equal(t, uint8(1), *filter.ChannelId)
+	require.Equal(t, uint64(5), *filter.FromSequence)
+	require.Nil(t, filter.ToSequence)
+	require.Equal(t, db.Delivered, *filter.Status)
+	require.Equal(t, int64(100), *filter.ToTime)
+	require.Nil(t, filter.FromTime)
+
+	_, err = relayFilterFromArgs(map[string]interface{}{"channelId": 256})
+	require.Error(t, err)
+	_, err = relayFilterFromArgs(map[string]interface{}{"limit": 0})
+	require.Error(t, err)
+}
+
+// queries rejected by the schema are answered without touching the DB
+func TestGraphQLValidation(t *testing.T) {
+	s := NewAdminServer(&config.Config{}, nil, nil, nil, nil)
+	for query, message := range map[string]string{
+		`{ votes(channelId: 1) { id } }`:                    "argument \"sequence\" of type \"Uint64!\" is required",
+		`{ bscPackages(fromSequence: -1) { id } }`:          "Expected type \"Uint64\"",
+		`{ bscPackages { unknownField } }`:                  "Cannot query field \"unknownField\"",
+		`mutation { bscPackages { id } }`:                   "not configured for mutations",
+		`{ greenfieldTransactions(channelId: "a") { id } }`: "Expected type \"Int\"",
+	} {
+		body, err := json.Marshal(graphQLRequest{Query: query})
+		require.NoError(t, err)
+		w := httptest.NewRecorder()
+		s.handleGraphQL(w, httptest.NewRequest("POST", "/admin/graphql", strings.NewReader(string(body))))
+		var resp struct {
+			Errors []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		require.NotEmpty(t, resp.Errors, query)
+		require.Contains(t, resp.Errors[0].Message, message, query)
+	}
+}
@@ -0,0 +1,226 @@
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	OpenAPIPath    = "/admin/openapi.json"
+	openAPIVersion = "3.0.3"
+
+	paramTypeInteger = "integer"
+	paramTypeString  = "string"
+	paramTypeObject  = "object"
+
+	maxRequestBodySize = 1 << 20
+)
+
+// param describes a query parameter or a property of a JSON body, it is used both to generate the OpenAPI spec and to
+// validate requests
+type param struct {
+	name        string
+	typ         string
+	required    bool
+	description string
+	enum        []string
+	min         uint64
+	max         uint64 // 0 means unbounded
+}
+
+type bodySchema struct {
+	properties []param
+}
+
+// validate checks the request against the parameters and body schema of the route, unknown parameters are rejected
+func (r *route) validate(req *http.Request) error {
+	known := make(map[string]bool, len(r.params))
+	for _, p := range r.params {
+		known[p.name] = true
+		values, ok := req.Form[p.name]
+		if !ok || len(values) == 0 {
+			if p.required {
+				return fmt.Errorf("parameter %s is required", p.name)
+			}
+			continue
+		}
+		if len(values) > 1 {
+			return fmt.Errorf("parameter %s should be provided once", p.name)
+		}
+		if err := p.validateString(values[0]); err != nil {
+			return err
+		}
+	}
+	for name := range req.Form {
+		if !known[name] {
+			return fmt.Errorf("unknown parameter %s", name)
+		}
+	}
+	if r.body != nil {
+		return r.body.validate(req)
+	}
+	return nil
+}
+
+func (p *param) validateString(v string) error {
+	switch p.typ {
+	case paramTypeInteger:
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return fmt.Errorf("parameter %s should be an unsigned integer", p.name)
+		}
+		if n < p.min || (p.max != 0 && n > p.max) {
+			return fmt.Errorf("parameter %s is out of range", p.name)
+		}
+	case paramTypeString:
+		if len(p.enum) != 0 && !contains(p.enum, v) {
+			return fmt.Errorf("parameter %s should be one of %s", p.name, strings.Join(p.enum, ", "))
+		}
+	}
+	return nil
+}
+
+// validate checks the JSON body of the request, the body is restored so that it can be read again by the handler
+func (b *bodySchema) validate(req *http.Request) error {
+	bz, err := io.ReadAll(io.LimitReader(req.Body, maxRequestBodySize))
+	if err != nil {
+		return err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(bz))
+	var body map[string]interface{}
+	if err := json.Unmarshal(bz, &body); err != nil {
+		return fmt.Errorf("body should be a JSON object")
+	}
+	known := make(map[string]bool, len(b.properties))
+	for _, p := range b.properties {
+		known[p.name] = true
+		v, ok := body[p.name]
+		if !ok || v == nil {
+			if p.required {
+				return fmt.Errorf("property %s is required", p.name)
+			}
+			continue
+		}
+		valid := true
+		switch p.typ {
+		case paramTypeString:
+			_, valid = v.(string)
+		case paramTypeInteger:
+			n, isNumber := v.(float64)
+			valid = isNumber && n >= 0 && n == float64(uint64(n))
+		case paramTypeObject:
+			_, valid = v.(map[string]interface{})
+		}
+		if !valid {
+			return fmt.Errorf("property %s should be of type %s", p.name, p.typ)
+		}
+	}
+	for name := range body {
+		if !known[name] {
+			return fmt.Errorf("unknown property %s", name)
+		}
+	}
+	return nil
+}
+
+func (s *AdminServer) handleOpenAPI(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, s.openAPISpec())
+}
+
+// openAPISpec generates the OpenAPI document from the route definitions
+func (s *AdminServer) openAPISpec() map[string]interface{} {
+	paths := make(map[string]interface{}, len(s.routes))
+	for path, r := range s.routes {
+		op := map[string]interface{}{
+			"summary":     r.summary,
+			"description": fmt.Sprintf("requires %s permission", r.permission),
+			"responses": map[string]interface{}{
+				"200": map[string]interface{}{"description": "OK"},
+				"400": map[string]interface{}{"description": "invalid request"},
+				"401": map[string]interface{}{"description": "unauthenticated"},
+				"403": map[string]interface{}{"description": "permission denied"},
+			},
+		}
+		if len(r.params) != 0 {
+			params := make([]interface{}, 0, len(r.params))
+			for _, p := range r.params {
+				params = append(params, map[string]interface{}{
+					"name":        p.name,
+					"in":          "query",
+					"required":    p.required,
+					"description": p.description,
+					"schema":      p.schema(),
+				})
+			}
+			op["parameters"] = params
+		}
+		if r.body != nil {
+			props := make(map[string]interface{}, len(r.body.properties))
+			required := make([]string, 0)
+			for _, p := range r.body.properties {
+				props[p.name] = p.schema()
+				if p.required {
+					required = append(required, p.name)
+				}
+			}
+			sort.Strings(required)
+			op["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{
+						"schema": map[string]interface{}{
+							"type":                 paramTypeObject,
+							"properties":           props,
+							"required":             required,
+							"additionalProperties": false,
+						},
+					},
+				},
+			}
+		}
+		paths[path] = map[string]interface{}{strings.ToLower(r.method): op}
+	}
+	return map[string]interface{}{
+		"openapi": openAPIVersion,
+		"info": map[string]interface{}{
+			"title":   "greenfield-relayer admin API",
+			"version": "1.0.0",
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"securitySchemes": map[string]interface{}{
+				"apiKey": map[string]interface{}{"type": "apiKey", "in": "header", "name": APIKeyHeader},
+			},
+		},
+		"security": []interface{}{map[string]interface{}{"apiKey": []string{}}},
+	}
+}
+
+func (p *param) schema() map[string]interface{} {
+	schema := map[string]interface{}{"type": p.typ}
+	if len(p.enum) != 0 {
+		schema["enum"] = p.enum
+	}
+	if p.typ == paramTypeInteger {
+		schema["minimum"] = p.min
+		if p.max != 0 {
+			schema["maximum"] = p.max
+		}
+	}
+	return schema
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
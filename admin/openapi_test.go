@@ -0,0 +1,44 @@
+package admin
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-relayer/config"
+)
+
+func TestRouteValidate(t *testing.T) {
+	s := NewAdminServer(&config.Config{}, nil, nil, nil, nil)
+
+	backfill := s.routes["/admin/backfill"]
+	for query, valid := range map[string]bool{
+		"chain=bsc&from=1&to=10":         true,
+		"chain=bsc&from=1":               false,
+		"chain=eth&from=1&to=10":         false,
+		"chain=bsc&from=-1&to=10":        false,
+		"chain=bsc&from=1&to=10&extra=1": false,
+	} {
+		req := httptest.NewRequest("POST", "/admin/backfill?"+query, nil)
+		require.NoError(t, req.ParseForm())
+		require.Equal(t, valid, backfill.validate(req) == nil, query)
+	}
+
+	graphql := s.routes["/admin/graphql"]
+	for body, valid := range map[string]bool{
+		`{"query": "{ votes { id } }"}`:                  true,
+		`{"query": "{ votes { id } }", "variables": {}}`: true,
+		`{"variables": {}}`:                              false,
+		`{"query": 1}`:                                   false,
+		`[]`:                                             false,
+	} {
+		req := httptest.NewRequest("POST", "/admin/graphql", strings.NewReader(body))
+		require.NoError(t, req.ParseForm())
+		require.Equal(t, valid, graphql.validate(req) == nil, body)
+	}
+
+	spec := s.openAPISpec()
+	require.Len(t, spec["paths"], len(s.routes))
+}
@@ -3,18 +3,27 @@ package app
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/bnb-chain/greenfield-relayer/admin"
 	"github.com/bnb-chain/greenfield-relayer/assembler"
+	"github.com/bnb-chain/greenfield-relayer/canary"
+	relayercommon "github.com/bnb-chain/greenfield-relayer/common"
 	"github.com/bnb-chain/greenfield-relayer/config"
+	"github.com/bnb-chain/greenfield-relayer/coordinator"
+	relayerdb "github.com/bnb-chain/greenfield-relayer/db"
 	"github.com/bnb-chain/greenfield-relayer/db/dao"
 	"github.com/bnb-chain/greenfield-relayer/db/model"
+	"github.com/bnb-chain/greenfield-relayer/events"
 	"github.com/bnb-chain/greenfield-relayer/executor"
+	"github.com/bnb-chain/greenfield-relayer/exporter"
+	"github.com/bnb-chain/greenfield-relayer/hook"
+	"github.com/bnb-chain/greenfield-relayer/journal"
 	"github.com/bnb-chain/greenfield-relayer/listener"
+	"github.com/bnb-chain/greenfield-relayer/logging"
 	"github.com/bnb-chain/greenfield-relayer/metric"
 	"github.com/bnb-chain/greenfield-relayer/relayer"
 	"github.com/bnb-chain/greenfield-relayer/vote"
 	"github.com/spf13/viper"
 	"gorm.io/driver/mysql"
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 	"log"
@@ -23,12 +32,169 @@ import (
 )
 
 type App struct {
+	config        *config.Config
 	BSCRelayer    *relayer.BSCRelayer
 	GnfdRelayer   *relayer.GreenfieldRelayer
 	metricService *metric.MetricService
+	adminServer   *admin.AdminServer
+	eventServer   *admin.EventStreamServer
+	exporter      *exporter.Exporter
+	coordinator   *coordinator.Coordinator
+	canary        *canary.Canary
+	voteLag       *vote.LagMonitor
+	heightLag     *listener.HeightLagMonitor
+	votePool      *vote.PoolMonitor
+	voteGossip    *vote.GossipMonitor
+	validator     *vote.ValidatorMonitor
+	blackout      *vote.BlackoutMonitor
+	channel       *vote.ChannelMonitor
+	roleExecutors []*executor.BSCExecutor // BSC executors of roles with dedicated endpoints, their clients are switched separately
+	lockDao       *dao.LockDao
+
+	// used by the startup work of Start, which runs before the relayers start
+	daoManager         *dao.DaoManager
+	greenfieldExecutor *executor.GreenfieldExecutor
+	bscExecutor        *executor.BSCExecutor
+	bscAssembler       *assembler.BSCAssembler
+	claimJournal       *journal.ClaimJournal
 }
 
 func NewApp(cfg *config.Config) *App {
+	daoManager, readDaoManager := newDaoManagers(cfg)
+	if cfg.DBConfig.PackageCacheSize >= 0 {
+		cacheSize := relayercommon.DefaultPackageCacheSize
+		if cfg.DBConfig.PackageCacheSize > 0 {
+			cacheSize = cfg.DBConfig.PackageCacheSize
+		}
+		daoManager.EnableCache(cacheSize)
+	}
+
+	greenfieldExecutor, bscExecutor := newExecutors(cfg)
+	if cfg.RelayConfig.BSCToGreenfieldEnabled() {
+		greenfieldExecutor.ValidateRelayerDelegation()
+	}
+
+	metricService := metric.NewMetricService(cfg)
+
+	retryBudget := relayercommon.DefaultRetryBudgetPerMinute
+	if cfg.RelayConfig.RetryBudgetPerMinute > 0 {
+		retryBudget = cfg.RelayConfig.RetryBudgetPerMinute
+	}
+	relayercommon.SetRetryBudget(relayercommon.NewRetryBudget(retryBudget, metricService.ObserveRetry))
+	relayercommon.SetScheduler(relayercommon.NewScheduler(cfg.RelayConfig.SchedulerJitterPercent, metricService.ObserveScheduledTask))
+	hooks, err := hook.NewHooks(cfg.Hooks)
+	if err != nil {
+		panic(fmt.Sprintf("load hooks error, err=%s", err.Error()))
+	}
+	hook.SetHooks(hooks)
+	greenfieldExecutor.SetClaimSimulationObserver(metricService.ObserveClaimSimulation)
+	bscExecutor.SetGasSpentObserver(metricService.SetBSCGasSpent)
+	relayerdb.RegisterTransitionHook(func(t *relayerdb.Transition) {
+		metricService.AddStatusTransitions(t.Chain, t.To.String(), t.Count)
+	})
+
+	// report what the relayer is about to do after downtime, failures are not fatal
+	if report, err := newReconciliationReport(cfg, readDaoManager, greenfieldExecutor, bscExecutor); err != nil {
+		logging.Logger.Errorf("failed to reconcile DB with chains, err=%s", err.Error())
+	} else {
+		logging.Logger.Info(report.String())
+	}
+
+	// vote signer
+	signer := vote.NewVoteSigner(greenfieldExecutor.BlsPrivateKey)
+
+	eventBus := events.NewBus()
+
+	// coordinator among non-inturn relayers, all claims are allowed if disabled
+	var claimCoordinator *coordinator.Coordinator
+	if cfg.CoordinationConfig.Enabled {
+		claimCoordinator = coordinator.NewCoordinator(cfg, signer, greenfieldExecutor.GetValidatorsBlsPublicKey)
+	}
+
+	// journal of broadcast claims, in-flight claims missing in DB are recovered from it when the relayer starts
+	var claimJournal *journal.ClaimJournal
+	if cfg.RelayConfig.ClaimJournalPath != "" {
+		claimJournal, err = journal.NewClaimJournal(cfg.RelayConfig.ClaimJournalPath)
+		if err != nil {
+			panic(fmt.Sprintf("open claim journal error, err=%s", err.Error()))
+		}
+	}
+
+	// executors of the roles, which use dedicated endpoints if they are configured
+	var roleBSCExecutors []*executor.BSCExecutor
+	forRole := func(role string) (*executor.GreenfieldExecutor, *executor.BSCExecutor) {
+		g, b := greenfieldExecutor.ForRole(role), bscExecutor.ForRole(role)
+		if g != greenfieldExecutor {
+			g.SetBSCExecutor(b)
+		}
+		if b != bscExecutor {
+			b.SetGreenfieldExecutor(g)
+			roleBSCExecutors = append(roleBSCExecutors, b)
+		}
+		return g, b
+	}
+	listenerGnfdExecutor, listenerBSCExecutor := forRole(config.RoleListener)
+	voteGnfdExecutor, voteBSCExecutor := forRole(config.RoleVote)
+	assemblerGnfdExecutor, assemblerBSCExecutor := forRole(config.RoleAssembler)
+
+	// listeners
+	greenfieldListener := listener.NewGreenfieldListener(cfg, listenerGnfdExecutor, listenerBSCExecutor, daoManager, metricService, eventBus)
+	bscListener := listener.NewBSCListener(cfg, listenerBSCExecutor, listenerGnfdExecutor, daoManager, metricService, eventBus)
+
+	// voteProcessors, packages are verified against source chains by listeners before voted
+	greenfieldVoteProcessor := vote.NewGreenfieldVoteProcessor(cfg, daoManager, signer, voteGnfdExecutor, greenfieldListener, metricService)
+	bscVoteProcessor := vote.NewBSCVoteProcessor(cfg, daoManager, signer, voteBSCExecutor, bscListener, metricService)
+
+	// assemblers
+	greenfieldAssembler := assembler.NewGreenfieldAssembler(cfg, assemblerGnfdExecutor, daoManager, assemblerBSCExecutor, metricService, eventBus, claimCoordinator, claimJournal)
+	bscAssembler := assembler.NewBSCAssembler(cfg, assemblerBSCExecutor, daoManager, assemblerGnfdExecutor, metricService, eventBus, claimCoordinator, claimJournal)
+
+	// relayers
+	gnfdRelayer := relayer.NewGreenfieldRelayer(greenfieldListener, greenfieldExecutor, bscExecutor, greenfieldVoteProcessor, greenfieldAssembler)
+	bscRelayer := relayer.NewBSCRelayer(bscListener, greenfieldExecutor, bscExecutor, bscVoteProcessor, bscAssembler)
+
+	a := &App{
+		config:        cfg,
+		BSCRelayer:    bscRelayer,
+		GnfdRelayer:   gnfdRelayer,
+		metricService: metricService,
+		coordinator:   claimCoordinator,
+		voteLag:       vote.NewLagMonitor(cfg, readDaoManager, metricService),
+		heightLag:     listener.NewHeightLagMonitor(cfg, readDaoManager, greenfieldExecutor, bscExecutor, metricService),
+		votePool:      vote.NewPoolMonitor(cfg, readDaoManager, greenfieldExecutor, metricService),
+		voteGossip:    vote.NewGossipMonitor(cfg, readDaoManager, greenfieldExecutor, metricService),
+		validator:     vote.NewValidatorMonitor(cfg, greenfieldExecutor, metricService),
+		channel:       vote.NewChannelMonitor(cfg, greenfieldExecutor, bscExecutor, metricService),
+		roleExecutors: roleBSCExecutors,
+		lockDao:       daoManager.LockDao,
+
+		daoManager:         daoManager,
+		greenfieldExecutor: greenfieldExecutor,
+		bscExecutor:        bscExecutor,
+		bscAssembler:       bscAssembler,
+		claimJournal:       claimJournal,
+	}
+	if cfg.AdminConfig.APIPort != 0 {
+		a.adminServer = admin.NewAdminServer(cfg, daoManager, readDaoManager, a, eventBus)
+	}
+	if cfg.AdminConfig.GRPCPort != 0 {
+		a.eventServer = admin.NewEventStreamServer(cfg, eventBus)
+	}
+	if cfg.ExportConfig.Enabled {
+		a.exporter = exporter.NewExporter(cfg, daoManager)
+	}
+	if cfg.CanaryConfig.Enabled {
+		a.canary = canary.NewCanary(cfg, greenfieldExecutor, bscExecutor, metricService)
+	}
+	if len(cfg.RelayConfig.BlackoutWindows) > 0 {
+		a.blackout = vote.NewBlackoutMonitor(cfg)
+	}
+	return a
+}
+
+// newDaoManagers opens the database and creates the tables, it returns the dao manager of the primary and the one
+// heavy read queries of admin API and backlog computation go to, which is of the replica if configured
+func newDaoManagers(cfg *config.Config) (*dao.DaoManager, *dao.DaoManager) {
 	username := cfg.DBConfig.Username
 	password := viper.GetString(config.FlagConfigDbPass)
 	if password == "" {
@@ -43,21 +209,55 @@ func NewApp(cfg *config.Config) *App {
 			Colorful:                  true,          // Disable color
 		},
 	)
-	var db *gorm.DB
-	var err error
-	var dialector gorm.Dialector
+	db := openDB(&cfg.DBConfig, username, password, cfg.DBConfig.Url, newLogger)
 
-	if cfg.DBConfig.Dialect == config.DBDialectMysql {
-		url := cfg.DBConfig.Url
+	model.SetTablePrefix(cfg.DBConfig.TablePrefix)
+	model.InitBSCTables(db)
+	model.InitGreenfieldTables(db)
+	model.InitVoteTables(db)
+	model.InitAdminTables(db)
+	model.InitExportTables(db)
+	model.InitPeerTables(db)
+	model.InitCheckpointTables(db)
+	model.InitDiagnosticTables(db)
+	model.InitNonceTables(db)
+	model.InitLockTables(db)
+	model.InitWatermarkTables(db)
+
+	daoManager := dao.NewDaoManager(dao.NewGreenfieldDao(db), dao.NewBSCDao(db), dao.NewVoteDao(db), dao.NewAdminDao(db),
+		dao.NewExportDao(db), dao.NewPeerDao(db), dao.NewDiagnosticDao(db), dao.NewNonceDao(db), dao.NewLockDao(db))
+	readDaoManager := daoManager
+	if cfg.DBConfig.ReplicaUrl != "" {
+		replica := openDB(&cfg.DBConfig, username, password, cfg.DBConfig.ReplicaUrl, newLogger)
+		readDaoManager = dao.NewDaoManager(dao.NewGreenfieldDao(replica), dao.NewBSCDao(replica), dao.NewVoteDao(replica),
+			dao.NewAdminDao(replica), dao.NewExportDao(replica), dao.NewPeerDao(replica), dao.NewDiagnosticDao(replica),
+			dao.NewNonceDao(replica), dao.NewLockDao(replica))
+	}
+	return daoManager, readDaoManager
+}
+
+// newExecutors creates the executors of both chains and links them with each other
+func newExecutors(cfg *config.Config) (*executor.GreenfieldExecutor, *executor.BSCExecutor) {
+	greenfieldExecutor := executor.NewGreenfieldExecutor(cfg)
+	bscExecutor := executor.NewBSCExecutor(cfg)
+	greenfieldExecutor.SetBSCExecutor(bscExecutor)
+	bscExecutor.SetGreenfieldExecutor(greenfieldExecutor)
+	return greenfieldExecutor, bscExecutor
+}
+
+// openDB opens the database at url and applies the connection settings of the db config
+func openDB(cfg *config.DBConfig, username, password, url string, dbLogger logger.Interface) *gorm.DB {
+	var dialector gorm.Dialector
+	if cfg.Dialect == config.DBDialectMysql {
 		dbPath := fmt.Sprintf("%s:%s@%s", username, password, url)
 		dialector = mysql.Open(dbPath)
-	} else if cfg.DBConfig.Dialect == config.DBDialectSqlite3 {
-		dialector = sqlite.Open(cfg.DBConfig.Url)
+	} else if cfg.Dialect == config.DBDialectSqlite3 {
+		dialector = openSqlite(url)
 	} else {
-		panic(fmt.Sprintf("unexpected DB dialect %s", cfg.DBConfig.Dialect))
+		panic(fmt.Sprintf("unexpected DB dialect %s", cfg.Dialect))
 	}
-	db, err = gorm.Open(dialector, &gorm.Config{
-		Logger: newLogger,
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger: dbLogger,
 	})
 	if err != nil {
 		panic(fmt.Sprintf("open db error, err=%s", err.Error()))
@@ -67,58 +267,76 @@ func NewApp(cfg *config.Config) *App {
 		panic(err)
 	}
 
-	dbConfig.SetMaxIdleConns(cfg.DBConfig.MaxIdleConns)
-	dbConfig.SetMaxOpenConns(cfg.DBConfig.MaxOpenConns)
-
-	model.InitBSCTables(db)
-	model.InitGreenfieldTables(db)
-	model.InitVoteTables(db)
-
-	greenfieldDao := dao.NewGreenfieldDao(db)
-	bscDao := dao.NewBSCDao(db)
-	voteDao := dao.NewVoteDao(db)
-	daoManager := dao.NewDaoManager(greenfieldDao, bscDao, voteDao)
-
-	greenfieldExecutor := executor.NewGreenfieldExecutor(cfg)
-	bscExecutor := executor.NewBSCExecutor(cfg)
-
-	greenfieldExecutor.SetBSCExecutor(bscExecutor)
-	bscExecutor.SetGreenfieldExecutor(greenfieldExecutor)
-
-	metricService := metric.NewMetricService(cfg)
-
-	// vote signer
-	signer := vote.NewVoteSigner(greenfieldExecutor.BlsPrivateKey)
-
-	// voteProcessors
-	greenfieldVoteProcessor := vote.NewGreenfieldVoteProcessor(cfg, daoManager, signer, greenfieldExecutor)
-	bscVoteProcessor := vote.NewBSCVoteProcessor(cfg, daoManager, signer, bscExecutor)
-
-	// listeners
-	greenfieldListener := listener.NewGreenfieldListener(cfg, greenfieldExecutor, bscExecutor, daoManager, metricService)
-	bscListener := listener.NewBSCListener(cfg, bscExecutor, greenfieldExecutor, daoManager, metricService)
-
-	// assemblers
-	greenfieldAssembler := assembler.NewGreenfieldAssembler(cfg, greenfieldExecutor, daoManager, bscExecutor, metricService)
-	bscAssembler := assembler.NewBSCAssembler(cfg, bscExecutor, daoManager, greenfieldExecutor, metricService)
-
-	// relayers
-	gnfdRelayer := relayer.NewGreenfieldRelayer(greenfieldListener, greenfieldExecutor, bscExecutor, greenfieldVoteProcessor, greenfieldAssembler)
-	bscRelayer := relayer.NewBSCRelayer(bscListener, greenfieldExecutor, bscExecutor, bscVoteProcessor, bscAssembler)
-
-	return &App{
-		BSCRelayer:    bscRelayer,
-		GnfdRelayer:   gnfdRelayer,
-		metricService: metricService,
+	if err = relayerdb.RegisterQueryTimeout(db, time.Duration(cfg.QueryTimeoutInSecond)*time.Second); err != nil {
+		panic(fmt.Sprintf("register db query timeout error, err=%s", err.Error()))
 	}
+	if cfg.Dialect == config.DBDialectMysql {
+		if err = relayerdb.RegisterFailoverRecovery(db, cfg.MaxIdleConns); err != nil {
+			panic(fmt.Sprintf("register db failover recovery error, err=%s", err.Error()))
+		}
+	}
+
+	dbConfig.SetMaxIdleConns(cfg.MaxIdleConns)
+	dbConfig.SetMaxOpenConns(cfg.MaxOpenConns)
+	return db
 }
 
 func (a *App) Start() {
-	a.GnfdRelayer.Start()
-	a.BSCRelayer.Start()
+	a.reconcileNonces()
+	if a.claimJournal != nil {
+		if err := recoverInFlightClaims(a.daoManager, a.claimJournal); err != nil {
+			panic(fmt.Sprintf("recover in-flight claims error, err=%s", err.Error()))
+		}
+	}
+	go a.drainOnSignal()
+	if a.config.RelayConfig.GreenfieldToBSCEnabled() {
+		a.GnfdRelayer.Start()
+		go a.channel.StartLoop()
+	} else {
+		logging.Logger.Info("greenfield to bsc relaying is disabled")
+	}
+	if a.config.RelayConfig.BSCToGreenfieldEnabled() {
+		a.BSCRelayer.Start()
+	} else {
+		logging.Logger.Info("bsc to greenfield relaying is disabled")
+		// claims to BSC still need the client switching of BSC executor
+		go a.BSCRelayer.UpdateClientLoop()
+	}
+	for _, e := range a.roleExecutors {
+		go e.UpdateClientLoop()
+	}
+	go a.voteLag.StartLoop()
+	go a.heightLag.StartLoop()
+	go a.votePool.StartLoop()
+	go a.voteGossip.StartLoop()
+	go a.validator.StartLoop()
+	go a.cleanupSequenceLocksLoop()
+	if a.adminServer != nil {
+		go a.adminServer.Start()
+	}
+	if a.eventServer != nil {
+		go a.eventServer.Start()
+	}
+	if a.coordinator != nil {
+		go a.coordinator.Start()
+	}
+	if a.exporter != nil {
+		go a.exporter.StartLoop()
+	}
+	if a.canary != nil {
+		go a.canary.StartLoop()
+	}
+	if a.blackout != nil {
+		go a.blackout.StartLoop()
+	}
 	a.metricService.Start()
 }
 
+// Backfill re-scans the height range [from, to] of the chain and saves missing cross-chain packages into DB
+func (a *App) Backfill(chain string, from, to uint64) error {
+	return backfill(a.GnfdRelayer.Listener, a.BSCRelayer.Listener, chain, from, to)
+}
+
 func getDBPass(cfg *config.DBConfig) string {
 	if cfg.KeyType == config.KeyTypeAWSPrivateKey {
 		result, err := config.GetSecret(cfg.AWSSecretName, cfg.AWSRegion)
@@ -0,0 +1,49 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/bnb-chain/greenfield-relayer/config"
+	"github.com/bnb-chain/greenfield-relayer/events"
+	"github.com/bnb-chain/greenfield-relayer/listener"
+	"github.com/bnb-chain/greenfield-relayer/metric"
+)
+
+// Backfiller re-scans height ranges of the chains next to a running relayer. It is built from the DB, the executors
+// and the listeners only, so that none of the startup work of the relayer, e.g. nonce reconciliation and claim journal
+// recovery, runs against the state of the live relayer.
+type Backfiller struct {
+	greenfieldListener *listener.GreenfieldListener
+	bscListener        *listener.BSCListener
+}
+
+func NewBackfiller(cfg *config.Config) *Backfiller {
+	daoManager, _ := newDaoManagers(cfg)
+	greenfieldExecutor, bscExecutor := newExecutors(cfg)
+	// the metric service is never started, the listeners only need it to record what they observe
+	metricService := metric.NewMetricService(cfg)
+	eventBus := events.NewBus()
+	return &Backfiller{
+		greenfieldListener: listener.NewGreenfieldListener(cfg, greenfieldExecutor, bscExecutor, daoManager, metricService, eventBus),
+		bscListener:        listener.NewBSCListener(cfg, bscExecutor, greenfieldExecutor, daoManager, metricService, eventBus),
+	}
+}
+
+// Backfill re-scans the height range [from, to] of the chain and saves missing cross-chain packages into DB
+func (b *Backfiller) Backfill(chain string, from, to uint64) error {
+	return backfill(b.greenfieldListener, b.bscListener, chain, from, to)
+}
+
+func backfill(greenfieldListener *listener.GreenfieldListener, bscListener *listener.BSCListener, chain string, from, to uint64) error {
+	if from > to {
+		return fmt.Errorf("from height %d should not be larger than to height %d", from, to)
+	}
+	switch chain {
+	case config.ChainGreenfield:
+		return greenfieldListener.Backfill(from, to)
+	case config.ChainBSC:
+		return bscListener.Backfill(from, to)
+	default:
+		return fmt.Errorf("unexpected chain %s, only %s and %s supported", chain, config.ChainGreenfield, config.ChainBSC)
+	}
+}
@@ -0,0 +1,68 @@
+package app
+
+import (
+	"time"
+
+	relayercommon "github.com/bnb-chain/greenfield-relayer/common"
+	"github.com/bnb-chain/greenfield-relayer/db/dao"
+	"github.com/bnb-chain/greenfield-relayer/journal"
+	"github.com/bnb-chain/greenfield-relayer/logging"
+	"github.com/bnb-chain/greenfield-relayer/metric"
+	"github.com/bnb-chain/greenfield-relayer/types"
+)
+
+// recoverInFlightClaims restores claim tx hashes journaled but lost in DB, e.g. by a DB outage followed by a restart,
+// so that the relayer is aware of its in-flight claims, and drops journal entries beyond the retention
+func recoverInFlightClaims(daoManager *dao.DaoManager, claimJournal *journal.ClaimJournal) error {
+	entries, err := claimJournal.Entries()
+	if err != nil {
+		return err
+	}
+	since := time.Now().Add(-relayercommon.ClaimJournalRetention)
+	for _, e := range entries {
+		if e.Time < since.Unix() {
+			continue
+		}
+		switch e.Direction {
+		case metric.DirectionGnfdToBSC:
+			tx, err := daoManager.GreenfieldDao.GetTransactionByChannelIdAndSequence(types.ChannelId(e.ChannelId), e.Sequence)
+			if err != nil {
+				return err
+			}
+			if tx.Id == 0 {
+				logging.Logger.Errorf("journaled claim tx %s of channel %d and sequence %d is missing in DB", e.TxHash, e.ChannelId, e.Sequence)
+				continue
+			}
+			if tx.ClaimedTxHash != "" {
+				continue
+			}
+			if err = daoManager.GreenfieldDao.UpdateTransactionClaimedTxHash(tx.Id, e.TxHash); err != nil {
+				return err
+			}
+		case metric.DirectionBSCToGnfd:
+			pkgs, err := daoManager.BSCDao.GetPackagesByOracleSequence(e.Sequence)
+			if err != nil {
+				return err
+			}
+			if len(pkgs) == 0 {
+				logging.Logger.Errorf("journaled claim tx %s of oracle sequence %d is missing in DB", e.TxHash, e.Sequence)
+				continue
+			}
+			if pkgs[0].ClaimTxHash != "" {
+				continue
+			}
+			var pkgIds []int64
+			for _, p := range pkgs {
+				pkgIds = append(pkgIds, p.Id)
+			}
+			if err = daoManager.BSCDao.UpdateBatchPackagesClaimedTxHash(pkgIds, e.TxHash); err != nil {
+				return err
+			}
+		default:
+			continue
+		}
+		logging.Logger.Infof("recovered in-flight claim tx %s of %s, channel %d, sequence %d and nonce %d from journal",
+			e.TxHash, e.Direction, e.ChannelId, e.Sequence, e.Nonce)
+	}
+	return claimJournal.Compact(since)
+}
@@ -0,0 +1,23 @@
+package app
+
+import (
+	"time"
+
+	relayercommon "github.com/bnb-chain/greenfield-relayer/common"
+	"github.com/bnb-chain/greenfield-relayer/logging"
+)
+
+// cleanupSequenceLocksLoop deletes expired sequence locks at startup and then periodically, locks are kept after a
+// claim succeeds so that they would pile up otherwise
+func (a *App) cleanupSequenceLocksLoop() {
+	relayercommon.Schedule(relayercommon.TaskSequenceLockCleanup, relayercommon.SequenceLockCleanupInterval, func() {
+		deleted, err := a.lockDao.DeleteExpiredSequenceLocks(time.Now().Unix())
+		if err != nil {
+			logging.Logger.Errorf("failed to delete expired sequence locks, err=%s", err.Error())
+			return
+		}
+		if deleted != 0 {
+			logging.Logger.Debugf("deleted %d expired sequence locks", deleted)
+		}
+	}).RunNow()
+}
@@ -0,0 +1,182 @@
+package app
+
+import (
+	"time"
+
+	relayercommon "github.com/bnb-chain/greenfield-relayer/common"
+	"github.com/bnb-chain/greenfield-relayer/config"
+	"github.com/bnb-chain/greenfield-relayer/db"
+	"github.com/bnb-chain/greenfield-relayer/db/dao"
+	"github.com/bnb-chain/greenfield-relayer/db/model"
+	"github.com/bnb-chain/greenfield-relayer/executor"
+	"github.com/bnb-chain/greenfield-relayer/logging"
+	"github.com/bnb-chain/greenfield-relayer/metric"
+	"github.com/bnb-chain/greenfield-relayer/types"
+)
+
+// reservedClaims are the claims of a direction which reserved nonces of the relayer's account
+type reservedClaims struct {
+	nextNonce func() (uint64, error)
+	// pendingCounted tells whether the next nonce counts txs in the mempool, e.g. the pending nonce of BSC, otherwise
+	// claims broadcast but not included yet still hold their nonces
+	pendingCounted bool
+	// resolve marks the packages of a claim whose nonce is used on chain as delivered if the sequence is delivered,
+	// it returns whether the sequence is delivered
+	resolve func(r *model.NonceReservation) (bool, error)
+}
+
+// reconcileNonces reconciles the nonce reservations before the assemblers start, so that they start from nonces which
+// are not held by in-flight claims
+func (a *App) reconcileNonces() {
+	startNonces, err := reconcileNonceReservations(a.config, a.daoManager, a.greenfieldExecutor, a.bscExecutor)
+	if err != nil {
+		logging.Logger.Errorf("failed to reconcile nonce reservations, err=%s", err.Error())
+		return
+	}
+	// the pending nonce of BSC already skips claims in the mempool, while the nonce of Greenfield does not
+	if nonce, ok := startNonces[metric.DirectionBSCToGnfd]; ok {
+		a.bscAssembler.SetStartNonce(nonce)
+	}
+}
+
+// reconcileNonceReservations compares the nonces reserved by claims with the next nonces of the relayer's accounts on
+// chain after a crash. Packages of claims whose nonces are used on chain are marked delivered if their sequences are
+// delivered, others are claimed again, and settled reservations are dropped. Unbroadcast reservations within the grace
+// period are kept, since they may be held by another relayer process sharing the DB. The nonce each assembler starts from is
+// returned by direction, it skips the nonces held by claims which are broadcast but not included yet.
+func reconcileNonceReservations(cfg *config.Config, daoManager *dao.DaoManager, greenfieldExecutor *executor.GreenfieldExecutor,
+	bscExecutor *executor.BSCExecutor) (map[string]uint64, error) {
+	claims := make(map[string]*reservedClaims)
+	if cfg.RelayConfig.GreenfieldToBSCEnabled() {
+		nextSequences := make(map[uint8]uint64)
+		claims[metric.DirectionGnfdToBSC] = &reservedClaims{
+			nextNonce:      bscExecutor.GetNonce,
+			pendingCounted: true,
+			resolve: func(r *model.NonceReservation) (bool, error) {
+				next, ok := nextSequences[r.ChannelId]
+				if !ok {
+					var err error
+					if next, err = greenfieldExecutor.GetNextDeliverySequenceForChannelWithRetry(types.ChannelId(r.ChannelId)); err != nil {
+						return false, err
+					}
+					nextSequences[r.ChannelId] = next
+				}
+				if r.Sequence >= next {
+					return false, nil
+				}
+				tx, err := daoManager.GreenfieldDao.GetTransactionByChannelIdAndSequence(types.ChannelId(r.ChannelId), r.Sequence)
+				if err != nil || tx.Id == 0 {
+					return true, err
+				}
+				return true, daoManager.GreenfieldDao.UpdateTransactionStatus(tx.Id, db.Delivered)
+			},
+		}
+	}
+	if cfg.RelayConfig.BSCToGreenfieldEnabled() {
+		var nextOracleSequence *uint64
+		claims[metric.DirectionBSCToGnfd] = &reservedClaims{
+			nextNonce: greenfieldExecutor.GetNonce,
+			resolve: func(r *model.NonceReservation) (bool, error) {
+				if nextOracleSequence == nil {
+					next, err := greenfieldExecutor.GetNextReceiveOracleSequence()
+					if err != nil {
+						return false, err
+					}
+					nextOracleSequence = &next
+				}
+				if r.Sequence >= *nextOracleSequence {
+					return false, nil
+				}
+				pkgs, err := daoManager.BSCDao.GetPackagesByOracleSequence(r.Sequence)
+				if err != nil || len(pkgs) == 0 {
+					return true, err
+				}
+				ids := make([]int64, 0, len(pkgs))
+				for _, p := range pkgs {
+					ids = append(ids, p.Id)
+				}
+				return true, daoManager.BSCDao.UpdateBatchPackagesStatus(ids, db.Delivered)
+			},
+		}
+	}
+	retainedSince := time.Now().Add(-relayercommon.NonceReservationRetention).Unix()
+	graceSince := time.Now().Add(-relayercommon.NonceReservationGracePeriod).Unix()
+	startNonces := make(map[string]uint64)
+	for direction, c := range claims {
+		reservations, err := daoManager.NonceDao.GetNonceReservations(direction)
+		if err != nil {
+			return nil, err
+		}
+		if len(reservations) == 0 {
+			continue
+		}
+		next, err := c.nextNonce()
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range reservations {
+			if r.Nonce >= next || r.ReservedTime < retainedSince {
+				continue
+			}
+			delivered, err := c.resolve(r)
+			if err != nil {
+				return nil, err
+			}
+			if !delivered {
+				logging.Logger.Infof("nonce %d reserved by claim of %s, channel %d and sequence %d is used on chain but the sequence is not delivered, it is claimed again",
+					r.Nonce, r.Direction, r.ChannelId, r.Sequence)
+			}
+		}
+		startNonces[direction] = next
+		if !c.pendingCounted {
+			startNonces[direction] = startNonce(reservations, next)
+		}
+		if err = daoManager.NonceDao.DeleteNonceReservations(settledNonceReservations(reservations, next, retainedSince, graceSince)); err != nil {
+			return nil, err
+		}
+	}
+	return startNonces, nil
+}
+
+// startNonce returns the nonce to send the next claim with, it skips the nonces from the next nonce on chain which are
+// held by claims broadcast but not included yet
+func startNonce(reservations []*model.NonceReservation, nextNonce uint64) uint64 {
+	start := nextNonce
+	for _, r := range reservations {
+		if r.Nonce == start && r.TxHash != "" {
+			start++
+		}
+	}
+	return start
+}
+
+// settledNonceReservations returns ids of the reservations which need no more tracking given the next nonce on chain,
+// unbroadcast reservations made since graceSince are kept
+func settledNonceReservations(reservations []*model.NonceReservation, nextNonce uint64, retainedSince, graceSince int64) []int64 {
+	var settled []int64
+	for _, r := range reservations {
+		switch {
+		case r.TxHash == "" && r.ReservedTime >= graceSince:
+			logging.Logger.Infof("nonce %d reserved by claim of %s, channel %d and sequence %d is not broadcast yet, it is kept within the grace period",
+				r.Nonce, r.Direction, r.ChannelId, r.Sequence)
+		case r.Nonce >= nextNonce && r.TxHash == "":
+			logging.Logger.Infof("claim of %s, channel %d and sequence %d was not broadcast with reserved nonce %d, it is claimed again",
+				r.Direction, r.ChannelId, r.Sequence, r.Nonce)
+			settled = append(settled, r.Id)
+		case r.Nonce >= nextNonce:
+			logging.Logger.Infof("claim tx %s of %s, channel %d and sequence %d with nonce %d is not included yet",
+				r.TxHash, r.Direction, r.ChannelId, r.Sequence, r.Nonce)
+			if r.ReservedTime < retainedSince {
+				settled = append(settled, r.Id)
+			}
+		case r.TxHash == "":
+			// the claim was broadcast right before a crash, or the nonce was taken by another tx of the account
+			logging.Logger.Errorf("nonce %d reserved by claim of %s, channel %d and sequence %d is used on chain by an unknown tx",
+				r.Nonce, r.Direction, r.ChannelId, r.Sequence)
+			settled = append(settled, r.Id)
+		case r.ReservedTime < retainedSince:
+			settled = append(settled, r.Id)
+		}
+	}
+	return settled
+}
@@ -0,0 +1,34 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-relayer/db/model"
+)
+
+func TestSettledNonceReservations(t *testing.T) {
+	reservations := []*model.NonceReservation{
+		{Id: 1, Nonce: 8, TxHash: "0x1", ReservedTime: 50},   // included, beyond retention
+		{Id: 2, Nonce: 9, TxHash: "0x2", ReservedTime: 200},  // included
+		{Id: 3, Nonce: 10, ReservedTime: 200},                // used by an unknown tx
+		{Id: 4, Nonce: 11, TxHash: "0x4", ReservedTime: 200}, // in flight
+		{Id: 5, Nonce: 12, ReservedTime: 200},                // not broadcast
+		{Id: 6, Nonce: 13, ReservedTime: 300},                // not broadcast yet, within the grace period
+		{Id: 7, Nonce: 7, ReservedTime: 300},                 // used on chain, the tx hash may not be saved yet
+	}
+	require.Equal(t, []int64{1, 3, 5}, settledNonceReservations(reservations, 11, 100, 250))
+}
+
+func TestStartNonce(t *testing.T) {
+	reservations := []*model.NonceReservation{
+		{Id: 1, Nonce: 9, TxHash: "0x1"},  // included
+		{Id: 2, Nonce: 10, TxHash: "0x2"}, // in flight
+		{Id: 3, Nonce: 11, TxHash: "0x3"}, // in flight
+		{Id: 4, Nonce: 12},                // not broadcast
+		{Id: 5, Nonce: 13, TxHash: "0x5"}, // stuck behind the gap
+	}
+	require.Equal(t, uint64(12), startNonce(reservations, 10))
+	require.Equal(t, uint64(14), startNonce(reservations, 14))
+}
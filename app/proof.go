@@ -0,0 +1,172 @@
+package app
+
+import (
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+
+	relayercommon "github.com/bnb-chain/greenfield-relayer/common"
+	"github.com/bnb-chain/greenfield-relayer/config"
+	"github.com/bnb-chain/greenfield-relayer/db/dao"
+	"github.com/bnb-chain/greenfield-relayer/db/model"
+	"github.com/bnb-chain/greenfield-relayer/executor"
+	"github.com/bnb-chain/greenfield-relayer/logging"
+	"github.com/bnb-chain/greenfield-relayer/types"
+	"github.com/bnb-chain/greenfield-relayer/util"
+	"github.com/bnb-chain/greenfield-relayer/vote"
+)
+
+// ProofBundle is a self-contained proof that a cross-chain message was relayed, it carries the source event, the votes
+// of validators, the aggregated signature and the claim tx, so that it can be checked by counterparties with verify-claim
+type ProofBundle struct {
+	Chain               string                            `json:"chain"` // source chain of the message
+	ChannelId           uint8                             `json:"channel_id"`
+	Sequence            uint64                            `json:"sequence"` // oracle sequence for bsc
+	GreenfieldEvent     *model.GreenfieldRelayTransaction `json:"greenfield_event,omitempty"`
+	BSCEvents           []*model.BscRelayPackage          `json:"bsc_events,omitempty"`
+	EventHash           string                            `json:"event_hash"`
+	ClaimPayload        string                            `json:"claim_payload"`
+	Votes               []*ProofVote                      `json:"votes"`
+	Validators          []string                          `json:"validators"` // hex encoded BLS keys in bitset order
+	AggregatedSignature string                            `json:"aggregated_signature"`
+	ValidatorBitSet     string                            `json:"validator_bitset"`
+	ClaimTx             *ProofClaimTx                     `json:"claim_tx,omitempty"`
+	ExportedTime        int64                             `json:"exported_time"`
+}
+
+type ProofVote struct {
+	PubKey      string `json:"pub_key"`
+	Signature   string `json:"signature"`
+	CreatedTime int64  `json:"created_time"`
+}
+
+// ProofClaimTx is the claim tx on the destination chain, its result is omitted if the tx can not be found on chain
+type ProofClaimTx struct {
+	Hash    string `json:"hash"`
+	Height  uint64 `json:"height,omitempty"`
+	Success *bool  `json:"success,omitempty"`
+}
+
+// ProofExporter builds proof bundles from the DB and the chains, it is built from the DB and the executors only since
+// exporting a proof must not change anything shared with a running relayer
+type ProofExporter struct {
+	daoManager         *dao.DaoManager
+	greenfieldExecutor *executor.GreenfieldExecutor
+	bscExecutor        *executor.BSCExecutor
+}
+
+func NewProofExporter(cfg *config.Config) *ProofExporter {
+	_, readDaoManager := newDaoManagers(cfg)
+	greenfieldExecutor, bscExecutor := newExecutors(cfg)
+	return &ProofExporter{
+		daoManager:         readDaoManager,
+		greenfieldExecutor: greenfieldExecutor,
+		bscExecutor:        bscExecutor,
+	}
+}
+
+// ExportProof builds the proof bundle of the message of the channel and sequence sent from the chain, the bitset is
+// computed against the current validators, which are the BSC relayers for messages from Greenfield and the Greenfield
+// validators for messages from BSC
+func (e *ProofExporter) ExportProof(chain string, channelId uint8, sequence uint64) (*ProofBundle, error) {
+	bundle := &ProofBundle{
+		Chain:        chain,
+		ChannelId:    channelId,
+		Sequence:     sequence,
+		ExportedTime: time.Now().Unix(),
+	}
+	var (
+		validators  interface{}
+		claimTxHash string
+	)
+	switch chain {
+	case config.ChainGreenfield:
+		tx, err := e.daoManager.GreenfieldDao.GetTransactionByChannelIdAndSequence(types.ChannelId(channelId), sequence)
+		if err != nil {
+			return nil, err
+		}
+		if tx.Id == 0 {
+			return nil, fmt.Errorf("no package with channel id %d and sequence %d in db", channelId, sequence)
+		}
+		bundle.GreenfieldEvent = tx
+		claimTxHash = tx.ClaimedTxHash
+		validators, err = e.bscExecutor.QueryCachedLatestValidators()
+		if err != nil {
+			return nil, err
+		}
+	case config.ChainBSC:
+		bundle.ChannelId = uint8(relayercommon.OracleChannelId)
+		pkgs, err := e.daoManager.BSCDao.GetPackagesByOracleSequence(sequence)
+		if err != nil {
+			return nil, err
+		}
+		if len(pkgs) == 0 {
+			return nil, fmt.Errorf("no packages with oracle sequence %d in db", sequence)
+		}
+		bundle.BSCEvents = pkgs
+		claimTxHash = pkgs[0].ClaimTxHash
+		validators, err = e.greenfieldExecutor.QueryCachedLatestValidators()
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unexpected chain %s, only %s and %s supported", chain, config.ChainGreenfield, config.ChainBSC)
+	}
+
+	votes, err := e.daoManager.VoteDao.GetVotesByChannelIdAndSequence(bundle.ChannelId, sequence)
+	if err != nil {
+		return nil, err
+	}
+	if len(votes) == 0 {
+		return nil, fmt.Errorf("no votes for channel id %d and sequence %d in db", bundle.ChannelId, sequence)
+	}
+	for _, v := range votes {
+		bundle.Votes = append(bundle.Votes, &ProofVote{PubKey: v.PubKey, Signature: v.Signature, CreatedTime: v.CreatedTime})
+	}
+	bundle.EventHash = hex.EncodeToString(votes[0].EventHash)
+	bundle.ClaimPayload = hex.EncodeToString(votes[0].ClaimPayload)
+	bundle.Validators = vote.ValidatorBlsKeys(validators)
+
+	// votes signed by keys which are not registered any more can not be verified against the current validators
+	valid, _ := vote.SplitVotesByBlsKeys(votes, bundle.Validators)
+	if len(valid) != 0 {
+		signature, valBitSet, err := vote.AggregateSignatureAndValidatorBitSet(valid, validators)
+		if err != nil {
+			return nil, err
+		}
+		bundle.AggregatedSignature = hex.EncodeToString(signature)
+		bundle.ValidatorBitSet = fmt.Sprintf("0x%x", util.BitSetToBigInt(valBitSet))
+	}
+
+	if claimTxHash != "" {
+		bundle.ClaimTx = e.proofClaimTx(chain, claimTxHash)
+	}
+	return bundle, nil
+}
+
+// proofClaimTx looks up the result of the claim tx on the destination chain, failures are not fatal since the tx could
+// be pruned by the node
+func (e *ProofExporter) proofClaimTx(chain string, txHash string) *ProofClaimTx {
+	claimTx := &ProofClaimTx{Hash: txHash}
+	if chain == config.ChainGreenfield {
+		receipt, err := e.bscExecutor.GetTransactionReceipt(common.HexToHash(txHash))
+		if err != nil {
+			logging.Logger.Errorf("failed to get receipt of claim tx %s, err=%s", txHash, err.Error())
+			return claimTx
+		}
+		success := receipt.Status == ethtypes.ReceiptStatusSuccessful
+		claimTx.Height, claimTx.Success = receipt.BlockNumber.Uint64(), &success
+		return claimTx
+	}
+	res, err := e.greenfieldExecutor.GetTx(txHash)
+	if err != nil {
+		logging.Logger.Errorf("failed to get claim tx %s, err=%s", txHash, err.Error())
+		return claimTx
+	}
+	success := res.TxResult.Code == 0
+	claimTx.Height, claimTx.Success = uint64(res.Height), &success
+	return claimTx
+}
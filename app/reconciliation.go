@@ -0,0 +1,170 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	relayercommon "github.com/bnb-chain/greenfield-relayer/common"
+	"github.com/bnb-chain/greenfield-relayer/config"
+	"github.com/bnb-chain/greenfield-relayer/db/dao"
+	"github.com/bnb-chain/greenfield-relayer/executor"
+	"github.com/bnb-chain/greenfield-relayer/metric"
+	"github.com/bnb-chain/greenfield-relayer/types"
+)
+
+// listenerReconciliation compares the latest block processed by a listener with the chain head
+type listenerReconciliation struct {
+	chain        string
+	dbHeight     uint64
+	headHeight   uint64
+	blocksBehind uint64
+}
+
+// channelReconciliation compares the packages of a channel in DB with the sequences on both chains
+type channelReconciliation struct {
+	direction        string
+	channelId        uint8
+	nextSendSeq      uint64 // on the source chain
+	nextDeliverySeq  uint64 // on the destination chain
+	undeliveredRows  int64  // rows to be replayed by the vote processor and assembler
+	deliveredRows    int64  // undelivered rows whose sequences are already delivered by others
+	toRelay          uint64
+	estimatedCatchUp time.Duration
+}
+
+// reconciliationReport describes what the relayer is about to do after downtime
+type reconciliationReport struct {
+	listeners []*listenerReconciliation
+	channels  []*channelReconciliation
+}
+
+func newReconciliationReport(cfg *config.Config, daoManager *dao.DaoManager, greenfieldExecutor *executor.GreenfieldExecutor,
+	bscExecutor *executor.BSCExecutor) (*reconciliationReport, error) {
+	report := &reconciliationReport{}
+	// disabled directions are left out, their listeners are not running
+	if cfg.RelayConfig.BSCToGreenfieldEnabled() {
+		if err := report.reconcileBSCToGreenfield(cfg, daoManager, bscExecutor); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.RelayConfig.GreenfieldToBSCEnabled() {
+		if err := report.reconcileGreenfieldToBSC(cfg, daoManager, greenfieldExecutor); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// reconcileBSCToGreenfield adds the BSC listener and the oracle channel, BSC -> Greenfield packages are relayed by
+// oracle sequence
+func (r *reconciliationReport) reconcileBSCToGreenfield(cfg *config.Config, daoManager *dao.DaoManager, bscExecutor *executor.BSCExecutor) error {
+	bscHead, err := bscExecutor.GetLatestBlockHeight()
+	if err != nil {
+		return err
+	}
+	bscBlock, err := daoManager.BSCDao.GetLatestBlock()
+	if err != nil {
+		return err
+	}
+	r.listeners = append(r.listeners, newListenerReconciliation(metric.ChainBSC, bscBlock.Height, bscHead))
+
+	gnfdLatency := relayercommon.DefaultGreenfieldClaimInclusionLatency
+	if cfg.RelayConfig.BSCToGreenfieldClaimInclusionLatency > 0 {
+		gnfdLatency = time.Duration(cfg.RelayConfig.BSCToGreenfieldClaimInclusionLatency) * time.Second
+	}
+	nextSendSeq, err := bscExecutor.GetNextSendSequenceForChannelWithRetry()
+	if err != nil {
+		return err
+	}
+	nextDeliverySeq, err := bscExecutor.GetNextDeliveryOracleSequenceWithRetry()
+	if err != nil {
+		return err
+	}
+	undelivered, delivered, err := daoManager.BSCDao.CountUndeliveredPackages(nextDeliverySeq)
+	if err != nil {
+		return err
+	}
+	r.channels = append(r.channels, newChannelReconciliation(metric.DirectionBSCToGnfd, uint8(relayercommon.OracleChannelId),
+		nextSendSeq, nextDeliverySeq, undelivered, delivered, gnfdLatency))
+	return nil
+}
+
+// reconcileGreenfieldToBSC adds the Greenfield listener and the monitored channels
+func (r *reconciliationReport) reconcileGreenfieldToBSC(cfg *config.Config, daoManager *dao.DaoManager,
+	greenfieldExecutor *executor.GreenfieldExecutor) error {
+	gnfdHead, err := greenfieldExecutor.GetLatestBlockHeight()
+	if err != nil {
+		return err
+	}
+	gnfdBlock, err := daoManager.GreenfieldDao.GetLatestBlock()
+	if err != nil {
+		return err
+	}
+	r.listeners = append(r.listeners, newListenerReconciliation(metric.ChainGreenfield, gnfdBlock.Height, gnfdHead))
+
+	bscLatency := relayercommon.DefaultBSCClaimInclusionLatency
+	if cfg.RelayConfig.GreenfieldToBSCClaimInclusionLatency > 0 {
+		bscLatency = time.Duration(cfg.RelayConfig.GreenfieldToBSCClaimInclusionLatency) * time.Second
+	}
+	for _, c := range cfg.GreenfieldConfig.MonitorChannelList {
+		channelId := types.ChannelId(c)
+		nextSendSeq, err := greenfieldExecutor.GetNextSendSequenceForChannelWithRetry(channelId)
+		if err != nil {
+			return err
+		}
+		nextDeliverySeq, err := greenfieldExecutor.GetNextDeliverySequenceForChannelWithRetry(channelId)
+		if err != nil {
+			return err
+		}
+		undelivered, delivered, err := daoManager.GreenfieldDao.CountUndeliveredTransactions(channelId, nextDeliverySeq)
+		if err != nil {
+			return err
+		}
+		r.channels = append(r.channels, newChannelReconciliation(metric.DirectionGnfdToBSC, c,
+			nextSendSeq, nextDeliverySeq, undelivered, delivered, bscLatency))
+	}
+	return nil
+}
+
+func newListenerReconciliation(chain string, dbHeight, headHeight uint64) *listenerReconciliation {
+	r := &listenerReconciliation{chain: chain, dbHeight: dbHeight, headHeight: headHeight}
+	if headHeight > dbHeight {
+		r.blocksBehind = headHeight - dbHeight
+	}
+	return r
+}
+
+// newChannelReconciliation estimates the catch-up time by claiming the sequences to relay one by one, each taking the
+// claim inclusion latency of the destination chain
+func newChannelReconciliation(direction string, channelId uint8, nextSendSeq, nextDeliverySeq uint64, undelivered, delivered int64,
+	inclusionLatency time.Duration) *channelReconciliation {
+	r := &channelReconciliation{
+		direction:       direction,
+		channelId:       channelId,
+		nextSendSeq:     nextSendSeq,
+		nextDeliverySeq: nextDeliverySeq,
+		undeliveredRows: undelivered,
+		deliveredRows:   delivered,
+	}
+	if nextSendSeq > nextDeliverySeq {
+		r.toRelay = nextSendSeq - nextDeliverySeq
+	}
+	r.estimatedCatchUp = time.Duration(r.toRelay) * inclusionLatency
+	return r
+}
+
+func (r *reconciliationReport) String() string {
+	var sb strings.Builder
+	sb.WriteString("startup reconciliation report:")
+	for _, l := range r.listeners {
+		sb.WriteString(fmt.Sprintf("\n  %s listener: db height=%d, chain head=%d, %d blocks to catch up",
+			l.chain, l.dbHeight, l.headHeight, l.blocksBehind))
+	}
+	for _, c := range r.channels {
+		sb.WriteString(fmt.Sprintf("\n  %s channel %d: next send seq=%d, next delivery seq=%d, %d sequences to relay, "+
+			"%d rows to replay, %d of them likely already delivered, estimated catch-up %s",
+			c.direction, c.channelId, c.nextSendSeq, c.nextDeliverySeq, c.toRelay, c.undeliveredRows, c.deliveredRows, c.estimatedCatchUp))
+	}
+	return sb.String()
+}
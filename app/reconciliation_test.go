@@ -0,0 +1,21 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-relayer/metric"
+)
+
+func TestChannelReconciliation(t *testing.T) {
+	c := newChannelReconciliation(metric.DirectionGnfdToBSC, 2, 120, 100, 30, 8, 6*time.Second)
+	require.Equal(t, uint64(20), c.toRelay)
+	require.Equal(t, 2*time.Minute, c.estimatedCatchUp)
+
+	// the destination chain is ahead of the source chain node
+	c = newChannelReconciliation(metric.DirectionBSCToGnfd, 0, 100, 101, 0, 0, 3*time.Second)
+	require.Equal(t, uint64(0), c.toRelay)
+	require.Equal(t, time.Duration(0), c.estimatedCatchUp)
+}
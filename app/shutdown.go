@@ -0,0 +1,91 @@
+package app
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	relayercommon "github.com/bnb-chain/greenfield-relayer/common"
+	"github.com/bnb-chain/greenfield-relayer/logging"
+)
+
+// tasks producing votes, paused first on shutdown so that no batch is left half-processed
+var voteTasks = []string{
+	relayercommon.TaskGreenfieldVoteBroadcast,
+	relayercommon.TaskGreenfieldVoteCollect,
+	relayercommon.TaskBSCVoteBroadcast,
+	relayercommon.TaskBSCVoteCollect,
+}
+
+var assemblerTasks = []string{
+	relayercommon.TaskGreenfieldAssembler,
+	relayercommon.TaskBSCAssembler,
+}
+
+// drainOnSignal drains in-flight claims and exits on SIGINT or SIGTERM
+func (a *App) drainOnSignal() {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	sig := <-sigs
+	timeout := relayercommon.DefaultShutdownDrainTimeout
+	if a.config.RelayConfig.ShutdownDrainTimeout > 0 {
+		timeout = time.Duration(a.config.RelayConfig.ShutdownDrainTimeout) * time.Second
+	}
+	logging.Logger.Infof("received %s, draining in-flight claims for up to %s", sig, timeout)
+	done := make(chan struct{})
+	go func() {
+		a.Drain(time.Now().Add(timeout))
+		close(done)
+	}()
+	select {
+	case <-done:
+		logging.Logger.Info("drained in-flight claims, exit")
+	case <-time.After(timeout):
+		logging.Logger.Info("drain timed out, exit")
+	case sig = <-sigs:
+		logging.Logger.Infof("received %s again, exit without draining", sig)
+	}
+	os.Exit(0)
+}
+
+// Drain stops voting, keeps the assemblers claiming until the in-turn relayer has no sequence with enough votes left
+// to claim in its turn or the deadline passes, and then waits for the claims in progress to finish. Sequences claimable
+// by the in-turn relayer are not handed to other relayers by a planned restart this way.
+func (a *App) Drain(deadline time.Time) {
+	pauseAndWait(voteTasks)
+	for time.Now().Before(deadline) && a.hasPendingInturnClaims() {
+		time.Sleep(relayercommon.AssembleInterval)
+	}
+	pauseAndWait(assemblerTasks)
+}
+
+func (a *App) hasPendingInturnClaims() bool {
+	if a.config.RelayConfig.GreenfieldToBSCEnabled() {
+		pending, err := a.GnfdRelayer.HasPendingInturnClaims()
+		if err != nil {
+			logging.Logger.Errorf("failed to check pending claims to BSC, err=%s", err.Error())
+		}
+		if pending {
+			return true
+		}
+	}
+	if a.config.RelayConfig.BSCToGreenfieldEnabled() {
+		pending, err := a.BSCRelayer.HasPendingInturnClaims()
+		if err != nil {
+			logging.Logger.Errorf("failed to check pending claims to Greenfield, err=%s", err.Error())
+		}
+		if pending {
+			return true
+		}
+	}
+	return false
+}
+
+func pauseAndWait(tasks []string) {
+	for _, name := range tasks {
+		if t := relayercommon.GetScheduler().Task(name); t != nil {
+			t.PauseAndWait()
+		}
+	}
+}
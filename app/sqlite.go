@@ -0,0 +1,12 @@
+//go:build cgo
+
+package app
+
+import (
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func openSqlite(url string) gorm.Dialector {
+	return sqlite.Open(url)
+}
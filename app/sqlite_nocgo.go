@@ -0,0 +1,12 @@
+//go:build !cgo
+
+package app
+
+import (
+	"gorm.io/gorm"
+)
+
+// openSqlite panics since the sqlite driver requires cgo, builds without cgo only support mysql
+func openSqlite(url string) gorm.Dialector {
+	panic("sqlite3 is not supported in builds without cgo, use mysql instead")
+}
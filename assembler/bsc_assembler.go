@@ -3,16 +3,23 @@ package assembler
 import (
 	"bytes"
 	"encoding/hex"
+	"errors"
 	"fmt"
-	sdkclient "github.com/bnb-chain/greenfield-go-sdk/client/chain"
+	"sync"
 	"time"
 
+	oracletypes "github.com/cosmos/cosmos-sdk/x/oracle/types"
+
 	"github.com/bnb-chain/greenfield-relayer/common"
 	"github.com/bnb-chain/greenfield-relayer/config"
+	"github.com/bnb-chain/greenfield-relayer/coordinator"
 	"github.com/bnb-chain/greenfield-relayer/db"
 	"github.com/bnb-chain/greenfield-relayer/db/dao"
 	"github.com/bnb-chain/greenfield-relayer/db/model"
+	"github.com/bnb-chain/greenfield-relayer/events"
 	"github.com/bnb-chain/greenfield-relayer/executor"
+	"github.com/bnb-chain/greenfield-relayer/hook"
+	"github.com/bnb-chain/greenfield-relayer/journal"
 	"github.com/bnb-chain/greenfield-relayer/logging"
 	"github.com/bnb-chain/greenfield-relayer/metric"
 	"github.com/bnb-chain/greenfield-relayer/types"
@@ -20,6 +27,7 @@ import (
 )
 
 type BSCAssembler struct {
+	mutex                       sync.RWMutex
 	config                      *config.Config
 	greenfieldExecutor          *executor.GreenfieldExecutor
 	bscExecutor                 *executor.BSCExecutor
@@ -27,10 +35,24 @@ type BSCAssembler struct {
 	blsPubKey                   []byte
 	inturnRelayerSequenceStatus *types.SequenceStatus
 	relayerNonce                uint64
+	startNonce                  uint64 // the first nonce fetched from chain is raised to it, see SetStartNonce
 	metricService               *metric.MetricService
+	eventBus                    *events.Bus
+	coordinator                 *coordinator.Coordinator
+	claimJournal                *journal.ClaimJournal
+	upgradeGuard                *upgradeGuard
+	diagnostic                  *claimDiagnostic
+	inclusionLatency            time.Duration
+	inturnEnd                   uint64 // end of the current in-turn interval, 0 if not in turn
+	idle                        *idleTracker
 }
 
-func NewBSCAssembler(cfg *config.Config, executor *executor.BSCExecutor, dao *dao.DaoManager, greenfieldExecutor *executor.GreenfieldExecutor, ms *metric.MetricService) *BSCAssembler {
+func NewBSCAssembler(cfg *config.Config, executor *executor.BSCExecutor, dao *dao.DaoManager, greenfieldExecutor *executor.GreenfieldExecutor, ms *metric.MetricService,
+	eventBus *events.Bus, coordinator *coordinator.Coordinator, claimJournal *journal.ClaimJournal) *BSCAssembler {
+	inclusionLatency := common.DefaultGreenfieldClaimInclusionLatency
+	if cfg.RelayConfig.BSCToGreenfieldClaimInclusionLatency > 0 {
+		inclusionLatency = time.Duration(cfg.RelayConfig.BSCToGreenfieldClaimInclusionLatency) * time.Second
+	}
 	return &BSCAssembler{
 		config:                      cfg,
 		bscExecutor:                 executor,
@@ -39,7 +61,30 @@ func NewBSCAssembler(cfg *config.Config, executor *executor.BSCExecutor, dao *da
 		blsPubKey:                   greenfieldExecutor.BlsPubKey,
 		inturnRelayerSequenceStatus: &types.SequenceStatus{},
 		metricService:               ms,
+		eventBus:                    eventBus,
+		coordinator:                 coordinator,
+		claimJournal:                claimJournal,
+		upgradeGuard:                newUpgradeGuard(config.ChainGreenfield, cfg.GreenfieldConfig.Upgrades),
+		diagnostic:                  &claimDiagnostic{daoManager: dao, greenfieldExecutor: greenfieldExecutor, bscExecutor: executor, metricService: ms},
+		inclusionLatency:            inclusionLatency,
+		idle:                        newIdleTracker(),
+	}
+}
+
+// SetStartNonce makes the assembler send its first claim with at least the nonce, so that nonces held by claims which
+// were broadcast before a restart and are not included yet are not reused
+func (a *BSCAssembler) SetStartNonce(nonce uint64) {
+	a.startNonce = nonce
+}
+
+// raiseToStartNonce returns the nonce fetched from chain raised to the start nonce, the start nonce only applies once,
+// since nonces are re-fetched after a claim fails with a nonce mismatch
+func (a *BSCAssembler) raiseToStartNonce(nonce uint64) uint64 {
+	if a.startNonce > nonce {
+		nonce = a.startNonce
 	}
+	a.startNonce = 0
+	return nonce
 }
 
 // AssemblePackagesAndClaimLoop assemble packages and then claim in Greenfield
@@ -48,26 +93,34 @@ func (a *BSCAssembler) AssemblePackagesAndClaimLoop() {
 }
 
 func (a *BSCAssembler) assemblePackagesAndClaimForOracleChannel(channelId types.ChannelId) {
-	ticker := time.NewTicker(common.AssembleInterval)
-	for range ticker.C {
-		if err := a.process(channelId); err != nil {
+	common.Schedule(common.TaskBSCAssembler, common.AssembleInterval, func() {
+		if a.idleTick(channelId) && !a.pollInturnRelayer() {
+			return
+		}
+		// the node is pinned for the whole tick, so that a node switch does not mix up the in-turn relayer and nonces
+		snapshot := a.greenfieldExecutor.NewSnapshot()
+		if a.upgradeGuard.shouldPause(snapshot.Height()) {
+			return
+		}
+		if err := a.process(channelId, snapshot); err != nil {
+			if errors.Is(err, common.ErrNotEnoughVotes) {
+				logging.Logger.Debugf("waiting for votes, err=%s ", err.Error())
+				return
+			}
 			logging.Logger.Errorf("encounter error when relaying packages, err=%s ", err.Error())
 		}
-	}
+	}).Run()
 }
 
-func (a *BSCAssembler) process(channelId types.ChannelId) error {
-	inturnRelayer, err := a.greenfieldExecutor.GetInturnRelayer()
+func (a *BSCAssembler) process(channelId types.ChannelId, snapshot *executor.GreenfieldSnapshot) error {
+	inturnRelayer, err := snapshot.GetInturnRelayer()
 	if err != nil {
 		return err
 	}
-	inturnRelayerPubkey, err := hex.DecodeString(inturnRelayer.BlsPubKey)
+	isInturnRelyer, _, err := a.updateInturnRelayer(inturnRelayer)
 	if err != nil {
 		return err
 	}
-	isInturnRelyer := bytes.Equal(a.blsPubKey, inturnRelayerPubkey)
-
-	a.metricService.SetGnfdInturnRelayerMetrics(isInturnRelyer, inturnRelayer.RelayInterval.Start, inturnRelayer.RelayInterval.End)
 	var startSeq uint64
 
 	if isInturnRelyer {
@@ -82,39 +135,41 @@ func (a *BSCAssembler) process(channelId types.ChannelId) error {
 				}
 				return nil
 			}
-			inTurnRelayerStartSeq, err := a.bscExecutor.GetNextDeliveryOracleSequenceWithRetry()
+			inTurnRelayerStartSeq, err := snapshot.GetNextReceiveOracleSequence()
 			if err != nil {
 				return err
 			}
-			nonce, err := a.greenfieldExecutor.GetNonce()
+			nonce, err := snapshot.GetNonce()
 			if err != nil {
 				return err
 			}
-			a.relayerNonce = nonce
+			a.relayerNonce = a.raiseToStartNonce(nonce)
+			a.mutex.Lock()
 			a.inturnRelayerSequenceStatus.HasRetrieved = true
 			a.inturnRelayerSequenceStatus.NextDeliverySeq = inTurnRelayerStartSeq
+			a.mutex.Unlock()
 		}
 		startSeq = a.inturnRelayerSequenceStatus.NextDeliverySeq
 	} else {
-		a.inturnRelayerSequenceStatus.HasRetrieved = false
 		// non-inturn relayer retries every 10 second, gets the sequence from chain
 		time.Sleep(time.Duration(a.config.RelayConfig.GreenfieldSequenceUpdateLatency) * time.Second)
-		startSeq, err = a.bscExecutor.GetNextDeliveryOracleSequenceWithRetry()
+		startSeq, err = snapshot.GetNextReceiveOracleSequence()
 		if err != nil {
 			return err
 		}
-		startNonce, err := a.greenfieldExecutor.GetNonce()
+		startNonce, err := snapshot.GetNonce()
 		if err != nil {
 			return err
 		}
-		a.relayerNonce = startNonce
+		a.relayerNonce = a.raiseToStartNonce(startNonce)
 	}
+	a.idle.observe(uint8(channelId), startSeq)
 	err = a.updateMetrics(uint8(channelId), startSeq)
 	if err != nil {
 		return err
 	}
 
-	endSequence, err := a.daoManager.BSCDao.GetLatestOracleSequenceByStatus(db.AllVoted)
+	endSequence, err := a.daoManager.BSCDao.GetOracleSequenceWatermark(db.AllVoted)
 	if err != nil {
 		return err
 	}
@@ -123,8 +178,6 @@ func (a *BSCAssembler) process(channelId types.ChannelId) error {
 	}
 	logging.Logger.Debugf("start seq and end enq are %d and %d", startSeq, endSequence)
 
-	client := a.greenfieldExecutor.GetGnfdClient()
-
 	for i := startSeq; i <= uint64(endSequence); i++ {
 		pkgs, err := a.daoManager.BSCDao.GetPackagesByOracleSequence(i)
 		if err != nil {
@@ -136,75 +189,246 @@ func (a *BSCAssembler) process(channelId types.ChannelId) error {
 		status := pkgs[0].Status
 		pkgTime := pkgs[0].TxTime
 
-		if status != db.AllVoted && status != db.Delivered {
-			return fmt.Errorf("packages with oracle sequence %d does not get enough votes yet", i)
+		// sequences are delivered in order, later sequences wait until the skipped one is delivered by others
+		if status == db.Skipped {
+			logging.Logger.Infof("packages with oracle sequence %d are skipped, wait for them to be delivered, cid=%s", i,
+				common.CorrelationId(metric.DirectionBSCToGnfd, uint8(channelId), i))
+			return nil
+		}
+		if status == db.NeedsAttention {
+			logging.Logger.Debugf("packages with oracle sequence %d need attention, wait for them to be retried, cid=%s", i,
+				common.CorrelationId(metric.DirectionBSCToGnfd, uint8(channelId), i))
+			return nil
+		}
+		if !status.HasEnoughVotes() {
+			return fmt.Errorf("%w, packages with oracle sequence %d does not get enough votes yet", common.ErrNotEnoughVotes, i)
 		}
 
-		// non-inturn relayer can not relay tx within the timeout of in-turn relayer
-		if !isInturnRelyer && time.Now().Unix() < pkgTime+a.config.RelayConfig.BSCToGreenfieldInturnRelayerTimeout {
+		// defer to the next in-turn relayer rather than sending a claim which would land after the turn
+		if isInturnRelyer && inturnWindowClosing(inturnRelayer.RelayInterval.End, a.inclusionLatency) {
+			logging.Logger.Infof("in-turn interval ends at %d, defer oracle sequence %d to the next relayer, cid=%s", inturnRelayer.RelayInterval.End, i,
+				common.CorrelationId(metric.DirectionBSCToGnfd, uint8(channelId), i))
+			return nil
+		}
+		// non-inturn relayer can not relay tx within the timeout of in-turn relayer, which is checked by the block time
+		if !isInturnRelyer && !snapshot.OutturnClaimAllowed(pkgTime, a.config.RelayConfig.BSCToGreenfieldInturnRelayerTimeout) {
+			return nil
+		}
+		if err := hook.RunBSCPackages(hook.PointBeforeClaim, pkgs); err != nil {
+			logging.Logger.Infof("skip claiming packages with oracle sequence %d, err=%s", i, err.Error())
+			return nil
+		}
+		// avoid racing with other non-inturn relayers for the same sequence
+		if !isInturnRelyer && !a.coordinator.TryClaim(metric.DirectionBSCToGnfd, uint8(channelId), i) {
 			return nil
 		}
-		if err := a.processPkgs(client, pkgs, uint8(channelId), i, a.relayerNonce, isInturnRelyer); err != nil {
+		// avoid claiming the same sequence with other workers sharing the DB
+		locked, err := lockSequence(a.daoManager, metric.DirectionBSCToGnfd, uint8(channelId), i)
+		if err != nil || !locked {
+			return err
+		}
+		if err := a.processPkgs(snapshot, pkgs, uint8(channelId), i, a.relayerNonce, isInturnRelyer); err != nil {
+			unlockSequence(a.daoManager, metric.DirectionBSCToGnfd, uint8(channelId), i)
+			if errors.Is(err, errClaimUnprofitable) {
+				logging.Logger.Infof("skip claiming packages with oracle sequence %d, err=%s", i, err.Error())
+				return nil
+			}
+			if errors.Is(err, common.ErrNonceMismatch) || errors.Is(err, common.ErrSequenceMismatch) {
+				// the cached sequence or nonce is outdated, re-fetch them from chain in next round
+				a.mutex.Lock()
+				reset := resetSequenceStatus(a.inturnRelayerSequenceStatus)
+				a.mutex.Unlock()
+				if reset {
+					recordSequenceReset(a.metricService, a.eventBus, metric.DirectionBSCToGnfd, uint8(channelId), resetMismatch)
+				}
+			}
 			return err
 		}
 
-		logging.Logger.Infof("relayed packages with oracle sequence %d ", i)
+		logging.Logger.Infof("relayed packages with oracle sequence %d, cid=%s", i, common.CorrelationId(metric.DirectionBSCToGnfd, uint8(channelId), i))
 		a.relayerNonce++
 	}
 	return nil
 }
 
-func (a *BSCAssembler) processPkgs(client *sdkclient.GreenfieldClient, pkgs []*model.BscRelayPackage, channelId uint8, sequence uint64, nonce uint64, isInturnRelyer bool) error {
+// updateInturnRelayer records whether the relayer is in turn, and returns it along with whether the turn changed
+func (a *BSCAssembler) updateInturnRelayer(inturnRelayer *oracletypes.QueryInturnRelayerResponse) (bool, bool, error) {
+	inturnRelayerPubkey, err := hex.DecodeString(inturnRelayer.BlsPubKey)
+	if err != nil {
+		return false, false, err
+	}
+	isInturnRelyer := bytes.Equal(a.blsPubKey, inturnRelayerPubkey)
+	a.metricService.SetInturnRelayerMetrics(metric.DirectionBSCToGnfd, isInturnRelyer, inturnRelayer.RelayInterval.Start, inturnRelayer.RelayInterval.End)
+	return isInturnRelyer, a.setInturnEnd(isInturnRelyer, inturnRelayer.RelayInterval.End), nil
+}
+
+func (a *BSCAssembler) processPkgs(snapshot *executor.GreenfieldSnapshot, pkgs []*model.BscRelayPackage, channelId uint8, sequence uint64, nonce uint64, isInturnRelyer bool) error {
 	// Get votes result for a packages, which are already validated and qualified to aggregate sig
 
 	votes, err := a.daoManager.VoteDao.GetVotesByChannelIdAndSequence(channelId, sequence)
 	if err != nil {
-		logging.Logger.Errorf("failed to get votes result for packages for channel %d and sequence %d", channelId, sequence)
+		logging.Logger.Errorf("failed to get votes result for packages for channel %d and sequence %d, cid=%s", channelId, sequence,
+			common.CorrelationId(metric.DirectionBSCToGnfd, channelId, sequence))
 		return err
 	}
 	validators, err := a.greenfieldExecutor.QueryCachedLatestValidators()
 	if err != nil {
 		return err
 	}
+	var pkgIds []int64
+	for _, p := range pkgs {
+		pkgIds = append(pkgIds, p.Id)
+	}
+	votes, err = dropStaleVotes(a.daoManager, channelId, sequence, votes, vote.GreenfieldValidatorBlsKeys(validators), func(txManager *dao.DaoManager) error {
+		return txManager.BSCDao.UpdateBatchPackagesStatus(pkgIds, db.SelfVoted)
+	})
+	if err != nil {
+		return err
+	}
 
 	aggregatedSignature, valBitSet, err := vote.AggregateSignatureAndValidatorBitSet(votes, validators)
 	if err != nil {
 		return err
 	}
+	if !isInturnRelyer {
+		if err := a.checkClaimReward(snapshot, votes[0].ClaimPayload, aggregatedSignature, valBitSet.Bytes(), pkgs[0].TxTime, sequence, nonce); err != nil {
+			return err
+		}
+	}
 
-	txHash, err := a.greenfieldExecutor.ClaimPackages(client, votes[0].ClaimPayload, aggregatedSignature, valBitSet.Bytes(), pkgs[0].TxTime, sequence, nonce)
+	// the nonce is persisted before broadcast, so that it is known which claim used it after a crash, claims sent by fee
+	// payers do not use the nonce
+	reserved := !a.greenfieldExecutor.ClaimsByFeePayers()
+	if reserved {
+		if err = a.daoManager.NonceDao.ReserveNonce(&model.NonceReservation{
+			Direction:    metric.DirectionBSCToGnfd,
+			Nonce:        nonce,
+			ChannelId:    channelId,
+			Sequence:     sequence,
+			ReservedTime: time.Now().Unix(),
+		}); err != nil {
+			return err
+		}
+	}
+	a.eventBus.Publish(&events.Event{
+		Type:           events.EventTypeClaimAttempt,
+		Direction:      metric.DirectionBSCToGnfd,
+		ChannelId:      channelId,
+		OracleSequence: sequence,
+		Height:         pkgs[0].Height,
+		Nonce:          nonce,
+		Time:           time.Now().Unix(),
+	})
+	txHash, err := snapshot.ClaimPackages(votes[0].ClaimPayload, aggregatedSignature, valBitSet.Bytes(), pkgs[0].TxTime, sequence, nonce)
 	if err != nil {
+		a.diagnostic.capture(metric.DirectionBSCToGnfd, channelId, sequence, nonce, votes, validators, err)
+		recordClaimFailure(a.config, metric.DirectionBSCToGnfd, channelId, sequence, err, func(maxFailures uint32) (bool, error) {
+			return a.daoManager.BSCDao.IncreaseClaimFailures(pkgIds, maxFailures)
+		})
+		a.eventBus.Publish(&events.Event{
+			Type:           events.EventTypeClaimFailure,
+			Direction:      metric.DirectionBSCToGnfd,
+			ChannelId:      channelId,
+			OracleSequence: sequence,
+			Height:         pkgs[0].Height,
+			Nonce:          nonce,
+			Error:          err.Error(),
+			Time:           time.Now().Unix(),
+		})
 		return err
 	}
 
-	logging.Logger.Infof("claimed transaction with oracle_sequence=%d, txHash=%s", sequence, txHash)
-	var pkgIds []int64
+	logging.Logger.Infof("claimed transaction with oracle_sequence=%d, txHash=%s, cid=%s", sequence, txHash,
+		common.CorrelationId(metric.DirectionBSCToGnfd, channelId, sequence))
+	a.metricService.ObserveClaimLatency(metric.DirectionBSCToGnfd, channelId, sequence, time.Now().Unix()-pkgs[0].TxTime)
+	if err := a.claimJournal.Append(&journal.ClaimEntry{
+		Direction: metric.DirectionBSCToGnfd,
+		ChannelId: channelId,
+		Sequence:  sequence,
+		Nonce:     nonce,
+		TxHash:    txHash,
+		Inturn:    isInturnRelyer,
+		Time:      time.Now().Unix(),
+	}); err != nil {
+		logging.Logger.Errorf("failed to journal claim tx %s, err=%s", txHash, err.Error())
+	}
+	a.metricService.SetProcessedBlockHeight(metric.ChainBSC, pkgs[0].Height)
 	for _, p := range pkgs {
-		pkgIds = append(pkgIds, p.Id)
+		a.eventBus.Publish(&events.Event{
+			Type:           events.EventTypeClaim,
+			Direction:      metric.DirectionBSCToGnfd,
+			ChannelId:      p.ChannelId,
+			Sequence:       p.PackageSequence,
+			OracleSequence: p.OracleSequence,
+			Height:         p.Height,
+			TxHash:         p.TxHash,
+			ClaimTxHash:    txHash,
+			Delivered:      isInturnRelyer,
+			Nonce:          nonce,
+			Time:           time.Now().Unix(),
+		})
 	}
-	a.metricService.SetBSCProcessedBlockHeight(pkgs[0].Height)
 
+	status := db.Delivered
 	if !isInturnRelyer {
-		if err = a.daoManager.BSCDao.UpdateBatchPackagesClaimedTxHash(pkgIds, txHash); err != nil {
-			return err
-		}
-		return nil
+		status = db.Claimed
 	}
-
-	if err = a.daoManager.BSCDao.UpdateBatchPackagesStatusAndClaimedTxHash(pkgIds, db.Delivered, txHash); err != nil {
-		logging.Logger.Errorf("failed to update packages to 'Delivered', error=%s", err.Error())
+	if reserved {
+		// the reservation and the packages are updated together, so that a claimed nonce is never left without tx hash
+		err = a.daoManager.ExecTx(func(txManager *dao.DaoManager) error {
+			if err := txManager.NonceDao.UpdateNonceReservationTxHash(metric.DirectionBSCToGnfd, nonce, txHash); err != nil {
+				return err
+			}
+			return txManager.BSCDao.UpdateBatchPackagesStatusAndClaimedTxHash(pkgIds, status, txHash)
+		})
+	} else {
+		err = a.daoManager.BSCDao.UpdateBatchPackagesStatusAndClaimedTxHash(pkgIds, status, txHash)
+	}
+	if err != nil {
+		logging.Logger.Errorf("failed to update claimed packages, error=%s", err.Error())
 		return err
 	}
+	if !isInturnRelyer {
+		return nil
+	}
+	for _, p := range pkgs {
+		event := hook.BSCPackageEvent(hook.PointAfterDelivery, p)
+		event.ClaimTxHash = txHash
+		hook.Notify(event)
+	}
+	a.mutex.Lock()
 	a.inturnRelayerSequenceStatus.NextDeliverySeq = sequence + 1
+	a.mutex.Unlock()
 	return nil
 }
 
+// checkClaimReward returns errClaimUnprofitable if the share of the relayer fees of the packages that the relayer gets
+// is below non_inturn_min_reward_percent of the fee of claiming them
+func (a *BSCAssembler) checkClaimReward(snapshot *executor.GreenfieldSnapshot, payload []byte, blsSignature []byte, voteAddressSet []uint64, claimTs int64, sequence uint64, nonce uint64) error {
+	minPercent := a.config.RelayConfig.NonInturnMinRewardPercent
+	if minPercent == 0 {
+		return nil
+	}
+	reward, err := snapshot.EstimateClaimReward(payload)
+	if err != nil {
+		return err
+	}
+	fee, err := snapshot.EstimateClaimFee(payload, blsSignature, voteAddressSet, claimTs, sequence, nonce)
+	if err != nil {
+		return err
+	}
+	if rewardCoversFee(reward, fee, minPercent) {
+		return nil
+	}
+	return fmt.Errorf("%w, relayer reward %s is below %d%% of the claim fee %s", errClaimUnprofitable, reward, minPercent, fee)
+}
+
 func (a *BSCAssembler) updateMetrics(channelId uint8, nextDeliveryOracleSeq uint64) error {
-	a.metricService.SetNextReceiveSequenceForChannel(channelId, nextDeliveryOracleSeq)
+	a.metricService.SetNextReceiveSequence(metric.DirectionBSCToGnfd, channelId, nextDeliveryOracleSeq)
 	nextSendOracleSeq, err := a.bscExecutor.GetNextSendSequenceForChannelWithRetry()
 	if err != nil {
 		return err
 	}
-	a.metricService.SetNextSendSequenceForChannel(channelId, nextSendOracleSeq)
+	a.metricService.SetNextSendSequence(metric.DirectionBSCToGnfd, channelId, nextSendOracleSeq)
 	return nil
 }
@@ -0,0 +1,38 @@
+package assembler
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bnb-chain/greenfield-relayer/common"
+	"github.com/bnb-chain/greenfield-relayer/config"
+	"github.com/bnb-chain/greenfield-relayer/logging"
+)
+
+// countsAsClaimFailure tells whether the failed claim counts towards claim_max_attempts, failures caused by the
+// relayer rather than the package, e.g. outdated nonces and sequences or the fee ceiling, are not counted
+func countsAsClaimFailure(err error) bool {
+	return !errors.Is(err, common.ErrNonceMismatch) && !errors.Is(err, common.ErrSequenceMismatch) &&
+		!errors.Is(err, common.ErrFeeCeilingExceeded)
+}
+
+// recordClaimFailure counts the failed claim of the sequence by increase, which moves the sequence to the needs
+// attention status once the failures reach claim_max_attempts, and alerts if it is moved
+func recordClaimFailure(cfg *config.Config, direction string, channelId uint8, sequence uint64, claimErr error,
+	increase func(maxFailures uint32) (bool, error)) {
+	if !countsAsClaimFailure(claimErr) {
+		return
+	}
+	moved, err := increase(cfg.RelayConfig.ClaimMaxAttempts)
+	if err != nil {
+		logging.Logger.Errorf("failed to record claim failure of %s channel id %d and sequence %d, err=%s", direction, channelId, sequence, err.Error())
+		return
+	}
+	if !moved {
+		return
+	}
+	msg := fmt.Sprintf("claims of %s channel id %d and sequence %d failed %d times and need attention, retry it by /admin/retry_sequence once fixed, last err=%s, cid=%s",
+		direction, channelId, sequence, cfg.RelayConfig.ClaimMaxAttempts, claimErr.Error(), common.CorrelationId(direction, channelId, sequence))
+	logging.Logger.Error(msg)
+	config.SendTelegramMessage(cfg.AlertConfig.Identity, cfg.AlertConfig.TelegramBotId, cfg.AlertConfig.TelegramChatId, msg)
+}
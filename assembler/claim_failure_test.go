@@ -0,0 +1,16 @@
+package assembler
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-relayer/common"
+)
+
+func TestCountsAsClaimFailure(t *testing.T) {
+	require.True(t, countsAsClaimFailure(fmt.Errorf("%w, execution reverted", common.ErrClaimSimulationFailed)))
+	require.False(t, countsAsClaimFailure(fmt.Errorf("%w, nonce too low", common.ErrNonceMismatch)))
+	require.False(t, countsAsClaimFailure(fmt.Errorf("%w, fee above max_fee_per_tx", common.ErrFeeCeilingExceeded)))
+}
@@ -0,0 +1,171 @@
+package assembler
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/bnb-chain/greenfield-relayer/common"
+	"github.com/bnb-chain/greenfield-relayer/db"
+	"github.com/bnb-chain/greenfield-relayer/db/dao"
+	"github.com/bnb-chain/greenfield-relayer/db/model"
+	"github.com/bnb-chain/greenfield-relayer/executor"
+	"github.com/bnb-chain/greenfield-relayer/executor/crosschain"
+	"github.com/bnb-chain/greenfield-relayer/logging"
+)
+
+// ClaimReceiptEventUnknown is the name of events not emitted by the CrossChain contract, e.g. relayer rewards
+const ClaimReceiptEventUnknown = "unknown"
+
+// claimReceiptDecoder decodes the receipts of claim txs sent to BSC once they are mined, so that what happened to a
+// Greenfield package on BSC is recorded in DB
+type claimReceiptDecoder struct {
+	daoManager     *dao.DaoManager
+	bscExecutor    *executor.BSCExecutor
+	crossChainAbi  abi.ABI
+	crossChainAddr ethcommon.Address
+}
+
+func newClaimReceiptDecoder(daoManager *dao.DaoManager, bscExecutor *executor.BSCExecutor, crossChainAddr string) *claimReceiptDecoder {
+	crossChainAbi, err := abi.JSON(strings.NewReader(crosschain.CrosschainMetaData.ABI))
+	if err != nil {
+		panic("marshal abi error")
+	}
+	return &claimReceiptDecoder{
+		daoManager:     daoManager,
+		bscExecutor:    bscExecutor,
+		crossChainAbi:  crossChainAbi,
+		crossChainAddr: ethcommon.HexToAddress(crossChainAddr),
+	}
+}
+
+// decodePending decodes receipts of claim txs which are not decoded yet, claim txs not found for long are dropped
+func (d *claimReceiptDecoder) decodePending(now time.Time) error {
+	txs, err := d.daoManager.GreenfieldDao.GetTransactionsWithPendingReceipt(common.ClaimReceiptBatchSize)
+	if err != nil {
+		return err
+	}
+	for _, tx := range txs {
+		receipt, err := d.bscExecutor.GetTransactionReceipt(ethcommon.HexToHash(tx.ClaimedTxHash))
+		if errors.Is(err, ethereum.NotFound) {
+			if now.Unix()-tx.UpdatedTime > int64(common.ClaimReceiptDropTimeout.Seconds()) {
+				logging.Logger.Infof("claim tx %s of channel id %d and sequence %d is dropped", tx.ClaimedTxHash, tx.ChannelId, tx.Sequence)
+				if err := d.daoManager.GreenfieldDao.SaveClaimReceipt(tx.Id, db.ReceiptDropped, 0, 0, "", nil); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		status := db.ReceiptSuccess
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			status = db.ReceiptReverted
+		}
+		gasPrice, err := d.bscExecutor.GetReceiptGasPrice(receipt)
+		if err != nil {
+			return err
+		}
+		if err := d.daoManager.GreenfieldDao.SaveClaimReceipt(tx.Id, status, receipt.BlockNumber.Uint64(), receipt.GasUsed,
+			gasPrice.String(), d.decodeLogs(tx.Id, receipt)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeLogs decodes events of the CrossChain contract in the receipt, events of other contracts are kept raw
+func (d *claimReceiptDecoder) decodeLogs(relayTransactionId int64, receipt *types.Receipt) []*model.ClaimReceiptEvent {
+	events := make([]*model.ClaimReceiptEvent, 0, len(receipt.Logs))
+	for _, log := range receipt.Logs {
+		e := &model.ClaimReceiptEvent{
+			RelayTransactionId: relayTransactionId,
+			ClaimTxHash:        receipt.TxHash.String(),
+			LogIndex:           log.Index,
+			Contract:           log.Address.String(),
+			Name:               ClaimReceiptEventUnknown,
+		}
+		args, name, err := d.decodeCrossChainEvent(log)
+		if err != nil {
+			logging.Logger.Errorf("failed to decode log %d of claim tx %s, err=%s", log.Index, receipt.TxHash.String(), err.Error())
+		}
+		if args == nil {
+			args = rawLogArgs(log)
+		} else {
+			e.Name = name
+			if channelId, ok := args["channelId"].(uint8); ok {
+				e.ChannelId = channelId
+			}
+			if sequence, ok := args["packageSequence"].(uint64); ok {
+				e.Sequence = sequence
+			}
+		}
+		data, err := json.Marshal(jsonArgs(args))
+		if err != nil {
+			logging.Logger.Errorf("failed to encode log %d of claim tx %s, err=%s", log.Index, receipt.TxHash.String(), err.Error())
+		}
+		e.Data = string(data)
+		events = append(events, e)
+	}
+	return events
+}
+
+// decodeCrossChainEvent returns nil arguments if the log is not an event of the CrossChain contract
+func (d *claimReceiptDecoder) decodeCrossChainEvent(log *types.Log) (map[string]interface{}, string, error) {
+	if log.Address != d.crossChainAddr || len(log.Topics) == 0 {
+		return nil, "", nil
+	}
+	event, err := d.crossChainAbi.EventByID(log.Topics[0])
+	if err != nil {
+		return nil, "", nil
+	}
+	args := make(map[string]interface{})
+	if len(log.Data) > 0 {
+		if err := d.crossChainAbi.UnpackIntoMap(args, event.Name, log.Data); err != nil {
+			return nil, "", err
+		}
+	}
+	indexed := make(abi.Arguments, 0)
+	for _, arg := range event.Inputs {
+		if arg.Indexed {
+			indexed = append(indexed, arg)
+		}
+	}
+	if err := abi.ParseTopicsIntoMap(args, indexed, log.Topics[1:]); err != nil {
+		return nil, "", err
+	}
+	return args, event.Name, nil
+}
+
+func rawLogArgs(log *types.Log) map[string]interface{} {
+	topics := make([]string, 0, len(log.Topics))
+	for _, t := range log.Topics {
+		topics = append(topics, t.String())
+	}
+	return map[string]interface{}{"topics": topics, "data": log.Data}
+}
+
+// jsonArgs encodes bytes as hex and big integers as decimal strings
+func jsonArgs(args map[string]interface{}) map[string]interface{} {
+	encoded := make(map[string]interface{}, len(args))
+	for k, v := range args {
+		switch value := v.(type) {
+		case []byte:
+			encoded[k] = hex.EncodeToString(value)
+		case *big.Int:
+			encoded[k] = value.String()
+		default:
+			encoded[k] = v
+		}
+	}
+	return encoded
+}
@@ -0,0 +1,51 @@
+package assembler
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeClaimReceiptLogs(t *testing.T) {
+	crossChainAddr := "0x3a282380958194D1131bC49056abb712Ab98b82B"
+	d := newClaimReceiptDecoder(nil, nil, crossChainAddr)
+
+	event := d.crossChainAbi.Events["ReceivedPackage"]
+	data, err := event.Inputs.NonIndexed().Pack(uint8(1))
+	require.NoError(t, err)
+	received := &types.Log{
+		Address: ethcommon.HexToAddress(crossChainAddr),
+		Topics: []ethcommon.Hash{
+			event.ID,
+			ethcommon.BigToHash(big.NewInt(42)),
+			ethcommon.BigToHash(big.NewInt(2)),
+		},
+		Data:  data,
+		Index: 3,
+	}
+	reward := &types.Log{
+		Address: ethcommon.HexToAddress("0x0000000000000000000000000000000000001005"),
+		Topics:  []ethcommon.Hash{ethcommon.HexToHash("0x01")},
+		Data:    []byte{0xab},
+		Index:   4,
+	}
+	events := d.decodeLogs(7, &types.Receipt{Logs: []*types.Log{received, reward}})
+	require.Len(t, events, 2)
+
+	require.Equal(t, "ReceivedPackage", events[0].Name)
+	require.Equal(t, int64(7), events[0].RelayTransactionId)
+	require.Equal(t, uint8(2), events[0].ChannelId)
+	require.Equal(t, uint64(42), events[0].Sequence)
+	require.Equal(t, uint(3), events[0].LogIndex)
+	args := make(map[string]interface{})
+	require.NoError(t, json.Unmarshal([]byte(events[0].Data), &args))
+	require.Equal(t, float64(1), args["packageType"])
+
+	require.Equal(t, ClaimReceiptEventUnknown, events[1].Name)
+	require.NoError(t, json.Unmarshal([]byte(events[1].Data), &args))
+	require.Equal(t, "ab", args["data"])
+}
@@ -0,0 +1,111 @@
+package assembler
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/bnb-chain/greenfield-relayer/common"
+	"github.com/bnb-chain/greenfield-relayer/db/dao"
+	"github.com/bnb-chain/greenfield-relayer/db/model"
+	"github.com/bnb-chain/greenfield-relayer/executor"
+	"github.com/bnb-chain/greenfield-relayer/logging"
+	"github.com/bnb-chain/greenfield-relayer/metric"
+)
+
+const (
+	FailureClassNonceMismatch        = "nonce_mismatch"
+	FailureClassOutOfGas             = "out_of_gas"
+	FailureClassInsufficientQuorum   = "insufficient_quorum"
+	FailureClassWrongBitset          = "wrong_bitset"
+	FailureClassSequenceMismatch     = "sequence_mismatch"
+	FailureClassPayloadDecodeFailure = "payload_decode_failure"
+	FailureClassUnknown              = "unknown"
+)
+
+// failureClassPatterns matches raw logs of failed claims on both chains, checked in order since e.g. a nonce mismatch on
+// Greenfield is reported as account sequence mismatch
+var failureClassPatterns = []struct {
+	class    string
+	keywords []string
+}{
+	{FailureClassNonceMismatch, []string{"account sequence mismatch", "nonce too low", "nonce too high"}},
+	{FailureClassOutOfGas, []string{"out of gas", "gas required exceeds", "intrinsic gas too low"}},
+	{FailureClassInsufficientQuorum, []string{"not enough votes", "insufficient votes", "quorum", "voting power"}},
+	{FailureClassWrongBitset, []string{"bitset", "bit set", "bitmap", "validator set"}},
+	{FailureClassSequenceMismatch, []string{"sequence mismatch", "sequence not in order", "sequence is not in order", "invalid sequence"}},
+	{FailureClassPayloadDecodeFailure, []string{"decode", "unmarshal", "invalid payload", "rlp"}},
+}
+
+// classifyClaimFailure returns the root cause class of a failed claim by its error and raw tx response
+func classifyClaimFailure(claimErr error, rawResponse string) string {
+	switch {
+	case errors.Is(claimErr, common.ErrNonceMismatch):
+		return FailureClassNonceMismatch
+	case errors.Is(claimErr, common.ErrNotEnoughVotes):
+		return FailureClassInsufficientQuorum
+	case errors.Is(claimErr, common.ErrSequenceMismatch):
+		return FailureClassSequenceMismatch
+	}
+	msg := strings.ToLower(claimErr.Error() + " " + rawResponse)
+	for _, p := range failureClassPatterns {
+		for _, k := range p.keywords {
+			if strings.Contains(msg, k) {
+				return p.class
+			}
+		}
+	}
+	return FailureClassUnknown
+}
+
+// claimDiagnostic captures the context of a failed claim, the bundle is saved to the claim_diagnostic table instead of
+// only logging the error
+type claimDiagnostic struct {
+	daoManager         *dao.DaoManager
+	greenfieldExecutor *executor.GreenfieldExecutor
+	bscExecutor        *executor.BSCExecutor
+	metricService      *metric.MetricService
+}
+
+// capture saves a diagnostic bundle of the failed claim, failures of capturing are only logged so that they never mask
+// the claim error
+func (c *claimDiagnostic) capture(direction string, channelId uint8, sequence, nonce uint64, votes []*model.Vote, validators interface{}, claimErr error) {
+	diagnostic := &model.ClaimDiagnostic{
+		Direction:   direction,
+		ChannelId:   channelId,
+		Sequence:    sequence,
+		Nonce:       nonce,
+		Error:       claimErr.Error(),
+		CreatedTime: time.Now().Unix(),
+	}
+	if len(votes) > 0 {
+		diagnostic.Payload = hex.EncodeToString(votes[0].ClaimPayload)
+	}
+	if bts, err := json.Marshal(votes); err == nil {
+		diagnostic.Votes = string(bts)
+	}
+	if bts, err := json.Marshal(validators); err == nil {
+		diagnostic.Validators = string(bts)
+	}
+	if height, err := c.greenfieldExecutor.GetLatestBlockHeight(); err == nil {
+		diagnostic.GreenfieldHeight = height
+	}
+	if height, err := c.bscExecutor.GetLatestBlockHeight(); err == nil {
+		diagnostic.BSCHeight = height
+	}
+	var txErr *executor.ClaimTxError
+	if errors.As(claimErr, &txErr) {
+		diagnostic.TxResponse = txErr.RawResponse
+	}
+	diagnostic.FailureClass = classifyClaimFailure(claimErr, diagnostic.TxResponse)
+	c.metricService.IncClaimFailures(direction, diagnostic.FailureClass)
+	if err := c.daoManager.DiagnosticDao.SaveClaimDiagnostic(diagnostic); err != nil {
+		logging.Logger.Errorf("failed to save claim diagnostic for channel %d and sequence %d, cid=%s, err=%s", channelId, sequence,
+			common.CorrelationId(direction, channelId, sequence), err.Error())
+		return
+	}
+	logging.Logger.Infof("saved claim diagnostic %d for channel %d and sequence %d, failure class=%s, cid=%s", diagnostic.Id,
+		channelId, sequence, diagnostic.FailureClass, common.CorrelationId(direction, channelId, sequence))
+}
@@ -0,0 +1,24 @@
+package assembler
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-relayer/common"
+)
+
+func TestClassifyClaimFailure(t *testing.T) {
+	require.Equal(t, FailureClassNonceMismatch, classifyClaimFailure(fmt.Errorf("%w, claim error", common.ErrNonceMismatch), ""))
+	require.Equal(t, FailureClassSequenceMismatch, classifyClaimFailure(fmt.Errorf("%w, claim error", common.ErrSequenceMismatch), ""))
+	require.Equal(t, FailureClassInsufficientQuorum, classifyClaimFailure(fmt.Errorf("%w, exceed max retry", common.ErrNotEnoughVotes), ""))
+
+	// greenfield reports nonce mismatch as account sequence mismatch
+	require.Equal(t, FailureClassNonceMismatch, classifyClaimFailure(fmt.Errorf("claim error, code=32, log=account sequence mismatch, expected 5, got 4"), ""))
+	require.Equal(t, FailureClassOutOfGas, classifyClaimFailure(fmt.Errorf("claim error"), `{"raw_log":"out of gas in location: WriteFlat"}`))
+	require.Equal(t, FailureClassWrongBitset, classifyClaimFailure(fmt.Errorf("claim error, code=3, log=invalid validator set bitmap"), ""))
+	require.Equal(t, FailureClassSequenceMismatch, classifyClaimFailure(fmt.Errorf("execution reverted: sequence not in order"), ""))
+	require.Equal(t, FailureClassPayloadDecodeFailure, classifyClaimFailure(fmt.Errorf("claim error, code=9, log=failed to decode payload"), ""))
+	require.Equal(t, FailureClassUnknown, classifyClaimFailure(fmt.Errorf("connection refused"), ""))
+}
@@ -0,0 +1,99 @@
+package assembler
+
+import (
+	"github.com/bnb-chain/greenfield-relayer/common"
+	"github.com/bnb-chain/greenfield-relayer/db"
+	"github.com/bnb-chain/greenfield-relayer/metric"
+	"github.com/bnb-chain/greenfield-relayer/types"
+)
+
+// setInturnEnd records the end of the current in-turn interval, and records entering or leaving in-turn status. The
+// sequences cached by the in-turn relayer are reset once it is out of turn. It returns whether the turn changed.
+func (a *GreenfieldAssembler) setInturnEnd(isInturnRelyer bool, end uint64) bool {
+	a.mutex.Lock()
+	transition := turnTransition(a.inturnEnd, isInturnRelyer, end)
+	a.inturnEnd = 0
+	var resets []types.ChannelId
+	if isInturnRelyer {
+		a.inturnEnd = end
+	} else {
+		for c, s := range a.inturnRelayerSequenceStatusMap {
+			if resetSequenceStatus(s) {
+				resets = append(resets, c)
+			}
+		}
+	}
+	a.mutex.Unlock()
+	recordTurnTransition(a.metricService, a.eventBus, metric.DirectionGnfdToBSC, transition)
+	for _, c := range resets {
+		recordSequenceReset(a.metricService, a.eventBus, metric.DirectionGnfdToBSC, uint8(c), resetTurnEnded)
+	}
+	return transition != ""
+}
+
+// HasPendingInturnClaims tells whether the relayer is in turn with time left to claim, and has sequences with enough
+// votes not claimed yet, which are handed to other relayers if it shuts down now
+func (a *GreenfieldAssembler) HasPendingInturnClaims() (bool, error) {
+	a.mutex.RLock()
+	end := a.inturnEnd
+	nextSeqs := make(map[types.ChannelId]uint64)
+	for c, s := range a.inturnRelayerSequenceStatusMap {
+		if s.HasRetrieved {
+			nextSeqs[c] = s.NextDeliverySeq
+		}
+	}
+	a.mutex.RUnlock()
+	if end == 0 || inturnWindowClosing(end, a.inclusionLatency) {
+		return false, nil
+	}
+	for c, next := range nextSeqs {
+		if !common.IsChannelEnabled(uint8(c)) {
+			continue
+		}
+		watermark, err := a.daoManager.GreenfieldDao.GetSequenceWatermark(c, db.AllVoted)
+		if err != nil {
+			return false, err
+		}
+		if watermark >= int64(next) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// setInturnEnd records the end of the current in-turn interval, and records entering or leaving in-turn status. The
+// oracle sequence cached by the in-turn relayer is reset once it is out of turn. It returns whether the turn changed.
+func (a *BSCAssembler) setInturnEnd(isInturnRelyer bool, end uint64) bool {
+	a.mutex.Lock()
+	transition := turnTransition(a.inturnEnd, isInturnRelyer, end)
+	a.inturnEnd = 0
+	reset := false
+	if isInturnRelyer {
+		a.inturnEnd = end
+	} else {
+		reset = resetSequenceStatus(a.inturnRelayerSequenceStatus)
+	}
+	a.mutex.Unlock()
+	recordTurnTransition(a.metricService, a.eventBus, metric.DirectionBSCToGnfd, transition)
+	if reset {
+		recordSequenceReset(a.metricService, a.eventBus, metric.DirectionBSCToGnfd, uint8(common.OracleChannelId), resetTurnEnded)
+	}
+	return transition != ""
+}
+
+// HasPendingInturnClaims tells whether the relayer is in turn with time left to claim, and has oracle sequences with
+// enough votes not claimed yet, which are handed to other relayers if it shuts down now
+func (a *BSCAssembler) HasPendingInturnClaims() (bool, error) {
+	a.mutex.RLock()
+	end := a.inturnEnd
+	retrieved, next := a.inturnRelayerSequenceStatus.HasRetrieved, a.inturnRelayerSequenceStatus.NextDeliverySeq
+	a.mutex.RUnlock()
+	if end == 0 || !retrieved || inturnWindowClosing(end, a.inclusionLatency) {
+		return false, nil
+	}
+	watermark, err := a.daoManager.BSCDao.GetOracleSequenceWatermark(db.AllVoted)
+	if err != nil {
+		return false, err
+	}
+	return watermark >= int64(next), nil
+}
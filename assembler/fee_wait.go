@@ -0,0 +1,40 @@
+package assembler
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/bnb-chain/greenfield-relayer/common"
+	"github.com/bnb-chain/greenfield-relayer/db/model"
+	"github.com/bnb-chain/greenfield-relayer/logging"
+	"github.com/bnb-chain/greenfield-relayer/metric"
+)
+
+// waitForLowerGasPrice tells whether the claim of the tx should wait for a lower BSC gas price. Claims are due when the
+// package is emitted for the in-turn relayer and when the in-turn relayer times out for the others, they wait at most
+// the fee wait window after that.
+func (a *GreenfieldAssembler) waitForLowerGasPrice(tx *model.GreenfieldRelayTransaction, isInturnRelyer bool) bool {
+	window := a.config.RelayConfig.GreenfieldToBSCFeeWaitWindow
+	if window == 0 {
+		return false
+	}
+	dueAt := tx.TxTime
+	if !isInturnRelyer {
+		dueAt += a.config.RelayConfig.GreenfieldToBSCInturnRelayerTimeout
+	}
+	if time.Now().Unix() >= dueAt+window {
+		return false
+	}
+	gasPrice, err := a.bscExecutor.GetEffectiveGasPrice()
+	if err != nil {
+		logging.Logger.Errorf("failed to get BSC gas price, claim without waiting, err=%s", err.Error())
+		return false
+	}
+	target := new(big.Int).SetUint64(a.config.RelayConfig.GreenfieldToBSCFeeWaitGasPrice)
+	if gasPrice.Cmp(target) <= 0 {
+		return false
+	}
+	logging.Logger.Infof("BSC gas price %s wei is above %s wei, wait until %d to claim tx with channel id %d and sequence %d, cid=%s",
+		gasPrice, target, dueAt+window, tx.ChannelId, tx.Sequence, common.CorrelationId(metric.DirectionGnfdToBSC, tx.ChannelId, tx.Sequence))
+	return true
+}
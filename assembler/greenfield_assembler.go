@@ -3,16 +3,22 @@ package assembler
 import (
 	"bytes"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"math/big"
 	"sync"
 	"time"
 
 	"github.com/bnb-chain/greenfield-relayer/common"
 	"github.com/bnb-chain/greenfield-relayer/config"
+	"github.com/bnb-chain/greenfield-relayer/coordinator"
 	"github.com/bnb-chain/greenfield-relayer/db"
 	"github.com/bnb-chain/greenfield-relayer/db/dao"
 	"github.com/bnb-chain/greenfield-relayer/db/model"
+	"github.com/bnb-chain/greenfield-relayer/events"
 	"github.com/bnb-chain/greenfield-relayer/executor"
+	"github.com/bnb-chain/greenfield-relayer/hook"
+	"github.com/bnb-chain/greenfield-relayer/journal"
 	"github.com/bnb-chain/greenfield-relayer/logging"
 	"github.com/bnb-chain/greenfield-relayer/metric"
 	"github.com/bnb-chain/greenfield-relayer/types"
@@ -20,6 +26,9 @@ import (
 	"github.com/bnb-chain/greenfield-relayer/vote"
 )
 
+// errClaimUnprofitable is returned when a non-inturn claim is skipped as its reward does not cover the fee
+var errClaimUnprofitable = errors.New("claim is unprofitable")
+
 type GreenfieldAssembler struct {
 	mutex                          sync.RWMutex
 	config                         *config.Config
@@ -30,16 +39,30 @@ type GreenfieldAssembler struct {
 	inturnRelayerSequenceStatusMap map[types.ChannelId]*types.SequenceStatus // flag for in-turn relayer that if it has requested the sequence from chain during its interval
 	relayerNonceStatus             *types.NonceStatus
 	metricService                  *metric.MetricService
+	eventBus                       *events.Bus
+	coordinator                    *coordinator.Coordinator
+	claimJournal                   *journal.ClaimJournal
+	upgradeGuard                   *upgradeGuard
+	diagnostic                     *claimDiagnostic
+	inclusionLatency               time.Duration
+	lastHeaderSyncAt               time.Time // when the light block of a validator set change is re-synced last time
+	inturnEnd                      uint64    // end of the current in-turn interval, 0 if not in turn
+	idle                           *idleTracker
+	receipts                       *claimReceiptDecoder
 }
 
 func NewGreenfieldAssembler(cfg *config.Config, executor *executor.GreenfieldExecutor, dao *dao.DaoManager, bscExecutor *executor.BSCExecutor,
-	ms *metric.MetricService) *GreenfieldAssembler {
+	ms *metric.MetricService, eventBus *events.Bus, coordinator *coordinator.Coordinator, claimJournal *journal.ClaimJournal) *GreenfieldAssembler {
 	channels := cfg.GreenfieldConfig.MonitorChannelList
 	inturnRelayerSequenceStatusMap := make(map[types.ChannelId]*types.SequenceStatus)
 
 	for _, c := range channels {
 		inturnRelayerSequenceStatusMap[types.ChannelId(c)] = &types.SequenceStatus{}
 	}
+	inclusionLatency := common.DefaultBSCClaimInclusionLatency
+	if cfg.RelayConfig.GreenfieldToBSCClaimInclusionLatency > 0 {
+		inclusionLatency = time.Duration(cfg.RelayConfig.GreenfieldToBSCClaimInclusionLatency) * time.Second
+	}
 
 	return &GreenfieldAssembler{
 		config:                         cfg,
@@ -50,48 +73,98 @@ func NewGreenfieldAssembler(cfg *config.Config, executor *executor.GreenfieldExe
 		inturnRelayerSequenceStatusMap: inturnRelayerSequenceStatusMap,
 		relayerNonceStatus:             &types.NonceStatus{},
 		metricService:                  ms,
+		eventBus:                       eventBus,
+		coordinator:                    coordinator,
+		claimJournal:                   claimJournal,
+		upgradeGuard:                   newUpgradeGuard(config.ChainBSC, cfg.BSCConfig.Upgrades),
+		diagnostic:                     &claimDiagnostic{daoManager: dao, greenfieldExecutor: executor, bscExecutor: bscExecutor, metricService: ms},
+		inclusionLatency:               inclusionLatency,
+		idle:                           newIdleTracker(),
+		receipts:                       newClaimReceiptDecoder(dao, bscExecutor, cfg.RelayConfig.CrossChainContractAddr),
 	}
 }
 
 // AssembleTransactionsLoop assemble a tx by gathering votes signature and then call the build-in smart-contract
 func (a *GreenfieldAssembler) AssembleTransactionsLoop() {
-	ticker := time.NewTicker(common.AssembleInterval)
-	for range ticker.C {
+	common.Schedule(common.TaskGreenfieldAssembler, common.AssembleInterval, func() {
+		if a.upgradeGuard.shouldPause(a.bscExecutor.GetCachedLatestHeight()) {
+			return
+		}
+		if a.idleTick() && !a.pollInturnRelayer() {
+			return
+		}
 		inturnRelayer, err := a.bscExecutor.GetInturnRelayer()
 		if err != nil {
 			logging.Logger.Errorf("encounter error when retrieving in-turn relayer from chain, err=%s ", err.Error())
-			continue
+			return
 		}
-		inturnRelayerPubkey, err := hex.DecodeString(inturnRelayer.BlsPublicKey)
+		isInturnRelyer, _, err := a.updateInturnRelayer(inturnRelayer)
 		if err != nil {
 			logging.Logger.Errorf("encounter error when decode in-turn relayer key, err=%s ", err.Error())
-			continue
+			return
 		}
-		isInturnRelyer := bytes.Equal(a.blsPubKey, inturnRelayerPubkey)
-		a.metricService.SetBSCInturnRelayerMetrics(isInturnRelyer, inturnRelayer.Start, inturnRelayer.End)
 
 		if (isInturnRelyer && !a.relayerNonceStatus.HasRetrieved) || !isInturnRelyer {
 			nonce, err := a.bscExecutor.GetNonce()
 			if err != nil {
 				logging.Logger.Errorf("encounter error when get relayer nonce, err=%s ", err.Error())
-				continue
+				return
 			}
 			a.relayerNonceStatus.Nonce = nonce
 		}
 
-		wg := new(sync.WaitGroup)
-		for _, c := range a.getMonitorChannels() {
-			wg.Add(1)
-			go a.assembleTransactionAndSendForChannel(types.ChannelId(c), inturnRelayer, isInturnRelyer, wg)
+		// priority channels are claimed before the others, so their claims take the earlier nonces
+		for _, channels := range a.config.GreenfieldConfig.ChannelsByPriority() {
+			wg := new(sync.WaitGroup)
+			for _, c := range channels {
+				// claims of channels disabled by governance would keep failing
+				if !common.IsChannelEnabled(c) {
+					continue
+				}
+				wg.Add(1)
+				go a.assembleTransactionAndSendForChannel(types.ChannelId(c), inturnRelayer, isInturnRelyer, wg)
+			}
+			wg.Wait()
+		}
+	}).Run()
+}
+
+// DecodeClaimReceiptsLoop decodes receipts of claim txs sent to BSC once they are mined
+func (a *GreenfieldAssembler) DecodeClaimReceiptsLoop() {
+	common.Schedule(common.TaskClaimReceiptDecoder, common.ClaimReceiptDecodeInterval, func() {
+		if err := a.receipts.decodePending(time.Now()); err != nil {
+			logging.Logger.Errorf("failed to decode claim receipts, err=%s", err.Error())
 		}
-		wg.Wait()
+	}).Run()
+}
+
+// updateInturnRelayer records whether the relayer is in turn, and returns it along with whether the turn changed
+func (a *GreenfieldAssembler) updateInturnRelayer(inturnRelayer *types.InturnRelayer) (bool, bool, error) {
+	inturnRelayerPubkey, err := hex.DecodeString(inturnRelayer.BlsPublicKey)
+	if err != nil {
+		return false, false, err
 	}
+	isInturnRelyer := bytes.Equal(a.blsPubKey, inturnRelayerPubkey)
+	a.metricService.SetInturnRelayerMetrics(metric.DirectionGnfdToBSC, isInturnRelyer, inturnRelayer.Start, inturnRelayer.End)
+	return isInturnRelyer, a.setInturnEnd(isInturnRelyer, inturnRelayer.End), nil
 }
 
 func (a *GreenfieldAssembler) assembleTransactionAndSendForChannel(channelId types.ChannelId, inturnRelayer *types.InturnRelayer, isInturnRelyer bool, wg *sync.WaitGroup) {
 	defer wg.Done()
 	err := a.process(channelId, inturnRelayer, isInturnRelyer)
 	if err != nil {
+		if errors.Is(err, common.ErrNotEnoughVotes) {
+			logging.Logger.Debugf("waiting for votes, err=%s", err.Error())
+			return
+		}
+		if errors.Is(err, common.ErrLightClientBehind) {
+			logging.Logger.Infof("waiting for the light client, err=%s", err.Error())
+			return
+		}
+		if errors.Is(err, common.ErrFeeCeilingExceeded) {
+			logging.Logger.Infof("deferring claims until the fee is below the ceiling, err=%s", err.Error())
+			return
+		}
 		logging.Logger.Errorf("encounter err in assembleTransactionAndSendForChannel, err=%s", err.Error())
 	}
 }
@@ -120,9 +193,6 @@ func (a *GreenfieldAssembler) process(channelId types.ChannelId, inturnRelayer *
 		}
 		startSeq = a.inturnRelayerSequenceStatusMap[channelId].NextDeliverySeq
 	} else {
-		a.mutex.Lock()
-		a.inturnRelayerSequenceStatusMap[channelId].HasRetrieved = false
-		a.mutex.Unlock()
 		time.Sleep(time.Duration(a.config.RelayConfig.BSCSequenceUpdateLatency) * time.Second)
 		var err error
 		startSeq, err = a.greenfieldExecutor.GetNextDeliverySequenceForChannelWithRetry(channelId)
@@ -131,12 +201,13 @@ func (a *GreenfieldAssembler) process(channelId types.ChannelId, inturnRelayer *
 		}
 	}
 
+	a.idle.observe(uint8(channelId), startSeq)
 	err := a.updateMetrics(channelId, startSeq)
 	if err != nil {
 		return err
 	}
 
-	endSequence, err := a.daoManager.GreenfieldDao.GetLatestSequenceByChannelIdAndStatus(channelId, db.AllVoted)
+	endSequence, err := a.daoManager.GreenfieldDao.GetSequenceWatermark(channelId, db.AllVoted)
 	if err != nil {
 		return err
 	}
@@ -154,17 +225,72 @@ func (a *GreenfieldAssembler) process(channelId types.ChannelId, inturnRelayer *
 		if (*tx == model.GreenfieldRelayTransaction{}) {
 			return nil
 		}
-		if tx.Status != db.AllVoted && tx.Status != db.Delivered {
-			return fmt.Errorf("tx with channel id %d and sequence %d does not get enough votes yet", tx.ChannelId, tx.Sequence)
+		// sequences are delivered in order, later sequences wait until the skipped one is delivered by others
+		if tx.Status == db.Skipped {
+			logging.Logger.Infof("tx with channel id %d and sequence %d is skipped, wait for it to be delivered, cid=%s", tx.ChannelId, tx.Sequence,
+				common.CorrelationId(metric.DirectionGnfdToBSC, tx.ChannelId, tx.Sequence))
+			return nil
+		}
+		if tx.Status == db.NeedsAttention {
+			logging.Logger.Debugf("tx with channel id %d and sequence %d needs attention, wait for it to be retried, cid=%s", tx.ChannelId, tx.Sequence,
+				common.CorrelationId(metric.DirectionGnfdToBSC, tx.ChannelId, tx.Sequence))
+			return nil
+		}
+		if !tx.Status.HasEnoughVotes() {
+			return fmt.Errorf("%w, tx with channel id %d and sequence %d does not get enough votes yet", common.ErrNotEnoughVotes, tx.ChannelId, tx.Sequence)
+		}
+		// defer to the next in-turn relayer rather than sending a claim which would land after the turn
+		if isInturnRelyer && inturnWindowClosing(inturnRelayer.End, a.inclusionLatency) {
+			logging.Logger.Infof("in-turn interval ends at %d, defer channel %d and sequence %d to the next relayer, cid=%s", inturnRelayer.End, tx.ChannelId, tx.Sequence,
+				common.CorrelationId(metric.DirectionGnfdToBSC, tx.ChannelId, tx.Sequence))
+			return nil
 		}
 		if !isInturnRelyer && time.Now().Unix() < tx.TxTime+a.config.RelayConfig.GreenfieldToBSCInturnRelayerTimeout {
 			return nil
 		}
+		// claims of out-turn relayers are not essential, they are paused when the daily gas budget is exceeded
+		if !isInturnRelyer && a.bscExecutor.GasBudgetExceeded() {
+			return nil
+		}
+		if a.waitForLowerGasPrice(tx, isInturnRelyer) {
+			return nil
+		}
+		if err := hook.Run(hook.GreenfieldTxEvent(hook.PointBeforeClaim, tx)); err != nil {
+			logging.Logger.Infof("skip claiming tx with channel id %d and sequence %d, err=%s", tx.ChannelId, tx.Sequence, err.Error())
+			return nil
+		}
+		// avoid racing with other non-inturn relayers for the same sequence
+		if !isInturnRelyer && !a.coordinator.TryClaim(metric.DirectionGnfdToBSC, tx.ChannelId, tx.Sequence) {
+			return nil
+		}
+		if err := a.ensureLightClientSynced(tx, isInturnRelyer); err != nil {
+			return err
+		}
+		// avoid claiming the same sequence with other workers sharing the DB
+		locked, err := lockSequence(a.daoManager, metric.DirectionGnfdToBSC, tx.ChannelId, tx.Sequence)
+		if err != nil || !locked {
+			return err
+		}
 
 		if err := a.processTx(tx, a.relayerNonceStatus.Nonce, isInturnRelyer); err != nil {
+			unlockSequence(a.daoManager, metric.DirectionGnfdToBSC, tx.ChannelId, tx.Sequence)
+			if errors.Is(err, errClaimUnprofitable) {
+				logging.Logger.Infof("skip claiming tx with channel id %d and sequence %d, err=%s", tx.ChannelId, tx.Sequence, err.Error())
+				return nil
+			}
+			if errors.Is(err, common.ErrNonceMismatch) || errors.Is(err, common.ErrSequenceMismatch) {
+				// the cached sequence or nonce is outdated, re-fetch them from chain in next round
+				a.mutex.Lock()
+				a.relayerNonceStatus.HasRetrieved = false
+				reset := resetSequenceStatus(a.inturnRelayerSequenceStatusMap[channelId])
+				a.mutex.Unlock()
+				if reset {
+					recordSequenceReset(a.metricService, a.eventBus, metric.DirectionGnfdToBSC, uint8(channelId), resetMismatch)
+				}
+			}
 			return err
 		}
-		logging.Logger.Infof("relayed tx with channel id %d and sequence %d ", tx.ChannelId, tx.Sequence)
+		logging.Logger.Infof("relayed tx with channel id %d and sequence %d, cid=%s", tx.ChannelId, tx.Sequence, common.CorrelationId(metric.DirectionGnfdToBSC, tx.ChannelId, tx.Sequence))
 		a.mutex.Lock()
 		a.relayerNonceStatus.Nonce++
 		a.mutex.Unlock()
@@ -172,11 +298,71 @@ func (a *GreenfieldAssembler) process(channelId types.ChannelId, inturnRelayer *
 	return nil
 }
 
+// ensureLightClientSynced verifies that the light client on BSC has the validator set in effect at the height of the
+// package, i.e. the light block of the latest validator set change at or below the height is synced, otherwise the
+// claim would revert. The in-turn relayer re-syncs the light blocks of the changes the light client has missed if their
+// sync txs have not landed.
+func (a *GreenfieldAssembler) ensureLightClientSynced(tx *model.GreenfieldRelayTransaction, isInturnRelyer bool) error {
+	lightClientHeight, err := a.bscExecutor.GetLightClientLatestHeight()
+	if err != nil {
+		return err
+	}
+	if lightClientHeight >= tx.Height {
+		return nil
+	}
+	changes, err := a.daoManager.GreenfieldDao.GetSyncedTransactionsInRange(lightClientHeight, tx.Height)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	behindErr := fmt.Errorf("%w, light client height %d is below the validator set change at height %d, cid=%s", common.ErrLightClientBehind,
+		lightClientHeight, changes[len(changes)-1].Height, common.CorrelationId(metric.DirectionGnfdToBSC, tx.ChannelId, tx.Sequence))
+	if !isInturnRelyer {
+		return behindErr
+	}
+	// give the last sync tx time to land before sending another one
+	a.mutex.Lock()
+	if time.Since(a.lastHeaderSyncAt) < common.SleepTimeAfterSyncLightBlock {
+		a.mutex.Unlock()
+		return behindErr
+	}
+	a.lastHeaderSyncAt = time.Now()
+	// the sync tx takes a nonce of the relayer, re-fetch it in next round
+	a.relayerNonceStatus.HasRetrieved = false
+	a.mutex.Unlock()
+
+	// the light blocks of all the validator set changes the light client has missed are re-synced in height order
+	heights := make([]uint64, 0, len(changes))
+	for _, change := range changes {
+		heights = append(heights, change.Height)
+	}
+	txHashes, syncErr := a.bscExecutor.SyncTendermintLightBlocks(heights)
+	if err := a.daoManager.ExecTx(func(txManager *dao.DaoManager) error {
+		for i, txHash := range txHashes {
+			if err := txManager.GreenfieldDao.UpdateSyncLightBlockTxHash(changes[i].Id, txHash.String()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	for i, txHash := range txHashes {
+		logging.Logger.Infof("re-synced light block at height %d with txHash %s", changes[i].Height, txHash.String())
+	}
+	if syncErr != nil {
+		return fmt.Errorf("failed to re-sync light blocks, err=%w", syncErr)
+	}
+	return behindErr
+}
+
 func (a *GreenfieldAssembler) processTx(tx *model.GreenfieldRelayTransaction, nonce uint64, isInturnRelyer bool) error {
 	// Get votes result for a tx, which are already validated and qualified to aggregate sig
 	votes, err := a.daoManager.VoteDao.GetVotesByChannelIdAndSequence(tx.ChannelId, tx.Sequence)
 	if err != nil {
-		logging.Logger.Errorf("failed to get votes for event with channel id %d and sequence %d", tx.ChannelId, tx.Sequence)
+		logging.Logger.Errorf("failed to get votes for event with channel id %d and sequence %d, cid=%s", tx.ChannelId, tx.Sequence, common.CorrelationId(metric.DirectionGnfdToBSC, tx.ChannelId, tx.Sequence))
 		return err
 	}
 
@@ -184,47 +370,148 @@ func (a *GreenfieldAssembler) processTx(tx *model.GreenfieldRelayTransaction, no
 	if err != nil {
 		return err
 	}
+	votes, err = dropStaleVotes(a.daoManager, tx.ChannelId, tx.Sequence, votes, vote.BSCRelayerBlsKeys(validators), func(txManager *dao.DaoManager) error {
+		return txManager.GreenfieldDao.UpdateTransactionStatus(tx.Id, db.SelfVoted)
+	})
+	if err != nil {
+		return err
+	}
 	aggregatedSignature, valBitSet, err := vote.AggregateSignatureAndValidatorBitSet(votes, validators)
 	if err != nil {
 		return err
 	}
+	if !isInturnRelyer {
+		if err := a.checkClaimReward(tx, aggregatedSignature, util.BitSetToBigInt(valBitSet), votes[0].ClaimPayload); err != nil {
+			return err
+		}
+	}
 
+	// the nonce is persisted before broadcast, so that it is known which claim used it after a crash
+	if err = a.daoManager.NonceDao.ReserveNonce(&model.NonceReservation{
+		Direction:    metric.DirectionGnfdToBSC,
+		Nonce:        nonce,
+		ChannelId:    tx.ChannelId,
+		Sequence:     tx.Sequence,
+		ReservedTime: time.Now().Unix(),
+	}); err != nil {
+		return err
+	}
+	a.eventBus.Publish(&events.Event{
+		Type:        events.EventTypeClaimAttempt,
+		Direction:   metric.DirectionGnfdToBSC,
+		ChannelId:   tx.ChannelId,
+		Sequence:    tx.Sequence,
+		PackageType: tx.PackageType,
+		Height:      tx.Height,
+		Nonce:       nonce,
+		Time:        time.Now().Unix(),
+	})
 	txHash, err := a.bscExecutor.CallBuildInSystemContract(aggregatedSignature, util.BitSetToBigInt(valBitSet), votes[0].ClaimPayload, nonce)
 	if err != nil {
+		a.diagnostic.capture(metric.DirectionGnfdToBSC, tx.ChannelId, tx.Sequence, nonce, votes, validators, err)
+		recordClaimFailure(a.config, metric.DirectionGnfdToBSC, tx.ChannelId, tx.Sequence, err, func(maxFailures uint32) (bool, error) {
+			return a.daoManager.GreenfieldDao.IncreaseClaimFailures(tx.Id, maxFailures)
+		})
+		a.eventBus.Publish(&events.Event{
+			Type:        events.EventTypeClaimFailure,
+			Direction:   metric.DirectionGnfdToBSC,
+			ChannelId:   tx.ChannelId,
+			Sequence:    tx.Sequence,
+			PackageType: tx.PackageType,
+			Height:      tx.Height,
+			Nonce:       nonce,
+			Error:       err.Error(),
+			Time:        time.Now().Unix(),
+		})
 		return err
 	}
 
-	logging.Logger.Infof("relayed transaction with channel id %d and sequence %d, get txHash %s", tx.ChannelId, tx.Sequence, txHash)
-	a.metricService.SetGnfdProcessedBlockHeight(tx.Height)
+	logging.Logger.Infof("relayed transaction with channel id %d and sequence %d, get txHash %s, cid=%s", tx.ChannelId, tx.Sequence, txHash, common.CorrelationId(metric.DirectionGnfdToBSC, tx.ChannelId, tx.Sequence))
+	a.metricService.ObserveClaimLatency(metric.DirectionGnfdToBSC, tx.ChannelId, tx.Sequence, time.Now().Unix()-tx.TxTime)
+	if err := a.claimJournal.Append(&journal.ClaimEntry{
+		Direction: metric.DirectionGnfdToBSC,
+		ChannelId: tx.ChannelId,
+		Sequence:  tx.Sequence,
+		Nonce:     nonce,
+		TxHash:    txHash.String(),
+		Inturn:    isInturnRelyer,
+		Time:      time.Now().Unix(),
+	}); err != nil {
+		logging.Logger.Errorf("failed to journal claim tx %s, err=%s", txHash.String(), err.Error())
+	}
+	a.metricService.SetProcessedBlockHeight(metric.ChainGreenfield, tx.Height)
+	a.eventBus.Publish(&events.Event{
+		Type:        events.EventTypeClaim,
+		Direction:   metric.DirectionGnfdToBSC,
+		ChannelId:   tx.ChannelId,
+		Sequence:    tx.Sequence,
+		PackageType: tx.PackageType,
+		Height:      tx.Height,
+		ClaimTxHash: txHash.String(),
+		Delivered:   isInturnRelyer,
+		Nonce:       nonce,
+		Time:        time.Now().Unix(),
+	})
 
 	// update next delivery sequence in DB for inturn relayer, for non-inturn relayer, there is enough time for
 	// sequence update, so they can track next start seq from chain
+	status := db.Delivered
 	if !isInturnRelyer {
-		if err = a.daoManager.GreenfieldDao.UpdateTransactionClaimedTxHash(tx.Id, txHash.String()); err != nil {
+		status = db.Claimed
+	}
+	// the reservation and the tx are updated together, so that a claimed nonce is never left without tx hash
+	if err = a.daoManager.ExecTx(func(txManager *dao.DaoManager) error {
+		if err := txManager.NonceDao.UpdateNonceReservationTxHash(metric.DirectionGnfdToBSC, nonce, txHash.String()); err != nil {
 			return err
 		}
-		return nil
-	}
-
-	if err = a.daoManager.GreenfieldDao.UpdateTransactionStatusAndClaimedTxHash(tx.Id, db.Delivered, txHash.String()); err != nil {
+		return txManager.GreenfieldDao.UpdateTransactionStatusAndClaimedTxHash(tx.Id, status, txHash.String())
+	}); err != nil {
 		return err
 	}
+	if !isInturnRelyer {
+		return nil
+	}
+	event := hook.GreenfieldTxEvent(hook.PointAfterDelivery, tx)
+	event.ClaimTxHash = txHash.String()
+	hook.Notify(event)
 	a.mutex.Lock()
 	a.inturnRelayerSequenceStatusMap[types.ChannelId(tx.ChannelId)].NextDeliverySeq = tx.Sequence + 1
 	a.mutex.Unlock()
 	return nil
 }
 
-func (a *GreenfieldAssembler) getMonitorChannels() []uint8 {
-	return a.config.GreenfieldConfig.MonitorChannelList
+// checkClaimReward returns errClaimUnprofitable if the relayer fee of the package is below non_inturn_min_reward_percent
+// of the estimated fee of claiming it
+func (a *GreenfieldAssembler) checkClaimReward(tx *model.GreenfieldRelayTransaction, blsSignature []byte, validatorSet *big.Int, payload []byte) error {
+	minPercent := a.config.RelayConfig.NonInturnMinRewardPercent
+	if minPercent == 0 {
+		return nil
+	}
+	reward, ok := new(big.Int).SetString(tx.RelayerFee, 10)
+	if !ok {
+		return fmt.Errorf("invalid relayer fee %s of channel id %d and sequence %d", tx.RelayerFee, tx.ChannelId, tx.Sequence)
+	}
+	fee, err := a.bscExecutor.EstimateClaimFee(blsSignature, validatorSet, payload)
+	if err != nil {
+		return err
+	}
+	if rewardCoversFee(reward, fee, minPercent) {
+		return nil
+	}
+	return fmt.Errorf("%w, relayer fee %s is below %d%% of the estimated claim fee %s", errClaimUnprofitable, reward, minPercent, fee)
+}
+
+// rewardCoversFee returns whether reward is at least minPercent of fee
+func rewardCoversFee(reward, fee *big.Int, minPercent int) bool {
+	return new(big.Int).Mul(reward, big.NewInt(100)).Cmp(new(big.Int).Mul(fee, big.NewInt(int64(minPercent)))) >= 0
 }
 
 func (a *GreenfieldAssembler) updateMetrics(channelId types.ChannelId, nextDeliverySeq uint64) error {
-	a.metricService.SetNextReceiveSequenceForChannel(uint8(channelId), nextDeliverySeq)
+	a.metricService.SetNextReceiveSequence(metric.DirectionGnfdToBSC, uint8(channelId), nextDeliverySeq)
 	nextSendSeq, err := a.greenfieldExecutor.GetNextSendSequenceForChannelWithRetry(channelId)
 	if err != nil {
 		return err
 	}
-	a.metricService.SetNextSendSequenceForChannel(uint8(channelId), nextSendSeq)
+	a.metricService.SetNextSendSequence(metric.DirectionGnfdToBSC, uint8(channelId), nextSendSeq)
 	return nil
 }
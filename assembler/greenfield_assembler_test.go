@@ -0,0 +1,16 @@
+package assembler
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRewardCoversFee(t *testing.T) {
+	require.True(t, rewardCoversFee(big.NewInt(100), big.NewInt(100), 100))
+	require.False(t, rewardCoversFee(big.NewInt(99), big.NewInt(100), 100))
+	require.True(t, rewardCoversFee(big.NewInt(50), big.NewInt(100), 50))
+	require.True(t, rewardCoversFee(big.NewInt(0), big.NewInt(100), 0))
+	require.False(t, rewardCoversFee(big.NewInt(120), big.NewInt(100), 150))
+}
@@ -0,0 +1,108 @@
+package assembler
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bnb-chain/greenfield-relayer/common"
+	"github.com/bnb-chain/greenfield-relayer/db"
+	"github.com/bnb-chain/greenfield-relayer/logging"
+	"github.com/bnb-chain/greenfield-relayer/types"
+)
+
+// idleTracker tells whether an assembler tick has no work, i.e. every sequence with enough votes was already below
+// the next delivery sequence seen by the last full tick, so that the tick is skipped with a watermark query per
+// channel instead of querying the nonce, sequences and packages. The in-turn relayer is still polled by idle ticks.
+type idleTracker struct {
+	mutex    sync.Mutex
+	nextSeqs map[uint8]uint64 // next delivery sequence per channel seen by full ticks
+	lastRun  time.Time        // when the last full tick ran
+}
+
+func newIdleTracker() *idleTracker {
+	return &idleTracker{nextSeqs: make(map[uint8]uint64)}
+}
+
+// observe records the next delivery sequence of the channel seen by a full tick
+func (t *idleTracker) observe(channelId uint8, nextSeq uint64) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.nextSeqs[channelId] = nextSeq
+}
+
+// idle tells whether the tick at now can be skipped given the AllVoted watermarks of the channels, -1 if there is no
+// watermark. A full tick is due if it is not idle or AssembleIdleRefreshInterval has passed since the last one.
+func (t *idleTracker) idle(watermarks map[uint8]int64, now time.Time) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if now.Sub(t.lastRun) >= common.AssembleIdleRefreshInterval {
+		t.lastRun = now
+		return false
+	}
+	for channelId, watermark := range watermarks {
+		next, ok := t.nextSeqs[channelId]
+		if !ok || watermark >= int64(next) {
+			t.lastRun = now
+			return false
+		}
+	}
+	return true
+}
+
+// idleTick tells whether the tick can be skipped as no enabled channel has new sequences with enough votes
+func (a *GreenfieldAssembler) idleTick() bool {
+	watermarks := make(map[uint8]int64)
+	for _, c := range a.config.GreenfieldConfig.MonitorChannelList {
+		if !common.IsChannelEnabled(c) {
+			continue
+		}
+		watermark, err := a.daoManager.GreenfieldDao.GetSequenceWatermark(types.ChannelId(c), db.AllVoted)
+		if err != nil {
+			logging.Logger.Errorf("failed to get watermark of channel %d, err=%s", c, err.Error())
+			return false
+		}
+		watermarks[c] = watermark
+	}
+	return a.idle.idle(watermarks, time.Now())
+}
+
+// idleTick tells whether the tick can be skipped as there are no new oracle sequences with enough votes
+func (a *BSCAssembler) idleTick(channelId types.ChannelId) bool {
+	watermark, err := a.daoManager.BSCDao.GetOracleSequenceWatermark(db.AllVoted)
+	if err != nil {
+		logging.Logger.Errorf("failed to get oracle sequence watermark, err=%s", err.Error())
+		return false
+	}
+	return a.idle.idle(map[uint8]int64{uint8(channelId): watermark}, time.Now())
+}
+
+// pollInturnRelayer refreshes the in-turn status on an idle tick, as it is a cheap read and turn changes should not
+// wait for the next full tick. It returns whether the turn changed, in which case the tick runs in full.
+func (a *GreenfieldAssembler) pollInturnRelayer() bool {
+	inturnRelayer, err := a.bscExecutor.GetInturnRelayer()
+	if err != nil {
+		logging.Logger.Errorf("failed to poll in-turn relayer on idle tick, err=%s", err.Error())
+		return false
+	}
+	_, changed, err := a.updateInturnRelayer(inturnRelayer)
+	if err != nil {
+		logging.Logger.Errorf("failed to decode in-turn relayer key, err=%s", err.Error())
+		return false
+	}
+	return changed
+}
+
+// pollInturnRelayer refreshes the in-turn status on an idle tick, see GreenfieldAssembler.pollInturnRelayer
+func (a *BSCAssembler) pollInturnRelayer() bool {
+	inturnRelayer, err := a.greenfieldExecutor.GetInturnRelayer()
+	if err != nil {
+		logging.Logger.Errorf("failed to poll in-turn relayer on idle tick, err=%s", err.Error())
+		return false
+	}
+	_, changed, err := a.updateInturnRelayer(inturnRelayer)
+	if err != nil {
+		logging.Logger.Errorf("failed to decode in-turn relayer key, err=%s", err.Error())
+		return false
+	}
+	return changed
+}
@@ -0,0 +1,29 @@
+package assembler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-relayer/common"
+)
+
+func TestIdleTracker(t *testing.T) {
+	tracker := newIdleTracker()
+	now := time.Now()
+	// the first tick is full
+	require.False(t, tracker.idle(map[uint8]int64{1: 9}, now))
+	tracker.observe(1, 10)
+	require.True(t, tracker.idle(map[uint8]int64{1: 9}, now.Add(time.Second)))
+	require.True(t, tracker.idle(map[uint8]int64{1: -1}, now.Add(time.Second)))
+	// a new sequence gets enough votes
+	require.False(t, tracker.idle(map[uint8]int64{1: 10}, now.Add(2*time.Second)))
+	tracker.observe(1, 11)
+	// channels not seen by full ticks are not idle
+	require.False(t, tracker.idle(map[uint8]int64{1: 10, 2: 0}, now.Add(3*time.Second)))
+	tracker.observe(2, 1)
+	require.True(t, tracker.idle(map[uint8]int64{1: 10, 2: 0}, now.Add(4*time.Second)))
+	// a full tick is due after the refresh interval
+	require.False(t, tracker.idle(map[uint8]int64{1: 10, 2: 0}, now.Add(4*time.Second+common.AssembleIdleRefreshInterval)))
+}
@@ -0,0 +1,11 @@
+package assembler
+
+import (
+	"time"
+)
+
+// inturnWindowClosing tells whether the in-turn interval ending at end(unix timestamp in second) has less time left than
+// the expected inclusion latency of a claim, a claim sent then is likely to land after the turn and get rejected
+func inturnWindowClosing(end uint64, inclusionLatency time.Duration) bool {
+	return time.Until(time.Unix(int64(end), 0)) < inclusionLatency
+}
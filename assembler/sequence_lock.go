@@ -0,0 +1,44 @@
+package assembler
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/bnb-chain/greenfield-relayer/common"
+	"github.com/bnb-chain/greenfield-relayer/db/dao"
+	"github.com/bnb-chain/greenfield-relayer/logging"
+)
+
+// lockOwner identifies the assemblers of this process in sequence locks
+var lockOwner = newLockOwner()
+
+func newLockOwner() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%d-%d", host, os.Getpid(), time.Now().UnixNano())
+}
+
+// lockSequence locks the sequence before it is claimed, false is returned if it is being claimed by another worker. A
+// lock is kept until it expires after a successful claim, so that workers which read the status of the sequence before
+// the claim do not claim it again, and released by unlockSequence after a failed one.
+func lockSequence(daoManager *dao.DaoManager, direction string, channelId uint8, sequence uint64) (bool, error) {
+	now := time.Now()
+	locked, err := daoManager.LockDao.TryLockSequence(direction, channelId, sequence, lockOwner, now.Unix(), now.Add(common.SequenceLockTTL).Unix())
+	if err != nil {
+		return false, err
+	}
+	if !locked {
+		logging.Logger.Infof("sequence %d of channel %d is being claimed by another worker, cid=%s", sequence, channelId,
+			common.CorrelationId(direction, channelId, sequence))
+	}
+	return locked, nil
+}
+
+func unlockSequence(daoManager *dao.DaoManager, direction string, channelId uint8, sequence uint64) {
+	if err := daoManager.LockDao.UnlockSequence(direction, channelId, sequence, lockOwner); err != nil {
+		logging.Logger.Errorf("failed to unlock sequence %d of channel %d, err=%s", sequence, channelId, err.Error())
+	}
+}
@@ -0,0 +1,44 @@
+package assembler
+
+import (
+	"fmt"
+
+	"github.com/bnb-chain/greenfield-relayer/common"
+	"github.com/bnb-chain/greenfield-relayer/db/dao"
+	"github.com/bnb-chain/greenfield-relayer/db/model"
+	"github.com/bnb-chain/greenfield-relayer/logging"
+	"github.com/bnb-chain/greenfield-relayer/vote"
+)
+
+// dropStaleVotes deletes votes signed by BLS keys which validators no longer register, blsKeys are the registered keys
+// of all validators, since a claim aggregating them would not match the on-chain keys. If the remaining votes are not
+// more than 2/3 of validators, recollect is called in the same transaction to send the package back to vote collection.
+func dropStaleVotes(daoManager *dao.DaoManager, channelId uint8, sequence uint64, votes []*model.Vote, blsKeys []string,
+	recollect func(txManager *dao.DaoManager) error) ([]*model.Vote, error) {
+	valid, stale := vote.SplitVotesByBlsKeys(votes, blsKeys)
+	if len(stale) == 0 {
+		return votes, nil
+	}
+	staleIds := make([]int64, 0, len(stale))
+	for _, v := range stale {
+		staleIds = append(staleIds, v.Id)
+		logging.Logger.Infof("BLS key %s of vote for channel %d and sequence %d is not registered any more", v.PubKey, channelId, sequence)
+	}
+	enough := len(valid) > len(blsKeys)*2/3
+	if err := daoManager.ExecTx(func(txManager *dao.DaoManager) error {
+		if err := txManager.VoteDao.DeleteVotesByIds(staleIds); err != nil {
+			return err
+		}
+		if enough {
+			return nil
+		}
+		return recollect(txManager)
+	}); err != nil {
+		return nil, err
+	}
+	if !enough {
+		return nil, fmt.Errorf("%w, %d votes for channel %d and sequence %d are signed by stale BLS keys, re-collect votes",
+			common.ErrNotEnoughVotes, len(stale), channelId, sequence)
+	}
+	return valid, nil
+}
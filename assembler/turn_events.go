@@ -0,0 +1,67 @@
+package assembler
+
+import (
+	"time"
+
+	"github.com/bnb-chain/greenfield-relayer/events"
+	"github.com/bnb-chain/greenfield-relayer/metric"
+	"github.com/bnb-chain/greenfield-relayer/types"
+)
+
+const (
+	turnEnter = "enter"
+	turnLeave = "leave"
+
+	// reasons of resetting the next delivery sequence cached by the in-turn relayer
+	resetTurnEnded = "turn_ended"
+	resetMismatch  = "mismatch" // the nonce or sequence of a claim is rejected by the destination chain
+)
+
+// turnTransition returns whether the relayer enters or leaves in-turn status given the end of the previous in-turn
+// interval, 0 if it was not in turn. Consecutive in-turn intervals count as entering the later one.
+func turnTransition(prevEnd uint64, isInturnRelayer bool, end uint64) string {
+	if isInturnRelayer && end != prevEnd {
+		return turnEnter
+	}
+	if !isInturnRelayer && prevEnd != 0 {
+		return turnLeave
+	}
+	return ""
+}
+
+// recordTurnTransition counts the transition and publishes it to the event bus, nothing is recorded for an empty one
+func recordTurnTransition(ms *metric.MetricService, bus *events.Bus, direction, transition string) {
+	if transition == "" {
+		return
+	}
+	ms.IncInturnTransitions(direction, transition)
+	eventType := events.EventTypeInturnEnter
+	if transition == turnLeave {
+		eventType = events.EventTypeInturnLeave
+	}
+	bus.Publish(&events.Event{
+		Type:      eventType,
+		Direction: direction,
+		Time:      time.Now().Unix(),
+	})
+}
+
+// resetSequenceStatus marks the sequence status as not retrieved, returns whether it was retrieved. The caller holds
+// the mutex guarding the status.
+func resetSequenceStatus(s *types.SequenceStatus) bool {
+	retrieved := s.HasRetrieved
+	s.HasRetrieved = false
+	return retrieved
+}
+
+// recordSequenceReset counts the reset of the sequence status of the channel and publishes it to the event bus
+func recordSequenceReset(ms *metric.MetricService, bus *events.Bus, direction string, channelId uint8, reason string) {
+	ms.IncSequenceStatusResets(direction, reason)
+	bus.Publish(&events.Event{
+		Type:      events.EventTypeSequenceReset,
+		Direction: direction,
+		ChannelId: channelId,
+		Reason:    reason,
+		Time:      time.Now().Unix(),
+	})
+}
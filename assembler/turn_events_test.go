@@ -0,0 +1,15 @@
+package assembler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTurnTransition(t *testing.T) {
+	require.Equal(t, turnEnter, turnTransition(0, true, 100))
+	require.Equal(t, "", turnTransition(100, true, 100))
+	require.Equal(t, turnEnter, turnTransition(100, true, 200))
+	require.Equal(t, turnLeave, turnTransition(100, false, 0))
+	require.Equal(t, "", turnTransition(0, false, 0))
+}
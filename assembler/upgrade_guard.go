@@ -0,0 +1,38 @@
+package assembler
+
+import (
+	"github.com/bnb-chain/greenfield-relayer/config"
+	"github.com/bnb-chain/greenfield-relayer/logging"
+)
+
+// upgradeGuard pauses claims to a chain around its known upgrade heights, when claims are likely to fail and nodes
+// may behave differently, and resumes them automatically once the chain passes the upgrade
+type upgradeGuard struct {
+	chain    string
+	upgrades []config.ChainUpgrade
+	paused   *config.ChainUpgrade
+}
+
+func newUpgradeGuard(chain string, upgrades []config.ChainUpgrade) *upgradeGuard {
+	return &upgradeGuard{
+		chain:    chain,
+		upgrades: upgrades,
+	}
+}
+
+// shouldPause tells whether claims should be paused at the latest height of the destination chain, 0 means the height
+// is unknown and claims are not paused
+func (g *upgradeGuard) shouldPause(height uint64) bool {
+	if height == 0 {
+		return false
+	}
+	upgrade := config.UpgradeAt(g.upgrades, height)
+	if upgrade != nil && g.paused == nil {
+		logging.Logger.Infof("pause claims to %s at height %d for upgrade %s at height %d", g.chain, height, upgrade.Name, upgrade.Height)
+	}
+	if upgrade == nil && g.paused != nil {
+		logging.Logger.Infof("resume claims to %s at height %d after upgrade %s", g.chain, height, g.paused.Name)
+	}
+	g.paused = upgrade
+	return upgrade != nil
+}
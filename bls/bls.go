@@ -0,0 +1,108 @@
+// Package bls abstracts the BLS operations of the relayer behind a Backend, implemented by blst and by a pure Go
+// fallback for platforms where the assembly or cgo of blst is problematic.
+package bls
+
+import (
+	"github.com/pkg/errors"
+	blscmn "github.com/prysmaticlabs/prysm/crypto/bls/common"
+
+	"github.com/bnb-chain/greenfield-relayer/config"
+)
+
+type (
+	SecretKey = blscmn.SecretKey
+	PublicKey = blscmn.PublicKey
+	Signature = blscmn.Signature
+)
+
+// Backend implements the BLS operations, keys and signatures of different backends should not be mixed
+type Backend interface {
+	RandKey() (SecretKey, error)
+	SecretKeyFromBytes(privKey []byte) (SecretKey, error)
+	PublicKeyFromBytes(pubKey []byte) (PublicKey, error)
+	SignatureFromBytes(sig []byte) (Signature, error)
+	AggregatePublicKeys(pubKeys [][]byte) (PublicKey, error)
+	AggregateSignatures(sigs []Signature) Signature
+}
+
+// NewBackend returns the backend of name, empty name means blst if it is available in the build, otherwise pure Go
+func NewBackend(name string) (Backend, error) {
+	switch name {
+	case "":
+		return defaultBackend(), nil
+	case config.BlsBackendGo:
+		return pureGoBackend{}, nil
+	case config.BlsBackendBlst:
+		if b := newBlstBackend(); b != nil {
+			return b, nil
+		}
+		return nil, errors.Errorf("bls backend %s is not available in this build", name)
+	default:
+		return nil, errors.Errorf("unknown bls backend %s", name)
+	}
+}
+
+func defaultBackend() Backend {
+	if b := newBlstBackend(); b != nil {
+		return b
+	}
+	return pureGoBackend{}
+}
+
+var backend = defaultBackend()
+
+// SetBackend sets the backend used by the package functions
+func SetBackend(name string) error {
+	b, err := NewBackend(name)
+	if err != nil {
+		return err
+	}
+	backend = b
+	return nil
+}
+
+// RandKey creates a random secret key
+func RandKey() (SecretKey, error) {
+	return backend.RandKey()
+}
+
+// SecretKeyFromBytes creates a secret key from big endian bytes
+func SecretKeyFromBytes(privKey []byte) (SecretKey, error) {
+	return backend.SecretKeyFromBytes(privKey)
+}
+
+// PublicKeyFromBytes creates a public key from its compressed form
+func PublicKeyFromBytes(pubKey []byte) (PublicKey, error) {
+	return backend.PublicKeyFromBytes(pubKey)
+}
+
+// SignatureFromBytes creates a signature from its compressed form
+func SignatureFromBytes(sig []byte) (Signature, error) {
+	return backend.SignatureFromBytes(sig)
+}
+
+// MultipleSignaturesFromBytes creates signatures from their compressed forms
+func MultipleSignaturesFromBytes(sigs [][]byte) ([]Signature, error) {
+	if len(sigs) == 0 {
+		return nil, errors.New("0 signatures provided to the method")
+	}
+	res := make([]Signature, 0, len(sigs))
+	for _, s := range sigs {
+		sig, err := backend.SignatureFromBytes(s)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, sig)
+	}
+	return res, nil
+}
+
+// AggregatePublicKeys aggregates public keys of compressed forms into a single key
+func AggregatePublicKeys(pubKeys [][]byte) (PublicKey, error) {
+	return backend.AggregatePublicKeys(pubKeys)
+}
+
+// AggregateSignatures aggregates signatures into a single signature, it returns nil if sigs is empty
+func AggregateSignatures(sigs []Signature) Signature {
+	return backend.AggregateSignatures(sigs)
+}
@@ -0,0 +1,85 @@
+package bls
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-relayer/config"
+)
+
+func TestPureGoBackendVectors(t *testing.T) {
+	b := pureGoBackend{}
+	privKey, err := b.SecretKeyFromBytes(decode(t, "263dbd792f5b1be47ed85f8938c0f29586af0d3ac7b977f21c278fe1462040e3"))
+	require.NoError(t, err)
+	require.Equal(t, "a491d1b0ecd9bb917989f0e74f0dea0422eac4a873e5e2644f368dffb9a6e20fd6e10c1b77654d067c0618f6e5a7f79a",
+		hex.EncodeToString(privKey.PublicKey().Marshal()))
+	// signature of 32 zero bytes, from the BLS test vectors of the Ethereum consensus specs
+	sig := privKey.Sign(make([]byte, 32))
+	require.Equal(t, "b6ed936746e01f8ecf281f020953fbf1f01debd5657c4a383940b020b26507f6076334f91e2366c96e9ab279fb5158090352ea1c5b0c9274504f4f0e7053af24802e51e4568d164fe986834f41e55c8e850ce1f98458c0cfc9ab380b55285a55",
+		hex.EncodeToString(sig.Marshal()))
+
+	require.Equal(t, "68a985b87eb6b46952128911f2a4412bbc302a9d759667f87f7a21d803f07235",
+		hex.EncodeToString(expandMessageXMD(nil, []byte("QUUX-V01-CS02-with-expander-SHA256-128"), 32)))
+
+	_, err = b.PublicKeyFromBytes(append([]byte{flagCompressed | flagInfinity}, make([]byte, publicKeyLength-1)...))
+	require.Error(t, err)
+	uncompressed := sig.Marshal()
+	uncompressed[0] &^= flagCompressed
+	_, err = b.SignatureFromBytes(uncompressed)
+	require.Error(t, err)
+}
+
+func TestBackends(t *testing.T) {
+	var backends []Backend
+	for _, name := range []string{config.BlsBackendGo, config.BlsBackendBlst} {
+		// blst is not available in builds with the tag blst_disabled
+		if b, err := NewBackend(name); err == nil {
+			backends = append(backends, b)
+		}
+	}
+	_, err := NewBackend("unknown")
+	require.Error(t, err)
+
+	msg := [32]byte{1}
+	var privKeys [][]byte
+	for i := 0; i < 3; i++ {
+		privKey, err := pureGoBackend{}.RandKey()
+		require.NoError(t, err)
+		privKeys = append(privKeys, privKey.Marshal())
+	}
+	// keys and signatures serialized by a backend are verified by the others
+	for _, signer := range backends {
+		var pubKeys, sigs [][]byte
+		for _, k := range privKeys {
+			privKey, err := signer.SecretKeyFromBytes(k)
+			require.NoError(t, err)
+			pubKeys = append(pubKeys, privKey.PublicKey().Marshal())
+			sigs = append(sigs, privKey.Sign(msg[:]).Marshal())
+		}
+		for _, verifier := range backends {
+			var signatures []Signature
+			for _, s := range sigs {
+				sig, err := verifier.SignatureFromBytes(s)
+				require.NoError(t, err)
+				signatures = append(signatures, sig)
+			}
+			sig, err := verifier.SignatureFromBytes(verifier.AggregateSignatures(signatures).Marshal())
+			require.NoError(t, err)
+			aggregated, err := verifier.AggregatePublicKeys(pubKeys)
+			require.NoError(t, err)
+			require.True(t, sig.Verify(aggregated, msg[:]))
+
+			aggregated, err = verifier.AggregatePublicKeys(pubKeys[1:])
+			require.NoError(t, err)
+			require.False(t, sig.Verify(aggregated, msg[:]))
+		}
+	}
+}
+
+func decode(t *testing.T, s string) []byte {
+	bz, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return bz
+}
@@ -0,0 +1,38 @@
+//go:build ((linux && amd64) || (linux && arm64) || (darwin && amd64) || (darwin && arm64) || (windows && amd64)) && cgo && !blst_disabled
+
+package bls
+
+import (
+	"github.com/prysmaticlabs/prysm/crypto/bls/blst"
+)
+
+// blstBackend implements the BLS operations by blst, it is only built with cgo on the platforms supported by prysm
+type blstBackend struct{}
+
+func newBlstBackend() Backend {
+	return blstBackend{}
+}
+
+func (blstBackend) RandKey() (SecretKey, error) {
+	return blst.RandKey()
+}
+
+func (blstBackend) SecretKeyFromBytes(privKey []byte) (SecretKey, error) {
+	return blst.SecretKeyFromBytes(privKey)
+}
+
+func (blstBackend) PublicKeyFromBytes(pubKey []byte) (PublicKey, error) {
+	return blst.PublicKeyFromBytes(pubKey)
+}
+
+func (blstBackend) SignatureFromBytes(sig []byte) (Signature, error) {
+	return blst.SignatureFromBytes(sig)
+}
+
+func (blstBackend) AggregatePublicKeys(pubKeys [][]byte) (PublicKey, error) {
+	return blst.AggregatePublicKeys(pubKeys)
+}
+
+func (blstBackend) AggregateSignatures(sigs []Signature) Signature {
+	return blst.AggregateSignatures(sigs)
+}
@@ -0,0 +1,8 @@
+//go:build !((linux && amd64) || (linux && arm64) || (darwin && amd64) || (darwin && arm64) || (windows && amd64)) || !cgo || blst_disabled
+
+package bls
+
+// newBlstBackend returns nil since blst is not available in the build
+func newBlstBackend() Backend {
+	return nil
+}
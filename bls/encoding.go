@@ -0,0 +1,280 @@
+package bls
+
+import (
+	"crypto/sha256"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/crypto/bls12381"
+	"github.com/pkg/errors"
+)
+
+const fieldElementLength = 48
+
+// flags in the first byte of the compressed forms of points, see
+// https://github.com/zcash/librustzcash/blob/6e0364cd42a2b3d2b958a54771ef51a8db79dd29/pairing/src/bls12_381/README.md#serialization
+const (
+	flagCompressed = 0x80
+	flagInfinity   = 0x40
+	flagSort       = 0x20
+)
+
+var (
+	fieldModulus, _ = new(big.Int).SetString("1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaaab", 16)
+	// (p-1)/2, elements larger than it are the lexicographically largest of the square roots
+	halfModulus = new(big.Int).Rsh(fieldModulus, 1)
+	// (p+1)/4, a^((p+1)/4) is a square root of a since p = 3 mod 4
+	sqrtExp = new(big.Int).Rsh(new(big.Int).Add(fieldModulus, big.NewInt(1)), 2)
+	// (p-3)/4
+	sqrtExpFp2 = new(big.Int).Rsh(new(big.Int).Sub(fieldModulus, big.NewInt(3)), 2)
+	// b of the curve y^2 = x^3 + b over Fp, and of its twist over Fp2
+	curveB = big.NewInt(4)
+	twistB = fp2{c0: big.NewInt(4), c1: big.NewInt(4)}
+)
+
+func compressG1(p *bls12381.PointG1) []byte {
+	g1 := bls12381.NewG1()
+	out := make([]byte, fieldElementLength)
+	if g1.IsZero(p) {
+		out[0] = flagCompressed | flagInfinity
+		return out
+	}
+	raw := g1.ToBytes(p)
+	copy(out, raw[:fieldElementLength])
+	out[0] |= flagCompressed
+	if new(big.Int).SetBytes(raw[fieldElementLength:]).Cmp(halfModulus) > 0 {
+		out[0] |= flagSort
+	}
+	return out
+}
+
+// decompressG1 decodes a point of G1, which is on the curve and in the subgroup
+func decompressG1(in []byte) (*bls12381.PointG1, error) {
+	x, infinity, sort, err := decodeFlags(in)
+	if err != nil {
+		return nil, err
+	}
+	g1 := bls12381.NewG1()
+	if infinity {
+		return g1.Zero(), nil
+	}
+	xe := new(big.Int).SetBytes(x)
+	if xe.Cmp(fieldModulus) >= 0 {
+		return nil, errors.New("x is not a field element")
+	}
+	y2 := new(big.Int).Exp(xe, big.NewInt(3), fieldModulus)
+	y2.Add(y2, curveB).Mod(y2, fieldModulus)
+	y := new(big.Int).Exp(y2, sqrtExp, fieldModulus)
+	if new(big.Int).Exp(y, big.NewInt(2), fieldModulus).Cmp(y2) != 0 {
+		return nil, errors.New("point is not on curve")
+	}
+	if (y.Cmp(halfModulus) > 0) != sort {
+		y.Sub(fieldModulus, y)
+	}
+	p, err := g1.FromBytes(append(x, fieldBytes(y)...))
+	if err != nil {
+		return nil, err
+	}
+	if !g1.InCorrectSubgroup(p) {
+		return nil, errors.New("point is not in group")
+	}
+	return p, nil
+}
+
+func compressG2(p *bls12381.PointG2) []byte {
+	g2 := bls12381.NewG2()
+	out := make([]byte, 2*fieldElementLength)
+	if g2.IsZero(p) {
+		out[0] = flagCompressed | flagInfinity
+		return out
+	}
+	// x and y are serialized as c1 || c0
+	raw := g2.ToBytes(p)
+	copy(out, raw[:2*fieldElementLength])
+	out[0] |= flagCompressed
+	y := fp2{
+		c0: new(big.Int).SetBytes(raw[3*fieldElementLength:]),
+		c1: new(big.Int).SetBytes(raw[2*fieldElementLength : 3*fieldElementLength]),
+	}
+	if y.largest() {
+		out[0] |= flagSort
+	}
+	return out
+}
+
+// decompressG2 decodes a point of G2, which is on the curve and in the subgroup
+func decompressG2(in []byte) (*bls12381.PointG2, error) {
+	x, infinity, sort, err := decodeFlags(in)
+	if err != nil {
+		return nil, err
+	}
+	g2 := bls12381.NewG2()
+	if infinity {
+		return g2.Zero(), nil
+	}
+	xe := fp2{
+		c0: new(big.Int).SetBytes(x[fieldElementLength:]),
+		c1: new(big.Int).SetBytes(x[:fieldElementLength]),
+	}
+	if xe.c0.Cmp(fieldModulus) >= 0 || xe.c1.Cmp(fieldModulus) >= 0 {
+		return nil, errors.New("x is not a field element")
+	}
+	y2 := xe.square().mul(xe).add(twistB)
+	y, ok := y2.sqrt()
+	if !ok {
+		return nil, errors.New("point is not on curve")
+	}
+	if y.largest() != sort {
+		y = y.neg()
+	}
+	p, err := g2.FromBytes(append(x, append(fieldBytes(y.c1), fieldBytes(y.c0)...)...))
+	if err != nil {
+		return nil, err
+	}
+	if !g2.InCorrectSubgroup(p) {
+		return nil, errors.New("point is not in group")
+	}
+	return p, nil
+}
+
+// decodeFlags returns the x coordinate without flags of a compressed point, or whether it is the point at infinity
+func decodeFlags(in []byte) (x []byte, infinity bool, sort bool, err error) {
+	if in[0]&flagCompressed == 0 {
+		return nil, false, false, errors.New("point is not compressed")
+	}
+	x = append([]byte{}, in...)
+	x[0] &^= flagCompressed | flagInfinity | flagSort
+	if in[0]&flagInfinity != 0 {
+		if in[0]&flagSort != 0 || new(big.Int).SetBytes(x).Sign() != 0 {
+			return nil, false, false, errors.New("invalid encoding of point at infinity")
+		}
+		return nil, true, false, nil
+	}
+	return x, false, in[0]&flagSort != 0, nil
+}
+
+func fieldBytes(e *big.Int) []byte {
+	return e.FillBytes(make([]byte, fieldElementLength))
+}
+
+// hashToG2 hashes msg to a point of G2 as hash_to_curve of https://www.rfc-editor.org/rfc/rfc9380 with the suite
+// BLS12381G2_XMD:SHA-256_SSWU_RO_
+func hashToG2(msg []byte) (*bls12381.PointG2, error) {
+	// 2 elements of Fp2, each coefficient is reduced from 64 bytes
+	uniform := expandMessageXMD(msg, dst, 4*64)
+	g2 := bls12381.NewG2()
+	q := g2.Zero()
+	for i := 0; i < 2; i++ {
+		c0 := new(big.Int).SetBytes(uniform[128*i : 128*i+64])
+		c1 := new(big.Int).SetBytes(uniform[128*i+64 : 128*i+128])
+		c0.Mod(c0, fieldModulus)
+		c1.Mod(c1, fieldModulus)
+		// MapToCurve clears the cofactor of each point, which is the same as clearing that of their sum
+		p, err := g2.MapToCurve(append(fieldBytes(c1), fieldBytes(c0)...))
+		if err != nil {
+			return nil, err
+		}
+		g2.Add(q, q, p)
+	}
+	return g2.Affine(q), nil
+}
+
+// expandMessageXMD is expand_message_xmd of https://www.rfc-editor.org/rfc/rfc9380 with SHA-256
+func expandMessageXMD(msg, dst []byte, length int) []byte {
+	dstPrime := append(append([]byte{}, dst...), byte(len(dst)))
+	h := sha256.New()
+	h.Write(make([]byte, h.BlockSize()))
+	h.Write(msg)
+	h.Write([]byte{byte(length >> 8), byte(length), 0})
+	h.Write(dstPrime)
+	b0 := h.Sum(nil)
+
+	out := make([]byte, 0, length)
+	// b_i = H(b_0 xor b_(i-1) || i || dst_prime), b_0 xor b_(i-1) is b_0 for i = 1
+	bi := make([]byte, sha256.Size)
+	for i := 1; len(out) < length; i++ {
+		for j := range bi {
+			bi[j] ^= b0[j]
+		}
+		h.Reset()
+		h.Write(bi)
+		h.Write([]byte{byte(i)})
+		h.Write(dstPrime)
+		bi = h.Sum(nil)
+		out = append(out, bi...)
+	}
+	return out[:length]
+}
+
+// fp2 is an element c0 + c1 * i of Fp2, where i^2 = -1
+type fp2 struct {
+	c0, c1 *big.Int
+}
+
+func (a fp2) add(b fp2) fp2 {
+	return fp2{
+		c0: mod(new(big.Int).Add(a.c0, b.c0)),
+		c1: mod(new(big.Int).Add(a.c1, b.c1)),
+	}
+}
+
+func (a fp2) mul(b fp2) fp2 {
+	return fp2{
+		c0: mod(new(big.Int).Sub(new(big.Int).Mul(a.c0, b.c0), new(big.Int).Mul(a.c1, b.c1))),
+		c1: mod(new(big.Int).Add(new(big.Int).Mul(a.c0, b.c1), new(big.Int).Mul(a.c1, b.c0))),
+	}
+}
+
+func (a fp2) square() fp2 {
+	return a.mul(a)
+}
+
+func (a fp2) neg() fp2 {
+	return fp2{c0: mod(new(big.Int).Neg(a.c0)), c1: mod(new(big.Int).Neg(a.c1))}
+}
+
+func (a fp2) exp(e *big.Int) fp2 {
+	res := fp2{c0: big.NewInt(1), c1: big.NewInt(0)}
+	for i := e.BitLen() - 1; i >= 0; i-- {
+		res = res.square()
+		if e.Bit(i) == 1 {
+			res = res.mul(a)
+		}
+	}
+	return res
+}
+
+func (a fp2) equal(b fp2) bool {
+	return a.c0.Cmp(b.c0) == 0 && a.c1.Cmp(b.c1) == 0
+}
+
+// largest reports whether a is the lexicographically largest of a and -a, c1 is compared first
+func (a fp2) largest() bool {
+	if a.c1.Sign() != 0 {
+		return a.c1.Cmp(halfModulus) > 0
+	}
+	return a.c0.Cmp(halfModulus) > 0
+}
+
+// sqrt computes a square root of a by algorithm 9 of https://eprint.iacr.org/2012/685.pdf
+func (a fp2) sqrt() (fp2, bool) {
+	a1 := a.exp(sqrtExpFp2)
+	alpha := a1.square().mul(a)
+	x0 := a1.mul(a)
+	minusOne := fp2{c0: new(big.Int).Sub(fieldModulus, big.NewInt(1)), c1: big.NewInt(0)}
+	var x fp2
+	if alpha.equal(minusOne) {
+		// i * x0
+		x = fp2{c0: mod(new(big.Int).Neg(x0.c1)), c1: x0.c0}
+	} else {
+		b := alpha.add(fp2{c0: big.NewInt(1), c1: big.NewInt(0)}).exp(halfModulus)
+		x = b.mul(x0)
+	}
+	if !x.square().equal(a) {
+		return fp2{}, false
+	}
+	return x, true
+}
+
+func mod(e *big.Int) *big.Int {
+	return e.Mod(e, fieldModulus)
+}
@@ -0,0 +1,225 @@
+package bls
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/crypto/bls12381"
+	"github.com/pkg/errors"
+	blscmn "github.com/prysmaticlabs/prysm/crypto/bls/common"
+)
+
+const (
+	secretKeyLength = 32
+	publicKeyLength = 48
+	signatureLength = 96
+)
+
+// domain separation tag of the proof of possession scheme, the same as blst is used with
+var dst = []byte("BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_")
+
+var groupOrder = bls12381.NewG1().Q()
+
+// pureGoBackend implements the BLS operations on the BLS12-381 curve of go-ethereum, which has no cgo. Keys and
+// signatures are serialized in the same compressed forms as blst, points are kept in affine form so that they can be
+// read concurrently.
+type pureGoBackend struct{}
+
+func (pureGoBackend) RandKey() (SecretKey, error) {
+	for {
+		k, err := rand.Int(rand.Reader, groupOrder)
+		if err != nil {
+			return nil, err
+		}
+		if k.Sign() != 0 {
+			return &secretKey{k: k}, nil
+		}
+	}
+}
+
+func (pureGoBackend) SecretKeyFromBytes(privKey []byte) (SecretKey, error) {
+	if len(privKey) != secretKeyLength {
+		return nil, errors.Errorf("secret key must be %d bytes", secretKeyLength)
+	}
+	k := new(big.Int).SetBytes(privKey)
+	if k.Cmp(groupOrder) >= 0 {
+		return nil, blscmn.ErrSecretUnmarshal
+	}
+	if k.Sign() == 0 {
+		return nil, blscmn.ErrZeroKey
+	}
+	return &secretKey{k: k}, nil
+}
+
+func (pureGoBackend) PublicKeyFromBytes(pubKey []byte) (PublicKey, error) {
+	if len(pubKey) != publicKeyLength {
+		return nil, errors.Errorf("public key must be %d bytes", publicKeyLength)
+	}
+	p, err := decompressG1(pubKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not unmarshal bytes into public key")
+	}
+	if bls12381.NewG1().IsZero(p) {
+		return nil, blscmn.ErrInfinitePubKey
+	}
+	return &publicKey{p: p}, nil
+}
+
+func (pureGoBackend) SignatureFromBytes(sig []byte) (Signature, error) {
+	if len(sig) != signatureLength {
+		return nil, errors.Errorf("signature must be %d bytes", signatureLength)
+	}
+	s, err := decompressG2(sig)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not unmarshal bytes into signature")
+	}
+	return &signature{s: s}, nil
+}
+
+func (b pureGoBackend) AggregatePublicKeys(pubKeys [][]byte) (PublicKey, error) {
+	if len(pubKeys) == 0 {
+		return nil, errors.New("nil or empty public keys")
+	}
+	g1 := bls12381.NewG1()
+	agg := g1.Zero()
+	for _, pubKey := range pubKeys {
+		p, err := b.PublicKeyFromBytes(pubKey)
+		if err != nil {
+			return nil, err
+		}
+		g1.Add(agg, agg, p.(*publicKey).p)
+	}
+	return &publicKey{p: g1.Affine(agg)}, nil
+}
+
+func (pureGoBackend) AggregateSignatures(sigs []Signature) Signature {
+	if len(sigs) == 0 {
+		return nil
+	}
+	g2 := bls12381.NewG2()
+	agg := g2.Zero()
+	for _, sig := range sigs {
+		g2.Add(agg, agg, sig.(*signature).s)
+	}
+	return &signature{s: g2.Affine(agg)}
+}
+
+type secretKey struct {
+	k *big.Int
+}
+
+func (s *secretKey) PublicKey() PublicKey {
+	g1 := bls12381.NewG1()
+	p := g1.New()
+	g1.MulScalar(p, g1.One(), s.k)
+	return &publicKey{p: g1.Affine(p)}
+}
+
+func (s *secretKey) Sign(msg []byte) Signature {
+	g2 := bls12381.NewG2()
+	h, err := hashToG2(msg)
+	if err != nil {
+		// elements hashed to the field are always valid
+		panic(err)
+	}
+	g2.MulScalar(h, h, s.k)
+	return &signature{s: g2.Affine(h)}
+}
+
+func (s *secretKey) Marshal() []byte {
+	return s.k.FillBytes(make([]byte, secretKeyLength))
+}
+
+type publicKey struct {
+	p *bls12381.PointG1
+}
+
+func (p *publicKey) Marshal() []byte {
+	return compressG1(p.p)
+}
+
+func (p *publicKey) Copy() PublicKey {
+	return &publicKey{p: new(bls12381.PointG1).Set(p.p)}
+}
+
+func (p *publicKey) Aggregate(p2 PublicKey) PublicKey {
+	g1 := bls12381.NewG1()
+	agg := g1.New()
+	g1.Add(agg, p.p, p2.(*publicKey).p)
+	p.p = g1.Affine(agg)
+	return p
+}
+
+func (p *publicKey) IsInfinite() bool {
+	return bls12381.NewG1().IsZero(p.p)
+}
+
+type signature struct {
+	s *bls12381.PointG2
+}
+
+func (s *signature) Verify(pubKey PublicKey, msg []byte) bool {
+	h, err := hashToG2(msg)
+	if err != nil {
+		return false
+	}
+	return s.verifyPairs([]*bls12381.PointG1{pubKey.(*publicKey).p}, []*bls12381.PointG2{h})
+}
+
+func (s *signature) AggregateVerify(pubKeys []PublicKey, msgs [][32]byte) bool {
+	if len(pubKeys) == 0 || len(pubKeys) != len(msgs) {
+		return false
+	}
+	ps := make([]*bls12381.PointG1, 0, len(pubKeys))
+	hs := make([]*bls12381.PointG2, 0, len(msgs))
+	for i := range pubKeys {
+		h, err := hashToG2(msgs[i][:])
+		if err != nil {
+			return false
+		}
+		ps = append(ps, pubKeys[i].(*publicKey).p)
+		hs = append(hs, h)
+	}
+	return s.verifyPairs(ps, hs)
+}
+
+func (s *signature) FastAggregateVerify(pubKeys []PublicKey, msg [32]byte) bool {
+	if len(pubKeys) == 0 {
+		return false
+	}
+	agg := pubKeys[0].Copy()
+	for _, p := range pubKeys[1:] {
+		agg = agg.Aggregate(p)
+	}
+	return s.Verify(agg, msg[:])
+}
+
+func (s *signature) Eth2FastAggregateVerify(pubKeys []PublicKey, msg [32]byte) bool {
+	if len(pubKeys) == 0 && bytes.Equal(s.Marshal(), blscmn.InfiniteSignature[:]) {
+		return true
+	}
+	return s.FastAggregateVerify(pubKeys, msg)
+}
+
+// verifyPairs checks e(ps[0], hs[0]) * ... * e(ps[n], hs[n]) == e(g1, s)
+func (s *signature) verifyPairs(ps []*bls12381.PointG1, hs []*bls12381.PointG2) bool {
+	g1 := bls12381.NewG1()
+	engine := bls12381.NewPairingEngine()
+	for i := range ps {
+		if g1.IsZero(ps[i]) {
+			return false
+		}
+		engine.AddPair(ps[i], hs[i])
+	}
+	engine.AddPairInv(g1.One(), s.s)
+	return engine.Check()
+}
+
+func (s *signature) Marshal() []byte {
+	return compressG2(s.s)
+}
+
+func (s *signature) Copy() Signature {
+	return &signature{s: new(bls12381.PointG2).Set(s.s)}
+}
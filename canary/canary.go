@@ -0,0 +1,151 @@
+package canary
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/bnb-chain/greenfield-relayer/common"
+	"github.com/bnb-chain/greenfield-relayer/config"
+	"github.com/bnb-chain/greenfield-relayer/executor"
+	"github.com/bnb-chain/greenfield-relayer/logging"
+	"github.com/bnb-chain/greenfield-relayer/metric"
+	"github.com/bnb-chain/greenfield-relayer/types"
+	"github.com/bnb-chain/greenfield-relayer/util"
+)
+
+const (
+	checkInterval = 5 * time.Second
+	txQueryLimit  = 60 // times to query the canary tx before giving up
+)
+
+// Canary actively verifies the whole bridge path by periodically transferring a tiny amount of BNB from Greenfield to
+// BSC, and alerts if the transfer is not delivered to BSC within the SLA. The transfer is sent by a dedicated account,
+// so that it never takes a nonce of the relayer account cached by the assembler.
+type Canary struct {
+	config             *config.Config
+	greenfieldExecutor *executor.GreenfieldExecutor
+	account            *executor.Account
+	bscExecutor        *executor.BSCExecutor
+	metricService      *metric.MetricService
+	interval           time.Duration
+	sla                time.Duration
+	amount             sdk.Int
+	recipient          string
+}
+
+func NewCanary(cfg *config.Config, gnfdExecutor *executor.GreenfieldExecutor, bscExecutor *executor.BSCExecutor, ms *metric.MetricService) *Canary {
+	interval := common.DefaultCanaryInterval
+	if cfg.CanaryConfig.IntervalInSecond > 0 {
+		interval = time.Duration(cfg.CanaryConfig.IntervalInSecond) * time.Second
+	}
+	sla := common.DefaultCanarySLA
+	if cfg.CanaryConfig.SLAInSecond > 0 {
+		sla = time.Duration(cfg.CanaryConfig.SLAInSecond) * time.Second
+	}
+	amount, _ := new(big.Int).SetString(cfg.CanaryConfig.Amount, 10)
+	recipient := cfg.CanaryConfig.Recipient
+	if recipient == "" {
+		recipient = bscExecutor.GetAddress().String()
+	}
+	return &Canary{
+		config:             cfg,
+		greenfieldExecutor: gnfdExecutor,
+		account:            gnfdExecutor.NewAccount(cfg.CanaryConfig.PrivateKey),
+		bscExecutor:        bscExecutor,
+		metricService:      ms,
+		interval:           interval,
+		sla:                sla,
+		amount:             sdk.NewIntFromBigInt(amount),
+		recipient:          recipient,
+	}
+}
+
+func (c *Canary) StartLoop() {
+	common.Schedule(common.TaskCanary, c.interval, func() {
+		if err := c.run(); err != nil {
+			c.metricService.IncCanaryFailures()
+			logging.Logger.Errorf("canary transfer failed, err=%s", err.Error())
+		}
+	}).RunNow()
+}
+
+// run sends a canary transfer and waits until it is delivered, the alert is sent once when the SLA is breached
+func (c *Canary) run() error {
+	sentAt := time.Now()
+	txHash, err := c.account.TransferOut(c.recipient, c.amount)
+	if err != nil {
+		return err
+	}
+	channelId, sequence, err := c.getPackage(txHash)
+	if err != nil {
+		return err
+	}
+	logging.Logger.Infof("canary transfer sent, tx_hash=%s, channel_id=%d, sequence=%d", txHash, channelId, sequence)
+
+	breached := false
+	for {
+		nextSeq, err := c.bscExecutor.GetNextReceiveSequenceForChannelWithRetry(channelId)
+		if err != nil {
+			logging.Logger.Errorf("failed to get next receive sequence of channel %d, err=%s", channelId, err.Error())
+		} else if nextSeq > sequence {
+			latency := time.Since(sentAt)
+			c.metricService.SetCanaryLatency(latency.Seconds())
+			c.metricService.SetCanarySLABreached(false)
+			logging.Logger.Infof("canary transfer delivered, tx_hash=%s, latency=%s", txHash, latency)
+			if breached {
+				c.alert(fmt.Sprintf("canary transfer %s is delivered after %s", txHash, latency))
+			}
+			return nil
+		}
+		if !breached && time.Since(sentAt) > c.sla {
+			breached = true
+			c.metricService.SetCanarySLABreached(true)
+			c.alert(fmt.Sprintf("canary transfer %s (channel_id=%d, sequence=%d) is not delivered within %s", txHash, channelId, sequence, c.sla))
+		}
+		time.Sleep(checkInterval)
+	}
+}
+
+// getPackage waits for the canary tx to be committed and returns the channel and sequence of its cross-chain package
+func (c *Canary) getPackage(txHash string) (types.ChannelId, uint64, error) {
+	for i := 0; i < txQueryLimit; i++ {
+		res, err := c.greenfieldExecutor.GetTx(txHash)
+		if err != nil {
+			time.Sleep(checkInterval)
+			continue
+		}
+		if res.TxResult.Code != 0 {
+			return 0, 0, fmt.Errorf("canary tx %s failed, log=%s", txHash, res.TxResult.Log)
+		}
+		for _, e := range res.TxResult.Events {
+			if e.Type != c.config.RelayConfig.GreenfieldEventTypeCrossChain {
+				continue
+			}
+			var channelId, sequence uint64
+			for _, attr := range e.Attributes {
+				switch string(attr.Key) {
+				case "channel_id":
+					channelId, err = strconv.ParseUint(string(attr.Value), 10, 8)
+				case "sequence":
+					sequence, err = util.QuotedStrToIntWithBitSize(string(attr.Value), 64)
+				}
+				if err != nil {
+					return 0, 0, err
+				}
+			}
+			return types.ChannelId(channelId), sequence, nil
+		}
+		return 0, 0, fmt.Errorf("no cross-chain package found in canary tx %s", txHash)
+	}
+	return 0, 0, fmt.Errorf("canary tx %s is not committed", txHash)
+}
+
+func (c *Canary) alert(msg string) {
+	logging.Logger.Error(msg)
+	config.SendTelegramMessage(c.config.AlertConfig.Identity, c.config.AlertConfig.TelegramBotId,
+		c.config.AlertConfig.TelegramChatId, msg)
+}
@@ -0,0 +1,42 @@
+package common
+
+import (
+	"sort"
+	"sync"
+)
+
+// channelStates records the channels disabled by governance, votes and claims of their packages are skipped since they
+// would be rejected by the destination chain
+var channelStates = struct {
+	mutex    sync.RWMutex
+	disabled map[uint8]bool
+}{disabled: make(map[uint8]bool)}
+
+// SetChannelEnabled marks the channel enabled or disabled, channels are enabled unless marked
+func SetChannelEnabled(channelId uint8, enabled bool) {
+	channelStates.mutex.Lock()
+	defer channelStates.mutex.Unlock()
+	if enabled {
+		delete(channelStates.disabled, channelId)
+	} else {
+		channelStates.disabled[channelId] = true
+	}
+}
+
+func IsChannelEnabled(channelId uint8) bool {
+	channelStates.mutex.RLock()
+	defer channelStates.mutex.RUnlock()
+	return !channelStates.disabled[channelId]
+}
+
+// DisabledChannels returns the disabled channels in ascending order
+func DisabledChannels() []uint8 {
+	channelStates.mutex.RLock()
+	defer channelStates.mutex.RUnlock()
+	channels := make([]uint8, 0, len(channelStates.disabled))
+	for c := range channelStates.disabled {
+		channels = append(channels, c)
+	}
+	sort.Slice(channels, func(i, j int) bool { return channels[i] < channels[j] })
+	return channels
+}
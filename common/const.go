@@ -18,8 +18,72 @@ var (
 const (
 	OracleChannelId              types.ChannelId = 0
 	SleepTimeAfterSyncLightBlock                 = 15 * time.Second
+	// light blocks of a height range queried at the same time, before their sync txs are sent in height order
+	MaxConcurrentLightBlockQueries = 4
 
-	ListenerPauseTime  = 2 * time.Second
-	ErrorRetryInterval = 1 * time.Second
-	AssembleInterval   = 500 * time.Millisecond
+	ListenerPauseTime    = 2 * time.Second // pause before the block time of a chain is observed
+	MinListenerPauseTime = 200 * time.Millisecond
+	MaxListenerPauseTime = 10 * time.Second
+	ListenerNoPauseLag   = 4 // listeners this many blocks or more behind the chain poll without pause
+	ErrorRetryInterval   = 1 * time.Second
+	UpgradeRetryInterval = 10 * time.Second // retry interval on errors while a chain is around a known upgrade
+	AssembleInterval     = 500 * time.Millisecond
+	// assembler ticks without new packages to claim are skipped, a full tick still runs at least this often to
+	// refresh the in-turn relayer and sequence metrics
+	AssembleIdleRefreshInterval = 1 * time.Minute
+
+	ClaimReceiptDecodeInterval = 10 * time.Second
+	ClaimReceiptBatchSize      = 100
+	ClaimReceiptDropTimeout    = 1 * time.Hour // a claim tx not found on BSC after this is considered dropped
+
+	BlockPruneInterval = 100 // prune block rows every 100 blocks if block retention is configured
+
+	DefaultPackageCacheSize = 4096 // entries of each in-memory cache of packages and votes
+
+	DefaultVotePoolQueryPageSize = 100 // votes of an event queried from the vote pool per page
+	MaxVotePoolQueryPages        = 50  // pages of a vote query, bounding the loop against nodes ignoring pagination
+
+	DefaultRetryBudgetPerMinute = 600 // retries of all subsystems within a minute
+
+	DefaultGreenfieldClaimInclusionLatency = 3 * time.Second // expected time from broadcasting a claim to Greenfield to its inclusion
+	DefaultBSCClaimInclusionLatency        = 6 * time.Second // expected time from broadcasting a claim to BSC to its inclusion
+
+	DefaultFailAckSurgeWindow    = 5 * time.Minute
+	DefaultFailAckSurgeThreshold = 10
+
+	DefaultVoteLagThreshold = 10 * time.Minute
+	VoteLagCheckInterval    = 30 * time.Second
+
+	DefaultGreenfieldHeightLagThreshold = 300 // in blocks
+	DefaultBSCHeightLagThreshold        = 100 // in blocks
+	HeightLagCheckInterval              = 30 * time.Second
+	HeightLagAlertInterval              = 10 * time.Minute // an alert is sent at most once per interval for each chain
+
+	ShadowListenerQueueSize     = 100              // blocks waiting to be verified by the shadow listener, newer ones are skipped when full
+	ShadowListenerAlertInterval = 10 * time.Minute // an alert is sent at most once per interval
+
+	VotePoolProbeInterval = 1 * time.Minute
+	VotePoolAlertInterval = 10 * time.Minute // an alert is sent at most once per interval
+
+	VoteGossipProbeInterval = 2 * time.Second
+	VoteGossipTimeout       = 30 * time.Second // nodes on which an own vote is not queryable after this are unhealthy
+
+	ValidatorStatusCheckInterval = 30 * time.Second
+	BlackoutCheckInterval        = 10 * time.Second
+	ChannelStatusCheckInterval   = 1 * time.Minute
+
+	ClaimJournalRetention     = 24 * time.Hour  // journal entries older than this are dropped at startup
+	NonceReservationRetention = 24 * time.Hour  // settled nonce reservations older than this are dropped at startup
+	SequenceLockTTL           = 1 * time.Minute // a sequence locked by an assembler is claimed by others after this
+
+	// unbroadcast nonce reservations younger than this may be held by another relayer process sharing the DB, they are
+	// kept at startup
+	NonceReservationGracePeriod = 10 * time.Minute
+
+	SequenceLockCleanupInterval = 10 * time.Minute // expired sequence locks are deleted at startup and at this interval
+
+	DefaultShutdownDrainTimeout = 30 * time.Second
+
+	DefaultCanaryInterval = 30 * time.Minute
+	DefaultCanarySLA      = 5 * time.Minute
 )
@@ -0,0 +1,10 @@
+package common
+
+import "fmt"
+
+// CorrelationId identifies a package across the listener, vote and assembler stages, e.g. "greenfield_to_bsc-2-1024".
+// It is derived from the relay direction, channel and sequence only, so all stages and relayers agree on it without
+// storing it, and grepping logs of every relayer by it gives the end-to-end trace of the package.
+func CorrelationId(direction string, channelId uint8, sequence uint64) string {
+	return fmt.Sprintf("%s-%d-%d", direction, channelId, sequence)
+}
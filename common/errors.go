@@ -0,0 +1,29 @@
+package common
+
+import (
+	"errors"
+)
+
+// Errors shared across packages, callers wrap them with context via fmt.Errorf("%w") and branch on them via errors.Is
+var (
+	// ErrSequenceMismatch is returned when the sequence of a claim is not the one expected by the destination chain
+	ErrSequenceMismatch = errors.New("sequence mismatch")
+	// ErrNonceMismatch is returned when the nonce(account sequence) of a transaction is rejected by the chain
+	ErrNonceMismatch = errors.New("nonce mismatch")
+	// ErrNotEnoughVotes is returned when a package has not collected more than 2/3 valid votes
+	ErrNotEnoughVotes = errors.New("not enough votes")
+	// ErrNodeStale is returned when the node has not reached the requested height yet
+	ErrNodeStale = errors.New("node is stale")
+	// ErrRecordNotFound is returned by DAOs when the queried record does not exist
+	ErrRecordNotFound = errors.New("record not found")
+	// ErrPackageMismatch is returned when a package saved in DB is not found on the source chain with the same content
+	ErrPackageMismatch = errors.New("package mismatch with source chain")
+	// ErrClaimSimulationFailed is returned when a claim fails to be simulated before broadcast
+	ErrClaimSimulationFailed = errors.New("claim simulation failed")
+	// ErrLightClientBehind is returned when the light client on BSC misses the header of a validator set change
+	ErrLightClientBehind = errors.New("light client is behind")
+	// ErrFeeCeilingExceeded is returned when the fee of a BSC tx would exceed the fee ceiling per tx or per hour
+	ErrFeeCeilingExceeded = errors.New("fee ceiling exceeded")
+	// ErrClaimTimestampMismatch is returned when the timestamp of a claim differs from the one in its package headers
+	ErrClaimTimestampMismatch = errors.New("claim timestamp mismatch")
+)
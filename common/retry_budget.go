@@ -0,0 +1,67 @@
+package common
+
+import (
+	"sync"
+	"time"
+
+	"github.com/avast/retry-go/v4"
+)
+
+const (
+	SubsystemBSCExecutor             = "bsc_executor"
+	SubsystemGreenfieldExecutor      = "greenfield_executor"
+	SubsystemBSCVoteProcessor        = "bsc_vote_processor"
+	SubsystemGreenfieldVoteProcessor = "greenfield_vote_processor"
+)
+
+// RetryObserver is notified of every retry, allowed is false if the retry is rejected by the budget
+type RetryObserver func(subsystem string, allowed bool)
+
+// RetryBudget caps the number of retries of all subsystems within a minute, so that a retry storm against failing nodes
+// degrades into failing fast instead of amplifying the load
+type RetryBudget struct {
+	mutex       sync.Mutex
+	perMinute   int
+	windowStart time.Time
+	used        int
+	observer    RetryObserver
+}
+
+var retryBudget = NewRetryBudget(DefaultRetryBudgetPerMinute, nil)
+
+// NewRetryBudget returns a budget of perMinute retries, the observer is optional
+func NewRetryBudget(perMinute int, observer RetryObserver) *RetryBudget {
+	return &RetryBudget{
+		perMinute: perMinute,
+		observer:  observer,
+	}
+}
+
+// SetRetryBudget replaces the global retry budget, it should be called before any retry happens
+func SetRetryBudget(b *RetryBudget) {
+	retryBudget = b
+}
+
+func (b *RetryBudget) take(subsystem string, now time.Time) bool {
+	b.mutex.Lock()
+	if now.Sub(b.windowStart) >= time.Minute {
+		b.windowStart = now
+		b.used = 0
+	}
+	allowed := b.used < b.perMinute
+	if allowed {
+		b.used++
+	}
+	b.mutex.Unlock()
+	if b.observer != nil {
+		b.observer(subsystem, allowed)
+	}
+	return allowed
+}
+
+// RtyBudget makes each retry of the subsystem consume the global retry budget, retrying stops once it is exhausted
+func RtyBudget(subsystem string) retry.Option {
+	return retry.RetryIf(func(err error) bool {
+		return retry.IsRecoverable(err) && retryBudget.take(subsystem, time.Now())
+	})
+}
@@ -0,0 +1,24 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryBudget(t *testing.T) {
+	rejected := 0
+	b := NewRetryBudget(2, func(subsystem string, allowed bool) {
+		if !allowed {
+			rejected++
+		}
+	})
+	now := time.Now()
+	require.True(t, b.take(SubsystemBSCExecutor, now))
+	require.True(t, b.take(SubsystemGreenfieldExecutor, now))
+	require.False(t, b.take(SubsystemBSCExecutor, now.Add(time.Second)))
+	require.Equal(t, 1, rejected)
+	// the budget is renewed every minute
+	require.True(t, b.take(SubsystemBSCExecutor, now.Add(time.Minute)))
+}
@@ -0,0 +1,236 @@
+package common
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// names of the periodic tasks run by the scheduler
+const (
+	TaskBSCAssembler            = "bsc_assembler"
+	TaskClaimReceiptDecoder     = "claim_receipt_decoder"
+	TaskGreenfieldAssembler     = "greenfield_assembler"
+	TaskBSCVoteBroadcast        = "bsc_vote_broadcast"
+	TaskBSCVoteCollect          = "bsc_vote_collect"
+	TaskGreenfieldVoteBroadcast = "greenfield_vote_broadcast"
+	TaskGreenfieldVoteCollect   = "greenfield_vote_collect"
+	TaskBSCClientUpdate         = "bsc_client_update"
+	TaskValidatorMonitor        = "validator_monitor"
+	TaskBlackoutMonitor         = "blackout_monitor"
+	TaskChannelMonitor          = "channel_monitor"
+	TaskVoteLagMonitor          = "vote_lag_monitor"
+	TaskHeightLagMonitor        = "height_lag_monitor"
+	TaskVotePoolMonitor         = "vote_pool_monitor"
+	TaskVoteGossipMonitor       = "vote_gossip_monitor"
+	TaskExporter                = "exporter"
+	TaskCanary                  = "canary"
+	TaskSequenceLockCleanup     = "sequence_lock_cleanup"
+)
+
+// TaskObserver is notified of every tick of a task, ran is false if the task is paused
+type TaskObserver func(task string, ran bool, duration time.Duration)
+
+// Scheduler runs the periodic work of the relayer, so that every task can be paused, resumed and rescheduled at runtime
+type Scheduler struct {
+	mutex         sync.Mutex
+	tasks         map[string]*Task
+	jitterPercent int
+	observer      TaskObserver
+}
+
+var scheduler = NewScheduler(0, nil)
+
+// NewScheduler returns a scheduler delaying each tick by up to jitterPercent of the interval, the observer is optional
+func NewScheduler(jitterPercent int, observer TaskObserver) *Scheduler {
+	return &Scheduler{
+		tasks:         make(map[string]*Task),
+		jitterPercent: jitterPercent,
+		observer:      observer,
+	}
+}
+
+// SetScheduler replaces the global scheduler, it should be called before any task is scheduled
+func SetScheduler(s *Scheduler) {
+	scheduler = s
+}
+
+// GetScheduler returns the global scheduler
+func GetScheduler() *Scheduler {
+	return scheduler
+}
+
+// Schedule registers a task running fn every interval to the global scheduler, the task starts when Run is called
+func Schedule(name string, interval time.Duration, fn func()) *Task {
+	return scheduler.Schedule(name, interval, fn)
+}
+
+func (s *Scheduler) Schedule(name string, interval time.Duration, fn func()) *Task {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, ok := s.tasks[name]; ok {
+		panic(fmt.Sprintf("task %s is already scheduled", name))
+	}
+	t := &Task{
+		name:      name,
+		interval:  interval,
+		fn:        fn,
+		scheduler: s,
+		wake:      make(chan struct{}, 1),
+	}
+	s.tasks[name] = t
+	return t
+}
+
+// Task returns the scheduled task of the name, nil if not found
+func (s *Scheduler) Task(name string) *Task {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.tasks[name]
+}
+
+// Tasks returns the status of all scheduled tasks ordered by name
+func (s *Scheduler) Tasks() []TaskStatus {
+	s.mutex.Lock()
+	tasks := make([]*Task, 0, len(s.tasks))
+	for _, t := range s.tasks {
+		tasks = append(tasks, t)
+	}
+	s.mutex.Unlock()
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].name < tasks[j].name })
+	statuses := make([]TaskStatus, 0, len(tasks))
+	for _, t := range tasks {
+		statuses = append(statuses, t.Status())
+	}
+	return statuses
+}
+
+func (s *Scheduler) withJitter(interval time.Duration) time.Duration {
+	maxJitter := int64(interval) * int64(s.jitterPercent) / 100
+	if maxJitter <= 0 {
+		return interval
+	}
+	return interval + time.Duration(rand.Int63n(maxJitter))
+}
+
+// TaskStatus is a snapshot of a scheduled task
+type TaskStatus struct {
+	Name                      string    `json:"name"`
+	IntervalInMillisecond     int64     `json:"interval_in_millisecond"`
+	Paused                    bool      `json:"paused"`
+	Runs                      uint64    `json:"runs"`
+	LastRunAt                 time.Time `json:"last_run_at"`
+	LastDurationInMillisecond int64     `json:"last_duration_in_millisecond"`
+}
+
+// Task runs fn periodically, a tick is skipped while it is paused
+type Task struct {
+	mutex        sync.Mutex
+	name         string
+	interval     time.Duration
+	paused       bool
+	runs         uint64
+	lastRunAt    time.Time
+	lastDuration time.Duration
+	fn           func()
+	scheduler    *Scheduler
+	wake         chan struct{} // signaled when the interval is changed
+	running      sync.Mutex    // held while fn runs
+}
+
+// Run runs the task every interval, it blocks forever
+func (t *Task) Run() {
+	for {
+		t.mutex.Lock()
+		interval := t.interval
+		t.mutex.Unlock()
+
+		timer := time.NewTimer(t.scheduler.withJitter(interval))
+		select {
+		case <-timer.C:
+		case <-t.wake:
+			timer.Stop()
+			continue
+		}
+		t.tick(time.Now())
+	}
+}
+
+// RunNow runs the task once immediately and then every interval, it blocks forever
+func (t *Task) RunNow() {
+	t.tick(time.Now())
+	t.Run()
+}
+
+func (t *Task) tick(now time.Time) {
+	t.mutex.Lock()
+	paused := t.paused
+	t.mutex.Unlock()
+	if paused {
+		if t.scheduler.observer != nil {
+			t.scheduler.observer(t.name, false, 0)
+		}
+		return
+	}
+	t.running.Lock()
+	t.fn()
+	t.running.Unlock()
+	duration := time.Since(now)
+	t.mutex.Lock()
+	t.runs++
+	t.lastRunAt = now
+	t.lastDuration = duration
+	t.mutex.Unlock()
+	if t.scheduler.observer != nil {
+		t.scheduler.observer(t.name, true, duration)
+	}
+}
+
+func (t *Task) Pause() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.paused = true
+}
+
+// PauseAndWait pauses the task and waits for the tick in progress to finish
+func (t *Task) PauseAndWait() {
+	t.Pause()
+	t.running.Lock()
+	defer t.running.Unlock()
+}
+
+func (t *Task) Resume() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.paused = false
+}
+
+// SetInterval changes the interval of the task, the next tick is rescheduled with the new interval right away
+func (t *Task) SetInterval(interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("interval of task %s should be positive", t.name)
+	}
+	t.mutex.Lock()
+	t.interval = interval
+	t.mutex.Unlock()
+	select {
+	case t.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (t *Task) Status() TaskStatus {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return TaskStatus{
+		Name:                      t.name,
+		IntervalInMillisecond:     t.interval.Milliseconds(),
+		Paused:                    t.paused,
+		Runs:                      t.runs,
+		LastRunAt:                 t.lastRunAt,
+		LastDurationInMillisecond: t.lastDuration.Milliseconds(),
+	}
+}
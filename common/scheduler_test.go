@@ -0,0 +1,63 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedulerTask(t *testing.T) {
+	skipped := 0
+	s := NewScheduler(0, func(task string, ran bool, duration time.Duration) {
+		if !ran {
+			skipped++
+		}
+	})
+	runs := 0
+	task := s.Schedule(TaskExporter, time.Second, func() { runs++ })
+	require.Panics(t, func() { s.Schedule(TaskExporter, time.Second, func() {}) })
+
+	task.tick(time.Now())
+	task.Pause()
+	task.tick(time.Now())
+	require.Equal(t, 1, runs)
+	require.Equal(t, 1, skipped)
+	task.Resume()
+	task.tick(time.Now())
+	require.Equal(t, 2, runs)
+
+	require.Error(t, task.SetInterval(0))
+	require.NoError(t, task.SetInterval(time.Minute))
+	statuses := s.Tasks()
+	require.Len(t, statuses, 1)
+	require.Equal(t, int64(60000), statuses[0].IntervalInMillisecond)
+	require.Equal(t, uint64(2), statuses[0].Runs)
+}
+
+func TestSchedulerJitter(t *testing.T) {
+	s := NewScheduler(10, nil)
+	for i := 0; i < 100; i++ {
+		d := s.withJitter(time.Second)
+		require.GreaterOrEqual(t, d, time.Second)
+		require.Less(t, d, 1100*time.Millisecond)
+	}
+	require.Equal(t, time.Second, NewScheduler(0, nil).withJitter(time.Second))
+}
+
+func TestTaskPauseAndWait(t *testing.T) {
+	s := NewScheduler(0, nil)
+	started, release := make(chan struct{}), make(chan struct{})
+	finished := false
+	task := s.Schedule(TaskExporter, time.Second, func() {
+		close(started)
+		<-release
+		finished = true
+	})
+	go task.tick(time.Now())
+	<-started
+	time.AfterFunc(100*time.Millisecond, func() { close(release) })
+	task.PauseAndWait()
+	require.True(t, finished)
+	require.True(t, task.Status().Paused)
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// BlackoutWindow is a period, e.g. a planned maintenance of a chain, during which the relayer broadcasts no votes and
+// claims but keeps listening and saving events. Start and end are in RFC 3339, e.g. 2023-06-01T08:00:00Z
+type BlackoutWindow struct {
+	Name  string `json:"name"`
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+func (w *BlackoutWindow) period() (time.Time, time.Time, error) {
+	start, err := time.Parse(time.RFC3339, w.Start)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	end, err := time.Parse(time.RFC3339, w.End)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return start, end, nil
+}
+
+// BlackoutAt returns the window covering the time, nil if there is none
+func BlackoutAt(windows []BlackoutWindow, t time.Time) *BlackoutWindow {
+	for i := range windows {
+		start, end, err := windows[i].period()
+		if err != nil {
+			continue
+		}
+		if !t.Before(start) && t.Before(end) {
+			return &windows[i]
+		}
+	}
+	return nil
+}
+
+func validateBlackoutWindows(windows []BlackoutWindow) {
+	for _, w := range windows {
+		start, end, err := w.period()
+		if err != nil {
+			panic(fmt.Sprintf("blackout window %s should have start and end in RFC 3339, err=%s", w.Name, err.Error()))
+		}
+		if !end.After(start) {
+			panic(fmt.Sprintf("blackout window %s should end after it starts", w.Name))
+		}
+	}
+}
@@ -3,52 +3,131 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"math/big"
+	"net/url"
 	"os"
+	"regexp"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ethereum/go-ethereum/common"
 )
 
 type Config struct {
-	GreenfieldConfig GreenfieldConfig `json:"greenfield_config"`
-	BSCConfig        BSCConfig        `json:"bsc_config"`
-	RelayConfig      RelayConfig      `json:"relay_config"`
-	VotePoolConfig   VotePoolConfig   `json:"vote_pool_config"`
-	LogConfig        LogConfig        `json:"log_config"`
-	AdminConfig      AdminConfig      `json:"admin_config"`
-	AlertConfig      AlertConfig      `json:"alert_config"`
-	DBConfig         DBConfig         `json:"db_config"`
+	GreenfieldConfig   GreenfieldConfig   `json:"greenfield_config"`
+	BSCConfig          BSCConfig          `json:"bsc_config"`
+	RelayConfig        RelayConfig        `json:"relay_config"`
+	VotePoolConfig     VotePoolConfig     `json:"vote_pool_config"`
+	LogConfig          LogConfig          `json:"log_config"`
+	AdminConfig        AdminConfig        `json:"admin_config"`
+	AlertConfig        AlertConfig        `json:"alert_config"`
+	DBConfig           DBConfig           `json:"db_config"`
+	ExportConfig       ExportConfig       `json:"export_config"`
+	CoordinationConfig CoordinationConfig `json:"coordination_config"`
+	CanaryConfig       CanaryConfig       `json:"canary_config"`
+	Hooks              []Hook             `json:"hooks"`
 }
 
 type AdminConfig struct {
 	Port uint16 `json:"port"`
+
+	// admin API and relay event stream, disabled when api_port and grpc_port are 0 respectively
+	APIPort      uint16        `json:"api_port"`
+	GRPCPort     uint16        `json:"grpc_port"`
+	TLSCertFile  string        `json:"tls_cert_file"`
+	TLSKeyFile   string        `json:"tls_key_file"`
+	ClientCAFile string        `json:"client_ca_file"` // enables mutual TLS, clients are identified by certificate common name
+	Clients      []AdminClient `json:"clients"`
+}
+
+// AdminClient is a caller of the admin API, identified either by api key or by the common name of its TLS certificate
+type AdminClient struct {
+	Name        string   `json:"name"`
+	APIKey      string   `json:"api_key"`
+	Permissions []string `json:"permissions"`
 }
 
 func (cfg *AdminConfig) Validate() {
 	if cfg.Port <= 0 || cfg.Port > 65535 {
 		panic("port should be within (0, 65535]")
 	}
+	if cfg.APIPort == 0 && cfg.GRPCPort == 0 {
+		return
+	}
+	if cfg.APIPort == cfg.Port || cfg.GRPCPort == cfg.Port || (cfg.APIPort != 0 && cfg.APIPort == cfg.GRPCPort) {
+		panic("port, api_port and grpc_port should be different")
+	}
+	if (cfg.TLSCertFile == "") != (cfg.TLSKeyFile == "") {
+		panic("tls_cert_file and tls_key_file should be provided together")
+	}
+	if cfg.ClientCAFile != "" && cfg.TLSCertFile == "" {
+		panic("client_ca_file requires tls_cert_file and tls_key_file")
+	}
+	if len(cfg.Clients) == 0 {
+		panic("clients of admin API should not be empty")
+	}
+	names := make(map[string]bool)
+	for _, c := range cfg.Clients {
+		if c.Name == "" {
+			panic("name of admin client should not be empty")
+		}
+		if names[c.Name] {
+			panic(fmt.Sprintf("duplicate admin client %s", c.Name))
+		}
+		names[c.Name] = true
+		if c.APIKey == "" && cfg.ClientCAFile == "" {
+			panic(fmt.Sprintf("api_key of admin client %s should not be empty", c.Name))
+		}
+		for _, p := range c.Permissions {
+			if p != AdminPermissionRead && p != AdminPermissionWrite {
+				panic(fmt.Sprintf("unexpected permission %s of admin client %s, only %s and %s supported",
+					p, c.Name, AdminPermissionRead, AdminPermissionWrite))
+			}
+		}
+	}
 }
 
 type GreenfieldConfig struct {
-	KeyType                   string   `json:"key_type"`
-	AWSRegion                 string   `json:"aws_region"`
-	AWSSecretName             string   `json:"aws_secret_name"`
-	AWSBlsSecretName          string   `json:"aws_bls_secret_name"`
-	RPCAddrs                  []string `json:"rpc_addrs"`
-	GRPCAddrs                 []string `json:"grpc_addrs"`
-	PrivateKey                string   `json:"private_key"`
-	BlsPrivateKey             string   `json:"bls_private_key"`
-	ChainId                   uint64   `json:"chain_id"`
-	StartHeight               uint64   `json:"start_height"`
-	NumberOfBlocksForFinality uint64   `json:"number_of_blocks_for_finality"`
-	MonitorChannelList        []uint8  `json:"monitor_channel_list"`
-	GasLimit                  uint64   `json:"gas_limit"`
-	FeeAmount                 uint64   `json:"fee_amount"`
-	ChainIdString             string   `json:"chain_id_string"`
+	KeyType                   string         `json:"key_type"`
+	AWSRegion                 string         `json:"aws_region"`
+	AWSSecretName             string         `json:"aws_secret_name"`
+	AWSBlsSecretName          string         `json:"aws_bls_secret_name"`
+	RPCAddrs                  []string       `json:"rpc_addrs"`
+	GRPCAddrs                 []string       `json:"grpc_addrs"`
+	PrivateKey                string         `json:"private_key"`
+	BlsPrivateKey             string         `json:"bls_private_key"`
+	ChainId                   uint64         `json:"chain_id"`
+	StartHeight               uint64         `json:"start_height"`
+	ForceStartHeight          bool           `json:"force_start_height"` // start from start_height even if DB has processed higher blocks
+	NumberOfBlocksForFinality uint64         `json:"number_of_blocks_for_finality"`
+	MonitorChannelList        []uint8        `json:"monitor_channel_list"`
+	PriorityChannelList       []uint8        `json:"priority_channel_list"` // channels of monitor_channel_list voted and claimed before the others
+	GasLimit                  uint64         `json:"gas_limit"`
+	FeeAmount                 uint64         `json:"fee_amount"`
+	FeeDenom                  string         `json:"fee_denom"`       // empty means BNB
+	FeeStrategy               string         `json:"fee_strategy"`    // fixed, gas_price or simulate, empty means fixed
+	GasPrice                  string         `json:"gas_price"`       // decimal fee per gas in fee_denom, required unless fee strategy is fixed
+	GasAdjustment             float64        `json:"gas_adjustment"`  // multiplier of simulated gas, 0 means default
+	ClaimMemo                 string         `json:"claim_memo"`      // attached to claim txs, e.g. operator name or run id
+	SimulateClaims            bool           `json:"simulate_claims"` // simulate claims before broadcast to catch invalid ones without paying fees
+	ChainIdString             string         `json:"chain_id_string"`
+	RPCTimeoutInSecond        int64          `json:"rpc_timeout_in_second"` // timeout of each RPC/gRPC call, 0 means default
+	RPCRateLimit              float64        `json:"rpc_rate_limit"`        // max Tendermint RPC calls per second to each endpoint, 0 means unlimited
+	GRPCRateLimit             float64        `json:"grpc_rate_limit"`       // max gRPC calls per second to each endpoint, 0 means unlimited
+	Upgrades                  []ChainUpgrade `json:"upgrades"`
+	BlockRetention            uint64         `json:"block_retention"`        // number of latest block rows to keep, 0 means keeping all
+	FeePayerPrivateKeys       []string       `json:"fee_payer_private_keys"` // accounts granted by the relayer to submit claims via authz, used round-robin
+	RelayerAddress            string         `json:"relayer_address"`        // relayer address of the validator which delegates claims to the signing account, empty means the signing account itself
+	RoleEndpoints             RoleEndpoints  `json:"role_endpoints"`         // endpoints dedicated to the listener, vote processor and assembler
 }
 
 func (cfg *GreenfieldConfig) Validate() {
 	if len(cfg.RPCAddrs) == 0 {
 		panic("provider address of Greenfield should not be empty")
 	}
+	if len(cfg.GRPCAddrs) != len(cfg.RPCAddrs) {
+		panic("grpc_addrs and rpc_addrs of Greenfield should be of the same length")
+	}
+	cfg.RoleEndpoints.validate("Greenfield", true)
 
 	if cfg.KeyType == "" {
 		panic("key_type Greenfield should not be empty")
@@ -65,25 +144,124 @@ func (cfg *GreenfieldConfig) Validate() {
 	if cfg.KeyType != KeyTypeAWSPrivateKey && cfg.PrivateKey == "" {
 		panic("privateKey of Greenfield should not be empty")
 	}
+	validateUpgrades("Greenfield", cfg.Upgrades)
+	if cfg.RPCRateLimit < 0 || cfg.GRPCRateLimit < 0 {
+		panic("rpc_rate_limit and grpc_rate_limit of Greenfield should not be negative")
+	}
+	switch cfg.FeeStrategy {
+	case "", FeeStrategyFixed:
+	case FeeStrategyGasPrice, FeeStrategySimulate:
+		gasPrice, err := sdk.NewDecFromStr(cfg.GasPrice)
+		if err != nil || !gasPrice.IsPositive() {
+			panic(fmt.Sprintf("gas_price of Greenfield should be a positive decimal for fee strategy %s", cfg.FeeStrategy))
+		}
+	default:
+		panic(fmt.Sprintf("fee_strategy of Greenfield only supports %s, %s and %s", FeeStrategyFixed, FeeStrategyGasPrice, FeeStrategySimulate))
+	}
+	if cfg.GasAdjustment < 0 {
+		panic("gas_adjustment of Greenfield should not be negative")
+	}
+	if len(cfg.ClaimMemo) > MaxClaimMemoLength {
+		panic(fmt.Sprintf("claim_memo of Greenfield should not be longer than %d", MaxClaimMemoLength))
+	}
+	if cfg.RelayerAddress != "" && !common.IsHexAddress(cfg.RelayerAddress) {
+		panic("relayer_address of Greenfield should be a valid hex address")
+	}
+	for _, key := range cfg.FeePayerPrivateKeys {
+		if key == "" {
+			panic("fee_payer_private_keys of Greenfield should not contain empty key")
+		}
+	}
+	for _, c := range cfg.PriorityChannelList {
+		if !containsChannel(cfg.MonitorChannelList, c) {
+			panic(fmt.Sprintf("priority channel %d of Greenfield should be in monitor_channel_list", c))
+		}
+	}
+}
+
+// ChannelsByPriority groups the monitored channels into the priority ones and the others, empty groups are omitted
+func (cfg *GreenfieldConfig) ChannelsByPriority() [][]uint8 {
+	others := make([]uint8, 0, len(cfg.MonitorChannelList))
+	for _, c := range cfg.MonitorChannelList {
+		if !containsChannel(cfg.PriorityChannelList, c) {
+			others = append(others, c)
+		}
+	}
+	groups := make([][]uint8, 0, 2)
+	for _, g := range [][]uint8{cfg.PriorityChannelList, others} {
+		if len(g) > 0 {
+			groups = append(groups, g)
+		}
+	}
+	return groups
+}
+
+func containsChannel(channels []uint8, channel uint8) bool {
+	for _, c := range channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
 }
 
 type BSCConfig struct {
-	KeyType                   string   `json:"key_type"`
-	AWSRegion                 string   `json:"aws_region"`
-	AWSSecretName             string   `json:"aws_secret_name"`
-	RPCAddrs                  []string `json:"rpc_addrs"`
-	PrivateKey                string   `json:"private_key"`
-	GasLimit                  uint64   `json:"gas_limit"`
-	GasPrice                  uint64   `json:"gas_price"`
-	NumberOfBlocksForFinality uint64   `json:"number_of_blocks_for_finality"`
-	StartHeight               uint64   `json:"start_height"`
-	ChainId                   uint64   `json:"chain_id"`
+	KeyType                   string         `json:"key_type"`
+	AWSRegion                 string         `json:"aws_region"`
+	AWSSecretName             string         `json:"aws_secret_name"`
+	RPCAddrs                  []string       `json:"rpc_addrs"`
+	PrivateKey                string         `json:"private_key"`
+	GasLimit                  uint64         `json:"gas_limit"`
+	GasPrice                  uint64         `json:"gas_price"`
+	NumberOfBlocksForFinality uint64         `json:"number_of_blocks_for_finality"`
+	StartHeight               uint64         `json:"start_height"`
+	ForceStartHeight          bool           `json:"force_start_height"` // start from start_height even if DB has processed higher blocks
+	ChainId                   uint64         `json:"chain_id"`
+	RPCTimeoutInSecond        int64          `json:"rpc_timeout_in_second"` // timeout of each RPC call, 0 means default
+	RPCRateLimit              float64        `json:"rpc_rate_limit"`        // max JSON-RPC calls per second to each http endpoint, 0 means unlimited
+	Upgrades                  []ChainUpgrade `json:"upgrades"`
+	BlockRetention            uint64         `json:"block_retention"` // number of latest block rows to keep, 0 means keeping all
+	// gas of txs sent per UTC day, light block syncs and out-turn claims are paused when exceeded, 0 means unlimited
+	DailyGasBudget uint64 `json:"daily_gas_budget"`
+	// endpoints dedicated to the listener, vote processor and assembler
+	RoleEndpoints RoleEndpoints `json:"role_endpoints"`
+	// type of txs sent to BSC, legacy or dynamic_fee, empty means legacy
+	TxType string `json:"tx_type"`
+	// fee caps in wei of dynamic_fee txs, 0 means 2 * base fee + priority fee and the priority fee suggested by the node
+	MaxFeePerGas         uint64 `json:"max_fee_per_gas"`
+	MaxPriorityFeePerGas uint64 `json:"max_priority_fee_per_gas"`
+	// ceilings in wei of the fee of each tx and of txs sent per UTC hour, txs over them are deferred, 0 means unlimited
+	MaxFeePerTx   uint64 `json:"max_fee_per_tx"`
+	MaxFeePerHour uint64 `json:"max_fee_per_hour"`
+	// extract packages from tx receipts as well and diff them against the ones from filtered logs, mismatches are alerted
+	ShadowListenerEnabled bool `json:"shadow_listener_enabled"`
+	// endpoints with roles and weights in addition to rpc_addrs, which are read providers of weight 1
+	Providers []BSCProvider `json:"providers"`
+	// block tag, safe or finalized, of the highest block whose packages are voted, empty means
+	// number_of_blocks_for_finality confirmations, which are also used while nodes do not support the tag
+	FinalityTag string `json:"finality_tag"`
+}
+
+// ProvidersOf returns the providers of the role, including rpc_addrs for the read role
+func (cfg *BSCConfig) ProvidersOf(role string) []BSCProvider {
+	providers := make([]BSCProvider, 0)
+	if role == ProviderRoleRead {
+		providers = append(providers, ReadProviders(cfg.RPCAddrs)...)
+	}
+	for _, p := range cfg.Providers {
+		if p.Role == role {
+			providers = append(providers, p)
+		}
+	}
+	return providers
 }
 
 func (cfg *BSCConfig) Validate() {
-	if len(cfg.RPCAddrs) == 0 {
+	validateBSCProviders(cfg.Providers)
+	if len(cfg.ProvidersOf(ProviderRoleRead)) == 0 {
 		panic("provider address of Binance Smart Chain should not be empty")
 	}
+	cfg.RoleEndpoints.validate("Binance Smart Chain", false)
 
 	if cfg.KeyType == "" {
 		panic("key_type Binance Smart Chain should not be empty")
@@ -103,35 +281,187 @@ func (cfg *BSCConfig) Validate() {
 	if cfg.GasLimit == 0 {
 		panic("gas_limit of Binance Smart Chain should be larger than 0")
 	}
+	validateUpgrades("Binance Smart Chain", cfg.Upgrades)
+	if cfg.RPCRateLimit < 0 {
+		panic("rpc_rate_limit of Binance Smart Chain should not be negative")
+	}
+	switch cfg.TxType {
+	case "", BSCTxTypeLegacy:
+	case BSCTxTypeDynamicFee:
+		if cfg.MaxFeePerGas != 0 && cfg.MaxFeePerGas < cfg.MaxPriorityFeePerGas {
+			panic("max_fee_per_gas of Binance Smart Chain should not be less than max_priority_fee_per_gas")
+		}
+	default:
+		panic(fmt.Sprintf("tx_type of Binance Smart Chain only supports %s and %s", BSCTxTypeLegacy, BSCTxTypeDynamicFee))
+	}
+	if cfg.FinalityTag != "" && cfg.FinalityTag != BSCFinalityTagSafe && cfg.FinalityTag != BSCFinalityTagFinalized {
+		panic(fmt.Sprintf("finality_tag of Binance Smart Chain only supports %s and %s", BSCFinalityTagSafe, BSCFinalityTagFinalized))
+	}
 }
 
 type RelayConfig struct {
-	BSCToGreenfieldInturnRelayerTimeout int64  `json:"bsc_to_greenfield_inturn_relayer_timeout"` // in second
-	GreenfieldToBSCInturnRelayerTimeout int64  `json:"greenfield_to_bsc_inturn_relayer_timeout"` // in second
-	GreenfieldSequenceUpdateLatency     int64  `json:"greenfield_sequence_update_latency"`       // in second
-	BSCSequenceUpdateLatency            int64  `json:"bsc_sequence_update_latency"`              // in second
-	GreenfieldEventTypeCrossChain       string `json:"greenfield_event_type_cross_chain"`
-	BSCCrossChainPackageEventName       string `json:"bsc_cross_chain_package_event_name"`
-	CrossChainPackageEventHex           string `json:"cross_chain_package_event_hex"`
-	CrossChainContractAddr              string `json:"cross_chain_contract_addr"`
-	GreenfieldLightClientContractAddr   string `json:"greenfield_light_client_contract_addr"`
+	BSCToGreenfieldInturnRelayerTimeout int64    `json:"bsc_to_greenfield_inturn_relayer_timeout"` // in second
+	GreenfieldToBSCInturnRelayerTimeout int64    `json:"greenfield_to_bsc_inturn_relayer_timeout"` // in second
+	GreenfieldSequenceUpdateLatency     int64    `json:"greenfield_sequence_update_latency"`       // in second
+	BSCSequenceUpdateLatency            int64    `json:"bsc_sequence_update_latency"`              // in second
+	GreenfieldEventTypeCrossChain       string   `json:"greenfield_event_type_cross_chain"`
+	BSCCrossChainPackageEventName       string   `json:"bsc_cross_chain_package_event_name"`
+	CrossChainPackageEventHex           string   `json:"cross_chain_package_event_hex"`
+	CrossChainContractAddr              string   `json:"cross_chain_contract_addr"`
+	GreenfieldLightClientContractAddr   string   `json:"greenfield_light_client_contract_addr"`
+	MonitorContractAddrs                []string `json:"monitor_contract_addrs"`  // extra BSC contracts whose cross-chain package events are monitored besides the CrossChain contract
+	RetryBudgetPerMinute                int      `json:"retry_budget_per_minute"` // retries of all subsystems allowed within a minute, 0 means default
+	// in second, in-turn relayer stops claiming when its interval ends within the expected inclusion latency, 0 means default
+	BSCToGreenfieldClaimInclusionLatency int64 `json:"bsc_to_greenfield_claim_inclusion_latency"`
+	GreenfieldToBSCClaimInclusionLatency int64 `json:"greenfield_to_bsc_claim_inclusion_latency"`
+	// the listener, vote processor and assembler of a disabled direction are not started
+	DisableBSCToGreenfield bool `json:"disable_bsc_to_greenfield"`
+	DisableGreenfieldToBSC bool `json:"disable_greenfield_to_bsc"`
+	// periodic tasks are delayed by a random duration up to this percent of their intervals, 0 means no jitter
+	SchedulerJitterPercent int `json:"scheduler_jitter_percent"`
+	// non-inturn claims are skipped if the reward of the relayer is below this percent of the estimated claim fee, 0
+	// means claiming regardless of the fee
+	NonInturnMinRewardPercent int `json:"non_inturn_min_reward_percent"`
+	// local append-only file journaling broadcast claims in addition to the DB, disabled if empty
+	ClaimJournalPath string `json:"claim_journal_path"`
+	// windows during which votes and claims are not broadcast, events are still listened and saved
+	BlackoutWindows []BlackoutWindow `json:"blackout_windows"`
+	// in second, claims to BSC wait up to this long after they are due while the BSC gas price is above the target gas
+	// price in wei, 0 means claiming without waiting
+	GreenfieldToBSCFeeWaitWindow   int64  `json:"greenfield_to_bsc_fee_wait_window"`
+	GreenfieldToBSCFeeWaitGasPrice uint64 `json:"greenfield_to_bsc_fee_wait_gas_price"`
+	// in second, on SIGINT or SIGTERM the in-turn relayer keeps claiming sequences with enough votes up to this long
+	// before exiting, 0 means default
+	ShutdownDrainTimeout int64 `json:"shutdown_drain_timeout"`
+	// sequences whose claims fail this many times are moved to the needs attention status with an alert and not claimed
+	// again until retried by an operator, 0 means retrying forever
+	ClaimMaxAttempts uint32 `json:"claim_max_attempts"`
+}
+
+func (cfg *RelayConfig) Validate() {
+	if !common.IsHexAddress(cfg.CrossChainContractAddr) {
+		panic("cross_chain_contract_addr should be a valid hex address")
+	}
+	if !common.IsHexAddress(cfg.GreenfieldLightClientContractAddr) {
+		panic("greenfield_light_client_contract_addr should be a valid hex address")
+	}
+	for _, addr := range cfg.MonitorContractAddrs {
+		if !common.IsHexAddress(addr) {
+			panic(fmt.Sprintf("monitor contract address %s should be a valid hex address", addr))
+		}
+	}
+	if cfg.RetryBudgetPerMinute < 0 {
+		panic("retry_budget_per_minute should not be negative")
+	}
+	if cfg.BSCToGreenfieldClaimInclusionLatency < 0 || cfg.GreenfieldToBSCClaimInclusionLatency < 0 {
+		panic("claim inclusion latency should not be negative")
+	}
+	if cfg.DisableBSCToGreenfield && cfg.DisableGreenfieldToBSC {
+		panic("disable_bsc_to_greenfield and disable_greenfield_to_bsc should not be both true")
+	}
+	if cfg.SchedulerJitterPercent < 0 || cfg.SchedulerJitterPercent > 100 {
+		panic("scheduler_jitter_percent should be between 0 and 100")
+	}
+	if cfg.NonInturnMinRewardPercent < 0 {
+		panic("non_inturn_min_reward_percent should not be negative")
+	}
+	validateBlackoutWindows(cfg.BlackoutWindows)
+	if cfg.ShutdownDrainTimeout < 0 {
+		panic("shutdown_drain_timeout should not be negative")
+	}
+	if cfg.GreenfieldToBSCFeeWaitWindow < 0 {
+		panic("greenfield_to_bsc_fee_wait_window should not be negative")
+	}
+	if cfg.GreenfieldToBSCFeeWaitWindow > 0 && cfg.GreenfieldToBSCFeeWaitGasPrice == 0 {
+		panic("greenfield_to_bsc_fee_wait_gas_price should be larger than 0 if greenfield_to_bsc_fee_wait_window is set")
+	}
+	// the in-turn relayer should still claim before the others take over
+	if cfg.GreenfieldToBSCFeeWaitWindow > 0 && cfg.GreenfieldToBSCFeeWaitWindow >= cfg.GreenfieldToBSCInturnRelayerTimeout {
+		panic("greenfield_to_bsc_fee_wait_window should be less than greenfield_to_bsc_inturn_relayer_timeout")
+	}
+}
+
+func (cfg *RelayConfig) BSCToGreenfieldEnabled() bool {
+	return !cfg.DisableBSCToGreenfield
+}
+
+func (cfg *RelayConfig) GreenfieldToBSCEnabled() bool {
+	return !cfg.DisableGreenfieldToBSC
+}
+
+// GetMonitorContractAddrs returns addresses of all BSC contracts whose cross-chain package events are monitored
+func (cfg *RelayConfig) GetMonitorContractAddrs() []common.Address {
+	addrs := []common.Address{common.HexToAddress(cfg.CrossChainContractAddr)}
+	for _, a := range cfg.MonitorContractAddrs {
+		addr := common.HexToAddress(a)
+		duplicated := false
+		for _, existing := range addrs {
+			if existing == addr {
+				duplicated = true
+				break
+			}
+		}
+		if !duplicated {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
 }
 
 type VotePoolConfig struct {
 	BroadcastIntervalInMillisecond int64 `json:"broadcast_interval_in_millisecond"`
 	VotesBatchMaxSizePerInterval   int64 `json:"votes_batch_max_size_per_interval"`
 	QueryIntervalInMillisecond     int64 `json:"query_interval_in_millisecond"`
+	// votes of an event queried from the vote pool per page, 0 means default
+	QueryPageSize int `json:"query_page_size"`
+	// version of the vote event hash to sign with, 0 means EventHashVersionV1
+	EventHashVersion uint32 `json:"event_hash_version"`
+	// versions followed instead of event_hash_version when more votes of an event in the vote pool use them
+	AcceptedEventHashVersions []uint32 `json:"accepted_event_hash_versions"`
+	// backend of BLS operations, blst or go, empty means blst if it is available in the build
+	BlsBackend string `json:"bls_backend"`
+}
+
+func (cfg *VotePoolConfig) Validate() {
+	if cfg.EventHashVersion > LatestEventHashVersion {
+		panic(fmt.Sprintf("event_hash_version %d is not supported", cfg.EventHashVersion))
+	}
+	for _, v := range cfg.AcceptedEventHashVersions {
+		if v == 0 || v > LatestEventHashVersion {
+			panic(fmt.Sprintf("accepted event hash version %d is not supported", v))
+		}
+	}
+	if cfg.QueryPageSize < 0 {
+		panic("query_page_size should not be negative")
+	}
+	if cfg.BlsBackend != "" && cfg.BlsBackend != BlsBackendBlst && cfg.BlsBackend != BlsBackendGo {
+		panic(fmt.Sprintf("bls_backend only supports %s and %s", BlsBackendBlst, BlsBackendGo))
+	}
+}
+
+func (cfg *VotePoolConfig) GetEventHashVersion() uint32 {
+	if cfg.EventHashVersion == 0 {
+		return EventHashVersionV1
+	}
+	return cfg.EventHashVersion
 }
 
 type LogConfig struct {
-	Level                        string `json:"level"`
-	Filename                     string `json:"filename"`
-	MaxFileSizeInMB              int    `json:"max_file_size_in_mb"`
-	MaxBackupsOfLogFiles         int    `json:"max_backups_of_log_files"`
-	MaxAgeToRetainLogFilesInDays int    `json:"max_age_to_retain_log_files_in_days"`
-	UseConsoleLogger             bool   `json:"use_console_logger"`
-	UseFileLogger                bool   `json:"use_file_logger"`
-	Compress                     bool   `json:"compress"`
+	Level                        string            `json:"level"`
+	Filename                     string            `json:"filename"`
+	MaxFileSizeInMB              int               `json:"max_file_size_in_mb"`
+	MaxBackupsOfLogFiles         int               `json:"max_backups_of_log_files"`
+	MaxAgeToRetainLogFilesInDays int               `json:"max_age_to_retain_log_files_in_days"`
+	UseConsoleLogger             bool              `json:"use_console_logger"`
+	UseFileLogger                bool              `json:"use_file_logger"`
+	Compress                     bool              `json:"compress"`
+	UseSyslogLogger              bool              `json:"use_syslog_logger"`
+	SyslogNetwork                string            `json:"syslog_network"` // e.g. "udp" or "tcp", empty means the local syslog server
+	SyslogAddr                   string            `json:"syslog_addr"`
+	SyslogTag                    string            `json:"syslog_tag"` // empty means "greenfield-relayer"
+	UseLokiLogger                bool              `json:"use_loki_logger"`
+	LokiPushUrl                  string            `json:"loki_push_url"` // e.g. http://loki:3100/loki/api/v1/push
+	LokiLabels                   map[string]string `json:"loki_labels"`   // labels of the log stream, empty means {"job": "greenfield-relayer"}
 }
 
 func (cfg *LogConfig) Validate() {
@@ -146,24 +476,41 @@ func (cfg *LogConfig) Validate() {
 			panic("max_backups_off_log_files should be larger than 0 if use file logger")
 		}
 	}
+	if cfg.UseSyslogLogger && (cfg.SyslogNetwork == "") != (cfg.SyslogAddr == "") {
+		panic("syslog_network and syslog_addr should be set together if use syslog logger")
+	}
+	if cfg.UseLokiLogger && cfg.LokiPushUrl == "" {
+		panic("loki_push_url should not be empty if use loki logger")
+	}
 }
 
 type AlertConfig struct {
-	Identity       string `json:"identity"`
-	TelegramBotId  string `json:"telegram_bot_id"`
-	TelegramChatId string `json:"telegram_chat_id"`
+	Identity                     string `json:"identity"`
+	TelegramBotId                string `json:"telegram_bot_id"`
+	TelegramChatId               string `json:"telegram_chat_id"`
+	FailAckSurgeWindow           int64  `json:"fail_ack_surge_window"`           // in second
+	FailAckSurgeThreshold        int64  `json:"fail_ack_surge_threshold"`        // number of FAIL_ACK packages within the window that triggers an alert
+	VoteLagThreshold             int64  `json:"vote_lag_threshold"`              // in second, alert when the oldest unvoted package is older than it
+	GreenfieldHeightLagThreshold int64  `json:"greenfield_height_lag_threshold"` // in blocks, alert when the Greenfield listener is behind the chain head by more
+	BSCHeightLagThreshold        int64  `json:"bsc_height_lag_threshold"`        // in blocks, alert when the BSC listener is behind the chain head by more
 }
 
+var tablePrefixRegexp = regexp.MustCompile(`^[a-zA-Z0-9_]*$`)
+
 type DBConfig struct {
-	Dialect       string `json:"dialect"`
-	KeyType       string `json:"key_type"`
-	AWSRegion     string `json:"aws_region"`
-	AWSSecretName string `json:"aws_secret_name"`
-	Password      string `json:"password"`
-	Username      string `json:"username"`
-	Url           string `json:"url"`
-	MaxIdleConns  int    `json:"max_idle_conns"`
-	MaxOpenConns  int    `json:"max_open_conns"`
+	Dialect              string `json:"dialect"`
+	KeyType              string `json:"key_type"`
+	AWSRegion            string `json:"aws_region"`
+	AWSSecretName        string `json:"aws_secret_name"`
+	Password             string `json:"password"`
+	Username             string `json:"username"`
+	Url                  string `json:"url"`
+	MaxIdleConns         int    `json:"max_idle_conns"`
+	MaxOpenConns         int    `json:"max_open_conns"`
+	QueryTimeoutInSecond int64  `json:"query_timeout_in_second"` // timeout of each DB statement, 0 means default
+	TablePrefix          string `json:"table_prefix"`            // prefix of all table names, e.g. "testnet_", to share one database
+	ReplicaUrl           string `json:"replica_url"`             // read replica with the same credentials, empty means reading from the primary
+	PackageCacheSize     int    `json:"package_cache_size"`      // entries of each in-memory package and vote cache, 0 means default, negative disables
 }
 
 func (cfg *DBConfig) Validate() {
@@ -173,18 +520,161 @@ func (cfg *DBConfig) Validate() {
 	if cfg.Dialect == DBDialectMysql && (cfg.Username == "" || cfg.Url == "") {
 		panic("db config is not correct")
 	}
+	if !tablePrefixRegexp.MatchString(cfg.TablePrefix) {
+		panic("table_prefix of db config should only contain letters, digits and underscores")
+	}
+}
+
+// ExportConfig configures shipping delivered relay records to a data warehouse as JSON lines or parquet files
+type ExportConfig struct {
+	Enabled            bool   `json:"enabled"`
+	Sink               string `json:"sink"`               // file, s3 or clickhouse
+	Format             string `json:"format"`             // jsonl by default, or parquet for file and s3 sinks
+	IntervalInSecond   int64  `json:"interval_in_second"` // 0 means default
+	BatchSize          int    `json:"batch_size"`         // 0 means default
+	FileDir            string `json:"file_dir"`
+	S3Bucket           string `json:"s3_bucket"`
+	S3Region           string `json:"s3_region"`
+	S3Prefix           string `json:"s3_prefix"`
+	ClickHouseURL      string `json:"clickhouse_url"`
+	ClickHouseDatabase string `json:"clickhouse_database"`
+	ClickHouseUser     string `json:"clickhouse_user"`
+	ClickHousePassword string `json:"clickhouse_password"`
+}
+
+func (cfg *ExportConfig) Validate() {
+	if !cfg.Enabled {
+		return
+	}
+	switch cfg.Sink {
+	case ExportSinkFile:
+		if cfg.FileDir == "" {
+			panic("file_dir of export config should not be empty")
+		}
+	case ExportSinkS3:
+		if cfg.S3Bucket == "" || cfg.S3Region == "" {
+			panic("s3_bucket and s3_region of export config should not be empty")
+		}
+	case ExportSinkClickHouse:
+		if cfg.ClickHouseURL == "" {
+			panic("clickhouse_url of export config should not be empty")
+		}
+	default:
+		panic(fmt.Sprintf("export sink only supports %s, %s and %s", ExportSinkFile, ExportSinkS3, ExportSinkClickHouse))
+	}
+	switch cfg.Format {
+	case "", ExportFormatJSONLines:
+	case ExportFormatParquet:
+		if cfg.Sink == ExportSinkClickHouse {
+			panic("clickhouse export sink only supports jsonl format")
+		}
+	default:
+		panic(fmt.Sprintf("export format only supports %s and %s", ExportFormatJSONLines, ExportFormatParquet))
+	}
+}
+
+// CoordinationConfig configures announcing claim intents among non-inturn relayers
+type CoordinationConfig struct {
+	Enabled           bool     `json:"enabled"`
+	ListenPort        uint16   `json:"listen_port"`
+	Peers             []string `json:"peers"`                // base urls of peer relayers, e.g. http://relayer1:8090
+	IntentTTLInSecond int64    `json:"intent_ttl_in_second"` // 0 means default
+}
+
+func (cfg *CoordinationConfig) Validate() {
+	if !cfg.Enabled {
+		return
+	}
+	if cfg.ListenPort == 0 {
+		panic("listen_port of coordination config should not be 0")
+	}
+	for _, p := range cfg.Peers {
+		u, err := url.Parse(p)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			panic(fmt.Sprintf("invalid coordination peer %s", p))
+		}
+	}
+}
+
+// CanaryConfig configures the canary which periodically transfers a tiny amount of BNB from Greenfield to BSC with the
+// relayer account, and alerts if the transfer is not delivered within the SLA
+type CanaryConfig struct {
+	Enabled          bool   `json:"enabled"`
+	IntervalInSecond int64  `json:"interval_in_second"` // 0 means default
+	SLAInSecond      int64  `json:"sla_in_second"`      // 0 means default
+	Amount           string `json:"amount"`             // in wei
+	Recipient        string `json:"recipient"`          // BSC address, defaults to the relayer address on BSC
+	// funded Greenfield account the transfers are sent from, it should not be the relayer account whose nonces are
+	// used by claims
+	PrivateKey string `json:"private_key"`
+}
+
+func (cfg *CanaryConfig) Validate() {
+	if !cfg.Enabled {
+		return
+	}
+	amount, ok := new(big.Int).SetString(cfg.Amount, 10)
+	if !ok || amount.Sign() <= 0 {
+		panic("amount of canary config should be a positive integer")
+	}
+	if cfg.Recipient != "" && !common.IsHexAddress(cfg.Recipient) {
+		panic("recipient of canary config should be a valid hex address")
+	}
+	if cfg.PrivateKey == "" {
+		panic("private_key of canary config should not be empty")
+	}
+}
+
+// Hook is a Go plugin or an executable script invoked at lifecycle points of packages
+type Hook struct {
+	Type                 string   `json:"type"` // plugin or script
+	Path                 string   `json:"path"`
+	Points               []string `json:"points"`                 // all points if empty
+	TimeoutInMillisecond int64    `json:"timeout_in_millisecond"` // of scripts, 0 means default
+}
+
+func (cfg *Hook) Validate() {
+	if cfg.Type != HookTypePlugin && cfg.Type != HookTypeScript {
+		panic(fmt.Sprintf("hook type only supports %s and %s", HookTypePlugin, HookTypeScript))
+	}
+	if cfg.Path == "" {
+		panic("path of hook should not be empty")
+	}
+	for _, p := range cfg.Points {
+		switch p {
+		case HookPointPackageObserved, HookPointBeforeVote, HookPointBeforeClaim, HookPointAfterDelivery:
+		default:
+			panic(fmt.Sprintf("unexpected hook point %s", p))
+		}
+	}
 }
 
 func (cfg *Config) Validate() {
 	cfg.AdminConfig.Validate()
 	cfg.LogConfig.Validate()
 	cfg.BSCConfig.Validate()
+	cfg.RelayConfig.Validate()
+	// legacy txs pay the configured gas price whenever they are sent
+	if cfg.RelayConfig.GreenfieldToBSCFeeWaitWindow > 0 && cfg.BSCConfig.TxType != BSCTxTypeDynamicFee {
+		panic(fmt.Sprintf("greenfield_to_bsc_fee_wait_window only supports tx_type %s of Binance Smart Chain", BSCTxTypeDynamicFee))
+	}
+	cfg.VotePoolConfig.Validate()
 	cfg.DBConfig.Validate()
+	cfg.ExportConfig.Validate()
+	cfg.CoordinationConfig.Validate()
+	cfg.CanaryConfig.Validate()
+	for i := range cfg.Hooks {
+		cfg.Hooks[i].Validate()
+	}
 }
 
 func ParseConfigFromJson(content string) *Config {
+	bz, err := DecryptConfig([]byte(content))
+	if err != nil {
+		panic(err)
+	}
 	var config Config
-	if err := json.Unmarshal([]byte(content), &config); err != nil {
+	if err := json.Unmarshal(bz, &config); err != nil {
 		panic(err)
 	}
 	return &config
@@ -195,6 +685,10 @@ func ParseConfigFromFile(filePath string) *Config {
 	if err != nil {
 		panic(err)
 	}
+	bz, err = DecryptConfig(bz)
+	if err != nil {
+		panic(err)
+	}
 
 	var config Config
 	if err := json.Unmarshal(bz, &config); err != nil {
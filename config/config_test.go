@@ -0,0 +1,36 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChannelsByPriority(t *testing.T) {
+	cfg := &GreenfieldConfig{MonitorChannelList: []uint8{1, 2, 3, 8}}
+	require.Equal(t, [][]uint8{{1, 2, 3, 8}}, cfg.ChannelsByPriority())
+
+	cfg.PriorityChannelList = []uint8{8, 3}
+	require.Equal(t, [][]uint8{{8, 3}, {1, 2}}, cfg.ChannelsByPriority())
+
+	cfg.PriorityChannelList = []uint8{1, 2, 3, 8}
+	require.Equal(t, [][]uint8{{1, 2, 3, 8}}, cfg.ChannelsByPriority())
+}
+
+func TestValidateFeeWaitWindow(t *testing.T) {
+	cfg := &RelayConfig{
+		CrossChainContractAddr:              "0x3a282380958194D1131bC49056abb712Ab98b82B",
+		GreenfieldLightClientContractAddr:   "0x60B1E6259944Ea8CEEfFAe2d50Df33EE3CCc593A",
+		GreenfieldToBSCInturnRelayerTimeout: 45,
+		GreenfieldToBSCFeeWaitWindow:        20,
+		GreenfieldToBSCFeeWaitGasPrice:      3000000000,
+	}
+	require.NotPanics(t, cfg.Validate)
+
+	cfg.GreenfieldToBSCFeeWaitWindow = 45
+	require.Panics(t, cfg.Validate)
+
+	cfg.GreenfieldToBSCFeeWaitWindow = 20
+	cfg.GreenfieldToBSCFeeWaitGasPrice = 0
+	require.Panics(t, cfg.Validate)
+}
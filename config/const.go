@@ -8,6 +8,39 @@ const (
 	FlagConfigPrivateKey    = "private-key"
 	FlagConfigBlsPrivateKey = "bls-private-key"
 	FlagConfigDbPass        = "db-pass"
+	FlagForceFromHeight     = "force-from-height"
+	FlagBackfillChain       = "chain"
+	FlagBackfillFrom        = "from"
+	FlagBackfillTo          = "to"
+	FlagEncryptOutput       = "output"
+	FlagKMSKeyId            = "kms-key-id"
+	FlagPayload             = "payload"
+	FlagClaimEventHash      = "event-hash"
+	FlagEventHashVersion    = "event-hash-version"
+	FlagClaimSignature      = "signature"
+	FlagClaimBitSet         = "bitset"
+	FlagValidatorsFile      = "validators"
+	FlagValidatorsHeight    = "height"
+	FlagProofChain          = "proof-chain"
+	FlagProofOutput         = "proof-output"
+	FlagChannelId           = "channel-id"
+	FlagProofSequence       = "sequence"
+	FlagRelayerAddress      = "relayer-address"
+	FlagBlsPublicKey        = "bls-public-key"
+	FlagPackageType         = "package-type"
+
+	CmdBackfill      = "backfill"
+	CmdEncryptConfig = "encrypt-config"
+	CmdVerifyClaim   = "verify-claim"
+	CmdExportProof   = "export-proof"
+	CmdUpdateRelayer = "update-relayer"
+	CmdDecodePayload = "decode-payload"
+
+	EnvConfigEncryptionKey = "GREENFIELD_RELAYER_CONFIG_KEY"    // hex encoded 32 bytes AES key
+	EnvValidatorPrivateKey = "GREENFIELD_VALIDATOR_PRIVATE_KEY" // private key of the validator operator
+
+	ChainGreenfield = "greenfield"
+	ChainBSC        = "bsc"
 
 	DBDialectMysql   = "mysql"
 	DBDialectSqlite3 = "sqlite3"
@@ -16,4 +49,41 @@ const (
 	AWSConfig              = "aws"
 	KeyTypeLocalPrivateKey = "local_private_key"
 	KeyTypeAWSPrivateKey   = "aws_private_key"
+
+	ExportSinkFile       = "file"
+	ExportSinkS3         = "s3"
+	ExportSinkClickHouse = "clickhouse"
+
+	ExportFormatJSONLines = "jsonl"
+	ExportFormatParquet   = "parquet"
+
+	HookTypePlugin           = "plugin"
+	HookTypeScript           = "script"
+	HookPointPackageObserved = "package_observed"
+	HookPointBeforeVote      = "before_vote"
+	HookPointBeforeClaim     = "before_claim"
+	HookPointAfterDelivery   = "after_delivery"
+
+	BlsBackendBlst = "blst"
+	BlsBackendGo   = "go"
+
+	AdminPermissionRead  = "read"
+	AdminPermissionWrite = "write"
+
+	FeeStrategyFixed    = "fixed"     // fee_amount
+	FeeStrategyGasPrice = "gas_price" // gas_limit * gas_price
+	FeeStrategySimulate = "simulate"  // simulated gas * gas_adjustment * gas_price
+
+	DefaultGasAdjustment = 1.2
+
+	BSCTxTypeLegacy     = "legacy"      // gas_price
+	BSCTxTypeDynamicFee = "dynamic_fee" // EIP-1559, max_fee_per_gas and max_priority_fee_per_gas
+
+	BSCFinalityTagSafe      = "safe"      // block justified by fast finality votes
+	BSCFinalityTagFinalized = "finalized" // block finalized by fast finality votes
+
+	MaxClaimMemoLength = 256 // max memo characters of Greenfield txs
+
+	EventHashVersionV1     = 1 // keccak256 of the aggregated payload and sign bytes of the bls claim
+	LatestEventHashVersion = EventHashVersionV1
 )
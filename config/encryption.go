@@ -0,0 +1,164 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/kms"
+)
+
+const (
+	encryptedConfigVersion = 1
+	encryptionKeyLength    = 32 // AES-256
+)
+
+var encryptedConfigAAD = []byte("greenfield-relayer-config")
+
+// EncryptedConfig is the on-disk format of an encrypted config file. The config is encrypted with AES-256-GCM, the key
+// is either read from env EnvConfigEncryptionKey, or a data key generated by AWS KMS and stored encrypted along with the
+// config.
+type EncryptedConfig struct {
+	Version          int    `json:"version"`
+	KMSRegion        string `json:"kms_region,omitempty"`
+	EncryptedDataKey string `json:"encrypted_data_key,omitempty"` // base64 encoded, empty if the key is from env
+	Nonce            string `json:"nonce"`                        // base64 encoded
+	Ciphertext       string `json:"ciphertext"`                   // base64 encoded
+}
+
+// EncryptConfig encrypts the plain config. If kmsKeyId is empty, the key is read from env EnvConfigEncryptionKey,
+// otherwise a data key is generated by the KMS key.
+func EncryptConfig(plain []byte, kmsKeyId, kmsRegion string) ([]byte, error) {
+	enc := EncryptedConfig{Version: encryptedConfigVersion}
+	var key []byte
+	var err error
+	if kmsKeyId == "" {
+		key, err = getEncryptionKeyFromEnv()
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		svc, err := newKMSClient(kmsRegion)
+		if err != nil {
+			return nil, err
+		}
+		out, err := svc.GenerateDataKey(&kms.GenerateDataKeyInput{
+			KeyId:   aws.String(kmsKeyId),
+			KeySpec: aws.String(kms.DataKeySpecAes256),
+		})
+		if err != nil {
+			return nil, err
+		}
+		key = out.Plaintext
+		enc.KMSRegion = kmsRegion
+		enc.EncryptedDataKey = base64.StdEncoding.EncodeToString(out.CiphertextBlob)
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	enc.Nonce = base64.StdEncoding.EncodeToString(nonce)
+	enc.Ciphertext = base64.StdEncoding.EncodeToString(gcm.Seal(nil, nonce, plain, encryptedConfigAAD))
+	return json.MarshalIndent(enc, "", "  ")
+}
+
+// DecryptConfig decrypts the config if it is encrypted, otherwise returns it as is
+func DecryptConfig(bz []byte) ([]byte, error) {
+	var enc EncryptedConfig
+	if err := json.Unmarshal(bz, &enc); err != nil || enc.Version == 0 || enc.Ciphertext == "" {
+		return bz, nil
+	}
+	if enc.Version != encryptedConfigVersion {
+		return nil, fmt.Errorf("unsupported encrypted config version %d", enc.Version)
+	}
+
+	var key []byte
+	var err error
+	if enc.EncryptedDataKey == "" {
+		key, err = getEncryptionKeyFromEnv()
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		encryptedDataKey, err := base64.StdEncoding.DecodeString(enc.EncryptedDataKey)
+		if err != nil {
+			return nil, err
+		}
+		svc, err := newKMSClient(enc.KMSRegion)
+		if err != nil {
+			return nil, err
+		}
+		out, err := svc.Decrypt(&kms.DecryptInput{CiphertextBlob: encryptedDataKey})
+		if err != nil {
+			return nil, err
+		}
+		key = out.Plaintext
+	}
+
+	nonce, err := base64.StdEncoding.DecodeString(enc.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(enc.Ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid nonce length %d", len(nonce))
+	}
+	plain, err := gcm.Open(nil, nonce, ciphertext, encryptedConfigAAD)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt config error, err=%s", err.Error())
+	}
+	return plain, nil
+}
+
+func getEncryptionKeyFromEnv() ([]byte, error) {
+	hexKey := os.Getenv(EnvConfigEncryptionKey)
+	if hexKey == "" {
+		return nil, fmt.Errorf("env %s is not set", EnvConfigEncryptionKey)
+	}
+	key, err := hex.DecodeString(hexKey)
+	if err != nil {
+		return nil, fmt.Errorf("env %s should be hex encoded", EnvConfigEncryptionKey)
+	}
+	if len(key) != encryptionKeyLength {
+		return nil, fmt.Errorf("env %s should be a %d bytes key", EnvConfigEncryptionKey, encryptionKeyLength)
+	}
+	return key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func newKMSClient(region string) (*kms.KMS, error) {
+	sess, err := session.NewSession(&aws.Config{
+		Region: &region,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return kms.New(sess), nil
+}
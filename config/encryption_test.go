@@ -0,0 +1,29 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptAndDecryptConfig(t *testing.T) {
+	t.Setenv(EnvConfigEncryptionKey, "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+	plain := []byte(`{"admin_config": {"port": 8080}}`)
+
+	encrypted, err := EncryptConfig(plain, "", "")
+	require.NoError(t, err)
+	require.NotContains(t, string(encrypted), "admin_config")
+
+	decrypted, err := DecryptConfig(encrypted)
+	require.NoError(t, err)
+	require.Equal(t, plain, decrypted)
+
+	// plain config is returned as is
+	decrypted, err = DecryptConfig(plain)
+	require.NoError(t, err)
+	require.Equal(t, plain, decrypted)
+
+	t.Setenv(EnvConfigEncryptionKey, "1f1e1d1c1b1a191817161514131211100f0e0d0c0b0a09080706050403020100")
+	_, err = DecryptConfig(encrypted)
+	require.Error(t, err)
+}
@@ -0,0 +1,91 @@
+package config
+
+import "fmt"
+
+// roles of the relayer which can be given dedicated endpoints of a chain
+const (
+	RoleListener  = "listener"
+	RoleVote      = "vote"
+	RoleAssembler = "assembler"
+)
+
+// roles of BSC providers
+const (
+	ProviderRoleRead      = "read"      // serves queries, like rpc_addrs
+	ProviderRoleBroadcast = "broadcast" // only sends txs
+	ProviderRoleArchive   = "archive"   // only serves backfill
+)
+
+// BSCProvider is a BSC endpoint given a role and a weight, so that e.g. expensive archive nodes are only used for
+// backfill and txs are sent to the most reliable provider. Among healthy providers of a role, the one of the highest
+// weight is used.
+type BSCProvider struct {
+	RPCAddr string `json:"rpc_addr"`
+	Role    string `json:"role"`
+	Weight  int    `json:"weight"` // 0 means 1
+}
+
+// Endpoints are the endpoints of a chain dedicated to a role of the relayer, so that e.g. catch-up scanning of the
+// listener cannot starve claims of the assembler. A role without dedicated endpoints uses rpc_addrs and grpc_addrs of
+// the chain. grpc_addrs is only used by Greenfield.
+type Endpoints struct {
+	RPCAddrs  []string `json:"rpc_addrs"`
+	GRPCAddrs []string `json:"grpc_addrs"`
+}
+
+// RoleEndpoints are the dedicated endpoints of each role
+type RoleEndpoints struct {
+	Listener  Endpoints `json:"listener"`
+	Vote      Endpoints `json:"vote"`
+	Assembler Endpoints `json:"assembler"`
+}
+
+// Of returns the dedicated endpoints of the role
+func (r *RoleEndpoints) Of(role string) Endpoints {
+	switch role {
+	case RoleListener:
+		return r.Listener
+	case RoleVote:
+		return r.Vote
+	case RoleAssembler:
+		return r.Assembler
+	default:
+		panic(fmt.Sprintf("unknown role %s", role))
+	}
+}
+
+// ReadProviders returns the addresses as read providers of weight 1
+func ReadProviders(addrs []string) []BSCProvider {
+	providers := make([]BSCProvider, 0, len(addrs))
+	for _, addr := range addrs {
+		providers = append(providers, BSCProvider{RPCAddr: addr, Role: ProviderRoleRead, Weight: 1})
+	}
+	return providers
+}
+
+func validateBSCProviders(providers []BSCProvider) {
+	for _, p := range providers {
+		if p.RPCAddr == "" {
+			panic("rpc_addr of BSC providers should not be empty")
+		}
+		if p.Role != ProviderRoleRead && p.Role != ProviderRoleBroadcast && p.Role != ProviderRoleArchive {
+			panic(fmt.Sprintf("role of BSC provider %s only supports %s, %s and %s", p.RPCAddr, ProviderRoleRead,
+				ProviderRoleBroadcast, ProviderRoleArchive))
+		}
+		if p.Weight < 0 {
+			panic(fmt.Sprintf("weight of BSC provider %s should not be negative", p.RPCAddr))
+		}
+	}
+}
+
+func (r *RoleEndpoints) validate(chain string, withGRPC bool) {
+	for _, role := range []string{RoleListener, RoleVote, RoleAssembler} {
+		e := r.Of(role)
+		if withGRPC && len(e.GRPCAddrs) != len(e.RPCAddrs) {
+			panic(fmt.Sprintf("grpc_addrs and rpc_addrs of the %s role of %s should be of the same length", role, chain))
+		}
+		if !withGRPC && len(e.GRPCAddrs) > 0 {
+			panic(fmt.Sprintf("grpc_addrs of the %s role of %s is not supported", role, chain))
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package config
+
+import "fmt"
+
+const (
+	DefaultUpgradePauseBlocksBefore = 10
+	DefaultUpgradePauseBlocksAfter  = 20
+)
+
+// ChainUpgrade is a known upgrade of a chain, claims to the chain are paused while its height is within
+// [height - pause_blocks_before, height + pause_blocks_after]
+type ChainUpgrade struct {
+	Name              string `json:"name"`
+	Height            uint64 `json:"height"`
+	PauseBlocksBefore uint64 `json:"pause_blocks_before"` // 0 means default
+	PauseBlocksAfter  uint64 `json:"pause_blocks_after"`  // 0 means default
+}
+
+func (u *ChainUpgrade) pauseWindow() (uint64, uint64) {
+	before, after := uint64(DefaultUpgradePauseBlocksBefore), uint64(DefaultUpgradePauseBlocksAfter)
+	if u.PauseBlocksBefore > 0 {
+		before = u.PauseBlocksBefore
+	}
+	if u.PauseBlocksAfter > 0 {
+		after = u.PauseBlocksAfter
+	}
+	from := uint64(0)
+	if u.Height > before {
+		from = u.Height - before
+	}
+	return from, u.Height + after
+}
+
+// UpgradeAt returns the upgrade whose pause window covers the height, nil if there is none
+func UpgradeAt(upgrades []ChainUpgrade, height uint64) *ChainUpgrade {
+	for i := range upgrades {
+		from, to := upgrades[i].pauseWindow()
+		if height >= from && height <= to {
+			return &upgrades[i]
+		}
+	}
+	return nil
+}
+
+func validateUpgrades(chain string, upgrades []ChainUpgrade) {
+	for _, u := range upgrades {
+		if u.Name == "" || u.Height == 0 {
+			panic(fmt.Sprintf("upgrade of %s should have name and height", chain))
+		}
+	}
+}
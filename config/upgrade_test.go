@@ -0,0 +1,23 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpgradeAt(t *testing.T) {
+	upgrades := []ChainUpgrade{
+		{Name: "a", Height: 100},
+		{Name: "b", Height: 1000, PauseBlocksBefore: 5, PauseBlocksAfter: 1},
+	}
+	require.Nil(t, UpgradeAt(upgrades, 89))
+	require.Equal(t, "a", UpgradeAt(upgrades, 90).Name)
+	require.Equal(t, "a", UpgradeAt(upgrades, 120).Name)
+	require.Nil(t, UpgradeAt(upgrades, 121))
+	require.Nil(t, UpgradeAt(upgrades, 994))
+	require.Equal(t, "b", UpgradeAt(upgrades, 995).Name)
+	require.Equal(t, "b", UpgradeAt(upgrades, 1001).Name)
+	require.Nil(t, UpgradeAt(upgrades, 1002))
+	require.Nil(t, UpgradeAt(nil, 100))
+}
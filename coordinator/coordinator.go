@@ -0,0 +1,228 @@
+package coordinator
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/bnb-chain/greenfield-relayer/config"
+	"github.com/bnb-chain/greenfield-relayer/logging"
+	"github.com/bnb-chain/greenfield-relayer/vote"
+)
+
+const (
+	DefaultIntentTTL    = 60 * time.Second
+	AnnounceGracePeriod = time.Second // time to wait for conflicting intents after announcing
+
+	IntentPath          = "/coordination/intent"
+	peerRequestTimeout  = 2 * time.Second
+	maxIntentBodyLength = 4096
+)
+
+// Intent is the announcement of a non-inturn relayer that it is going to claim the sequence of the channel
+type Intent struct {
+	Direction string `json:"direction"`
+	ChannelId uint8  `json:"channel_id"`
+	Sequence  uint64 `json:"sequence"`
+	ExpireAt  int64  `json:"expire_at"`
+	PubKey    string `json:"pub_key"`   // hex encoded bls public key of the relayer
+	Signature string `json:"signature"` // hex encoded bls signature of the intent
+}
+
+func (i *Intent) signBytes() []byte {
+	hash := sha256.Sum256([]byte(fmt.Sprintf("relayer-claim-intent|%s|%d|%d|%d", i.Direction, i.ChannelId, i.Sequence, i.ExpireAt)))
+	return hash[:]
+}
+
+type intentKey struct {
+	direction string
+	channelId uint8
+	sequence  uint64
+}
+
+// RelayerKeysFetcher returns the hex encoded bls public keys of current relayers
+type RelayerKeysFetcher func() ([]string, error)
+
+// Coordinator lets non-inturn relayers announce intents to claim sequences to each other over http, so that after the
+// in-turn relayer times out, only one of them claims a sequence instead of all of them racing and wasting gas. Intents
+// are signed by relayer bls keys. When two relayers announce the same sequence, the one with the smaller public key
+// wins. A nil Coordinator allows every claim.
+type Coordinator struct {
+	mutex       sync.Mutex
+	config      *config.Config
+	signer      *vote.VoteSigner
+	pubKey      string
+	relayerKeys RelayerKeysFetcher
+	intentTTL   time.Duration
+	intents     map[intentKey][]*Intent
+	httpClient  *http.Client
+}
+
+func NewCoordinator(cfg *config.Config, signer *vote.VoteSigner, relayerKeys RelayerKeysFetcher) *Coordinator {
+	ttl := DefaultIntentTTL
+	if cfg.CoordinationConfig.IntentTTLInSecond > 0 {
+		ttl = time.Duration(cfg.CoordinationConfig.IntentTTLInSecond) * time.Second
+	}
+	return &Coordinator{
+		config:      cfg,
+		signer:      signer,
+		pubKey:      hex.EncodeToString(signer.PubKey()),
+		relayerKeys: relayerKeys,
+		intentTTL:   ttl,
+		intents:     make(map[intentKey][]*Intent),
+		httpClient:  &http.Client{Timeout: peerRequestTimeout},
+	}
+}
+
+func (c *Coordinator) Start() {
+	mux := http.NewServeMux()
+	mux.HandleFunc(IntentPath, c.handleIntent)
+	err := http.ListenAndServe(fmt.Sprintf(":%d", c.config.CoordinationConfig.ListenPort), mux)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// TryClaim announces the intent to claim the sequence and returns whether this relayer should claim it. It returns
+// false if another relayer has already announced the sequence, or announced it concurrently and wins the tie-break.
+func (c *Coordinator) TryClaim(direction string, channelId uint8, sequence uint64) bool {
+	if c == nil {
+		return true
+	}
+	key := intentKey{direction: direction, channelId: channelId, sequence: sequence}
+	if winner := c.winner(key); winner != "" && winner != c.pubKey {
+		return false
+	}
+	intent := &Intent{
+		Direction: direction,
+		ChannelId: channelId,
+		Sequence:  sequence,
+		ExpireAt:  time.Now().Add(c.intentTTL).Unix(),
+		PubKey:    c.pubKey,
+	}
+	intent.Signature = hex.EncodeToString(c.signer.Sign(intent.signBytes()))
+	c.add(key, intent)
+	c.broadcast(intent)
+
+	time.Sleep(AnnounceGracePeriod)
+	if winner := c.winner(key); winner != c.pubKey {
+		logging.Logger.Infof("yield claiming %s channel %d sequence %d to relayer %s", direction, channelId, sequence, winner)
+		return false
+	}
+	return true
+}
+
+// winner returns the public key of the relayer with the smallest public key among unexpired intents of the sequence
+func (c *Coordinator) winner(key intentKey) string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.pruneLocked()
+	winner := ""
+	for _, i := range c.intents[key] {
+		if winner == "" || i.PubKey < winner {
+			winner = i.PubKey
+		}
+	}
+	return winner
+}
+
+func (c *Coordinator) add(key intentKey, intent *Intent) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for _, i := range c.intents[key] {
+		if i.PubKey == intent.PubKey && i.ExpireAt >= intent.ExpireAt {
+			return
+		}
+	}
+	c.intents[key] = append(c.intents[key], intent)
+	c.pruneLocked()
+}
+
+func (c *Coordinator) pruneLocked() {
+	now := time.Now().Unix()
+	for key, intents := range c.intents {
+		alive := intents[:0]
+		for _, i := range intents {
+			if i.ExpireAt > now {
+				alive = append(alive, i)
+			}
+		}
+		if len(alive) == 0 {
+			delete(c.intents, key)
+		} else {
+			c.intents[key] = alive
+		}
+	}
+}
+
+func (c *Coordinator) broadcast(intent *Intent) {
+	bz, err := json.Marshal(intent)
+	if err != nil {
+		logging.Logger.Errorf("marshal claim intent error, err=%s", err.Error())
+		return
+	}
+	for _, peer := range c.config.CoordinationConfig.Peers {
+		go func(peer string) {
+			resp, err := c.httpClient.Post(peer+IntentPath, "application/json", bytes.NewReader(bz))
+			if err != nil {
+				logging.Logger.Debugf("send claim intent to peer %s error, err=%s", peer, err.Error())
+				return
+			}
+			resp.Body.Close()
+		}(peer)
+	}
+}
+
+func (c *Coordinator) handleIntent(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var intent Intent
+	if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxIntentBodyLength)).Decode(&intent); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := c.verify(&intent); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c.add(intentKey{direction: intent.Direction, channelId: intent.ChannelId, sequence: intent.Sequence}, &intent)
+	w.WriteHeader(http.StatusOK)
+}
+
+// verify checks the intent is signed by a current relayer and does not live longer than the ttl
+func (c *Coordinator) verify(intent *Intent) error {
+	now := time.Now()
+	if intent.ExpireAt <= now.Unix() || intent.ExpireAt > now.Add(c.intentTTL).Unix()+1 {
+		return fmt.Errorf("invalid expire_at %d", intent.ExpireAt)
+	}
+	keys, err := c.relayerKeys()
+	if err != nil {
+		return err
+	}
+	isRelayer := false
+	for _, k := range keys {
+		if k == intent.PubKey {
+			isRelayer = true
+			break
+		}
+	}
+	if !isRelayer {
+		return fmt.Errorf("%s is not a relayer", intent.PubKey)
+	}
+	pubKey, err := hex.DecodeString(intent.PubKey)
+	if err != nil {
+		return err
+	}
+	sig, err := hex.DecodeString(intent.Signature)
+	if err != nil {
+		return err
+	}
+	return vote.VerifyBlsSignature(pubKey, sig, intent.signBytes())
+}
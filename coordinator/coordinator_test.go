@@ -0,0 +1,29 @@
+package coordinator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWinner(t *testing.T) {
+	c := &Coordinator{pubKey: "bb", intents: make(map[intentKey][]*Intent)}
+	key := intentKey{direction: "greenfield_to_bsc", channelId: 1, sequence: 10}
+	require.Equal(t, "", c.winner(key))
+
+	expireAt := time.Now().Add(time.Minute).Unix()
+	c.add(key, &Intent{PubKey: "bb", ExpireAt: expireAt})
+	require.Equal(t, "bb", c.winner(key))
+
+	c.add(key, &Intent{PubKey: "cc", ExpireAt: expireAt})
+	require.Equal(t, "bb", c.winner(key))
+
+	c.add(key, &Intent{PubKey: "aa", ExpireAt: expireAt})
+	require.Equal(t, "aa", c.winner(key))
+
+	// expired intents are ignored
+	other := intentKey{direction: "greenfield_to_bsc", channelId: 1, sequence: 11}
+	c.add(other, &Intent{PubKey: "aa", ExpireAt: time.Now().Add(-time.Second).Unix()})
+	require.Equal(t, "", c.winner(other))
+}
@@ -1,10 +1,11 @@
 package db
 
-type TxStatus int
+// ReceiptStatus is the status of the receipt of a claim tx sent to BSC
+type ReceiptStatus int
 
 const (
-	Saved     TxStatus = 0
-	SelfVoted TxStatus = 1 // Tx is only voted by local relayer
-	AllVoted  TxStatus = 2 // TX is already voted by enough validators, more than (2/3) * (# of validators) valid votes collected.
-	Delivered TxStatus = 3 // Tx is delivered to the dest chain
+	ReceiptPending  ReceiptStatus = 0 // the claim tx is not mined yet, or no claim tx is sent
+	ReceiptSuccess  ReceiptStatus = 1
+	ReceiptReverted ReceiptStatus = 2
+	ReceiptDropped  ReceiptStatus = 3 // the claim tx is not found on BSC long after it is sent
 )
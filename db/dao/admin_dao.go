@@ -0,0 +1,30 @@
+package dao
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/bnb-chain/greenfield-relayer/db/model"
+)
+
+type AdminDao struct {
+	DB *gorm.DB
+}
+
+func NewAdminDao(db *gorm.DB) *AdminDao {
+	return &AdminDao{
+		DB: db,
+	}
+}
+
+func (d *AdminDao) SaveAuditLog(log *model.AdminAuditLog) error {
+	return d.DB.Create(log).Error
+}
+
+func (d *AdminDao) GetAuditLogs(limit int) ([]*model.AdminAuditLog, error) {
+	logs := make([]*model.AdminAuditLog, 0)
+	err := d.DB.Order("id desc").Limit(limit).Find(&logs).Error
+	if err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
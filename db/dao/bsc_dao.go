@@ -2,16 +2,20 @@ package dao
 
 import (
 	"database/sql"
+	"fmt"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/bnb-chain/greenfield-relayer/db"
 	"github.com/bnb-chain/greenfield-relayer/db/model"
 )
 
 type BSCDao struct {
-	DB *gorm.DB
+	DB    *gorm.DB
+	cache *lruCache[[]model.BscRelayPackage] // keyed by oracle sequence
+	inTx  *txInvalidations
 }
 
 func NewBSCDao(db *gorm.DB) *BSCDao {
@@ -20,9 +24,23 @@ func NewBSCDao(db *gorm.DB) *BSCDao {
 	}
 }
 
+// GetLatestBlock returns the latest processed block by the listener checkpoint, or by block rows if there is no
+// checkpoint yet
 func (d *BSCDao) GetLatestBlock() (*model.BscBlock, error) {
+	checkpoint, err := getCheckpoint(d.DB, model.CheckpointChainBSC)
+	if err != nil {
+		return nil, err
+	}
+	if checkpoint != nil {
+		return &model.BscBlock{
+			BlockHash:  checkpoint.BlockHash,
+			ParentHash: checkpoint.ParentHash,
+			Height:     checkpoint.Height,
+			BlockTime:  checkpoint.BlockTime,
+		}, nil
+	}
 	block := model.BscBlock{}
-	err := d.DB.Model(model.BscBlock{}).Order("height desc").Take(&block).Error
+	err = d.DB.Model(model.BscBlock{}).Order("height desc").Take(&block).Error
 	if err != nil && err != gorm.ErrRecordNotFound {
 		return nil, err
 	}
@@ -47,9 +65,18 @@ func (d *BSCDao) GetPackagesByHeightAndStatus(status db.TxStatus, height uint64)
 	return unVotedTxs, nil
 }
 
+func (d *BSCDao) QueryPackages(filter *RelayFilter) ([]*model.BscRelayPackage, error) {
+	pkgs := make([]*model.BscRelayPackage, 0)
+	err := filter.apply(d.DB, "package_sequence").Find(&pkgs).Error
+	if err != nil {
+		return nil, err
+	}
+	return pkgs, nil
+}
+
 func (d *BSCDao) GetLeastSavedPackagesHeight() (uint64, error) {
 	var result sql.NullInt64
-	res := d.DB.Table("bsc_relay_package").Select("MIN(height)").Where("status = ?", db.Saved)
+	res := d.DB.Model(&model.BscRelayPackage{}).Select("MIN(height)").Where("status = ?", db.Saved)
 	err := res.Row().Scan(&result)
 	if err != nil {
 		return 0, err
@@ -57,71 +84,217 @@ func (d *BSCDao) GetLeastSavedPackagesHeight() (uint64, error) {
 	return uint64(result.Int64), nil
 }
 
-func (d *BSCDao) GetLatestOracleSequenceByStatus(status db.TxStatus) (int64, error) {
-	var result sql.NullInt64
-	res := d.DB.Table("bsc_relay_package").Select("MAX(oracle_sequence)").Where("status = ?", status)
-	err := res.Row().Scan(&result)
+// GetOracleSequenceWatermark returns the highest oracle sequence which has ever reached the status, -1 if none has
+func (d *BSCDao) GetOracleSequenceWatermark(status db.TxStatus) (int64, error) {
+	return getWatermark(d.DB, model.CheckpointChainBSC, 0, status)
+}
+
+func (d *BSCDao) GetPackageByChannelIdAndSequence(channelId uint8, sequence uint64) (*model.BscRelayPackage, error) {
+	pkg := model.BscRelayPackage{}
+	err := d.DB.Where("channel_id = ? and package_sequence = ?", channelId, sequence).Find(&pkg).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+	return &pkg, nil
+}
+
+func (d *BSCDao) GetStatByStatus(status db.TxStatus) (*StatusStat, error) {
+	stat := StatusStat{}
+	err := d.DB.Model(&model.BscRelayPackage{}).Select("COUNT(*) AS count, COALESCE(MIN(tx_time), 0) AS oldest_tx_time").
+		Where("status = ?", status).Scan(&stat).Error
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-	if !result.Valid {
-		return -1, nil
+	return &stat, nil
+}
+
+// CountUndeliveredPackages returns the number of packages not delivered yet, and the number of them with oracle
+// sequences below the given one
+func (d *BSCDao) CountUndeliveredPackages(oracleSequence uint64) (total int64, below int64, err error) {
+	if err = d.DB.Model(&model.BscRelayPackage{}).Where("status <> ?", db.Delivered).Count(&total).Error; err != nil {
+		return 0, 0, err
 	}
-	return result.Int64, nil
+	err = d.DB.Model(&model.BscRelayPackage{}).Where("status <> ? and oracle_sequence < ?", db.Delivered, oracleSequence).
+		Count(&below).Error
+	return total, below, err
 }
 
 func (d *BSCDao) GetPackagesByOracleSequence(sequence uint64) ([]*model.BscRelayPackage, error) {
+	key := oracleSequenceKey(sequence)
+	if d.inTx == nil {
+		if cached, ok := d.cache.get(key); ok {
+			pkgs := make([]*model.BscRelayPackage, 0, len(cached))
+			for i := range cached {
+				pkg := cached[i]
+				pkgs = append(pkgs, &pkg)
+			}
+			return pkgs, nil
+		}
+	}
+	generation := d.cache.snapshot()
 	pkgs := make([]*model.BscRelayPackage, 0)
-	err := d.DB.Where("oracle_sequence = ?", sequence).Find(&pkgs).Error
-	if err != nil && err != gorm.ErrRecordNotFound {
+	query := fmt.Sprintf("SELECT * %s WHERE oracle_sequence = ?",
+		fromTable(d.DB, (&model.BscRelayPackage{}).TableName(), "idx_bsc_relay_package_oracle_sequence"))
+	if err := prepared(d.DB).Raw(query, sequence).Scan(&pkgs).Error; err != nil {
 		return nil, err
 	}
+	if d.inTx == nil && len(pkgs) != 0 {
+		cached := make([]model.BscRelayPackage, 0, len(pkgs))
+		ids := make([]int64, 0, len(pkgs))
+		for _, pkg := range pkgs {
+			cached = append(cached, *pkg)
+			ids = append(ids, pkg.Id)
+		}
+		d.cache.add(key, cached, ids, generation)
+	}
 	return pkgs, nil
 }
 
-func (d *BSCDao) UpdateBatchPackagesStatus(txIds []int64, status db.TxStatus) error {
-	return d.DB.Transaction(func(dbTx *gorm.DB) error {
-		return dbTx.Model(model.BscRelayPackage{}).Where("id IN (?)", txIds).Updates(
-			model.BscRelayPackage{Status: status, UpdatedTime: time.Now().Unix()}).Error
-	})
+// GetDeliveredPackagesUpdatedAfter returns delivered packages ordered by (updated_time, id) which are updated after the
+// cursor (updatedTime, id) and no later than updatedBefore
+func (d *BSCDao) GetDeliveredPackagesUpdatedAfter(updatedTime, id, updatedBefore int64, limit int) ([]*model.BscRelayPackage, error) {
+	pkgs := make([]*model.BscRelayPackage, 0)
+	err := d.DB.Where("status = ? and (updated_time > ? or (updated_time = ? and id > ?)) and updated_time <= ?",
+		db.Delivered, updatedTime, updatedTime, id, updatedBefore).
+		Order("updated_time asc, id asc").Limit(limit).Find(&pkgs).Error
+	if err != nil {
+		return nil, err
+	}
+	return pkgs, nil
 }
 
-func UpdateBatchPackagesStatus(dbTx *gorm.DB, txIds []int64, status db.TxStatus) error {
-	return dbTx.Transaction(func(dbTx *gorm.DB) error {
-		return dbTx.Model(model.BscRelayPackage{}).Where("id IN (?)", txIds).Updates(
-			model.BscRelayPackage{Status: status, UpdatedTime: time.Now().Unix()}).Error
+// UpdateBatchPackagesStatus moves the packages to the status in one statement, packages which can not transition to
+// the status are kept as is and logged
+func (d *BSCDao) UpdateBatchPackagesStatus(txIds []int64, status db.TxStatus) error {
+	defer d.invalidateIds(txIds...)
+	var moved int64
+	err := prepared(d.DB).Transaction(func(dbTx *gorm.DB) error {
+		res := dbTx.Exec(fmt.Sprintf("UPDATE %s SET status = ?, updated_time = ? WHERE id IN ? and status IN ?", (&model.BscRelayPackage{}).TableName()),
+			status, time.Now().Unix(), txIds, db.SourcesOf(status))
+		if res.Error != nil {
+			return res.Error
+		}
+		moved = res.RowsAffected
+		if err := logRejectedTransitions(dbTx, model.CheckpointChainBSC, (&model.BscRelayPackage{}).TableName(), txIds, status, moved); err != nil || moved == 0 {
+			return err
+		}
+		return raiseBSCWatermark(dbTx, txIds, status)
 	})
+	if err == nil {
+		db.NotifyTransition(model.CheckpointChainBSC, status, moved)
+	}
+	return err
 }
 
+// UpdateBatchPackagesStatusToDelivered marks the packages awaiting delivery before the oracle sequence as delivered
 func (d *BSCDao) UpdateBatchPackagesStatusToDelivered(seq uint64) error {
-	return d.DB.Transaction(func(dbTx *gorm.DB) error {
-		return dbTx.Model(model.BscRelayPackage{}).Where("oracle_sequence < ? and status = 2", seq).Updates(
-			model.BscRelayPackage{Status: db.Delivered, UpdatedTime: time.Now().Unix()}).Error
+	defer d.purgeCache()
+	var moved int64
+	err := d.DB.Transaction(func(dbTx *gorm.DB) error {
+		res := dbTx.Model(model.BscRelayPackage{}).Where("oracle_sequence < ? and status IN ?", seq, db.AwaitingDelivery).Updates(
+			model.BscRelayPackage{Status: db.Delivered, UpdatedTime: time.Now().Unix()})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		moved = res.RowsAffected
+		return raiseWatermark(dbTx, model.CheckpointChainBSC, 0, db.Delivered, seq-1)
 	})
+	if err == nil {
+		db.NotifyTransition(model.CheckpointChainBSC, db.Delivered, moved)
+	}
+	return err
 }
 
 func (d *BSCDao) UpdateBatchPackagesClaimedTxHash(txIds []int64, claimTxHash string) error {
+	defer d.invalidateIds(txIds...)
 	return d.DB.Transaction(func(dbTx *gorm.DB) error {
 		return dbTx.Model(model.BscRelayPackage{}).Where("id IN (?)", txIds).Updates(
 			model.BscRelayPackage{UpdatedTime: time.Now().Unix(), ClaimTxHash: claimTxHash}).Error
 	})
 }
 
+// UpdateBatchPackagesStatusAndClaimedTxHash records the claim tx of the packages and moves them to the status, the
+// claim tx is recorded even if the transition is invalid, which is logged
 func (d *BSCDao) UpdateBatchPackagesStatusAndClaimedTxHash(txIds []int64, status db.TxStatus, claimTxHash string) error {
-	return d.DB.Transaction(func(dbTx *gorm.DB) error {
-		return dbTx.Model(model.BscRelayPackage{}).Where("id IN (?)", txIds).Updates(
-			model.BscRelayPackage{Status: status, UpdatedTime: time.Now().Unix(), ClaimTxHash: claimTxHash}).Error
+	defer d.invalidateIds(txIds...)
+	var moved int64
+	err := d.DB.Transaction(func(dbTx *gorm.DB) error {
+		err := dbTx.Model(model.BscRelayPackage{}).Where("id IN (?)", txIds).Updates(
+			model.BscRelayPackage{UpdatedTime: time.Now().Unix(), ClaimTxHash: claimTxHash}).Error
+		if err != nil {
+			return err
+		}
+		res := dbTx.Model(model.BscRelayPackage{}).Where("id IN (?) and status IN ?", txIds, db.SourcesOf(status)).
+			Update("status", status)
+		if res.Error != nil {
+			return res.Error
+		}
+		moved = res.RowsAffected
+		if err := logRejectedTransitions(dbTx, model.CheckpointChainBSC, (&model.BscRelayPackage{}).TableName(), txIds, status, moved); err != nil || moved == 0 {
+			return err
+		}
+		return raiseBSCWatermark(dbTx, txIds, status)
 	})
+	if err == nil {
+		db.NotifyTransition(model.CheckpointChainBSC, status, moved)
+	}
+	return err
+}
+
+// SkipPackages marks the undelivered packages of the oracle sequence as skipped, returns the number of skipped packages
+func (d *BSCDao) SkipPackages(oracleSequence uint64) (int64, error) {
+	defer d.invalidateKeys(oracleSequenceKey(oracleSequence))
+	res := d.DB.Model(model.BscRelayPackage{}).Where("oracle_sequence = ? and status IN ?", oracleSequence, db.SourcesOf(db.Skipped)).Updates(
+		model.BscRelayPackage{Status: db.Skipped, UpdatedTime: time.Now().Unix()})
+	if res.Error == nil {
+		db.NotifyTransition(model.CheckpointChainBSC, db.Skipped, res.RowsAffected)
+	}
+	return res.RowsAffected, res.Error
+}
+
+// IncreaseClaimFailures increases the claim failures of the packages, and moves them to the needs attention status
+// once the failures reach maxFailures unless it is 0. Returns whether the packages are moved.
+func (d *BSCDao) IncreaseClaimFailures(txIds []int64, maxFailures uint32) (bool, error) {
+	defer d.invalidateIds(txIds...)
+	var movedCount int64
+	err := d.DB.Transaction(func(dbTx *gorm.DB) error {
+		err := dbTx.Model(model.BscRelayPackage{}).Where("id IN (?)", txIds).
+			Update("claim_failures", gorm.Expr("claim_failures + 1")).Error
+		if err != nil || maxFailures == 0 {
+			return err
+		}
+		res := dbTx.Model(model.BscRelayPackage{}).Where("id IN (?) and claim_failures >= ? and status IN ?", txIds, maxFailures, db.SourcesOf(db.NeedsAttention)).
+			Updates(model.BscRelayPackage{Status: db.NeedsAttention, UpdatedTime: time.Now().Unix()})
+		movedCount = res.RowsAffected
+		return res.Error
+	})
+	if err == nil {
+		db.NotifyTransition(model.CheckpointChainBSC, db.NeedsAttention, movedCount)
+	}
+	return movedCount > 0, err
+}
+
+// RetryPackages moves the packages of the oracle sequence from the needs attention status back to all voted with
+// their claim failures reset, returns the number of moved packages
+func (d *BSCDao) RetryPackages(oracleSequence uint64) (int64, error) {
+	defer d.invalidateKeys(oracleSequenceKey(oracleSequence))
+	res := d.DB.Model(model.BscRelayPackage{}).Where("oracle_sequence = ? and status = ?", oracleSequence, db.NeedsAttention).
+		Updates(map[string]interface{}{"status": db.AllVoted, "claim_failures": 0, "updated_time": time.Now().Unix()})
+	if res.Error == nil {
+		db.NotifyTransition(model.CheckpointChainBSC, db.AllVoted, res.RowsAffected)
+	}
+	return res.RowsAffected, res.Error
 }
 
 func (d *BSCDao) SaveBlockAndBatchPackages(b *model.BscBlock, pkgs []*model.BscRelayPackage) error {
+	defer d.invalidatePackages(pkgs)
 	return d.DB.Transaction(func(dbTx *gorm.DB) error {
-		err := dbTx.Create(b).Error
+		err := saveBscBlock(dbTx, b)
 		if err != nil {
 			return err
 		}
 		if len(pkgs) != 0 {
-			err := dbTx.Create(pkgs).Error
+			err := savePackages(dbTx, pkgs).Error
 			if err != nil {
 				return err
 			}
@@ -131,9 +304,10 @@ func (d *BSCDao) SaveBlockAndBatchPackages(b *model.BscBlock, pkgs []*model.BscR
 }
 
 func (d *BSCDao) SaveBatchPackages(pkgs []*model.BscRelayPackage) error {
+	defer d.invalidatePackages(pkgs)
 	return d.DB.Transaction(func(dbTx *gorm.DB) error {
 		if len(pkgs) != 0 {
-			err := dbTx.Create(pkgs).Error
+			err := savePackages(dbTx, pkgs).Error
 			if err != nil {
 				return err
 			}
@@ -142,9 +316,95 @@ func (d *BSCDao) SaveBatchPackages(pkgs []*model.BscRelayPackage) error {
 	})
 }
 
+// SaveMissingPackages saves the packages which do not exist in DB yet, returns the number of saved packages
+func (d *BSCDao) SaveMissingPackages(pkgs []*model.BscRelayPackage) (int, error) {
+	defer d.invalidatePackages(pkgs)
+	savedCnt := 0
+	err := d.DB.Transaction(func(dbTx *gorm.DB) error {
+		var err error
+		savedCnt, err = saveMissingPackages(dbTx, pkgs)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return savedCnt, nil
+}
+
+// SaveBlockAndMissingPackages saves the block and the packages which do not exist in DB yet, used when re-processing
+// blocks whose packages might have been saved before
+func (d *BSCDao) SaveBlockAndMissingPackages(b *model.BscBlock, pkgs []*model.BscRelayPackage) error {
+	defer d.invalidatePackages(pkgs)
+	return d.DB.Transaction(func(dbTx *gorm.DB) error {
+		if err := saveBscBlock(dbTx, b); err != nil {
+			return err
+		}
+		_, err := saveMissingPackages(dbTx, pkgs)
+		return err
+	})
+}
+
+// saveBscBlock saves the block and moves the listener checkpoint to it
+func saveBscBlock(dbTx *gorm.DB, b *model.BscBlock) error {
+	if err := dbTx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "height"}},
+		DoUpdates: clause.AssignmentColumns([]string{"block_hash", "parent_hash", "block_time"}),
+	}).Create(b).Error; err != nil {
+		return err
+	}
+	return saveCheckpoint(dbTx, &model.ListenerCheckpoint{
+		Chain:      model.CheckpointChainBSC,
+		Height:     b.Height,
+		BlockHash:  b.BlockHash,
+		ParentHash: b.ParentHash,
+		BlockTime:  b.BlockTime,
+	})
+}
+
+func saveMissingPackages(dbTx *gorm.DB, pkgs []*model.BscRelayPackage) (int, error) {
+	if len(pkgs) == 0 {
+		return 0, nil
+	}
+	res := savePackages(dbTx, pkgs)
+	if res.Error != nil {
+		return 0, res.Error
+	}
+	return int(res.RowsAffected), nil
+}
+
+// savePackages saves the packages, those already saved are skipped and keep their status
+func savePackages(dbTx *gorm.DB, pkgs []*model.BscRelayPackage) *gorm.DB {
+	return dbTx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "channel_id"}, {Name: "oracle_sequence"}, {Name: "package_sequence"}},
+		DoNothing: true,
+	}).Create(pkgs)
+}
+
+// DeleteBlocksFromHeight deletes blocks and the listener checkpoint at or above the height, packages are kept
+func (d *BSCDao) DeleteBlocksFromHeight(height uint64) error {
+	return d.DB.Transaction(func(dbTx *gorm.DB) error {
+		if err := dbTx.Where("height >= ?", height).Delete(model.BscBlock{}).Error; err != nil {
+			return err
+		}
+		return deleteCheckpointFromHeight(dbTx, model.CheckpointChainBSC, height)
+	})
+}
+
+// DeleteBlocksBelowHeight prunes blocks below the height, the listener resumes from its checkpoint
+func (d *BSCDao) DeleteBlocksBelowHeight(height uint64) error {
+	return d.DB.Where("height < ?", height).Delete(model.BscBlock{}).Error
+}
+
+// DeleteBlockAndPackagesAtHeight deletes the forked block and its packages, and moves the listener checkpoint back to
+// the parent block
 func (d *BSCDao) DeleteBlockAndPackagesAtHeight(height uint64) error {
+	defer d.purgeCache()
 	return d.DB.Transaction(func(dbTx *gorm.DB) error {
-		err := dbTx.Where("height = ?", height).Delete(model.BscBlock{}).Error
+		checkpoint, err := getCheckpoint(dbTx, model.CheckpointChainBSC)
+		if err != nil {
+			return err
+		}
+		err = dbTx.Where("height = ?", height).Delete(model.BscBlock{}).Error
 		if err != nil {
 			return err
 		}
@@ -152,6 +412,49 @@ func (d *BSCDao) DeleteBlockAndPackagesAtHeight(height uint64) error {
 		if err != nil {
 			return err
 		}
-		return nil
+		if checkpoint == nil || checkpoint.Height != height {
+			return nil
+		}
+		parent := model.BscBlock{}
+		if err = dbTx.Where("height = ?", height-1).Find(&parent).Error; err != nil {
+			return err
+		}
+		if parent.Id == 0 {
+			// the parent block has been pruned, its parent hash is unknown
+			parent = model.BscBlock{Height: height - 1, BlockHash: checkpoint.ParentHash}
+		}
+		return saveCheckpoint(dbTx, &model.ListenerCheckpoint{
+			Chain:      model.CheckpointChainBSC,
+			Height:     parent.Height,
+			BlockHash:  parent.BlockHash,
+			ParentHash: parent.ParentHash,
+			BlockTime:  parent.BlockTime,
+		})
 	})
 }
+
+// oracleSequenceKey is the cache key of packages of the oracle sequence
+func oracleSequenceKey(sequence uint64) cacheKey {
+	return cacheKey{sequence: sequence}
+}
+
+func (d *BSCDao) invalidateIds(ids ...int64) {
+	invalidate(d.inTx, func() { d.cache.invalidateIds(ids...) })
+}
+
+func (d *BSCDao) invalidateKeys(keys ...cacheKey) {
+	invalidate(d.inTx, func() { d.cache.invalidate(keys...) })
+}
+
+// invalidatePackages invalidates the oracle sequences of saved packages, which might be saved in several batches
+func (d *BSCDao) invalidatePackages(pkgs []*model.BscRelayPackage) {
+	keys := make([]cacheKey, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		keys = append(keys, oracleSequenceKey(pkg.OracleSequence))
+	}
+	d.invalidateKeys(keys...)
+}
+
+func (d *BSCDao) purgeCache() {
+	invalidate(d.inTx, d.cache.purge)
+}
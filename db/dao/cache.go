@@ -0,0 +1,160 @@
+package dao
+
+import (
+	"container/list"
+	"sync"
+)
+
+// cacheKey is the channel and sequence of packages, the oracle sequence is used for BSC packages
+type cacheKey struct {
+	channelId uint8
+	sequence  uint64
+}
+
+type cacheEntry[V any] struct {
+	key   cacheKey
+	value V
+	ids   []int64 // ids of the rows in the value
+}
+
+// lruCache is a bounded LRU cache of rows queried by channel and sequence. Entries are invalidated after their rows are
+// written, and a value queried while any entry is invalidated is not cached, so a row read before a commit never
+// outlives the invalidation after the commit. A nil cache is disabled.
+type lruCache[V any] struct {
+	mutex      sync.Mutex
+	capacity   int
+	entries    map[cacheKey]*list.Element
+	order      *list.List // the most recently used at front
+	ids        map[int64]cacheKey
+	generation uint64 // increased on every invalidation
+}
+
+func newLRUCache[V any](capacity int) *lruCache[V] {
+	return &lruCache[V]{
+		capacity: capacity,
+		entries:  make(map[cacheKey]*list.Element),
+		order:    list.New(),
+		ids:      make(map[int64]cacheKey),
+	}
+}
+
+func (c *lruCache[V]) get(key cacheKey) (value V, ok bool) {
+	if c == nil {
+		return value, false
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return value, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*cacheEntry[V]).value, true
+}
+
+// snapshot returns the generation to pass to add for a value about to be queried
+func (c *lruCache[V]) snapshot() uint64 {
+	if c == nil {
+		return 0
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.generation
+}
+
+// add caches the value unless an entry has been invalidated since the snapshot
+func (c *lruCache[V]) add(key cacheKey, value V, ids []int64, generation uint64) {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if generation != c.generation {
+		return
+	}
+	c.remove(key)
+	c.entries[key] = c.order.PushFront(&cacheEntry[V]{key: key, value: value, ids: ids})
+	for _, id := range ids {
+		c.ids[id] = key
+	}
+	for c.order.Len() > c.capacity {
+		c.remove(c.order.Back().Value.(*cacheEntry[V]).key)
+	}
+}
+
+func (c *lruCache[V]) invalidate(keys ...cacheKey) {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.generation++
+	for _, key := range keys {
+		c.remove(key)
+	}
+}
+
+// invalidateIds invalidates the entries containing the rows
+func (c *lruCache[V]) invalidateIds(ids ...int64) {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.generation++
+	for _, id := range ids {
+		if key, ok := c.ids[id]; ok {
+			c.remove(key)
+		}
+	}
+}
+
+func (c *lruCache[V]) purge() {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.generation++
+	c.entries = make(map[cacheKey]*list.Element)
+	c.order.Init()
+	c.ids = make(map[int64]cacheKey)
+}
+
+func (c *lruCache[V]) remove(key cacheKey) {
+	elem, ok := c.entries[key]
+	if !ok {
+		return
+	}
+	for _, id := range elem.Value.(*cacheEntry[V]).ids {
+		delete(c.ids, id)
+	}
+	c.order.Remove(elem)
+	delete(c.entries, key)
+}
+
+// txInvalidations collects the invalidations made in a transaction, they are made again after the transaction ends
+// since rows read by others before the commit might be cached in between
+type txInvalidations struct {
+	mutex sync.Mutex
+	fns   []func()
+}
+
+func (t *txInvalidations) replay() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	for _, fn := range t.fns {
+		fn()
+	}
+}
+
+// invalidate runs fn, and records it to be replayed if in a transaction
+func invalidate(inTx *txInvalidations, fn func()) {
+	fn()
+	if inTx == nil {
+		return
+	}
+	inTx.mutex.Lock()
+	defer inTx.mutex.Unlock()
+	inTx.fns = append(inTx.fns, fn)
+}
@@ -0,0 +1,74 @@
+package dao
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-relayer/db"
+	"github.com/bnb-chain/greenfield-relayer/types"
+)
+
+func TestLRUCache(t *testing.T) {
+	c := newLRUCache[string](2)
+	gen := c.snapshot()
+	c.add(cacheKey{1, 1}, "a", []int64{10}, gen)
+	c.add(cacheKey{1, 2}, "b", []int64{20, 21}, gen)
+	_, ok := c.get(cacheKey{1, 1})
+	require.True(t, ok)
+	// evicts the least recently used
+	c.add(cacheKey{1, 3}, "c", []int64{30}, gen)
+	_, ok = c.get(cacheKey{1, 2})
+	require.False(t, ok)
+
+	c.invalidateIds(10)
+	_, ok = c.get(cacheKey{1, 1})
+	require.False(t, ok)
+	v, ok := c.get(cacheKey{1, 3})
+	require.True(t, ok)
+	require.Equal(t, "c", v)
+
+	// queried before an invalidation, not cached
+	c.add(cacheKey{1, 4}, "d", nil, gen)
+	_, ok = c.get(cacheKey{1, 4})
+	require.False(t, ok)
+
+	c.purge()
+	_, ok = c.get(cacheKey{1, 3})
+	require.False(t, ok)
+
+	var disabled *lruCache[string]
+	disabled.add(cacheKey{1, 1}, "a", nil, disabled.snapshot())
+	_, ok = disabled.get(cacheKey{1, 1})
+	require.False(t, ok)
+}
+
+func TestDaoCacheInvalidation(t *testing.T) {
+	gormDB := newHotPathTestDB(t)
+	m := NewDaoManager(NewGreenfieldDao(gormDB), NewBSCDao(gormDB), NewVoteDao(gormDB), NewAdminDao(gormDB), NewExportDao(gormDB),
+		NewPeerDao(gormDB), NewDiagnosticDao(gormDB), NewNonceDao(gormDB), NewLockDao(gormDB))
+	m.EnableCache(16)
+
+	tx, err := m.GreenfieldDao.GetTransactionByChannelIdAndSequence(types.ChannelId(1), 7)
+	require.NoError(t, err)
+	require.Equal(t, db.Saved, tx.Status)
+	// the returned row is a copy of the cached one
+	tx.Status = db.Skipped
+	cached, err := m.GreenfieldDao.GetTransactionByChannelIdAndSequence(types.ChannelId(1), 7)
+	require.NoError(t, err)
+	require.Equal(t, db.Saved, cached.Status)
+	require.NoError(t, m.ExecTx(func(txManager *DaoManager) error {
+		return txManager.GreenfieldDao.UpdateTransactionStatus(tx.Id, db.SelfVoted)
+	}))
+	tx, err = m.GreenfieldDao.GetTransactionByChannelIdAndSequence(types.ChannelId(1), 7)
+	require.NoError(t, err)
+	require.Equal(t, db.SelfVoted, tx.Status)
+
+	votes, err := m.VoteDao.GetVotesByChannelIdAndSequence(1, 3)
+	require.NoError(t, err)
+	require.Len(t, votes, 4)
+	require.NoError(t, m.VoteDao.DeleteVotesByIds([]int64{votes[0].Id}))
+	votes, err = m.VoteDao.GetVotesByChannelIdAndSequence(1, 3)
+	require.NoError(t, err)
+	require.Len(t, votes, 3)
+}
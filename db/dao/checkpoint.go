@@ -0,0 +1,37 @@
+package dao
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/bnb-chain/greenfield-relayer/db/model"
+)
+
+// getCheckpoint returns the checkpoint of the chain, nil if there is none yet
+func getCheckpoint(db *gorm.DB, chain string) (*model.ListenerCheckpoint, error) {
+	checkpoint := model.ListenerCheckpoint{}
+	err := db.Where("chain = ?", chain).Find(&checkpoint).Error
+	if err != nil {
+		return nil, err
+	}
+	if checkpoint.Id == 0 {
+		return nil, nil
+	}
+	return &checkpoint, nil
+}
+
+func saveCheckpoint(dbTx *gorm.DB, checkpoint *model.ListenerCheckpoint) error {
+	checkpoint.UpdatedTime = time.Now().Unix()
+	return dbTx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "chain"}},
+		DoUpdates: clause.AssignmentColumns([]string{"height", "block_hash", "parent_hash", "block_time", "updated_time"}),
+	}).Omit("id").Create(checkpoint).Error
+}
+
+// deleteCheckpointFromHeight deletes the checkpoint of the chain if it is at or above the height, listeners then resume
+// from the latest remaining block
+func deleteCheckpointFromHeight(dbTx *gorm.DB, chain string, height uint64) error {
+	return dbTx.Where("chain = ? and height >= ?", chain, height).Delete(model.ListenerCheckpoint{}).Error
+}
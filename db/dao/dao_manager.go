@@ -1,15 +1,89 @@
 package dao
 
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/bnb-chain/greenfield-relayer/db"
+	"github.com/bnb-chain/greenfield-relayer/db/model"
+	"github.com/bnb-chain/greenfield-relayer/logging"
+)
+
 type DaoManager struct {
 	GreenfieldDao *GreenfieldDao
 	VoteDao       *VoteDao
 	BSCDao        *BSCDao
+	AdminDao      *AdminDao
+	ExportDao     *ExportDao
+	PeerDao       *PeerDao
+	DiagnosticDao *DiagnosticDao
+	NonceDao      *NonceDao
+	LockDao       *LockDao
 }
 
-func NewDaoManager(greenfieldDao *GreenfieldDao, bscDao *BSCDao, voteDao *VoteDao) *DaoManager {
+func NewDaoManager(greenfieldDao *GreenfieldDao, bscDao *BSCDao, voteDao *VoteDao, adminDao *AdminDao, exportDao *ExportDao, peerDao *PeerDao,
+	diagnosticDao *DiagnosticDao, nonceDao *NonceDao, lockDao *LockDao) *DaoManager {
 	return &DaoManager{
 		GreenfieldDao: greenfieldDao,
 		VoteDao:       voteDao,
 		BSCDao:        bscDao,
+		AdminDao:      adminDao,
+		ExportDao:     exportDao,
+		PeerDao:       peerDao,
+		DiagnosticDao: diagnosticDao,
+		NonceDao:      nonceDao,
+		LockDao:       lockDao,
 	}
 }
+
+// EnableCache caches packages and votes queried by channel and sequence in LRU caches of the size, so that assemblers
+// read them from memory in normal operation and from DB on misses
+func (m *DaoManager) EnableCache(size int) {
+	m.GreenfieldDao.cache = newLRUCache[model.GreenfieldRelayTransaction](size)
+	m.BSCDao.cache = newLRUCache[[]model.BscRelayPackage](size)
+	m.VoteDao.cache = newLRUCache[[]model.Vote](size)
+}
+
+// ExecTx runs fn as a unit of work in one DB transaction. The DaoManager passed to fn is bound to the transaction, all
+// updates made through it are committed if fn returns nil and rolled back otherwise. The transaction is re-run from
+// scratch if it fails by a DB failover, so fn should only make updates through the DaoManager.
+func (m *DaoManager) ExecTx(fn func(txManager *DaoManager) error) error {
+	var err error
+	// cache entries invalidated in the transaction are invalidated again after it ends
+	inTx := m.GreenfieldDao.inTx
+	if inTx == nil {
+		inTx = &txInvalidations{}
+	}
+	for i := 0; i < db.FailoverRetryAttempts; i++ {
+		err = m.GreenfieldDao.DB.Transaction(func(dbTx *gorm.DB) error {
+			return fn(m.withDB(dbTx, inTx))
+		})
+		inTx.replay()
+		if !db.IsFailoverError(err) {
+			return err
+		}
+		logging.Logger.Errorf("transaction failed by DB failover, attempt=%d, err=%s", i+1, err.Error())
+		time.Sleep(db.FailoverRetryInterval)
+	}
+	return err
+}
+
+func (m *DaoManager) withDB(dbTx *gorm.DB, inTx *txInvalidations) *DaoManager {
+	txManager := NewDaoManager(
+		NewGreenfieldDao(dbTx),
+		NewBSCDao(dbTx),
+		NewVoteDao(dbTx),
+		NewAdminDao(dbTx),
+		NewExportDao(dbTx),
+		NewPeerDao(dbTx),
+		NewDiagnosticDao(dbTx),
+		NewNonceDao(dbTx),
+		NewLockDao(dbTx),
+	)
+	// caches are not read in the transaction, which might see its own uncommitted updates
+	txManager.GreenfieldDao.cache, txManager.GreenfieldDao.inTx = m.GreenfieldDao.cache, inTx
+	txManager.BSCDao.cache, txManager.BSCDao.inTx = m.BSCDao.cache, inTx
+	txManager.VoteDao.cache, txManager.VoteDao.inTx = m.VoteDao.cache, inTx
+	return txManager
+}
@@ -0,0 +1,41 @@
+package dao
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/bnb-chain/greenfield-relayer/db/model"
+)
+
+type DiagnosticDao struct {
+	DB *gorm.DB
+}
+
+func NewDiagnosticDao(db *gorm.DB) *DiagnosticDao {
+	return &DiagnosticDao{
+		DB: db,
+	}
+}
+
+func (d *DiagnosticDao) SaveClaimDiagnostic(diagnostic *model.ClaimDiagnostic) error {
+	return d.DB.Create(diagnostic).Error
+}
+
+// GetClaimDiagnostics returns the latest diagnostics, optionally filtered by direction, channel and sequence
+func (d *DiagnosticDao) GetClaimDiagnostics(direction string, channelId *uint8, sequence *uint64, limit int) ([]*model.ClaimDiagnostic, error) {
+	diagnostics := make([]*model.ClaimDiagnostic, 0)
+	query := d.DB.Model(&model.ClaimDiagnostic{})
+	if direction != "" {
+		query = query.Where("direction = ?", direction)
+	}
+	if channelId != nil {
+		query = query.Where("channel_id = ?", *channelId)
+	}
+	if sequence != nil {
+		query = query.Where("sequence = ?", *sequence)
+	}
+	err := query.Order("id desc").Limit(limit).Find(&diagnostics).Error
+	if err != nil {
+		return nil, err
+	}
+	return diagnostics, nil
+}
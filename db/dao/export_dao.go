@@ -0,0 +1,36 @@
+package dao
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/bnb-chain/greenfield-relayer/db/model"
+)
+
+type ExportDao struct {
+	DB *gorm.DB
+}
+
+func NewExportDao(db *gorm.DB) *ExportDao {
+	return &ExportDao{
+		DB: db,
+	}
+}
+
+// GetCursor returns the cursor of the name, a zero cursor is returned if nothing has been exported yet
+func (d *ExportDao) GetCursor(name string) (*model.ExportCursor, error) {
+	cursor := model.ExportCursor{}
+	err := d.DB.Where("name = ?", name).Find(&cursor).Error
+	if err != nil {
+		return nil, err
+	}
+	cursor.Name = name
+	return &cursor, nil
+}
+
+func (d *ExportDao) SaveCursor(cursor *model.ExportCursor) error {
+	return d.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"updated_time", "record_id"}),
+	}).Omit("id").Create(cursor).Error
+}
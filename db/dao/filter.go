@@ -0,0 +1,60 @@
+package dao
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/bnb-chain/greenfield-relayer/db"
+)
+
+const MaxQueryLimit = 1000
+
+// RelayFilter filters relay packages/transactions, nil fields are not filtered on
+type RelayFilter struct {
+	ChannelId    *uint8
+	FromSequence *uint64 // inclusive
+	ToSequence   *uint64 // inclusive
+	Status       *db.TxStatus
+	FromTime     *int64  // tx_time inclusive, unix second
+	ToTime       *int64  // tx_time inclusive, unix second
+	ResourceType *string // only greenfield transactions have resource columns
+	ResourceId   *string
+	Limit        int
+}
+
+func (f *RelayFilter) apply(dbTx *gorm.DB, sequenceColumn string) *gorm.DB {
+	if f.ChannelId != nil {
+		dbTx = dbTx.Where("channel_id = ?", *f.ChannelId)
+	}
+	if f.FromSequence != nil {
+		dbTx = dbTx.Where(sequenceColumn+" >= ?", *f.FromSequence)
+	}
+	if f.ToSequence != nil {
+		dbTx = dbTx.Where(sequenceColumn+" <= ?", *f.ToSequence)
+	}
+	if f.Status != nil {
+		dbTx = dbTx.Where("status = ?", *f.Status)
+	}
+	if f.FromTime != nil {
+		dbTx = dbTx.Where("tx_time >= ?", *f.FromTime)
+	}
+	if f.ToTime != nil {
+		dbTx = dbTx.Where("tx_time <= ?", *f.ToTime)
+	}
+	if f.ResourceType != nil {
+		dbTx = dbTx.Where("resource_type = ?", *f.ResourceType)
+	}
+	if f.ResourceId != nil {
+		dbTx = dbTx.Where("resource_id = ?", *f.ResourceId)
+	}
+	limit := f.Limit
+	if limit <= 0 || limit > MaxQueryLimit {
+		limit = MaxQueryLimit
+	}
+	return dbTx.Order(sequenceColumn + " asc").Limit(limit)
+}
+
+// StatusStat is the number of records in a status and the tx time of the oldest one, 0 if there is none
+type StatusStat struct {
+	Count        int64
+	OldestTxTime int64
+}
@@ -2,9 +2,11 @@ package dao
 
 import (
 	"database/sql"
+	"fmt"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/bnb-chain/greenfield-relayer/db"
 	"github.com/bnb-chain/greenfield-relayer/db/model"
@@ -12,7 +14,9 @@ import (
 )
 
 type GreenfieldDao struct {
-	DB *gorm.DB
+	DB    *gorm.DB
+	cache *lruCache[model.GreenfieldRelayTransaction]
+	inTx  *txInvalidations
 }
 
 func NewGreenfieldDao(db *gorm.DB) *GreenfieldDao {
@@ -21,9 +25,22 @@ func NewGreenfieldDao(db *gorm.DB) *GreenfieldDao {
 	}
 }
 
+// GetLatestBlock returns the latest processed block by the listener checkpoint, or by block rows if there is no
+// checkpoint yet
 func (d *GreenfieldDao) GetLatestBlock() (*model.GreenfieldBlock, error) {
+	checkpoint, err := getCheckpoint(d.DB, model.CheckpointChainGreenfield)
+	if err != nil {
+		return nil, err
+	}
+	if checkpoint != nil {
+		return &model.GreenfieldBlock{
+			Height:    checkpoint.Height,
+			BlockTime: checkpoint.BlockTime,
+			BlockHash: checkpoint.BlockHash,
+		}, nil
+	}
 	block := model.GreenfieldBlock{}
-	err := d.DB.Model(model.GreenfieldBlock{}).Order("height desc").Take(&block).Error
+	err = d.DB.Model(model.GreenfieldBlock{}).Order("height desc").Take(&block).Error
 	if err != nil && err != gorm.ErrRecordNotFound {
 		return nil, err
 	}
@@ -39,9 +56,45 @@ func (d *GreenfieldDao) GetTransactionsByStatusWithLimit(s db.TxStatus, limit in
 	return txs, nil
 }
 
+// GetTransactionsByStatusWithPriority is GetTransactionsByStatusWithLimit returning txs of the priority channels first,
+// txs of the excluded channels are not returned
+func (d *GreenfieldDao) GetTransactionsByStatusWithPriority(s db.TxStatus, priorityChannels []uint8, excludedChannels []uint8, limit int64) ([]*model.GreenfieldRelayTransaction, error) {
+	if len(priorityChannels) == 0 && len(excludedChannels) == 0 {
+		return d.GetTransactionsByStatusWithLimit(s, limit)
+	}
+	txs := make([]*model.GreenfieldRelayTransaction, 0)
+	query := d.DB.Where("status = ? ", s)
+	if len(excludedChannels) != 0 {
+		query = query.Where("channel_id NOT IN ?", excludedChannels)
+	}
+	if len(priorityChannels) != 0 {
+		query = query.Order(clause.Expr{
+			SQL:                "CASE WHEN channel_id IN ? THEN 0 ELSE 1 END, height asc",
+			Vars:               []interface{}{priorityChannels},
+			WithoutParentheses: true,
+		})
+	} else {
+		query = query.Order("height asc")
+	}
+	err := query.Limit(int(limit)).Find(&txs).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+	return txs, nil
+}
+
+func (d *GreenfieldDao) QueryTransactions(filter *RelayFilter) ([]*model.GreenfieldRelayTransaction, error) {
+	txs := make([]*model.GreenfieldRelayTransaction, 0)
+	err := filter.apply(d.DB, "sequence").Find(&txs).Error
+	if err != nil {
+		return nil, err
+	}
+	return txs, nil
+}
+
 func (d *GreenfieldDao) GetLeastSavedTransactionHeight() (uint64, error) {
 	var result sql.NullInt64
-	res := d.DB.Table("greenfield_relay_transaction").Select("MIN(height)").Where("status = ?", db.Saved)
+	res := d.DB.Model(&model.GreenfieldRelayTransaction{}).Select("MIN(height)").Where("status = ?", db.Saved)
 	err := res.Row().Scan(&result)
 	if err != nil {
 		return 0, err
@@ -50,69 +103,263 @@ func (d *GreenfieldDao) GetLeastSavedTransactionHeight() (uint64, error) {
 }
 
 func (d *GreenfieldDao) GetTransactionByChannelIdAndSequence(channelId types.ChannelId, sequence uint64) (*model.GreenfieldRelayTransaction, error) {
+	key := cacheKey{channelId: uint8(channelId), sequence: sequence}
+	if d.inTx == nil {
+		if tx, ok := d.cache.get(key); ok {
+			return &tx, nil
+		}
+	}
+	generation := d.cache.snapshot()
 	tx := model.GreenfieldRelayTransaction{}
-	err := d.DB.Where("channel_id = ? and sequence = ?", channelId, sequence).Find(&tx).Error
-	if err != nil && err != gorm.ErrRecordNotFound {
+	query := fmt.Sprintf("SELECT * %s WHERE channel_id = ? AND sequence = ? LIMIT 1",
+		fromTable(d.DB, (&model.GreenfieldRelayTransaction{}).TableName(), "idx_greenfield_relay_transaction_unique_channel_seq"))
+	if err := prepared(d.DB).Raw(query, channelId, sequence).Scan(&tx).Error; err != nil {
 		return nil, err
 	}
+	if d.inTx == nil && tx.Id != 0 {
+		d.cache.add(key, tx, []int64{tx.Id}, generation)
+	}
 	return &tx, nil
 }
 
-func (d *GreenfieldDao) GetLatestSequenceByChannelIdAndStatus(channelId types.ChannelId, status db.TxStatus) (int64, error) {
-	var result sql.NullInt64
-	res := d.DB.Table("greenfield_relay_transaction").Select("MAX(sequence)").Where("channel_id = ? and status = ?", channelId, status)
-	err := res.Row().Scan(&result)
+func (d *GreenfieldDao) GetStatByStatus(status db.TxStatus) (*StatusStat, error) {
+	stat := StatusStat{}
+	err := d.DB.Model(&model.GreenfieldRelayTransaction{}).Select("COUNT(*) AS count, COALESCE(MIN(tx_time), 0) AS oldest_tx_time").
+		Where("status = ?", status).Scan(&stat).Error
 	if err != nil {
-		return 0, err
+		return nil, err
+	}
+	return &stat, nil
+}
+
+// CountUndeliveredTransactions returns the number of transactions of the channel not delivered yet, and the number of
+// them with sequences below the given one
+func (d *GreenfieldDao) CountUndeliveredTransactions(channelId types.ChannelId, sequence uint64) (total int64, below int64, err error) {
+	if err = d.DB.Model(&model.GreenfieldRelayTransaction{}).Where("channel_id = ? and status <> ?", channelId, db.Delivered).
+		Count(&total).Error; err != nil {
+		return 0, 0, err
 	}
-	if !result.Valid {
-		return -1, nil
+	err = d.DB.Model(&model.GreenfieldRelayTransaction{}).Where("channel_id = ? and status <> ? and sequence < ?", channelId, db.Delivered, sequence).
+		Count(&below).Error
+	return total, below, err
+}
+
+// GetSequenceWatermark returns the highest sequence of the channel which has ever reached the status, -1 if none has
+func (d *GreenfieldDao) GetSequenceWatermark(channelId types.ChannelId, status db.TxStatus) (int64, error) {
+	return getWatermark(d.DB, model.CheckpointChainGreenfield, uint8(channelId), status)
+}
+
+// GetDeliveredTransactionsUpdatedAfter returns delivered transactions ordered by (updated_time, id) which are updated
+// after the cursor (updatedTime, id) and no later than updatedBefore
+func (d *GreenfieldDao) GetDeliveredTransactionsUpdatedAfter(updatedTime, id, updatedBefore int64, limit int) ([]*model.GreenfieldRelayTransaction, error) {
+	txs := make([]*model.GreenfieldRelayTransaction, 0)
+	err := d.DB.Where("status = ? and (updated_time > ? or (updated_time = ? and id > ?)) and updated_time <= ?",
+		db.Delivered, updatedTime, updatedTime, id, updatedBefore).
+		Order("updated_time asc, id asc").Limit(limit).Find(&txs).Error
+	if err != nil {
+		return nil, err
 	}
-	return result.Int64, nil
+	return txs, nil
 }
 
+// UpdateTransactionStatus moves the transaction to the status, it is kept as is and logged if the transition is invalid
 func (d *GreenfieldDao) UpdateTransactionStatus(id int64, status db.TxStatus) error {
-	err := d.DB.Model(model.GreenfieldRelayTransaction{}).Where("id = ?", id).Updates(
-		model.GreenfieldRelayTransaction{Status: status, UpdatedTime: time.Now().Unix()}).Error
-	return err
+	return d.UpdateBatchTransactionStatus([]int64{id}, status)
 }
 
-func UpdateTransactionStatus(dbTx *gorm.DB, id int64, status db.TxStatus) error {
-	err := dbTx.Model(model.GreenfieldRelayTransaction{}).Where("id = ?", id).Updates(
-		model.GreenfieldRelayTransaction{Status: status, UpdatedTime: time.Now().Unix()}).Error
+// UpdateBatchTransactionStatus moves the transactions to the status in one statement, transactions which can not
+// transition to the status are kept as is and logged
+func (d *GreenfieldDao) UpdateBatchTransactionStatus(ids []int64, status db.TxStatus) error {
+	defer d.invalidateIds(ids...)
+	var moved int64
+	err := prepared(d.DB).Transaction(func(dbTx *gorm.DB) error {
+		res := dbTx.Exec(fmt.Sprintf("UPDATE %s SET status = ?, updated_time = ? WHERE id IN ? and status IN ?", (&model.GreenfieldRelayTransaction{}).TableName()),
+			status, time.Now().Unix(), ids, db.SourcesOf(status))
+		if res.Error != nil {
+			return res.Error
+		}
+		moved = res.RowsAffected
+		if err := logRejectedTransitions(dbTx, model.CheckpointChainGreenfield, (&model.GreenfieldRelayTransaction{}).TableName(), ids, status, moved); err != nil || moved == 0 {
+			return err
+		}
+		return raiseGreenfieldWatermarks(dbTx, ids, status)
+	})
+	if err == nil {
+		db.NotifyTransition(model.CheckpointChainGreenfield, status, moved)
+	}
 	return err
 }
 
 func (d *GreenfieldDao) UpdateTransactionClaimedTxHash(id int64, claimedTxHash string) error {
+	defer d.invalidateIds(id)
 	return d.DB.Transaction(func(dbTx *gorm.DB) error {
-		return dbTx.Model(model.GreenfieldRelayTransaction{}).Where("id = ?", id).Updates(
+		err := dbTx.Model(model.GreenfieldRelayTransaction{}).Where("id = ?", id).Updates(
 			model.GreenfieldRelayTransaction{UpdatedTime: time.Now().Unix(), ClaimedTxHash: claimedTxHash}).Error
+		if err != nil {
+			return err
+		}
+		return resetClaimReceipt(dbTx, id)
 	})
 }
 
+// UpdateTransactionStatusAndClaimedTxHash records the claim tx of the transaction and moves it to the status, the
+// claim tx is recorded even if the transition is invalid, which is logged
 func (d *GreenfieldDao) UpdateTransactionStatusAndClaimedTxHash(id int64, status db.TxStatus, claimedTxHash string) error {
+	defer d.invalidateIds(id)
+	var moved int64
+	err := d.DB.Transaction(func(dbTx *gorm.DB) error {
+		err := dbTx.Model(model.GreenfieldRelayTransaction{}).Where("id = ?", id).Updates(
+			model.GreenfieldRelayTransaction{UpdatedTime: time.Now().Unix(), ClaimedTxHash: claimedTxHash}).Error
+		if err != nil {
+			return err
+		}
+		if err = resetClaimReceipt(dbTx, id); err != nil {
+			return err
+		}
+		res := dbTx.Model(model.GreenfieldRelayTransaction{}).Where("id = ? and status IN ?", id, db.SourcesOf(status)).
+			Update("status", status)
+		if res.Error != nil {
+			return res.Error
+		}
+		moved = res.RowsAffected
+		if err := logRejectedTransitions(dbTx, model.CheckpointChainGreenfield, (&model.GreenfieldRelayTransaction{}).TableName(), []int64{id}, status, moved); err != nil || moved == 0 {
+			return err
+		}
+		return raiseGreenfieldWatermark(dbTx, id, status)
+	})
+	if err == nil {
+		db.NotifyTransition(model.CheckpointChainGreenfield, status, moved)
+	}
+	return err
+}
+
+// resetClaimReceipt marks the receipt of a new claim tx as pending, events of the previous claim tx are kept
+func resetClaimReceipt(dbTx *gorm.DB, id int64) error {
+	return dbTx.Model(model.GreenfieldRelayTransaction{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"claim_receipt_status": db.ReceiptPending,
+		"claim_block_height":   0,
+		"claim_gas_used":       0,
+	}).Error
+}
+
+// GetTransactionsWithPendingReceipt returns transactions whose claim tx has been sent but the receipt is not decoded
+func (d *GreenfieldDao) GetTransactionsWithPendingReceipt(limit int) ([]*model.GreenfieldRelayTransaction, error) {
+	txs := make([]*model.GreenfieldRelayTransaction, 0)
+	err := d.DB.Where("claim_receipt_status = ? and claimed_tx_hash <> ''", db.ReceiptPending).Order("id asc").Limit(limit).Find(&txs).Error
+	return txs, err
+}
+
+// SaveClaimReceipt updates the receipt of the claim tx of the transaction and saves its decoded events
+func (d *GreenfieldDao) SaveClaimReceipt(id int64, status db.ReceiptStatus, height, gasUsed uint64, gasPrice string, events []*model.ClaimReceiptEvent) error {
+	defer d.invalidateIds(id)
 	return d.DB.Transaction(func(dbTx *gorm.DB) error {
-		return dbTx.Model(model.GreenfieldRelayTransaction{}).Where("id = ?", id).Updates(
-			model.GreenfieldRelayTransaction{Status: status, UpdatedTime: time.Now().Unix(), ClaimedTxHash: claimedTxHash}).Error
+		err := dbTx.Model(model.GreenfieldRelayTransaction{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"claim_receipt_status": status,
+			"claim_block_height":   height,
+			"claim_gas_used":       gasUsed,
+			"claim_gas_price":      gasPrice,
+		}).Error
+		if err != nil || len(events) == 0 {
+			return err
+		}
+		return dbTx.Create(events).Error
 	})
 }
 
+func (d *GreenfieldDao) GetClaimReceiptEvents(relayTransactionId int64) ([]*model.ClaimReceiptEvent, error) {
+	events := make([]*model.ClaimReceiptEvent, 0)
+	err := d.DB.Where("relay_transaction_id = ?", relayTransactionId).Order("id asc").Find(&events).Error
+	return events, err
+}
+
+// UpdateBatchTransactionStatusToDelivered marks the transactions awaiting delivery before the sequence as delivered
 func (d *GreenfieldDao) UpdateBatchTransactionStatusToDelivered(seq uint64) error {
-	return d.DB.Transaction(func(dbTx *gorm.DB) error {
-		return dbTx.Model(model.GreenfieldRelayTransaction{}).Where("sequence < ? and status = 2", seq).Updates(
-			model.GreenfieldRelayTransaction{Status: db.Delivered, UpdatedTime: time.Now().Unix()}).Error
+	defer d.purgeCache()
+	var moved int64
+	err := d.DB.Transaction(func(dbTx *gorm.DB) error {
+		var watermarks []struct {
+			ChannelId uint8
+			Sequence  uint64
+		}
+		err := dbTx.Model(model.GreenfieldRelayTransaction{}).Select("channel_id, MAX(sequence) AS sequence").
+			Where("sequence < ? and status IN ?", seq, db.AwaitingDelivery).Group("channel_id").Scan(&watermarks).Error
+		if err != nil {
+			return err
+		}
+		res := dbTx.Model(model.GreenfieldRelayTransaction{}).Where("sequence < ? and status IN ?", seq, db.AwaitingDelivery).Updates(
+			model.GreenfieldRelayTransaction{Status: db.Delivered, UpdatedTime: time.Now().Unix()})
+		if res.Error != nil {
+			return res.Error
+		}
+		moved = res.RowsAffected
+		for _, w := range watermarks {
+			if err = raiseWatermark(dbTx, model.CheckpointChainGreenfield, w.ChannelId, db.Delivered, w.Sequence); err != nil {
+				return err
+			}
+		}
+		return nil
 	})
+	if err == nil {
+		db.NotifyTransition(model.CheckpointChainGreenfield, db.Delivered, moved)
+	}
+	return err
+}
+
+// SkipTransaction marks the transaction of the channel and sequence as skipped unless it is delivered, returns the
+// number of skipped transactions
+func (d *GreenfieldDao) SkipTransaction(channelId types.ChannelId, sequence uint64) (int64, error) {
+	defer d.invalidateKeys(cacheKey{channelId: uint8(channelId), sequence: sequence})
+	res := d.DB.Model(model.GreenfieldRelayTransaction{}).Where("channel_id = ? and sequence = ? and status IN ?", channelId, sequence, db.SourcesOf(db.Skipped)).Updates(
+		model.GreenfieldRelayTransaction{Status: db.Skipped, UpdatedTime: time.Now().Unix()})
+	if res.Error == nil {
+		db.NotifyTransition(model.CheckpointChainGreenfield, db.Skipped, res.RowsAffected)
+	}
+	return res.RowsAffected, res.Error
+}
+
+// IncreaseClaimFailures increases the claim failures of the transaction, and moves it to the needs attention status
+// once the failures reach maxFailures unless it is 0. Returns whether the transaction is moved.
+func (d *GreenfieldDao) IncreaseClaimFailures(id int64, maxFailures uint32) (bool, error) {
+	defer d.invalidateIds(id)
+	moved := false
+	err := d.DB.Transaction(func(dbTx *gorm.DB) error {
+		err := dbTx.Model(model.GreenfieldRelayTransaction{}).Where("id = ?", id).
+			Update("claim_failures", gorm.Expr("claim_failures + 1")).Error
+		if err != nil || maxFailures == 0 {
+			return err
+		}
+		res := dbTx.Model(model.GreenfieldRelayTransaction{}).Where("id = ? and claim_failures >= ? and status IN ?", id, maxFailures, db.SourcesOf(db.NeedsAttention)).
+			Updates(model.GreenfieldRelayTransaction{Status: db.NeedsAttention, UpdatedTime: time.Now().Unix()})
+		moved = res.RowsAffected > 0
+		return res.Error
+	})
+	if err == nil && moved {
+		db.NotifyTransition(model.CheckpointChainGreenfield, db.NeedsAttention, 1)
+	}
+	return moved, err
+}
+
+// RetryTransaction moves the transaction of the channel and sequence from the needs attention status back to all
+// voted with its claim failures reset, returns the number of moved transactions
+func (d *GreenfieldDao) RetryTransaction(channelId types.ChannelId, sequence uint64) (int64, error) {
+	defer d.invalidateKeys(cacheKey{channelId: uint8(channelId), sequence: sequence})
+	res := d.DB.Model(model.GreenfieldRelayTransaction{}).Where("channel_id = ? and sequence = ? and status = ?", channelId, sequence, db.NeedsAttention).
+		Updates(map[string]interface{}{"status": db.AllVoted, "claim_failures": 0, "updated_time": time.Now().Unix()})
+	if res.Error == nil {
+		db.NotifyTransition(model.CheckpointChainGreenfield, db.AllVoted, res.RowsAffected)
+	}
+	return res.RowsAffected, res.Error
 }
 
 func (d *GreenfieldDao) SaveBlockAndBatchTransactions(b *model.GreenfieldBlock, txs []*model.GreenfieldRelayTransaction) error {
 	return d.DB.Transaction(func(dbTx *gorm.DB) error {
-		err := dbTx.Create(b).Error
+		err := saveGreenfieldBlock(dbTx, b)
 		if err != nil {
 			return err
 		}
 
 		if len(txs) != 0 {
-			err := dbTx.Create(txs).Error
+			err := saveTransactions(dbTx, txs).Error
 			if err != nil {
 				return err
 			}
@@ -121,12 +368,103 @@ func (d *GreenfieldDao) SaveBlockAndBatchTransactions(b *model.GreenfieldBlock,
 	})
 }
 
+// SaveMissingTransactions saves the transactions which do not exist in DB yet, returns the number of saved transactions
+func (d *GreenfieldDao) SaveMissingTransactions(txs []*model.GreenfieldRelayTransaction) (int, error) {
+	savedCnt := 0
+	err := d.DB.Transaction(func(dbTx *gorm.DB) error {
+		var err error
+		savedCnt, err = saveMissingTransactions(dbTx, txs)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return savedCnt, nil
+}
+
+// SaveBlockAndMissingTransactions saves the block and the transactions which do not exist in DB yet, used when
+// re-processing blocks whose transactions might have been saved before
+func (d *GreenfieldDao) SaveBlockAndMissingTransactions(b *model.GreenfieldBlock, txs []*model.GreenfieldRelayTransaction) error {
+	return d.DB.Transaction(func(dbTx *gorm.DB) error {
+		if err := saveGreenfieldBlock(dbTx, b); err != nil {
+			return err
+		}
+		_, err := saveMissingTransactions(dbTx, txs)
+		return err
+	})
+}
+
+// saveGreenfieldBlock saves the block and moves the listener checkpoint to it
+func saveGreenfieldBlock(dbTx *gorm.DB, b *model.GreenfieldBlock) error {
+	if err := dbTx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "height"}},
+		DoUpdates: clause.AssignmentColumns([]string{"block_time"}),
+	}).Create(b).Error; err != nil {
+		return err
+	}
+	return saveCheckpoint(dbTx, &model.ListenerCheckpoint{
+		Chain:     model.CheckpointChainGreenfield,
+		Height:    b.Height,
+		BlockHash: b.BlockHash,
+		BlockTime: b.BlockTime,
+	})
+}
+
+func saveMissingTransactions(dbTx *gorm.DB, txs []*model.GreenfieldRelayTransaction) (int, error) {
+	if len(txs) == 0 {
+		return 0, nil
+	}
+	res := saveTransactions(dbTx, txs)
+	if res.Error != nil {
+		return 0, res.Error
+	}
+	return int(res.RowsAffected), nil
+}
+
+// saveTransactions saves the transactions, those already saved are skipped and keep their status
+func saveTransactions(dbTx *gorm.DB, txs []*model.GreenfieldRelayTransaction) *gorm.DB {
+	return dbTx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "channel_id"}, {Name: "sequence"}},
+		DoNothing: true,
+	}).Create(txs)
+}
+
+// DeleteBlocksFromHeight deletes blocks and the listener checkpoint at or above the height, transactions are kept
+func (d *GreenfieldDao) DeleteBlocksFromHeight(height uint64) error {
+	return d.DB.Transaction(func(dbTx *gorm.DB) error {
+		if err := dbTx.Where("height >= ?", height).Delete(model.GreenfieldBlock{}).Error; err != nil {
+			return err
+		}
+		return deleteCheckpointFromHeight(dbTx, model.CheckpointChainGreenfield, height)
+	})
+}
+
+// DeleteBlocksBelowHeight prunes blocks below the height, the listener resumes from its checkpoint
+func (d *GreenfieldDao) DeleteBlocksBelowHeight(height uint64) error {
+	return d.DB.Where("height < ?", height).Delete(model.GreenfieldBlock{}).Error
+}
+
 func (d *GreenfieldDao) SaveSyncLightBlockTransaction(t *model.SyncLightBlockTransaction) error {
 	return d.DB.Transaction(func(dbTx *gorm.DB) error {
 		return dbTx.Create(t).Error
 	})
 }
 
+// GetSyncedTransactionsInRange returns the light blocks synced for validator set changes within (from, to], ordered
+// by height
+func (d *GreenfieldDao) GetSyncedTransactionsInRange(from, to uint64) ([]*model.SyncLightBlockTransaction, error) {
+	txs := make([]*model.SyncLightBlockTransaction, 0)
+	err := d.DB.Model(model.SyncLightBlockTransaction{}).Where("height > ? and height <= ?", from, to).Order("height asc").Find(&txs).Error
+	if err != nil {
+		return nil, err
+	}
+	return txs, nil
+}
+
+func (d *GreenfieldDao) UpdateSyncLightBlockTxHash(id int64, txHash string) error {
+	return d.DB.Model(model.SyncLightBlockTransaction{}).Where("id = ?", id).Update("tx_hash", txHash).Error
+}
+
 func (d *GreenfieldDao) GetLatestSyncedTransaction() (*model.SyncLightBlockTransaction, error) {
 	tx := model.SyncLightBlockTransaction{}
 	err := d.DB.Model(model.SyncLightBlockTransaction{}).Order("height desc").Take(&tx).Error
@@ -135,3 +473,15 @@ func (d *GreenfieldDao) GetLatestSyncedTransaction() (*model.SyncLightBlockTrans
 	}
 	return &tx, nil
 }
+
+func (d *GreenfieldDao) invalidateIds(ids ...int64) {
+	invalidate(d.inTx, func() { d.cache.invalidateIds(ids...) })
+}
+
+func (d *GreenfieldDao) invalidateKeys(keys ...cacheKey) {
+	invalidate(d.inTx, func() { d.cache.invalidate(keys...) })
+}
+
+func (d *GreenfieldDao) purgeCache() {
+	invalidate(d.inTx, d.cache.purge)
+}
@@ -0,0 +1,28 @@
+package dao
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Queries on the hot paths of vote processors and assemblers are written in raw SQL forcing the index to use, and run
+// as prepared statements cached by GORM, which saves building the query on every call and keeps the planner from
+// scanning tables with skewed status distributions.
+
+// prepared returns a session running statements as prepared statements, statements are cached per connection pool
+func prepared(db *gorm.DB) *gorm.DB {
+	return db.Session(&gorm.Session{PrepareStmt: true})
+}
+
+// fromTable returns the FROM clause of the table forcing the index, in the syntax of the dialect
+func fromTable(db *gorm.DB, table, index string) string {
+	switch db.Dialector.Name() {
+	case "mysql":
+		return fmt.Sprintf("FROM %s FORCE INDEX (%s)", table, index)
+	case "sqlite":
+		return fmt.Sprintf("FROM %s INDEXED BY %s", table, index)
+	default:
+		return fmt.Sprintf("FROM %s", table)
+	}
+}
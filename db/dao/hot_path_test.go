@@ -0,0 +1,136 @@
+package dao
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-relayer/db"
+	"github.com/bnb-chain/greenfield-relayer/db/model"
+	"github.com/bnb-chain/greenfield-relayer/types"
+)
+
+func TestHotPathQueries(t *testing.T) {
+	gormDB := newHotPathTestDB(t)
+	bscDao, greenfieldDao, voteDao := NewBSCDao(gormDB), NewGreenfieldDao(gormDB), NewVoteDao(gormDB)
+
+	pkgs, err := bscDao.GetPackagesByOracleSequence(10)
+	require.NoError(t, err)
+	require.Len(t, pkgs, 2)
+	require.Equal(t, uint64(20), pkgs[0].PackageSequence)
+	require.NoError(t, bscDao.UpdateBatchPackagesStatus([]int64{pkgs[0].Id, pkgs[1].Id}, db.SelfVoted))
+	pkgs, err = bscDao.GetPackagesByOracleSequence(10)
+	require.NoError(t, err)
+	require.Equal(t, db.SelfVoted, pkgs[1].Status)
+
+	tx, err := greenfieldDao.GetTransactionByChannelIdAndSequence(types.ChannelId(1), 7)
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), tx.Sequence)
+	// seeded transactions are Saved, which moves to AllVoted through SelfVoted
+	require.NoError(t, greenfieldDao.UpdateTransactionStatus(tx.Id, db.SelfVoted))
+	require.NoError(t, greenfieldDao.UpdateTransactionStatus(tx.Id, db.AllVoted))
+	tx, err = greenfieldDao.GetTransactionByChannelIdAndSequence(types.ChannelId(1), 7)
+	require.NoError(t, err)
+	require.Equal(t, db.AllVoted, tx.Status)
+	// an invalid transition is logged and the transaction is kept as is
+	require.NoError(t, greenfieldDao.UpdateBatchTransactionStatus([]int64{tx.Id}, db.Saved))
+	tx, err = greenfieldDao.GetTransactionByChannelIdAndSequence(types.ChannelId(1), 7)
+	require.NoError(t, err)
+	require.Equal(t, db.AllVoted, tx.Status)
+	tx, err = greenfieldDao.GetTransactionByChannelIdAndSequence(types.ChannelId(1), 5000)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), tx.Id)
+
+	votes, err := voteDao.GetVotesByChannelIdAndSequence(1, 3)
+	require.NoError(t, err)
+	require.Len(t, votes, 4)
+}
+
+// the GORM versions the raw SQL replaced, kept for comparison
+func BenchmarkGetPackagesByOracleSequence(b *testing.B) {
+	gormDB := newHotPathTestDB(b)
+	bscDao := NewBSCDao(gormDB)
+	b.Run("gorm", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			pkgs := make([]*model.BscRelayPackage, 0)
+			require.NoError(b, gormDB.Where("oracle_sequence = ?", uint64(i%500)).Find(&pkgs).Error)
+		}
+	})
+	b.Run("raw", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, err := bscDao.GetPackagesByOracleSequence(uint64(i % 500))
+			require.NoError(b, err)
+		}
+	})
+}
+
+func BenchmarkGetVotesByChannelIdAndSequence(b *testing.B) {
+	gormDB := newHotPathTestDB(b)
+	voteDao := NewVoteDao(gormDB)
+	b.Run("gorm", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			votes := make([]*model.Vote, 0)
+			require.NoError(b, gormDB.Where("channel_id = ? and sequence = ?", 1, uint64(i%250)).Find(&votes).Error)
+		}
+	})
+	b.Run("raw", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, err := voteDao.GetVotesByChannelIdAndSequence(1, uint64(i%250))
+			require.NoError(b, err)
+		}
+	})
+}
+
+func BenchmarkUpdateBatchPackagesStatus(b *testing.B) {
+	gormDB := newHotPathTestDB(b)
+	bscDao := NewBSCDao(gormDB)
+	ids := []int64{1, 2}
+	b.Run("gorm", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			require.NoError(b, gormDB.Model(model.BscRelayPackage{}).Where("id IN (?)", ids).Updates(
+				model.BscRelayPackage{Status: db.SelfVoted, UpdatedTime: int64(i)}).Error)
+		}
+	})
+	b.Run("raw", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			require.NoError(b, bscDao.UpdateBatchPackagesStatus(ids, db.SelfVoted))
+		}
+	})
+}
+
+func BenchmarkGetTransactionsByStatusWithPriority(b *testing.B) {
+	greenfieldDao := NewGreenfieldDao(newHotPathTestDB(b))
+	b.Run("no_priority", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, err := greenfieldDao.GetTransactionsByStatusWithPriority(db.Saved, nil, nil, 50)
+			require.NoError(b, err)
+		}
+	})
+	b.Run("priority", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, err := greenfieldDao.GetTransactionsByStatusWithPriority(db.Saved, []uint8{2}, []uint8{3}, 50)
+			require.NoError(b, err)
+		}
+	})
+}
+
+func BenchmarkGetTransactionByChannelIdAndSequence(b *testing.B) {
+	greenfieldDao := NewGreenfieldDao(newHotPathTestDB(b))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := greenfieldDao.GetTransactionByChannelIdAndSequence(types.ChannelId(1), uint64(i%1000))
+		require.NoError(b, err)
+	}
+}
+
+func BenchmarkSaveBatchVotes(b *testing.B) {
+	voteDao := NewVoteDao(newHotPathTestDB(b))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		votes := make([]*model.Vote, 0, 4)
+		for j := 0; j < 4; j++ {
+			votes = append(votes, &model.Vote{ChannelId: 2, Sequence: uint64(i), PubKey: string(rune('a' + j)), Signature: "0x"})
+		}
+		require.NoError(b, voteDao.SaveBatchVotes(votes))
+	}
+}
@@ -0,0 +1,64 @@
+package dao
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/bnb-chain/greenfield-relayer/db/model"
+)
+
+type LockDao struct {
+	DB *gorm.DB
+}
+
+func NewLockDao(db *gorm.DB) *LockDao {
+	return &LockDao{
+		DB: db,
+	}
+}
+
+// TryLockSequence locks the sequence for the owner until expireTime, it returns false if the sequence is locked by
+// another owner and the lock has not expired at now. A lock held by the owner is extended.
+func (d *LockDao) TryLockSequence(direction string, channelId uint8, sequence uint64, owner string, now, expireTime int64) (bool, error) {
+	lock := &model.SequenceLock{
+		Direction:  direction,
+		ChannelId:  channelId,
+		Sequence:   sequence,
+		Owner:      owner,
+		ExpireTime: expireTime,
+	}
+	res := d.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(lock)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	if res.RowsAffected == 1 {
+		return true, nil
+	}
+	// the lock exists, it is taken over if it is expired
+	err := d.DB.Model(model.SequenceLock{}).
+		Where("direction = ? and channel_id = ? and sequence = ? and (owner = ? or expire_time < ?)", direction, channelId, sequence, owner, now).
+		Updates(map[string]interface{}{"owner": owner, "expire_time": expireTime}).Error
+	if err != nil {
+		return false, err
+	}
+	// rows affected is not reliable to tell whether the lock is taken since updates without changes are not counted
+	// by MySQL, so the owner is read back
+	held := model.SequenceLock{}
+	err = d.DB.Where("direction = ? and channel_id = ? and sequence = ?", direction, channelId, sequence).Take(&held).Error
+	if err != nil {
+		return false, err
+	}
+	return held.Owner == owner, nil
+}
+
+// UnlockSequence releases the lock of the sequence if it is held by the owner
+func (d *LockDao) UnlockSequence(direction string, channelId uint8, sequence uint64, owner string) error {
+	return d.DB.Where("direction = ? and channel_id = ? and sequence = ? and owner = ?", direction, channelId, sequence, owner).
+		Delete(&model.SequenceLock{}).Error
+}
+
+// DeleteExpiredSequenceLocks deletes locks expired before the time
+func (d *LockDao) DeleteExpiredSequenceLocks(before int64) (int64, error) {
+	res := d.DB.Where("expire_time < ?", before).Delete(&model.SequenceLock{})
+	return res.RowsAffected, res.Error
+}
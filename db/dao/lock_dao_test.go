@@ -0,0 +1,49 @@
+package dao
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-relayer/db/model"
+)
+
+func TestTryLockSequence(t *testing.T) {
+	gormDB := newHotPathTestDB(t)
+	model.InitLockTables(gormDB)
+	d := NewLockDao(gormDB)
+	const direction = "greenfield_to_bsc"
+
+	locked, err := d.TryLockSequence(direction, 1, 7, "a", 100, 160)
+	require.NoError(t, err)
+	require.True(t, locked)
+	// held by a, extended by a in the same second
+	locked, err = d.TryLockSequence(direction, 1, 7, "a", 100, 160)
+	require.NoError(t, err)
+	require.True(t, locked)
+	locked, err = d.TryLockSequence(direction, 1, 7, "b", 120, 180)
+	require.NoError(t, err)
+	require.False(t, locked)
+	// other sequences are not affected
+	locked, err = d.TryLockSequence(direction, 1, 8, "b", 120, 180)
+	require.NoError(t, err)
+	require.True(t, locked)
+
+	// taken over once expired
+	locked, err = d.TryLockSequence(direction, 1, 7, "b", 161, 221)
+	require.NoError(t, err)
+	require.True(t, locked)
+
+	require.NoError(t, d.UnlockSequence(direction, 1, 7, "a"))
+	locked, err = d.TryLockSequence(direction, 1, 7, "a", 170, 230)
+	require.NoError(t, err)
+	require.False(t, locked)
+	require.NoError(t, d.UnlockSequence(direction, 1, 7, "b"))
+	locked, err = d.TryLockSequence(direction, 1, 7, "a", 170, 230)
+	require.NoError(t, err)
+	require.True(t, locked)
+
+	deleted, err := d.DeleteExpiredSequenceLocks(200)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), deleted)
+}
@@ -0,0 +1,49 @@
+package dao
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/bnb-chain/greenfield-relayer/db/model"
+)
+
+type NonceDao struct {
+	DB *gorm.DB
+}
+
+func NewNonceDao(db *gorm.DB) *NonceDao {
+	return &NonceDao{
+		DB: db,
+	}
+}
+
+// ReserveNonce saves the reservation before the claim is broadcast, a nonce reserved earlier by a claim which failed
+// to be broadcast is taken over
+func (d *NonceDao) ReserveNonce(reservation *model.NonceReservation) error {
+	return d.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "direction"}, {Name: "nonce"}},
+		DoUpdates: clause.AssignmentColumns([]string{"channel_id", "sequence", "tx_hash", "reserved_time"}),
+	}).Create(reservation).Error
+}
+
+func (d *NonceDao) UpdateNonceReservationTxHash(direction string, nonce uint64, txHash string) error {
+	return d.DB.Model(model.NonceReservation{}).Where("direction = ? and nonce = ?", direction, nonce).
+		Update("tx_hash", txHash).Error
+}
+
+// GetNonceReservations returns reservations of the direction in the order of nonces
+func (d *NonceDao) GetNonceReservations(direction string) ([]*model.NonceReservation, error) {
+	reservations := make([]*model.NonceReservation, 0)
+	err := d.DB.Where("direction = ?", direction).Order("nonce asc").Find(&reservations).Error
+	if err != nil {
+		return nil, err
+	}
+	return reservations, nil
+}
+
+func (d *NonceDao) DeleteNonceReservations(ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return d.DB.Where("id IN (?)", ids).Delete(&model.NonceReservation{}).Error
+}
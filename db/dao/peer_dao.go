@@ -0,0 +1,54 @@
+package dao
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/bnb-chain/greenfield-relayer/db/model"
+)
+
+type PeerDao struct {
+	DB *gorm.DB
+}
+
+func NewPeerDao(db *gorm.DB) *PeerDao {
+	return &PeerDao{
+		DB: db,
+	}
+}
+
+// RelayerStat summarizes deliveries of a relayer, latencies are in seconds and only count known ones
+type RelayerStat struct {
+	Direction     string  `json:"direction"`
+	Relayer       string  `json:"relayer"`
+	Deliveries    int64   `json:"deliveries"`
+	AvgLatency    float64 `json:"avg_latency"`
+	MaxLatency    int64   `json:"max_latency"`
+	LastDelivered int64   `json:"last_delivered"`
+}
+
+// SavePeerDeliveries saves the deliveries, deliveries which have been observed are skipped
+func (d *PeerDao) SavePeerDeliveries(deliveries []*model.PeerDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return d.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(deliveries).Error
+}
+
+// GetRelayerStats aggregates deliveries after the given time by direction and relayer
+func (d *PeerDao) GetRelayerStats(direction string, deliveredAfter int64) ([]*RelayerStat, error) {
+	stats := make([]*RelayerStat, 0)
+	query := d.DB.Model(&model.PeerDelivery{}).
+		Select("direction, relayer, COUNT(*) AS deliveries, "+
+			"COALESCE(AVG(CASE WHEN latency >= 0 THEN latency END), 0) AS avg_latency, "+
+			"COALESCE(MAX(latency), 0) AS max_latency, MAX(delivered_time) AS last_delivered").
+		Where("delivered_time > ?", deliveredAfter)
+	if direction != "" {
+		query = query.Where("direction = ?", direction)
+	}
+	err := query.Group("direction, relayer").Order("direction, deliveries desc").Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
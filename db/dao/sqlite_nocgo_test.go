@@ -0,0 +1,15 @@
+//go:build !cgo
+
+package dao
+
+import (
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// newHotPathTestDB skips the test since the sqlite driver requires cgo
+func newHotPathTestDB(t testing.TB) *gorm.DB {
+	t.Skip("sqlite requires cgo")
+	return nil
+}
@@ -0,0 +1,29 @@
+//go:build cgo
+
+package dao
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/bnb-chain/greenfield-relayer/db/model"
+)
+
+func newHotPathTestDB(t testing.TB) *gorm.DB {
+	gormDB, err := gorm.Open(sqlite.Open(t.TempDir()+"/relayer.db"), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	model.InitBSCTables(gormDB)
+	model.InitGreenfieldTables(gormDB)
+	model.InitVoteTables(gormDB)
+	model.InitWatermarkTables(gormDB)
+	for i := uint64(0); i < 1000; i++ {
+		require.NoError(t, gormDB.Create(&model.BscRelayPackage{ChannelId: 1, OracleSequence: i / 2, PackageSequence: i, TxHash: "0x"}).Error)
+		require.NoError(t, gormDB.Create(&model.GreenfieldRelayTransaction{ChannelId: 1, Sequence: i, RelayerFee: "0", AckRelayerFee: "0"}).Error)
+		require.NoError(t, gormDB.Create(&model.Vote{ChannelId: 1, Sequence: i / 4, PubKey: string(rune('a' + i%4)), Signature: "0x"}).Error)
+	}
+	return gormDB
+}
@@ -0,0 +1,35 @@
+package dao
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/bnb-chain/greenfield-relayer/db"
+	"github.com/bnb-chain/greenfield-relayer/logging"
+)
+
+// logRejectedTransitions logs the rows of ids which are not moved to the status by an update of moved rows, as their
+// current status can not transition to it
+func logRejectedTransitions(dbTx *gorm.DB, chain, table string, ids []int64, status db.TxStatus, moved int64) error {
+	if moved >= int64(len(ids)) {
+		return nil
+	}
+	var rejected []struct {
+		Id     int64
+		Status db.TxStatus
+	}
+	if err := dbTx.Table(table).Select("id, status").Where("id IN ? and status <> ?", ids, status).Scan(&rejected).Error; err != nil {
+		return err
+	}
+	if len(rejected) == 0 {
+		return nil
+	}
+	rows := make([]string, 0, len(rejected))
+	for _, r := range rejected {
+		rows = append(rows, fmt.Sprintf("%d(%s)", r.Id, r.Status))
+	}
+	logging.Logger.Errorf("rejected invalid transition of %s packages to %s, ids(status)=%s", chain, status, strings.Join(rows, ","))
+	return nil
+}
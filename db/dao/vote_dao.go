@@ -1,13 +1,20 @@
 package dao
 
 import (
+	"fmt"
+	"sort"
+
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
+	"github.com/bnb-chain/greenfield-relayer/common"
 	"github.com/bnb-chain/greenfield-relayer/db/model"
 )
 
 type VoteDao struct {
-	DB *gorm.DB
+	DB    *gorm.DB
+	cache *lruCache[[]model.Vote]
+	inTx  *txInvalidations
 }
 
 func NewVoteDao(db *gorm.DB) *VoteDao {
@@ -17,14 +24,45 @@ func NewVoteDao(db *gorm.DB) *VoteDao {
 }
 
 func (d *VoteDao) GetVotesByChannelIdAndSequence(channelId uint8, sequence uint64) ([]*model.Vote, error) {
+	key := cacheKey{channelId: channelId, sequence: sequence}
+	if d.inTx == nil {
+		if cached, ok := d.cache.get(key); ok {
+			votes := make([]*model.Vote, 0, len(cached))
+			for i := range cached {
+				v := cached[i]
+				votes = append(votes, &v)
+			}
+			return votes, nil
+		}
+	}
+	generation := d.cache.snapshot()
 	votes := make([]*model.Vote, 0)
-	err := d.DB.Where("channel_id = ? and sequence = ?", channelId, sequence).Find(&votes).Error
-	if err != nil && err != gorm.ErrRecordNotFound {
+	query := fmt.Sprintf("SELECT * %s WHERE channel_id = ? AND sequence = ?",
+		fromTable(d.DB, (&model.Vote{}).TableName(), "idx_vote_channel_id_sequence_pub_key"))
+	if err := prepared(d.DB).Raw(query, channelId, sequence).Scan(&votes).Error; err != nil {
 		return nil, err
 	}
+	if d.inTx == nil && len(votes) != 0 {
+		cached := make([]model.Vote, 0, len(votes))
+		ids := make([]int64, 0, len(votes))
+		for _, v := range votes {
+			cached = append(cached, *v)
+			ids = append(ids, v.Id)
+		}
+		d.cache.add(key, cached, ids, generation)
+	}
 	return votes, nil
 }
 
+func (d *VoteDao) GetVotePubKeysByChannelIdAndSequence(channelId uint8, sequence uint64) ([]string, error) {
+	pubKeys := make([]string, 0)
+	err := d.DB.Model(model.Vote{}).Where("channel_id = ? and sequence = ?", channelId, sequence).Pluck("pub_key", &pubKeys).Error
+	if err != nil {
+		return nil, err
+	}
+	return pubKeys, nil
+}
+
 func (d *VoteDao) GetVotesCountByChannelIdAndSequence(channelId uint8, sequence uint64) (int64, error) {
 	var count int64
 	err := d.DB.Model(model.Vote{}).Where("channel_id = ? and sequence = ?", channelId, sequence).Count(&count).Error
@@ -37,6 +75,9 @@ func (d *VoteDao) GetVotesCountByChannelIdAndSequence(channelId uint8, sequence
 func (d *VoteDao) GetVoteByChannelIdAndSequenceAndPubKey(channelId uint8, sequence uint64, pubKey string) (*model.Vote, error) {
 	vote := model.Vote{}
 	err := d.DB.Model(model.Vote{}).Where("channel_id = ? and sequence = ? and pub_key = ?", channelId, sequence, pubKey).Take(&vote).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("%w, vote of channel %d, sequence %d and pub key %s", common.ErrRecordNotFound, channelId, sequence, pubKey)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -46,37 +87,173 @@ func (d *VoteDao) GetVoteByChannelIdAndSequenceAndPubKey(channelId uint8, sequen
 func (d *VoteDao) IsVoteExist(channelId uint8, sequence uint64, pubKey string) (bool, error) {
 	exists := false
 	if err := d.DB.Raw(
-		"SELECT EXISTS(SELECT id FROM vote WHERE channel_id = ? and sequence = ? and pub_key = ?)",
+		fmt.Sprintf("SELECT EXISTS(SELECT id FROM %s WHERE channel_id = ? and sequence = ? and pub_key = ?)", (&model.Vote{}).TableName()),
 		channelId, sequence, pubKey).Scan(&exists).Error; err != nil {
 		return false, err
 	}
 	return exists, nil
 }
 
-func IsVoteExist(dbTx *gorm.DB, channelId uint8, sequence uint64, pubKey string) (bool, error) {
-	exists := false
-	if err := dbTx.Raw(
-		"SELECT EXISTS(SELECT id FROM vote WHERE channel_id = ? and sequence = ? and pub_key = ?)",
-		channelId, sequence, pubKey).Scan(&exists).Error; err != nil {
-		return false, err
-	}
-	return exists, nil
+// voteConflict skips votes whose (channel, sequence, pub key) is saved already, so that votes saved again after a restart
+// or by concurrent polls of the vote pool do not fail the batch or duplicate rows counted towards quorum
+var voteConflict = clause.OnConflict{
+	Columns:   []clause.Column{{Name: "channel_id"}, {Name: "sequence"}, {Name: "pub_key"}},
+	DoNothing: true,
 }
 
 func (d *VoteDao) SaveVote(vote *model.Vote) error {
+	defer d.invalidateKeys(cacheKey{channelId: vote.ChannelId, sequence: vote.Sequence})
 	return d.DB.Transaction(func(dbTx *gorm.DB) error {
-		return dbTx.Create(vote).Error
+		return dbTx.Clauses(voteConflict).Create(vote).Error
 	})
 }
 
-func SaveVote(dbTx *gorm.DB, vote *model.Vote) error {
-	return dbTx.Transaction(func(dbTx *gorm.DB) error {
-		return dbTx.Create(vote).Error
+// SaveBatchVotes saves the votes which are not saved yet, duplicates within the batch are saved once
+func (d *VoteDao) SaveBatchVotes(votes []*model.Vote) error {
+	votes = uniqueVotes(votes)
+	if len(votes) == 0 {
+		return nil
+	}
+	defer d.invalidateVotes(votes)
+	return d.DB.Transaction(func(dbTx *gorm.DB) error {
+		return dbTx.Clauses(voteConflict).Create(votes).Error
 	})
 }
 
-func (d *VoteDao) SaveBatchVotes(votes []*model.Vote) error {
-	return d.DB.Transaction(func(dbTx *gorm.DB) error {
-		return dbTx.Create(votes).Error
+// uniqueVotes returns the first vote of every (channel, sequence, pub key) in order
+func uniqueVotes(votes []*model.Vote) []*model.Vote {
+	type voteKey struct {
+		channelId uint8
+		sequence  uint64
+		pubKey    string
+	}
+	seen := make(map[voteKey]bool, len(votes))
+	unique := make([]*model.Vote, 0, len(votes))
+	for _, v := range votes {
+		key := voteKey{channelId: v.ChannelId, sequence: v.Sequence, pubKey: v.PubKey}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, v)
+	}
+	return unique
+}
+
+func (d *VoteDao) DeleteVotesByIds(ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	defer d.invalidateIds(ids...)
+	return d.DB.Where("id IN (?)", ids).Delete(&model.Vote{}).Error
+}
+
+// GetOwnVote returns the cached signature of the event signed by the pub key, nil if the event has not been signed
+func (d *VoteDao) GetOwnVote(eventType uint32, eventHash string, pubKey string) (*model.OwnVote, error) {
+	ownVote := model.OwnVote{}
+	err := d.DB.Model(model.OwnVote{}).Where("event_type = ? and event_hash = ? and pub_key = ?", eventType, eventHash, pubKey).Take(&ownVote).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &ownVote, nil
+}
+
+// GetLatestOwnVote returns the latest vote signed by the pub key, nil if nothing has been signed
+func (d *VoteDao) GetLatestOwnVote(pubKey string) (*model.OwnVote, error) {
+	ownVote := model.OwnVote{}
+	err := d.DB.Model(model.OwnVote{}).Where("pub_key = ?", pubKey).Order("id desc").Take(&ownVote).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &ownVote, nil
+}
+
+func (d *VoteDao) SaveOwnVote(ownVote *model.OwnVote) error {
+	return d.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(ownVote).Error
+}
+
+// ValidatorVoteLatencyStat summarizes latencies in seconds of the votes of a validator
+type ValidatorVoteLatencyStat struct {
+	Direction string `json:"direction"`
+	PubKey    string `json:"pub_key"`
+	Votes     int    `json:"votes"`
+	P50       int64  `json:"p50"`
+	P90       int64  `json:"p90"`
+	P99       int64  `json:"p99"`
+	Max       int64  `json:"max"`
+}
+
+// SaveVoteLatencies saves the latencies, votes which have been recorded are skipped
+func (d *VoteDao) SaveVoteLatencies(latencies []*model.VoteLatency) error {
+	if len(latencies) == 0 {
+		return nil
+	}
+	return d.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(latencies).Error
+}
+
+// GetValidatorVoteLatencyStats computes latency percentiles of votes first seen after the given time by direction and
+// validator, ordered by p90 descending so that the slowest validators come first
+func (d *VoteDao) GetValidatorVoteLatencyStats(direction string, firstSeenAfter int64) ([]*ValidatorVoteLatencyStat, error) {
+	latencies := make([]*model.VoteLatency, 0)
+	query := d.DB.Model(&model.VoteLatency{}).Select("direction, pub_key, latency").Where("first_seen_time > ?", firstSeenAfter)
+	if direction != "" {
+		query = query.Where("direction = ?", direction)
+	}
+	if err := query.Order("direction, pub_key, latency").Find(&latencies).Error; err != nil {
+		return nil, err
+	}
+
+	stats := make([]*ValidatorVoteLatencyStat, 0)
+	for start := 0; start < len(latencies); {
+		end := start
+		sorted := make([]int64, 0)
+		for ; end < len(latencies) && latencies[end].Direction == latencies[start].Direction && latencies[end].PubKey == latencies[start].PubKey; end++ {
+			sorted = append(sorted, latencies[end].Latency)
+		}
+		stats = append(stats, &ValidatorVoteLatencyStat{
+			Direction: latencies[start].Direction,
+			PubKey:    latencies[start].PubKey,
+			Votes:     len(sorted),
+			P50:       percentile(sorted, 50),
+			P90:       percentile(sorted, 90),
+			P99:       percentile(sorted, 99),
+			Max:       sorted[len(sorted)-1],
+		})
+		start = end
+	}
+	sort.SliceStable(stats, func(i, j int) bool {
+		return stats[i].P90 > stats[j].P90
 	})
+	return stats, nil
+}
+
+// percentile returns the nearest-rank percentile of the ascending sorted values
+func percentile(sorted []int64, p int) int64 {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func (d *VoteDao) invalidateIds(ids ...int64) {
+	invalidate(d.inTx, func() { d.cache.invalidateIds(ids...) })
+}
+
+func (d *VoteDao) invalidateKeys(keys ...cacheKey) {
+	invalidate(d.inTx, func() { d.cache.invalidate(keys...) })
+}
+
+func (d *VoteDao) invalidateVotes(votes []*model.Vote) {
+	keys := make([]cacheKey, 0, len(votes))
+	for _, v := range votes {
+		keys = append(keys, cacheKey{channelId: v.ChannelId, sequence: v.Sequence})
+	}
+	d.invalidateKeys(keys...)
 }
@@ -0,0 +1,44 @@
+package dao
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-relayer/db/model"
+)
+
+func TestPercentile(t *testing.T) {
+	require.Equal(t, int64(7), percentile([]int64{7}, 50))
+	require.Equal(t, int64(7), percentile([]int64{7}, 99))
+
+	sorted := make([]int64, 0, 100)
+	for i := int64(1); i <= 100; i++ {
+		sorted = append(sorted, i)
+	}
+	require.Equal(t, int64(50), percentile(sorted, 50))
+	require.Equal(t, int64(90), percentile(sorted, 90))
+	require.Equal(t, int64(99), percentile(sorted, 99))
+
+	require.Equal(t, int64(2), percentile([]int64{1, 2, 3}, 50))
+	require.Equal(t, int64(3), percentile([]int64{1, 2, 3}, 90))
+}
+
+func TestSaveBatchVotesSkipsDuplicates(t *testing.T) {
+	d := NewVoteDao(newHotPathTestDB(t))
+	// pub key "a" of sequence 0 is saved already, "e" is duplicated within the batch
+	err := d.SaveBatchVotes([]*model.Vote{
+		{ChannelId: 1, Sequence: 0, PubKey: "a", Signature: "0x"},
+		{ChannelId: 1, Sequence: 0, PubKey: "e", Signature: "0x"},
+		{ChannelId: 1, Sequence: 0, PubKey: "e", Signature: "0x"},
+	})
+	require.NoError(t, err)
+	count, err := d.GetVotesCountByChannelIdAndSequence(1, 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), count)
+
+	require.NoError(t, d.SaveBatchVotes([]*model.Vote{{ChannelId: 1, Sequence: 0, PubKey: "e", Signature: "0x"}}))
+	count, err = d.GetVotesCountByChannelIdAndSequence(1, 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), count)
+}
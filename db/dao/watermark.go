@@ -0,0 +1,82 @@
+package dao
+
+import (
+	"database/sql"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/bnb-chain/greenfield-relayer/db"
+	"github.com/bnb-chain/greenfield-relayer/db/model"
+)
+
+// Watermarks are raised when packages change to SelfVoted, AllVoted or Delivered, the Saved status is not tracked.
+
+// getWatermark returns the highest sequence of the channel which has ever reached the status, -1 if none has
+func getWatermark(dbConn *gorm.DB, chain string, channelId uint8, status db.TxStatus) (int64, error) {
+	watermark := model.ChannelWatermark{}
+	err := dbConn.Where("chain = ? and channel_id = ? and status = ?", chain, channelId, status).Find(&watermark).Error
+	if err != nil {
+		return 0, err
+	}
+	if watermark.Id == 0 {
+		return -1, nil
+	}
+	return int64(watermark.Sequence), nil
+}
+
+// raiseWatermark raises the watermark of the status to the sequence, it is never lowered
+func raiseWatermark(dbTx *gorm.DB, chain string, channelId uint8, status db.TxStatus, sequence uint64) error {
+	now := time.Now().Unix()
+	return dbTx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "chain"}, {Name: "channel_id"}, {Name: "status"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"sequence":     gorm.Expr("CASE WHEN sequence < ? THEN ? ELSE sequence END", sequence, sequence),
+			"updated_time": now,
+		}),
+	}).Omit("id").Create(&model.ChannelWatermark{
+		Chain:       chain,
+		ChannelId:   channelId,
+		Status:      status,
+		Sequence:    sequence,
+		UpdatedTime: now,
+	}).Error
+}
+
+// raiseBSCWatermark raises the watermark of the status to the highest oracle sequence of the packages
+func raiseBSCWatermark(dbTx *gorm.DB, txIds []int64, status db.TxStatus) error {
+	var maxSeq sql.NullInt64
+	if err := dbTx.Model(&model.BscRelayPackage{}).Select("MAX(oracle_sequence)").Where("id IN (?)", txIds).
+		Row().Scan(&maxSeq); err != nil {
+		return err
+	}
+	if !maxSeq.Valid {
+		return nil
+	}
+	return raiseWatermark(dbTx, model.CheckpointChainBSC, 0, status, uint64(maxSeq.Int64))
+}
+
+// raiseGreenfieldWatermark raises the watermark of the status to the sequence of the transaction
+func raiseGreenfieldWatermark(dbTx *gorm.DB, id int64, status db.TxStatus) error {
+	return raiseGreenfieldWatermarks(dbTx, []int64{id}, status)
+}
+
+// raiseGreenfieldWatermarks raises the watermarks of the status to the highest sequences of the transactions by channel
+func raiseGreenfieldWatermarks(dbTx *gorm.DB, ids []int64, status db.TxStatus) error {
+	var watermarks []struct {
+		ChannelId uint8
+		Sequence  uint64
+	}
+	err := dbTx.Model(model.GreenfieldRelayTransaction{}).Select("channel_id, MAX(sequence) AS sequence").
+		Where("id IN (?)", ids).Group("channel_id").Scan(&watermarks).Error
+	if err != nil {
+		return err
+	}
+	for _, w := range watermarks {
+		if err = raiseWatermark(dbTx, model.CheckpointChainGreenfield, w.ChannelId, status, w.Sequence); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package db
+
+import (
+	"database/sql/driver"
+	"errors"
+	"syscall"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+const (
+	FailoverRetryAttempts = 5
+	FailoverRetryInterval = 2 * time.Second
+
+	failoverCallbackName = "relayer:failover"
+
+	mysqlErrServerShutdown      = 1053
+	mysqlErrOptionPreventsStmt  = 1290 // e.g. the server is running with --read-only
+	mysqlErrReadOnlyTransaction = 1792
+	mysqlErrReadOnlyMode        = 1836
+)
+
+// IsFailoverError reports whether err is caused by a MySQL failover, i.e. the connection is reset by the old primary,
+// or the old primary is demoted to read-only while connections to it are still pooled
+func IsFailoverError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, mysql.ErrInvalidConn) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) {
+		return false
+	}
+	switch mysqlErr.Number {
+	case mysqlErrServerShutdown, mysqlErrOptionPreventsStmt, mysqlErrReadOnlyTransaction, mysqlErrReadOnlyMode:
+		return true
+	}
+	return false
+}
+
+// RegisterFailoverRecovery closes pooled connections once a statement executed via db fails by a failover, so that
+// later statements re-establish sessions to the new primary instead of reusing connections to the old one.
+func RegisterFailoverRecovery(db *gorm.DB, maxIdleConns int) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	after := func(tx *gorm.DB) {
+		if !IsFailoverError(tx.Error) {
+			return
+		}
+		// closes idle connections, the ones in use are closed when they fail
+		sqlDB.SetMaxIdleConns(0)
+		sqlDB.SetMaxIdleConns(maxIdleConns)
+	}
+
+	cb := db.Callback()
+	if err = cb.Create().After("*").Register(failoverCallbackName, after); err != nil {
+		return err
+	}
+	if err = cb.Query().After("*").Register(failoverCallbackName, after); err != nil {
+		return err
+	}
+	if err = cb.Update().After("*").Register(failoverCallbackName, after); err != nil {
+		return err
+	}
+	if err = cb.Delete().After("*").Register(failoverCallbackName, after); err != nil {
+		return err
+	}
+	if err = cb.Row().After("*").Register(failoverCallbackName, after); err != nil {
+		return err
+	}
+	return cb.Raw().After("*").Register(failoverCallbackName, after)
+}
@@ -0,0 +1,22 @@
+package db
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsFailoverError(t *testing.T) {
+	require.False(t, IsFailoverError(nil))
+	require.False(t, IsFailoverError(errors.New("record not found")))
+	require.False(t, IsFailoverError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry"}))
+
+	require.True(t, IsFailoverError(fmt.Errorf("query: %w", driver.ErrBadConn)))
+	require.True(t, IsFailoverError(mysql.ErrInvalidConn))
+	require.True(t, IsFailoverError(&mysql.MySQLError{Number: 1290, Message: "running with the --read-only option"}))
+	require.True(t, IsFailoverError(fmt.Errorf("update: %w", &mysql.MySQLError{Number: 1836, Message: "read-only mode"})))
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"gorm.io/gorm"
+)
+
+type AdminAuditLog struct {
+	Id          int64
+	Identity    string `gorm:"NOT NULL;index:idx_admin_audit_log_identity"`
+	Method      string `gorm:"NOT NULL"`
+	Path        string `gorm:"NOT NULL"`
+	Params      string `gorm:"type:text"`
+	StatusCode  int    `gorm:"NOT NULL"`
+	RemoteAddr  string
+	CreatedTime int64 `gorm:"NOT NULL;index:idx_admin_audit_log_created_time"`
+}
+
+func (*AdminAuditLog) TableName() string {
+	return prefixed("admin_audit_log")
+}
+
+func InitAdminTables(db *gorm.DB) {
+	if !db.Migrator().HasTable(&AdminAuditLog{}) {
+		err := db.Migrator().CreateTable(&AdminAuditLog{})
+		if err != nil {
+			panic(err)
+		}
+	}
+}
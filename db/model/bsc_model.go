@@ -10,19 +10,19 @@ type BscBlock struct {
 	Id         int64
 	BlockHash  string `gorm:"NOT NULL"`
 	ParentHash string `gorm:"NOT NULL"`
-	Height     uint64 `gorm:"NOT NULL;index:idx_bsc_block_height"`
+	Height     uint64 `gorm:"NOT NULL;uniqueIndex:idx_bsc_block_unique_height"`
 	BlockTime  int64  `gorm:"NOT NULL"`
 }
 
 func (*BscBlock) TableName() string {
-	return "bsc_block"
+	return prefixed("bsc_block")
 }
 
 type BscRelayPackage struct {
 	Id              int64
-	ChannelId       uint8  `gorm:"NOT NULL"`
-	OracleSequence  uint64 `gorm:"NOT NULL;index:idx_bsc_relay_package_oracle_sequence"`
-	PackageSequence uint64 `gorm:"NOT NULL"`
+	ChannelId       uint8  `gorm:"NOT NULL;uniqueIndex:idx_bsc_relay_package_channel_seq"`
+	OracleSequence  uint64 `gorm:"NOT NULL;index:idx_bsc_relay_package_oracle_sequence;uniqueIndex:idx_bsc_relay_package_channel_seq"`
+	PackageSequence uint64 `gorm:"NOT NULL;uniqueIndex:idx_bsc_relay_package_channel_seq"`
 	PayLoad         string `gorm:"type:text"`
 	TxIndex         uint   `gorm:"NOT NULL"`
 	TxHash          string `gorm:"NOT NULL"`
@@ -31,10 +31,11 @@ type BscRelayPackage struct {
 	Status          db.TxStatus `gorm:"NOT NULL;index:idx_bsc_relay_package_height_status"`
 	TxTime          int64       `gorm:"NOT NULL"`
 	UpdatedTime     int64       `gorm:"NOT NULL"`
+	ClaimFailures   uint32      `gorm:"NOT NULL;default:0"` // number of failed claim attempts
 }
 
 func (l *BscRelayPackage) TableName() string {
-	return "bsc_relay_package"
+	return prefixed("bsc_relay_package")
 }
 
 func InitBSCTables(db *gorm.DB) {
@@ -51,4 +52,8 @@ func InitBSCTables(db *gorm.DB) {
 			panic(err)
 		}
 	}
+	addMissingColumns(db, &BscRelayPackage{}, "ClaimFailures")
+	addMissingUniqueIndex(db, &BscBlock{}, "idx_bsc_block_unique_height", "idx_bsc_block_height", "height")
+	addMissingUniqueIndex(db, &BscRelayPackage{}, "idx_bsc_relay_package_channel_seq", "",
+		"channel_id, oracle_sequence, package_sequence")
 }
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"gorm.io/gorm"
+)
+
+const (
+	CheckpointChainGreenfield = "greenfield"
+	CheckpointChainBSC        = "bsc"
+)
+
+// ListenerCheckpoint records the last block processed by the listener of a chain, listeners resume from it so that
+// block rows can be pruned
+type ListenerCheckpoint struct {
+	Id          int64
+	Chain       string `gorm:"NOT NULL;uniqueIndex:idx_listener_checkpoint_chain;size:32"`
+	Height      uint64 `gorm:"NOT NULL"`
+	BlockHash   string `gorm:"NOT NULL"`
+	ParentHash  string `gorm:"NOT NULL"`
+	BlockTime   int64  `gorm:"NOT NULL"`
+	UpdatedTime int64  `gorm:"NOT NULL"`
+}
+
+func (*ListenerCheckpoint) TableName() string {
+	return prefixed("listener_checkpoint")
+}
+
+func InitCheckpointTables(db *gorm.DB) {
+	if !db.Migrator().HasTable(&ListenerCheckpoint{}) {
+		err := db.Migrator().CreateTable(&ListenerCheckpoint{})
+		if err != nil {
+			panic(err)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"gorm.io/gorm"
+)
+
+// ClaimDiagnostic is the bundle captured when a claim tx fails, kept for post-mortem analysis
+type ClaimDiagnostic struct {
+	Id               int64
+	Direction        string `gorm:"NOT NULL;index:idx_claim_diagnostic_direction_channel_seq;size:32"`
+	ChannelId        uint8  `gorm:"NOT NULL;index:idx_claim_diagnostic_direction_channel_seq"`
+	Sequence         uint64 `gorm:"NOT NULL;index:idx_claim_diagnostic_direction_channel_seq"` // oracle sequence for bsc to greenfield
+	Nonce            uint64 `gorm:"NOT NULL"`
+	Payload          string `gorm:"type:text"` // hex encoded claim payload
+	Votes            string `gorm:"type:text"` // JSON encoded votes aggregated into the claim
+	Validators       string `gorm:"type:text"` // JSON encoded validator set the votes are aggregated against
+	GreenfieldHeight uint64 `gorm:"NOT NULL"`  // latest height of the Greenfield node when the claim failed, 0 if unknown
+	BSCHeight        uint64 `gorm:"NOT NULL"`  // latest height of the BSC node when the claim failed, 0 if unknown
+	TxResponse       string `gorm:"type:text"` // raw response of the tx if it is rejected by the chain
+	Error            string `gorm:"type:text"`
+	FailureClass     string `gorm:"NOT NULL;default:'';size:32;index:idx_claim_diagnostic_failure_class"` // root cause classified from the error and tx response
+	CreatedTime      int64  `gorm:"NOT NULL;index:idx_claim_diagnostic_created_time"`
+}
+
+func (*ClaimDiagnostic) TableName() string {
+	return prefixed("claim_diagnostic")
+}
+
+func InitDiagnosticTables(db *gorm.DB) {
+	if !db.Migrator().HasTable(&ClaimDiagnostic{}) {
+		err := db.Migrator().CreateTable(&ClaimDiagnostic{})
+		if err != nil {
+			panic(err)
+		}
+	}
+	addMissingColumns(db, &ClaimDiagnostic{}, "FailureClass")
+	addMissingIndex(db, &ClaimDiagnostic{}, "idx_claim_diagnostic_failure_class")
+}
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"gorm.io/gorm"
+)
+
+// ExportCursor records the position up to which records of a table have been exported
+type ExportCursor struct {
+	Id          int64
+	Name        string `gorm:"NOT NULL;uniqueIndex:idx_export_cursor_name;size:64"`
+	UpdatedTime int64  `gorm:"NOT NULL"` // updated_time of the last exported record
+	RecordId    int64  `gorm:"NOT NULL"` // id of the last exported record
+}
+
+func (*ExportCursor) TableName() string {
+	return prefixed("export_cursor")
+}
+
+func InitExportTables(db *gorm.DB) {
+	if !db.Migrator().HasTable(&ExportCursor{}) {
+		err := db.Migrator().CreateTable(&ExportCursor{})
+		if err != nil {
+			panic(err)
+		}
+	}
+}
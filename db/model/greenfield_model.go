@@ -9,20 +9,21 @@ import (
 type GreenfieldBlock struct {
 	Id        int64
 	Chain     string
-	Height    uint64 `gorm:"NOT NULL;index:idx_greenfield_block_height"`
+	Height    uint64 `gorm:"NOT NULL;uniqueIndex:idx_greenfield_block_unique_height"`
 	BlockTime int64  `gorm:"NOT NULL"`
+	BlockHash string `gorm:"-"` // only recorded in the listener checkpoint
 }
 
 func (*GreenfieldBlock) TableName() string {
-	return "greenfield_block"
+	return prefixed("greenfield_block")
 }
 
 type GreenfieldRelayTransaction struct {
 	Id            int64
 	SrcChainId    uint32 `gorm:"NOT NULL"`
 	DestChainId   uint32 `gorm:"NOT NULL"`
-	ChannelId     uint8  `gorm:"NOT NULL;index:idx_greenfield_relay_transaction_channel_seq_status"`
-	Sequence      uint64 `gorm:"NOT NULL;index:idx_greenfield_relay_transaction_channel_seq_status"`
+	ChannelId     uint8  `gorm:"NOT NULL;index:idx_greenfield_relay_transaction_channel_seq_status;uniqueIndex:idx_greenfield_relay_transaction_unique_channel_seq"`
+	Sequence      uint64 `gorm:"NOT NULL;index:idx_greenfield_relay_transaction_channel_seq_status;uniqueIndex:idx_greenfield_relay_transaction_unique_channel_seq"`
 	PackageType   uint32 `gorm:"NOT NULL"`
 	Height        uint64 `gorm:"NOT NULL;index:idx_greenfield_relay_transaction_height_status"`
 	PayLoad       string `gorm:"type:text"`
@@ -32,10 +33,36 @@ type GreenfieldRelayTransaction struct {
 	Status        db.TxStatus `gorm:"NOT NULL;index:idx_greenfield_relay_transaction_channel_seq_status;idx_greenfield_relay_transaction_height_status"`
 	TxTime        int64       `gorm:"NOT NULL"`
 	UpdatedTime   int64       `gorm:"NOT NULL"`
+	ResourceType  string      `gorm:"NOT NULL;default:'';size:16;index:idx_greenfield_relay_transaction_resource"` // bucket, object or group, empty if not decoded
+	ResourceId    string      `gorm:"NOT NULL;default:'';size:80;index:idx_greenfield_relay_transaction_resource"` // decimal id of the resource
+	// receipt of the claim tx on BSC, decoded events are saved as ClaimReceiptEvent
+	ClaimReceiptStatus db.ReceiptStatus `gorm:"NOT NULL;default:0;index:idx_greenfield_relay_transaction_receipt_status"`
+	ClaimBlockHeight   uint64           `gorm:"NOT NULL;default:0"`
+	ClaimGasUsed       uint64           `gorm:"NOT NULL;default:0"`
+	ClaimGasPrice      string           `gorm:"NOT NULL;default:''"` // price per gas in wei paid by the claim tx
+	ClaimFailures      uint32           `gorm:"NOT NULL;default:0"`  // number of failed claim attempts
 }
 
 func (*GreenfieldRelayTransaction) TableName() string {
-	return "greenfield_relay_transaction"
+	return prefixed("greenfield_relay_transaction")
+}
+
+// ClaimReceiptEvent is an event in the receipt of a claim tx of a Greenfield package sent to BSC, e.g. the package
+// received, the ack package emitted back to Greenfield, or events of other contracts like relayer rewards
+type ClaimReceiptEvent struct {
+	Id                 int64
+	RelayTransactionId int64  `gorm:"NOT NULL;index:idx_claim_receipt_event_relay_transaction_id"`
+	ClaimTxHash        string `gorm:"NOT NULL"`
+	LogIndex           uint   `gorm:"NOT NULL"`
+	Contract           string `gorm:"NOT NULL"`
+	Name               string `gorm:"NOT NULL;size:64"` // event name of the CrossChain contract, unknown for other contracts
+	ChannelId          uint8  `gorm:"NOT NULL"`
+	Sequence           uint64 `gorm:"NOT NULL"`
+	Data               string `gorm:"type:text"` // JSON encoded event arguments, or raw topics and data for unknown events
+}
+
+func (*ClaimReceiptEvent) TableName() string {
+	return prefixed("claim_receipt_event")
 }
 
 type SyncLightBlockTransaction struct {
@@ -46,7 +73,7 @@ type SyncLightBlockTransaction struct {
 }
 
 func (*SyncLightBlockTransaction) TableName() string {
-	return "sync_light_block_transaction"
+	return prefixed("sync_light_block_transaction")
 }
 
 func InitGreenfieldTables(db *gorm.DB) {
@@ -63,6 +90,19 @@ func InitGreenfieldTables(db *gorm.DB) {
 			panic(err)
 		}
 	}
+	addMissingColumns(db, &GreenfieldRelayTransaction{}, "ResourceType", "ResourceId", "ClaimReceiptStatus", "ClaimBlockHeight", "ClaimGasUsed", "ClaimGasPrice", "ClaimFailures")
+	addMissingIndex(db, &GreenfieldRelayTransaction{}, "idx_greenfield_relay_transaction_resource")
+	addMissingIndex(db, &GreenfieldRelayTransaction{}, "idx_greenfield_relay_transaction_receipt_status")
+	addMissingUniqueIndex(db, &GreenfieldBlock{}, "idx_greenfield_block_unique_height", "idx_greenfield_block_height", "height")
+	addMissingUniqueIndex(db, &GreenfieldRelayTransaction{}, "idx_greenfield_relay_transaction_unique_channel_seq", "",
+		"channel_id, sequence")
+
+	if !db.Migrator().HasTable(&ClaimReceiptEvent{}) {
+		err := db.Migrator().CreateTable(&ClaimReceiptEvent{})
+		if err != nil {
+			panic(err)
+		}
+	}
 
 	if !db.Migrator().HasTable(&SyncLightBlockTransaction{}) {
 		err := db.Migrator().CreateTable(&SyncLightBlockTransaction{})
@@ -0,0 +1,30 @@
+package model
+
+import (
+	"gorm.io/gorm"
+)
+
+// SequenceLock is an advisory lock of a sequence held by an assembler worker while it claims the sequence, so that
+// concurrent workers, e.g. of a relayer started twice by accident, do not claim the same sequence. Locks expire, so
+// that a sequence locked by a crashed worker is claimed by others.
+type SequenceLock struct {
+	Id         int64
+	Direction  string `gorm:"NOT NULL;uniqueIndex:idx_sequence_lock_direction_channel_seq;size:32"`
+	ChannelId  uint8  `gorm:"NOT NULL;uniqueIndex:idx_sequence_lock_direction_channel_seq"`
+	Sequence   uint64 `gorm:"NOT NULL;uniqueIndex:idx_sequence_lock_direction_channel_seq"` // oracle sequence for bsc to greenfield
+	Owner      string `gorm:"NOT NULL;size:128"`
+	ExpireTime int64  `gorm:"NOT NULL;index:idx_sequence_lock_expire_time"`
+}
+
+func (*SequenceLock) TableName() string {
+	return prefixed("sequence_lock")
+}
+
+func InitLockTables(db *gorm.DB) {
+	if !db.Migrator().HasTable(&SequenceLock{}) {
+		err := db.Migrator().CreateTable(&SequenceLock{})
+		if err != nil {
+			panic(err)
+		}
+	}
+}
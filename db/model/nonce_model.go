@@ -0,0 +1,30 @@
+package model
+
+import (
+	"gorm.io/gorm"
+)
+
+// NonceReservation records the nonce of the relayer's account a claim is sent with, it is saved before the claim is
+// broadcast and the tx hash is filled after, so that nonces used by claims are known after a crash
+type NonceReservation struct {
+	Id           int64
+	Direction    string `gorm:"NOT NULL;uniqueIndex:idx_nonce_reservation_direction_nonce;size:32"`
+	Nonce        uint64 `gorm:"NOT NULL;uniqueIndex:idx_nonce_reservation_direction_nonce"`
+	ChannelId    uint8  `gorm:"NOT NULL"`
+	Sequence     uint64 `gorm:"NOT NULL"`            // oracle sequence for bsc to greenfield
+	TxHash       string `gorm:"NOT NULL;default:''"` // empty until the claim is broadcast
+	ReservedTime int64  `gorm:"NOT NULL;index:idx_nonce_reservation_reserved_time"`
+}
+
+func (*NonceReservation) TableName() string {
+	return prefixed("nonce_reservation")
+}
+
+func InitNonceTables(db *gorm.DB) {
+	if !db.Migrator().HasTable(&NonceReservation{}) {
+		err := db.Migrator().CreateTable(&NonceReservation{})
+		if err != nil {
+			panic(err)
+		}
+	}
+}
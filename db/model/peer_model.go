@@ -0,0 +1,31 @@
+package model
+
+import (
+	"gorm.io/gorm"
+)
+
+// PeerDelivery records which relayer delivered a package to the destination chain, observed from claim txs
+type PeerDelivery struct {
+	Id            int64
+	Direction     string `gorm:"NOT NULL;uniqueIndex:idx_peer_delivery_direction_channel_seq;size:32"`
+	ChannelId     uint8  `gorm:"NOT NULL;uniqueIndex:idx_peer_delivery_direction_channel_seq"`
+	Sequence      uint64 `gorm:"NOT NULL;uniqueIndex:idx_peer_delivery_direction_channel_seq"`
+	Relayer       string `gorm:"NOT NULL;index:idx_peer_delivery_relayer;size:64"` // address of the claim tx sender
+	ClaimTxHash   string `gorm:"NOT NULL"`
+	Height        uint64 `gorm:"NOT NULL"` // height of the claim tx on the destination chain
+	Latency       int64  `gorm:"NOT NULL"` // seconds from the package sent to delivered, -1 if unknown
+	DeliveredTime int64  `gorm:"NOT NULL;index:idx_peer_delivery_delivered_time"`
+}
+
+func (*PeerDelivery) TableName() string {
+	return prefixed("peer_delivery")
+}
+
+func InitPeerTables(db *gorm.DB) {
+	if !db.Migrator().HasTable(&PeerDelivery{}) {
+		err := db.Migrator().CreateTable(&PeerDelivery{})
+		if err != nil {
+			panic(err)
+		}
+	}
+}
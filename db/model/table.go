@@ -0,0 +1,69 @@
+package model
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// tablePrefix is prepended to all table names, so that multiple relayers can share one database
+var tablePrefix string
+
+// SetTablePrefix sets the table name prefix, it should be called before any table is accessed
+func SetTablePrefix(prefix string) {
+	tablePrefix = prefix
+}
+
+func prefixed(name string) string {
+	return tablePrefix + name
+}
+
+// addMissingColumns adds the columns of the fields to the table created by an older version
+func addMissingColumns(db *gorm.DB, value interface{}, fields ...string) {
+	for _, field := range fields {
+		if db.Migrator().HasColumn(value, field) {
+			continue
+		}
+		if err := db.Migrator().AddColumn(value, field); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func addMissingIndex(db *gorm.DB, value interface{}, name string) {
+	if db.Migrator().HasIndex(value, name) {
+		return
+	}
+	if err := db.Migrator().CreateIndex(value, name); err != nil {
+		panic(err)
+	}
+}
+
+// addMissingUniqueIndex creates the unique index on the table created by an older version, rows duplicated on the
+// columns of the index are deleted first, keeping the earliest saved one. The non-unique index it replaces is dropped.
+func addMissingUniqueIndex(db *gorm.DB, value interface{}, name, replaced string, columns string) {
+	if db.Migrator().HasIndex(value, name) {
+		return
+	}
+	err := db.Transaction(func(dbTx *gorm.DB) error {
+		stmt := &gorm.Statement{DB: dbTx}
+		if err := stmt.Parse(value); err != nil {
+			return err
+		}
+		table := stmt.Schema.Table
+		// the derived table is required by MySQL to select from the table being deleted
+		if err := dbTx.Exec(fmt.Sprintf("DELETE FROM %s WHERE id NOT IN (SELECT id FROM (SELECT MIN(id) AS id FROM %s GROUP BY %s) AS kept)",
+			table, table, columns)).Error; err != nil {
+			return err
+		}
+		return dbTx.Migrator().CreateIndex(value, name)
+	})
+	if err != nil {
+		panic(err)
+	}
+	if replaced != "" && db.Migrator().HasIndex(value, replaced) {
+		if err = db.Migrator().DropIndex(value, replaced); err != nil {
+			panic(err)
+		}
+	}
+}
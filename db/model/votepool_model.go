@@ -17,7 +17,38 @@ type Vote struct {
 }
 
 func (*Vote) TableName() string {
-	return "vote"
+	return prefixed("vote")
+}
+
+// OwnVote caches the signature of an event signed by this relayer, so that events are not re-signed and re-broadcast
+// after a restart
+type OwnVote struct {
+	Id          int64
+	EventType   uint32 `gorm:"NOT NULL;uniqueIndex:idx_own_vote_event_type_hash_pub_key"`
+	EventHash   string `gorm:"NOT NULL;uniqueIndex:idx_own_vote_event_type_hash_pub_key;size:64"`
+	PubKey      string `gorm:"NOT NULL;uniqueIndex:idx_own_vote_event_type_hash_pub_key;size:96"`
+	Signature   string `gorm:"NOT NULL"`
+	CreatedTime int64  `gorm:"NOT NULL"`
+}
+
+func (*OwnVote) TableName() string {
+	return prefixed("own_vote")
+}
+
+// VoteLatency records when the vote of a validator for a package is first seen in the vote pool, the latency is in
+// seconds from the package sent on the source chain
+type VoteLatency struct {
+	Id            int64
+	Direction     string `gorm:"NOT NULL;uniqueIndex:idx_vote_latency_direction_channel_seq_pub_key;size:32"`
+	ChannelId     uint8  `gorm:"NOT NULL;uniqueIndex:idx_vote_latency_direction_channel_seq_pub_key"`
+	Sequence      uint64 `gorm:"NOT NULL;uniqueIndex:idx_vote_latency_direction_channel_seq_pub_key"` // oracle sequence for bsc to greenfield
+	PubKey        string `gorm:"NOT NULL;uniqueIndex:idx_vote_latency_direction_channel_seq_pub_key;size:96"`
+	Latency       int64  `gorm:"NOT NULL"`
+	FirstSeenTime int64  `gorm:"NOT NULL;index:idx_vote_latency_first_seen_time"`
+}
+
+func (*VoteLatency) TableName() string {
+	return prefixed("vote_latency")
 }
 
 func InitVoteTables(db *gorm.DB) {
@@ -27,4 +58,17 @@ func InitVoteTables(db *gorm.DB) {
 			panic(err)
 		}
 	}
+	addMissingUniqueIndex(db, &Vote{}, "idx_vote_channel_id_sequence_pub_key", "", "channel_id, sequence, pub_key")
+	if !db.Migrator().HasTable(&OwnVote{}) {
+		err := db.Migrator().CreateTable(&OwnVote{})
+		if err != nil {
+			panic(err)
+		}
+	}
+	if !db.Migrator().HasTable(&VoteLatency{}) {
+		err := db.Migrator().CreateTable(&VoteLatency{})
+		if err != nil {
+			panic(err)
+		}
+	}
 }
@@ -0,0 +1,50 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/bnb-chain/greenfield-relayer/db"
+)
+
+// ChannelWatermark records the highest sequence of a channel which has ever reached a status, it is raised in the same
+// DB transaction as the status change so that it is consistent under concurrent writers, and read instead of scanning
+// the package tables for MAX(sequence). Packages from BSC are tracked by oracle sequence of the oracle channel.
+type ChannelWatermark struct {
+	Id          int64
+	Chain       string      `gorm:"NOT NULL;uniqueIndex:idx_channel_watermark_chain_channel_status;size:32"` // source chain of the packages
+	ChannelId   uint8       `gorm:"NOT NULL;uniqueIndex:idx_channel_watermark_chain_channel_status"`
+	Status      db.TxStatus `gorm:"NOT NULL;uniqueIndex:idx_channel_watermark_chain_channel_status"`
+	Sequence    uint64      `gorm:"NOT NULL"`
+	UpdatedTime int64       `gorm:"NOT NULL"`
+}
+
+func (*ChannelWatermark) TableName() string {
+	return prefixed("channel_watermark")
+}
+
+// InitWatermarkTables creates the watermark table, and seeds it from existing packages when created, so it should be
+// called after package tables are initialised
+func InitWatermarkTables(gormDB *gorm.DB) {
+	if gormDB.Migrator().HasTable(&ChannelWatermark{}) {
+		return
+	}
+	err := gormDB.Transaction(func(dbTx *gorm.DB) error {
+		if err := dbTx.Migrator().CreateTable(&ChannelWatermark{}); err != nil {
+			return err
+		}
+		now := time.Now().Unix()
+		watermarkColumns := "(chain, channel_id, status, sequence, updated_time)"
+		if err := dbTx.Exec(fmt.Sprintf("INSERT INTO %s %s SELECT ?, 0, status, MAX(oracle_sequence), ? FROM %s GROUP BY status",
+			(&ChannelWatermark{}).TableName(), watermarkColumns, (&BscRelayPackage{}).TableName()), CheckpointChainBSC, now).Error; err != nil {
+			return err
+		}
+		return dbTx.Exec(fmt.Sprintf("INSERT INTO %s %s SELECT ?, channel_id, status, MAX(sequence), ? FROM %s GROUP BY channel_id, status",
+			(&ChannelWatermark{}).TableName(), watermarkColumns, (&GreenfieldRelayTransaction{}).TableName()), CheckpointChainGreenfield, now).Error
+	})
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,120 @@
+package db
+
+import (
+	"fmt"
+	"sync"
+)
+
+// TxStatus is the status of a package in the relay pipeline, a package moves along the transitions below:
+//
+//	Saved -> SelfVoted -> AllVoted -> Claimed -> Delivered
+//
+// AllVoted and Claimed packages are moved back to SelfVoted if votes turn stale, to NeedsAttention if claims keep
+// failing, and packages not delivered yet can be skipped by an operator or found delivered by others. SelfVoted
+// packages are moved back to Saved if the own vote is missing, so that it is signed again.
+type TxStatus int
+
+const (
+	Saved     TxStatus = 0
+	SelfVoted TxStatus = 1 // Tx is only voted by local relayer
+	AllVoted  TxStatus = 2 // TX is already voted by enough validators, more than (2/3) * (# of validators) valid votes collected.
+	Delivered TxStatus = 3 // Tx is delivered to the dest chain
+	Skipped   TxStatus = 4 // Tx is skipped by an operator as it can never be claimed, it is neither voted nor claimed
+	// NeedsAttention Tx failed to be claimed claim_max_attempts times, it is not claimed until retried by an operator
+	NeedsAttention TxStatus = 5
+	Claimed        TxStatus = 6 // a claim of Tx is sent by an out-turn relayer, it is delivered once seen on the dest chain
+)
+
+var statusNames = map[TxStatus]string{
+	Saved:          "saved",
+	SelfVoted:      "self_voted",
+	AllVoted:       "all_voted",
+	Delivered:      "delivered",
+	Skipped:        "skipped",
+	NeedsAttention: "needs_attention",
+	Claimed:        "claimed",
+}
+
+func (s TxStatus) String() string {
+	if name, ok := statusNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown_%d", int(s))
+}
+
+// transitions are the statuses each status can move to, claims are resent to Claimed and Delivered packages if the
+// sequence is not delivered on chain yet
+var transitions = map[TxStatus][]TxStatus{
+	Saved:          {SelfVoted, Delivered, Skipped},
+	SelfVoted:      {Saved, AllVoted, Delivered, Skipped},
+	AllVoted:       {SelfVoted, Claimed, Delivered, Skipped, NeedsAttention},
+	Claimed:        {SelfVoted, Claimed, Delivered, Skipped, NeedsAttention},
+	NeedsAttention: {AllVoted, Delivered, Skipped},
+	Skipped:        {Skipped, Delivered},
+	Delivered:      {Delivered},
+}
+
+// CanTransitionTo tells whether a package of the status can move to the target status
+func (s TxStatus) CanTransitionTo(to TxStatus) bool {
+	for _, t := range transitions[s] {
+		if t == to {
+			return true
+		}
+	}
+	return false
+}
+
+// SourcesOf returns the statuses which can move to the target status, DAOs only update packages in these statuses so
+// that invalid transitions are never persisted
+func SourcesOf(to TxStatus) []TxStatus {
+	sources := make([]TxStatus, 0)
+	for from := range statusNames {
+		if from.CanTransitionTo(to) {
+			sources = append(sources, from)
+		}
+	}
+	return sources
+}
+
+// AwaitingDelivery are the statuses of packages with enough votes which are not known to be delivered
+var AwaitingDelivery = []TxStatus{AllVoted, Claimed, NeedsAttention}
+
+// HasEnoughVotes tells whether packages of the status got enough votes to be claimed
+func (s TxStatus) HasEnoughVotes() bool {
+	return s == AllVoted || s == Claimed || s == Delivered
+}
+
+// Transition is a status change of packages persisted by a DAO
+type Transition struct {
+	Chain string // source chain of the packages
+	To    TxStatus
+	Count int64 // number of packages moved
+}
+
+// TransitionHook is invoked after transitions are committed, it should not block
+type TransitionHook func(t *Transition)
+
+var (
+	hooksMutex      sync.RWMutex
+	transitionHooks []TransitionHook
+)
+
+// RegisterTransitionHook adds a hook invoked on every persisted transition, e.g. to count transitions in metrics
+func RegisterTransitionHook(hook TransitionHook) {
+	hooksMutex.Lock()
+	defer hooksMutex.Unlock()
+	transitionHooks = append(transitionHooks, hook)
+}
+
+// NotifyTransition invokes the transition hooks if any package is moved
+func NotifyTransition(chain string, to TxStatus, count int64) {
+	if count == 0 {
+		return
+	}
+	hooksMutex.RLock()
+	defer hooksMutex.RUnlock()
+	t := &Transition{Chain: chain, To: to, Count: count}
+	for _, hook := range transitionHooks {
+		hook(t)
+	}
+}
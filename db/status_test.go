@@ -0,0 +1,19 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusTransitions(t *testing.T) {
+	require.True(t, Saved.CanTransitionTo(SelfVoted))
+	require.True(t, AllVoted.CanTransitionTo(Claimed))
+	require.True(t, Delivered.CanTransitionTo(Delivered))
+	require.False(t, Delivered.CanTransitionTo(Skipped))
+	require.False(t, Saved.CanTransitionTo(AllVoted))
+	require.ElementsMatch(t, []TxStatus{AllVoted, Claimed}, SourcesOf(NeedsAttention))
+	require.ElementsMatch(t, []TxStatus{Saved, AllVoted, Claimed}, SourcesOf(SelfVoted))
+	require.ElementsMatch(t, []TxStatus{SelfVoted}, SourcesOf(Saved))
+	require.Equal(t, "needs_attention", NeedsAttention.String())
+}
@@ -0,0 +1,77 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultQueryTimeout = 10 * time.Second
+
+	timeoutCallbackName = "relayer:timeout"
+	timeoutInstanceKey  = "relayer:timeout_ctx"
+)
+
+type timeoutCtx struct {
+	parent context.Context
+	cancel context.CancelFunc
+}
+
+// RegisterQueryTimeout bounds every create, query, update, delete and raw statement executed via db with the given
+// timeout, so that a wedged DB can not block the caller forever. Row queries are not bounded since rows are scanned
+// after the statement returns.
+func RegisterQueryTimeout(db *gorm.DB, timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = DefaultQueryTimeout
+	}
+	before := func(tx *gorm.DB) {
+		parent := tx.Statement.Context
+		if parent == nil {
+			parent = context.Background()
+		}
+		ctx, cancel := context.WithTimeout(parent, timeout)
+		tx.Statement.Context = ctx
+		tx.InstanceSet(timeoutInstanceKey, &timeoutCtx{parent: parent, cancel: cancel})
+	}
+	after := func(tx *gorm.DB) {
+		v, ok := tx.InstanceGet(timeoutInstanceKey)
+		if !ok {
+			return
+		}
+		t := v.(*timeoutCtx)
+		t.cancel()
+		tx.Statement.Context = t.parent
+	}
+
+	cb := db.Callback()
+	if err := cb.Create().Before("*").Register(timeoutCallbackName+":before", before); err != nil {
+		return err
+	}
+	if err := cb.Create().After("*").Register(timeoutCallbackName+":after", after); err != nil {
+		return err
+	}
+	if err := cb.Query().Before("*").Register(timeoutCallbackName+":before", before); err != nil {
+		return err
+	}
+	if err := cb.Query().After("*").Register(timeoutCallbackName+":after", after); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("*").Register(timeoutCallbackName+":before", before); err != nil {
+		return err
+	}
+	if err := cb.Update().After("*").Register(timeoutCallbackName+":after", after); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("*").Register(timeoutCallbackName+":before", before); err != nil {
+		return err
+	}
+	if err := cb.Delete().After("*").Register(timeoutCallbackName+":after", after); err != nil {
+		return err
+	}
+	if err := cb.Raw().Before("*").Register(timeoutCallbackName+":before", before); err != nil {
+		return err
+	}
+	return cb.Raw().After("*").Register(timeoutCallbackName+":after", after)
+}
@@ -0,0 +1,97 @@
+package events
+
+import (
+	"sync"
+)
+
+const (
+	EventTypePackage = "package" // a cross-chain package is saved from the source chain
+	EventTypeClaim   = "claim"   // a claim tx of packages is sent to the destination chain
+
+	EventTypeClaimAttempt = "claim_attempt" // a claim tx of packages is about to be broadcast
+	EventTypeClaimFailure = "claim_failure" // broadcasting a claim tx of packages failed
+
+	EventTypeInturnEnter   = "inturn_enter"   // the relayer enters an in-turn interval
+	EventTypeInturnLeave   = "inturn_leave"   // the relayer leaves the in-turn interval
+	EventTypeSequenceReset = "sequence_reset" // the next delivery sequence cached by the in-turn relayer is reset
+)
+
+// Event is a relay lifecycle event
+type Event struct {
+	Type           string `json:"type"`
+	Direction      string `json:"direction"`
+	ChannelId      uint8  `json:"channel_id"`
+	Sequence       uint64 `json:"sequence"`
+	OracleSequence uint64 `json:"oracle_sequence"`
+	PackageType    uint32 `json:"package_type"`
+	Height         uint64 `json:"height"`
+	TxHash         string `json:"tx_hash"`
+	ClaimTxHash    string `json:"claim_tx_hash"`
+	Delivered      bool   `json:"delivered"` // whether the claim is sent by the inturn relayer
+	Nonce          uint64 `json:"nonce"`     // nonce of claim attempts and failures
+	Error          string `json:"error"`     // error of claim failures
+	Reason         string `json:"reason"`    // reason of sequence resets
+	Time           int64  `json:"time"`
+}
+
+// Subscription receives events published after it is created. The channel is closed when the subscription is
+// cancelled or when the subscriber can not keep up and its buffer overflows, in which case Overflowed returns true.
+type Subscription struct {
+	ch         chan *Event
+	overflowed bool
+}
+
+func (s *Subscription) Events() <-chan *Event {
+	return s.ch
+}
+
+func (s *Subscription) Overflowed() bool {
+	return s.overflowed
+}
+
+// Bus fans out events to subscribers, publishing never blocks the relayer
+type Bus struct {
+	mutex       sync.Mutex
+	subscribers map[*Subscription]struct{}
+}
+
+func NewBus() *Bus {
+	return &Bus{
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+func (b *Bus) Subscribe(bufferSize int) *Subscription {
+	s := &Subscription{ch: make(chan *Event, bufferSize)}
+	b.mutex.Lock()
+	b.subscribers[s] = struct{}{}
+	b.mutex.Unlock()
+	return s
+}
+
+func (b *Bus) Unsubscribe(s *Subscription) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if _, ok := b.subscribers[s]; ok {
+		delete(b.subscribers, s)
+		close(s.ch)
+	}
+}
+
+// Publish delivers the event to all subscribers, a subscriber whose buffer is full is dropped
+func (b *Bus) Publish(e *Event) {
+	if b == nil {
+		return
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	for s := range b.subscribers {
+		select {
+		case s.ch <- e:
+		default:
+			s.overflowed = true
+			delete(b.subscribers, s)
+			close(s.ch)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBusPublish(t *testing.T) {
+	b := NewBus()
+	fast := b.Subscribe(2)
+	slow := b.Subscribe(1)
+
+	b.Publish(&Event{Type: EventTypePackage, Sequence: 1})
+	require.Equal(t, uint64(1), (<-fast.Events()).Sequence)
+
+	b.Publish(&Event{Type: EventTypePackage, Sequence: 2})
+	require.Equal(t, uint64(2), (<-fast.Events()).Sequence)
+
+	// slow subscriber has not consumed the first event, it is dropped
+	require.True(t, slow.Overflowed())
+	require.Equal(t, uint64(1), (<-slow.Events()).Sequence)
+	_, ok := <-slow.Events()
+	require.False(t, ok)
+
+	b.Unsubscribe(fast)
+	_, ok = <-fast.Events()
+	require.False(t, ok)
+	require.False(t, fast.Overflowed())
+}
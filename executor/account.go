@@ -0,0 +1,45 @@
+package executor
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	sdkclient "github.com/bnb-chain/greenfield-go-sdk/client/chain"
+	sdkkeys "github.com/bnb-chain/greenfield-go-sdk/keys"
+)
+
+// Account is a funded Greenfield account other than the relayer account, txs sent by it never take the nonces of the
+// relayer account cached by the assemblers
+type Account struct {
+	executor *GreenfieldExecutor
+	address  sdk.AccAddress
+	clients  *sdkclient.GnfdCompositeClients
+}
+
+// NewAccount returns the account of the private key, which sends txs to the Greenfield endpoints of e
+func (e *GreenfieldExecutor) NewAccount(privKey string) *Account {
+	km, err := sdkkeys.NewPrivateKeyManager(privKey)
+	if err != nil {
+		panic(err)
+	}
+	return &Account{
+		executor: e,
+		address:  km.GetAddr(),
+		clients: sdkclient.NewGnfdCompositClients(
+			e.config.GreenfieldConfig.GRPCAddrs,
+			e.config.GreenfieldConfig.RPCAddrs,
+			e.config.GreenfieldConfig.ChainIdString,
+			sdkclient.WithKeyManager(km),
+			sdkclient.WithGrpcDialOption(e.grpcDialOptions...),
+		),
+	}
+}
+
+func (a *Account) Address() string {
+	return a.address.String()
+}
+
+// TransferOut transfers the amount of BNB from the account to the BSC address by a cross-chain transfer, and returns
+// the hash of the tx
+func (a *Account) TransferOut(to string, amount sdk.Int) (string, error) {
+	return a.executor.transferOut(a.clients.GetClient().GreenfieldClient, a.Address(), to, amount)
+}
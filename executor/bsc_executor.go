@@ -7,15 +7,18 @@ import (
 	"encoding/json"
 	"fmt"
 	"math/big"
+	"net/http"
 	"sync"
 	"time"
 
 	"github.com/avast/retry-go/v4"
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/spf13/viper"
 
 	relayercommon "github.com/bnb-chain/greenfield-relayer/common"
@@ -24,13 +27,16 @@ import (
 	"github.com/bnb-chain/greenfield-relayer/executor/greenfieldlightclient"
 	"github.com/bnb-chain/greenfield-relayer/logging"
 	rtypes "github.com/bnb-chain/greenfield-relayer/types"
+	"github.com/bnb-chain/greenfield-relayer/util"
 )
 
 type BSCClient struct {
 	rpcClient             *ethclient.Client
+	rawClient             *rpc.Client // for calls not supported by rpcClient, like querying blocks by tag
 	crossChainClient      *crosschain.Crosschain
 	greenfieldLightClient *greenfieldlightclient.Greenfieldlightclient
 	provider              string
+	weight                int
 	height                uint64
 	updatedAt             time.Time
 }
@@ -41,21 +47,50 @@ type BSCExecutor struct {
 	GreenfieldExecutor *GreenfieldExecutor
 	clientIdx          int
 	bscClients         []*BSCClient
+	broadcast          *bscClientPool // clients txs are sent to, shared with the executors of roles
+	archiveClients     []*BSCClient   // clients only used for backfill
 	config             *config.Config
 	privateKey         *ecdsa.PrivateKey
 	txSender           common.Address
 	gasPrice           *big.Int
-	relayers           []rtypes.Validator // cached relayers
-}
-
-func initBSCClients(config *config.Config) []*BSCClient {
+	relayerCache       *validatorCache[rtypes.Validator]
+	rpcTimeout         time.Duration
+	gasBudget          *GasBudget
+	feeCeiling         *FeeCeiling
+	txBuilder          BSCTxBuilder
+	role               string // role with dedicated endpoints, empty for the executor of the default endpoints
+	// whether the last query of the finality_tag block failed and confirmations are used instead
+	finalityTagUnsupported bool
+	finalityTagCheckedAt   time.Time // when the finality_tag block is queried last time
+}
+
+// initBSCClients returns the clients of the providers ordered by weight descending
+func initBSCClients(config *config.Config, providers []config.BSCProvider) []*BSCClient {
 	bscClients := make([]*BSCClient, 0)
 
-	for _, provider := range config.BSCConfig.RPCAddrs {
-		rpcClient, err := ethclient.Dial(provider)
-		if err != nil {
-			panic("new eth client error")
+	limiter := util.NewRateLimiter(config.BSCConfig.RPCRateLimit)
+	for _, p := range sortByWeight(providers) {
+		provider := p.RPCAddr
+		var rawClient *rpc.Client
+		if isHTTPEndpoint(provider) {
+			// requests are traced after being rate limited, so waiting for the limiter is not counted as elapsed time
+			var transport http.RoundTripper = &tracingTransport{subsystem: relayercommon.SubsystemBSCExecutor, base: http.DefaultTransport}
+			if limiter != nil {
+				transport = &rateLimitedTransport{limiter: limiter, endpoint: provider, base: transport}
+			}
+			c, err := rpc.DialHTTPWithClient(provider, &http.Client{Transport: transport})
+			if err != nil {
+				panic("new eth client error")
+			}
+			rawClient = c
+		} else {
+			c, err := rpc.Dial(provider)
+			if err != nil {
+				panic("new eth client error")
+			}
+			rawClient = c
 		}
+		rpcClient := ethclient.NewClient(rawClient)
 		greenfieldLightClient, err := greenfieldlightclient.NewGreenfieldlightclient(
 			common.HexToAddress(config.RelayConfig.GreenfieldLightClientContractAddr),
 			rpcClient)
@@ -70,9 +105,11 @@ func initBSCClients(config *config.Config) []*BSCClient {
 		}
 		bscClients = append(bscClients, &BSCClient{
 			rpcClient:             rpcClient,
+			rawClient:             rawClient,
 			crossChainClient:      crossChainClient,
 			greenfieldLightClient: greenfieldLightClient,
 			provider:              provider,
+			weight:                providerWeight(p),
 			updatedAt:             time.Now(),
 		})
 	}
@@ -123,13 +160,70 @@ func NewBSCExecutor(cfg *config.Config) *BSCExecutor {
 	} else {
 		initGasPrice = big.NewInt(int64(cfg.BSCConfig.GasPrice))
 	}
+	rpcTimeout := RPCTimeout
+	if cfg.BSCConfig.RPCTimeoutInSecond > 0 {
+		rpcTimeout = time.Duration(cfg.BSCConfig.RPCTimeoutInSecond) * time.Second
+	}
+	e := &BSCExecutor{
+		rpcTimeout:     rpcTimeout,
+		clientIdx:      0,
+		bscClients:     initBSCClients(cfg, cfg.BSCConfig.ProvidersOf(config.ProviderRoleRead)),
+		broadcast:      newBSCClientPool(initBSCClients(cfg, cfg.BSCConfig.ProvidersOf(config.ProviderRoleBroadcast))),
+		archiveClients: initBSCClients(cfg, cfg.BSCConfig.ProvidersOf(config.ProviderRoleArchive)),
+		privateKey:     ecdsaPrivKey,
+		txSender:       txSender,
+		config:         cfg,
+		gasPrice:       initGasPrice,
+		gasBudget: NewGasBudget(cfg.BSCConfig.DailyGasBudget, func(spent, ceiling uint64) {
+			msg := fmt.Sprintf("gas spent on BSC today %d exceeds the daily budget %d, light block syncs and out-turn claims are paused",
+				spent, ceiling)
+			logging.Logger.Error(msg)
+			config.SendTelegramMessage(cfg.AlertConfig.Identity, cfg.AlertConfig.TelegramBotId, cfg.AlertConfig.TelegramChatId, msg)
+		}),
+	}
+	e.feeCeiling = NewFeeCeiling(cfg.BSCConfig.MaxFeePerTx, cfg.BSCConfig.MaxFeePerHour, func(msg string) {
+		msg = fmt.Sprintf("BSC txs are deferred, %s", msg)
+		logging.Logger.Error(msg)
+		config.SendTelegramMessage(cfg.AlertConfig.Identity, cfg.AlertConfig.TelegramBotId, cfg.AlertConfig.TelegramChatId, msg)
+	})
+	e.relayerCache = newValidatorCache(ValidatorCacheMaxAge, e.queryRelayers)
+	e.txBuilder = newBSCTxBuilder(&cfg.BSCConfig, e.getGasPrice)
+	return e
+}
+
+// SetGasSpentObserver sets the observer of gas spent on BSC today
+func (e *BSCExecutor) SetGasSpentObserver(observer func(spent uint64)) {
+	e.gasBudget.observer = observer
+}
+
+// GasBudgetExceeded returns whether the gas spent on BSC today exceeds daily_gas_budget, non-essential txs, i.e. light
+// block syncs and claims of out-turn relayers, should be paused then
+func (e *BSCExecutor) GasBudgetExceeded() bool {
+	return e.gasBudget.Exceeded(time.Now())
+}
+
+// ForRole returns the executor of the role, which sends requests to the dedicated endpoints of the role and shares
+// keys, caches and the gas budget with e. e itself is returned if the role has no dedicated endpoints.
+func (e *BSCExecutor) ForRole(role string) *BSCExecutor {
+	endpoints := e.config.BSCConfig.RoleEndpoints.Of(role)
+	if len(endpoints.RPCAddrs) == 0 {
+		return e
+	}
 	return &BSCExecutor{
-		clientIdx:  0,
-		bscClients: initBSCClients(cfg),
-		privateKey: ecdsaPrivKey,
-		txSender:   txSender,
-		config:     cfg,
-		gasPrice:   initGasPrice,
+		GreenfieldExecutor: e.GreenfieldExecutor,
+		bscClients:         initBSCClients(e.config, config.ReadProviders(endpoints.RPCAddrs)),
+		broadcast:          e.broadcast,
+		archiveClients:     e.archiveClients,
+		config:             e.config,
+		privateKey:         e.privateKey,
+		txSender:           e.txSender,
+		gasPrice:           e.getGasPrice(),
+		relayerCache:       e.relayerCache,
+		rpcTimeout:         e.rpcTimeout,
+		gasBudget:          e.gasBudget,
+		feeCeiling:         e.feeCeiling,
+		txBuilder:          e.txBuilder,
+		role:               role,
 	}
 }
 
@@ -137,6 +231,15 @@ func (e *BSCExecutor) SetGreenfieldExecutor(ge *GreenfieldExecutor) {
 	e.GreenfieldExecutor = ge
 }
 
+func (e *BSCExecutor) newRPCContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), e.rpcTimeout)
+}
+
+// GetAddress returns the address of the relayer on BSC
+func (e *BSCExecutor) GetAddress() common.Address {
+	return e.txSender
+}
+
 func (e *BSCExecutor) GetRpcClient() *ethclient.Client {
 	e.mutex.RLock()
 	defer e.mutex.RUnlock()
@@ -162,7 +265,12 @@ func (e *BSCExecutor) SwitchClient() {
 	if e.clientIdx >= len(e.bscClients) {
 		e.clientIdx = 0
 	}
-	logging.Logger.Infof("switch to provider: %s", e.config.BSCConfig.RPCAddrs[e.clientIdx])
+	logging.Logger.Infof("switch to provider: %s", e.bscClients[e.clientIdx].provider)
+}
+
+// GetLatestBlockHeight queries the latest height from the current provider without retry
+func (e *BSCExecutor) GetLatestBlockHeight() (uint64, error) {
+	return e.getLatestBlockHeight(e.GetRpcClient())
 }
 
 func (e *BSCExecutor) GetLatestBlockHeightWithRetry() (latestHeight uint64, err error) {
@@ -176,13 +284,14 @@ func (e *BSCExecutor) getLatestBlockHeightWithRetry(client *ethclient.Client) (l
 	}, relayercommon.RtyAttem,
 		relayercommon.RtyDelay,
 		relayercommon.RtyErr,
+		relayercommon.RtyBudget(relayercommon.SubsystemBSCExecutor),
 		retry.OnRetry(func(n uint, err error) {
 			logging.Logger.Errorf("failed to query latest height, attempt: %d times, max_attempts: %d", n+1, relayercommon.RtyAttNum)
 		}))
 }
 
 func (e *BSCExecutor) getLatestBlockHeight(client *ethclient.Client) (uint64, error) {
-	ctxWithTimeout, cancel := context.WithTimeout(context.Background(), RPCTimeout)
+	ctxWithTimeout, cancel := e.newRPCContext()
 	defer cancel()
 	block, err := client.BlockByNumber(ctxWithTimeout, nil)
 	if err != nil {
@@ -192,10 +301,18 @@ func (e *BSCExecutor) getLatestBlockHeight(client *ethclient.Client) (uint64, er
 }
 
 func (e *BSCExecutor) UpdateClientLoop() {
-	ticker := time.NewTicker(SleepSecondForUpdateClient * time.Second)
-	for range ticker.C {
+	task := relayercommon.TaskBSCClientUpdate
+	if e.role != "" {
+		task = fmt.Sprintf("%s_%s", task, e.role)
+	}
+	relayercommon.Schedule(task, SleepSecondForUpdateClient*time.Second, func() {
 		logging.Logger.Infof("start to monitor bsc data-seeds healthy")
-		for _, bscClient := range e.bscClients {
+		clients := e.bscClients
+		if e.role == "" {
+			// broadcast clients are shared, only the executor of the default endpoints refreshes them
+			clients = append(clients[:len(clients):len(clients)], e.broadcast.clients...)
+		}
+		for _, bscClient := range clients {
 			if time.Since(bscClient.updatedAt).Seconds() > DataSeedDenyServiceThreshold {
 				msg := fmt.Sprintf("data seed %s is not accessable", bscClient.provider)
 				logging.Logger.Error(msg)
@@ -207,31 +324,44 @@ func (e *BSCExecutor) UpdateClientLoop() {
 				logging.Logger.Errorf("get latest block height error, err=%s", err.Error())
 				continue
 			}
+			// heights are read by GetCachedLatestHeight from other goroutines
+			e.mutex.Lock()
 			bscClient.height = height
 			bscClient.updatedAt = time.Now()
+			e.mutex.Unlock()
 		}
 
-		highestHeight := uint64(0)
-		highestIdx := 0
-		for idx := 0; idx < len(e.bscClients); idx++ {
-			if e.bscClients[idx].height > highestHeight {
-				highestHeight = e.bscClients[idx].height
-				highestIdx = idx
-			}
-		}
-		// current client block sync is fall behind, switch to the client with the highest block height
-		if e.bscClients[e.clientIdx].height+FallBehindThreshold < highestHeight {
+		// switch to the client of the highest weight among the ones not falling behind
+		highestHeight := highestClientHeight(e.bscClients)
+		if idx := selectClient(e.bscClients, e.clientIdx, highestHeight); idx >= 0 && idx != e.clientIdx {
 			e.mutex.Lock()
-			e.clientIdx = highestIdx
+			e.clientIdx = idx
 			e.mutex.Unlock()
 		}
-	}
+		if e.role == "" {
+			if h := highestClientHeight(e.broadcast.clients); h > highestHeight {
+				highestHeight = h
+			}
+			e.broadcast.selectClient(highestHeight)
+		}
+	}).Run()
+}
+
+// GetCachedLatestHeight returns the highest block height among BSC clients which is updated periodically, 0 if it
+// has not been updated yet
+func (e *BSCExecutor) GetCachedLatestHeight() uint64 {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	return highestClientHeight(e.bscClients)
 }
 
 func (e *BSCExecutor) GetBlockHeaderAtHeight(height uint64) (*types.Header, error) {
-	ctxWithTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctxWithTimeout, cancel := e.newRPCContext()
 	defer cancel()
 	header, err := e.GetRpcClient().HeaderByNumber(ctxWithTimeout, big.NewInt(int64(height)))
+	if err == ethereum.NotFound {
+		return nil, fmt.Errorf("%w, block header at height %d not found", relayercommon.ErrNodeStale, height)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -246,19 +376,41 @@ func (e *BSCExecutor) GetNextReceiveSequenceForChannelWithRetry(channelID rtypes
 	}, relayercommon.RtyAttem,
 		relayercommon.RtyDelay,
 		relayercommon.RtyErr,
+		relayercommon.RtyBudget(relayercommon.SubsystemBSCExecutor),
 		retry.OnRetry(func(n uint, err error) {
 			logging.Logger.Errorf("failed to query receive sequence for channel %d, attempt: %d times, max_attempts: %d", channelID, n+1, relayercommon.RtyAttNum)
 		}))
 }
 
 func (e *BSCExecutor) getNextReceiveSequenceForChannel(channelID rtypes.ChannelId) (sequence uint64, err error) {
+	ctx, cancel := e.newRPCContext()
+	defer cancel()
 	callOpts := &bind.CallOpts{
 		Pending: true,
-		Context: context.Background(),
+		Context: ctx,
 	}
 	return e.getCrossChainClient().ChannelReceiveSequenceMap(callOpts, uint8(channelID))
 }
 
+// IsChannelEnabled returns whether the channel is registered to its handler in the CrossChain contract, channels are
+// disabled by governance of BSC
+func (e *BSCExecutor) IsChannelEnabled(channelID rtypes.ChannelId) (bool, error) {
+	ctx, cancel := e.newRPCContext()
+	defer cancel()
+	callOpts := &bind.CallOpts{
+		Pending: true,
+		Context: ctx,
+	}
+	handler, err := e.getCrossChainClient().ChannelHandlerMap(callOpts, uint8(channelID))
+	if err != nil {
+		return false, err
+	}
+	if handler == (common.Address{}) {
+		return false, nil
+	}
+	return e.getCrossChainClient().RegisteredContractChannelMap(callOpts, handler, uint8(channelID))
+}
+
 // GetNextSendSequenceForChannelWithRetry gets the next send oracle sequence from  BSC
 func (e *BSCExecutor) GetNextSendSequenceForChannelWithRetry() (sequence uint64, err error) {
 	return sequence, retry.Do(func() error {
@@ -267,15 +419,18 @@ func (e *BSCExecutor) GetNextSendSequenceForChannelWithRetry() (sequence uint64,
 	}, relayercommon.RtyAttem,
 		relayercommon.RtyDelay,
 		relayercommon.RtyErr,
+		relayercommon.RtyBudget(relayercommon.SubsystemBSCExecutor),
 		retry.OnRetry(func(n uint, err error) {
 			logging.Logger.Errorf("failed to query send oracle sequence, attempt: %d times, max_attempts: %d", n+1, relayercommon.RtyAttNum)
 		}))
 }
 
 func (e *BSCExecutor) getNextSendOracleSequence() (sequence uint64, err error) {
+	ctx, cancel := e.newRPCContext()
+	defer cancel()
 	callOpts := &bind.CallOpts{
 		Pending: true,
-		Context: context.Background(),
+		Context: ctx,
 	}
 	sentOracleSeq, err := e.getCrossChainClient().OracleSequence(callOpts)
 	if err != nil {
@@ -292,6 +447,7 @@ func (e *BSCExecutor) GetNextDeliveryOracleSequenceWithRetry() (sequence uint64,
 	}, relayercommon.RtyAttem,
 		relayercommon.RtyDelay,
 		relayercommon.RtyErr,
+		relayercommon.RtyBudget(relayercommon.SubsystemBSCExecutor),
 		retry.OnRetry(func(n uint, err error) {
 			logging.Logger.Errorf("failed to query oracle sequence, attempt: %d times, max_attempts: %d", n+1, relayercommon.RtyAttNum)
 		}))
@@ -305,15 +461,21 @@ func (e *BSCExecutor) getNextDeliveryOracleSequence() (uint64, error) {
 	return sequence, nil
 }
 
-func (e *BSCExecutor) getTransactor(nonce uint64) (*bind.TransactOpts, error) {
+func (e *BSCExecutor) getTransactor(ctx context.Context, nonce uint64) (*bind.TransactOpts, error) {
 	txOpts, err := bind.NewKeyedTransactorWithChainID(e.privateKey, big.NewInt(int64(e.config.BSCConfig.ChainId)))
 	if err != nil {
 		return nil, err
 	}
+	txOpts.Context = ctx
 	txOpts.Nonce = big.NewInt(int64(nonce))
 	txOpts.Value = big.NewInt(0)
 	txOpts.GasLimit = e.config.BSCConfig.GasLimit
-	txOpts.GasPrice = e.getGasPrice()
+	if err = e.txBuilder.Apply(ctx, e.GetRpcClient(), txOpts); err != nil {
+		return nil, err
+	}
+	if err = e.feeCeiling.Check(maxTxFee(txOpts), time.Now()); err != nil {
+		return nil, err
+	}
 	return txOpts, nil
 }
 
@@ -324,25 +486,79 @@ func (e *BSCExecutor) getGasPrice() *big.Int {
 }
 
 func (e *BSCExecutor) SyncTendermintLightBlock(height uint64) (common.Hash, error) {
-	nonce, err := e.GetRpcClient().PendingNonceAt(context.Background(), e.txSender)
+	hashes, err := e.SyncTendermintLightBlocks([]uint64{height})
 	if err != nil {
 		return common.Hash{}, err
 	}
-	txOpts, err := e.getTransactor(nonce)
+	return hashes[0], nil
+}
+
+// SyncTendermintLightBlocks syncs the light blocks at the ascending heights. The light blocks are queried concurrently,
+// at most MaxConcurrentLightBlockQueries at a time, and the sync txs are sent in height order with consecutive nonces,
+// since the light client only accepts a light block signed by the validator set it has synced. Hashes of the txs sent
+// are returned along with the error if a tx fails to be sent.
+func (e *BSCExecutor) SyncTendermintLightBlocks(heights []uint64) ([]common.Hash, error) {
+	lightBlocks, err := e.queryTendermintLightBlocks(heights)
 	if err != nil {
-		return common.Hash{}, err
+		return nil, err
+	}
+	nonce, err := e.GetNonce()
+	if err != nil {
+		return nil, err
+	}
+	hashes := make([]common.Hash, 0, len(heights))
+	for i, height := range heights {
+		hash, err := e.syncLightBlock(lightBlocks[i], height, nonce+uint64(i))
+		if err != nil {
+			return hashes, fmt.Errorf("failed to sync light block at height %d, err=%w", height, err)
+		}
+		hashes = append(hashes, hash)
 	}
-	lightBlock, err := e.QueryTendermintLightBlockWithRetry(int64(height))
+	return hashes, nil
+}
+
+func (e *BSCExecutor) syncLightBlock(lightBlock []byte, height, nonce uint64) (common.Hash, error) {
+	ctx, cancel := e.newRPCContext()
+	defer cancel()
+	txOpts, err := e.getTransactor(ctx, nonce)
 	if err != nil {
 		return common.Hash{}, err
 	}
-	tx, err := e.getGreenfieldLightClient().SyncLightBlock(txOpts, lightBlock, height)
+	tx, err := e.getBroadcastClient().greenfieldLightClient.SyncLightBlock(txOpts, lightBlock, height)
 	if err != nil {
 		return common.Hash{}, err
 	}
+	e.gasBudget.Charge(tx.Gas(), time.Now())
+	e.feeCeiling.Charge(maxTxFee(txOpts), time.Now())
 	return tx.Hash(), nil
 }
 
+// queryTendermintLightBlocks queries the light blocks at the heights concurrently and returns them in the same order
+func (e *BSCExecutor) queryTendermintLightBlocks(heights []uint64) ([][]byte, error) {
+	lightBlocks := make([][]byte, len(heights))
+	errs := make([]error, len(heights))
+	sem := make(chan struct{}, relayercommon.MaxConcurrentLightBlockQueries)
+	wg := new(sync.WaitGroup)
+	for i, height := range heights {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, height uint64) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			lightBlocks[i], errs[i] = e.QueryTendermintLightBlockWithRetry(int64(height))
+		}(i, height)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("failed to query light block at height %d, err=%w", heights[i], err)
+		}
+	}
+	return lightBlocks, nil
+}
+
 func (e *BSCExecutor) QueryTendermintLightBlockWithRetry(height int64) (lightBlock []byte, err error) {
 	return lightBlock, retry.Do(func() error {
 		lightBlock, err = e.GreenfieldExecutor.QueryTendermintLightBlock(height)
@@ -350,6 +566,7 @@ func (e *BSCExecutor) QueryTendermintLightBlockWithRetry(height int64) (lightBlo
 	}, relayercommon.RtyAttem,
 		relayercommon.RtyDelay,
 		relayercommon.RtyErr,
+		relayercommon.RtyBudget(relayercommon.SubsystemBSCExecutor),
 		retry.OnRetry(func(n uint, err error) {
 			logging.Logger.Errorf("failed to query tendermint header, attempt: %d times, max_attempts: %d", n+1, relayercommon.RtyAttNum)
 		}))
@@ -366,37 +583,98 @@ func (e *BSCExecutor) QueryLatestTendermintHeaderWithRetry() (lightBlock []byte,
 	}, relayercommon.RtyAttem,
 		relayercommon.RtyDelay,
 		relayercommon.RtyErr,
+		relayercommon.RtyBudget(relayercommon.SubsystemBSCExecutor),
 		retry.OnRetry(func(n uint, err error) {
 			logging.Logger.Errorf("failed to query tendermint header, attempt: %d times, max_attempts: %d", n+1, relayercommon.RtyAttNum)
 		}))
 }
 
 func (e *BSCExecutor) GetNonce() (uint64, error) {
-	return e.GetRpcClient().PendingNonceAt(context.Background(), e.txSender)
+	ctx, cancel := e.newRPCContext()
+	defer cancel()
+	return e.GetRpcClient().PendingNonceAt(ctx, e.txSender)
+}
+
+// GetEffectiveGasPrice returns the price per gas a tx sent now is expected to pay
+func (e *BSCExecutor) GetEffectiveGasPrice() (*big.Int, error) {
+	ctx, cancel := e.newRPCContext()
+	defer cancel()
+	return e.txBuilder.EffectiveGasPrice(ctx, e.GetRpcClient())
+}
+
+// EstimateClaimFee estimates the fee in wei of claiming the package with the current gas price
+func (e *BSCExecutor) EstimateClaimFee(blsSignature []byte, validatorSet *big.Int, msgBytes []byte) (*big.Int, error) {
+	crossChainAbi, err := crosschain.CrosschainMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	data, err := crossChainAbi.Pack("handlePackage", msgBytes, blsSignature, validatorSet)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := e.newRPCContext()
+	defer cancel()
+	crossChainAddr := common.HexToAddress(e.config.RelayConfig.CrossChainContractAddr)
+	gasPrice, err := e.txBuilder.EffectiveGasPrice(ctx, e.GetRpcClient())
+	if err != nil {
+		return nil, err
+	}
+	gas, err := e.GetRpcClient().EstimateGas(ctx, ethereum.CallMsg{
+		From:     e.txSender,
+		To:       &crossChainAddr,
+		GasPrice: gasPrice,
+		Data:     data,
+	})
+	if err != nil {
+		return nil, classifyBSCTxError(err)
+	}
+	return new(big.Int).Mul(new(big.Int).SetUint64(gas), gasPrice), nil
 }
 
 func (e *BSCExecutor) CallBuildInSystemContract(blsSignature []byte, validatorSet *big.Int, msgBytes []byte, nonce uint64) (common.Hash, error) {
-	txOpts, err := e.getTransactor(nonce)
+	ctx, cancel := e.newRPCContext()
+	defer cancel()
+	txOpts, err := e.getTransactor(ctx, nonce)
 	if err != nil {
 		return common.Hash{}, err
 	}
 
-	tx, err := e.getCrossChainClient().HandlePackage(txOpts, msgBytes, blsSignature, validatorSet)
+	tx, err := e.getBroadcastClient().crossChainClient.HandlePackage(txOpts, msgBytes, blsSignature, validatorSet)
 	if err != nil {
-		return common.Hash{}, err
+		return common.Hash{}, classifyBSCTxError(err)
 	}
+	e.gasBudget.Charge(tx.Gas(), time.Now())
+	e.feeCeiling.Charge(maxTxFee(txOpts), time.Now())
 	return tx.Hash(), nil
 }
 
 // QueryLatestValidators used for gnfd -> bsc
 func (e *BSCExecutor) QueryLatestValidators() ([]rtypes.Validator, error) {
-	relayerAddresses, err := e.getGreenfieldLightClient().GetRelayers(nil)
+	relayers, _, err := e.queryRelayers()
+	return relayers, err
+}
+
+// queryRelayers returns the relayers of the Greenfield light client and its latest Greenfield height, both are read at
+// the same BSC block
+func (e *BSCExecutor) queryRelayers() ([]rtypes.Validator, uint64, error) {
+	blockHeight, err := e.GetLatestBlockHeight()
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
-	blsKeys, err := e.getGreenfieldLightClient().BlsPubKeys(nil)
+	ctx, cancel := e.newRPCContext()
+	defer cancel()
+	callOpts := &bind.CallOpts{Context: ctx, BlockNumber: new(big.Int).SetUint64(blockHeight)}
+	lightClientHeight, err := e.getGreenfieldLightClient().GnfdHeight(callOpts)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
+	}
+	relayerAddresses, err := e.getGreenfieldLightClient().GetRelayers(callOpts)
+	if err != nil {
+		return nil, 0, err
+	}
+	blsKeys, err := e.getGreenfieldLightClient().BlsPubKeys(callOpts)
+	if err != nil {
+		return nil, 0, err
 	}
 	relayers := make([]rtypes.Validator, len(relayerAddresses))
 	nextRelayerBtsStartIdx := 0
@@ -409,37 +687,26 @@ func (e *BSCExecutor) QueryLatestValidators() ([]rtypes.Validator, error) {
 		nextRelayerBtsStartIdx = nextRelayerBtsStartIdx + RelayerBytesLength
 		relayers[i] = r
 	}
-	return relayers, nil
+	return relayers, lightClientHeight, nil
 }
 
 // QueryCachedLatestValidators Used for gnfd -> bsc
 func (e *BSCExecutor) QueryCachedLatestValidators() ([]rtypes.Validator, error) {
-	if len(e.relayers) != 0 {
-		return e.relayers, nil
-	}
-	relayers, err := e.QueryLatestValidators()
-	if err != nil {
-		return nil, err
-	}
-	return relayers, nil
+	return e.relayerCache.get(time.Now())
 }
 
-func (e *BSCExecutor) UpdateCachedLatestValidatorsLoop() {
-	ticker := time.NewTicker(UpdateCachedValidatorsInterval)
-	for range ticker.C {
-		relayers, err := e.QueryLatestValidators()
-		if err != nil {
-			logging.Logger.Errorf("update latest bsc relayers error, err=%s", err)
-			continue
-		}
-		e.relayers = relayers
-	}
+// InvalidateCachedValidators drops cached relayers once the validator set of Greenfield changes at the height, relayers
+// are re-queried until the change is synced to the light client
+func (e *BSCExecutor) InvalidateCachedValidators(height uint64) {
+	e.relayerCache.invalidate(height)
 }
 
 func (e *BSCExecutor) GetLightClientLatestHeight() (uint64, error) {
+	ctx, cancel := e.newRPCContext()
+	defer cancel()
 	callOpts := &bind.CallOpts{
 		Pending: true,
-		Context: context.Background(),
+		Context: ctx,
 	}
 	latestHeight, err := e.getGreenfieldLightClient().GnfdHeight(callOpts)
 	if err != nil {
@@ -460,10 +727,80 @@ func (e *BSCExecutor) GetValidatorsBlsPublicKey() ([]string, error) {
 	return keys, nil
 }
 
+// FilterLogs queries the logs matching the given filter query from the current BSC client
+func (e *BSCExecutor) FilterLogs(query ethereum.FilterQuery) ([]types.Log, error) {
+	ctx, cancel := e.newRPCContext()
+	defer cancel()
+	return e.GetRpcClient().FilterLogs(ctx, query)
+}
+
+// GetTransactionSender returns the sender of the tx at the index of the block
+// GetTransactionReceipt returns the receipt of the tx, ethereum.NotFound if it is not mined yet
+func (e *BSCExecutor) GetTransactionReceipt(txHash common.Hash) (*types.Receipt, error) {
+	ctx, cancel := e.newRPCContext()
+	defer cancel()
+	return e.GetRpcClient().TransactionReceipt(ctx, txHash)
+}
+
+// GetReceiptGasPrice returns the price per gas paid by the tx of the receipt, i.e. the effective tip plus the base fee
+// of its block for dynamic fee txs
+func (e *BSCExecutor) GetReceiptGasPrice(receipt *types.Receipt) (*big.Int, error) {
+	client := e.GetRpcClient()
+	ctx, cancel := e.newRPCContext()
+	defer cancel()
+	tx, _, err := client.TransactionByHash(ctx, receipt.TxHash)
+	if err != nil {
+		return nil, err
+	}
+	header, err := client.HeaderByNumber(ctx, receipt.BlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	if header.BaseFee == nil {
+		return tx.GasPrice(), nil
+	}
+	return new(big.Int).Add(tx.EffectiveGasTipValue(header.BaseFee), header.BaseFee), nil
+}
+
+// GetBlockReceipts returns the receipts of all txs in the block in order
+func (e *BSCExecutor) GetBlockReceipts(blockHash common.Hash) ([]*types.Receipt, error) {
+	client := e.GetRpcClient()
+	ctx, cancel := e.newRPCContext()
+	block, err := client.BlockByHash(ctx, blockHash)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+	receipts := make([]*types.Receipt, 0, len(block.Transactions()))
+	for _, tx := range block.Transactions() {
+		ctx, cancel := e.newRPCContext()
+		receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get receipt of tx %s, err=%s", tx.Hash().String(), err.Error())
+		}
+		receipts = append(receipts, receipt)
+	}
+	return receipts, nil
+}
+
+func (e *BSCExecutor) GetTransactionSender(blockHash common.Hash, txIndex uint) (common.Address, error) {
+	ctx, cancel := e.newRPCContext()
+	defer cancel()
+	client := e.GetRpcClient()
+	tx, err := client.TransactionInBlock(ctx, blockHash, txIndex)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return client.TransactionSender(ctx, tx, blockHash, txIndex)
+}
+
 func (e *BSCExecutor) GetInturnRelayer() (*rtypes.InturnRelayer, error) {
+	ctx, cancel := e.newRPCContext()
+	defer cancel()
 	callOpts := &bind.CallOpts{
 		Pending: true,
-		Context: context.Background(),
+		Context: ctx,
 	}
 	r, err := e.getGreenfieldLightClient().GetInturnRelayer(callOpts)
 	if err != nil {
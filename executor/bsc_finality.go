@@ -0,0 +1,99 @@
+package executor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/avast/retry-go/v4"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	relayercommon "github.com/bnb-chain/greenfield-relayer/common"
+	"github.com/bnb-chain/greenfield-relayer/logging"
+)
+
+// GetFinalizedBlockHeightWithRetry returns the height of the highest block whose packages can be voted, the block of
+// finality_tag if it is set and supported by the node, or the latest block minus number_of_blocks_for_finality
+// otherwise. The second return is false if there is no such block yet. Once the node does not support the tag, it is
+// queried again only every FinalityTagRecheckInterval and without retries, so that the retry budget is not drained.
+func (e *BSCExecutor) GetFinalizedBlockHeightWithRetry() (uint64, bool, error) {
+	if tag := e.config.BSCConfig.FinalityTag; tag != "" {
+		if unsupported, recheck := e.finalityTagStatus(); !unsupported || recheck {
+			var (
+				height uint64
+				err    error
+			)
+			if unsupported {
+				height, err = e.getTaggedBlockHeight(tag)
+			} else {
+				height, err = e.getTaggedBlockHeightWithRetry(tag)
+			}
+			e.setFinalityTagSupported(tag, err == nil)
+			if err == nil {
+				return height, true, nil
+			}
+		}
+	}
+	latestHeight, err := e.GetLatestBlockHeightWithRetry()
+	if err != nil {
+		return 0, false, err
+	}
+	if latestHeight < e.config.BSCConfig.NumberOfBlocksForFinality {
+		return 0, false, nil
+	}
+	return latestHeight - e.config.BSCConfig.NumberOfBlocksForFinality, true, nil
+}
+
+func (e *BSCExecutor) getTaggedBlockHeightWithRetry(tag string) (height uint64, err error) {
+	return height, retry.Do(func() error {
+		height, err = e.getTaggedBlockHeight(tag)
+		return err
+	}, relayercommon.RtyAttem,
+		relayercommon.RtyDelay,
+		relayercommon.RtyErr,
+		relayercommon.RtyBudget(relayercommon.SubsystemBSCExecutor),
+		retry.OnRetry(func(n uint, err error) {
+			logging.Logger.Errorf("failed to query %s block height, attempt: %d times, max_attempts: %d", tag, n+1, relayercommon.RtyAttNum)
+		}))
+}
+
+// getTaggedBlockHeight queries the height of the block of the tag, which is not supported by ethclient of this version
+func (e *BSCExecutor) getTaggedBlockHeight(tag string) (uint64, error) {
+	e.mutex.RLock()
+	client := e.bscClients[e.clientIdx].rawClient
+	e.mutex.RUnlock()
+	ctxWithTimeout, cancel := e.newRPCContext()
+	defer cancel()
+	var header *types.Header
+	if err := client.CallContext(ctxWithTimeout, &header, "eth_getBlockByNumber", tag, false); err != nil {
+		return 0, err
+	}
+	if header == nil {
+		return 0, fmt.Errorf("%s block is not found", tag)
+	}
+	return header.Number.Uint64(), nil
+}
+
+// finalityTagStatus returns whether the finality tag is unsupported by the node, and if so whether it is time to query it
+// again
+func (e *BSCExecutor) finalityTagStatus() (unsupported bool, recheck bool) {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	return e.finalityTagUnsupported, time.Since(e.finalityTagCheckedAt) >= FinalityTagRecheckInterval
+}
+
+// setFinalityTagSupported logs when the node starts or stops supporting the finality tag
+func (e *BSCExecutor) setFinalityTagSupported(tag string, supported bool) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.finalityTagCheckedAt = time.Now()
+	if e.finalityTagUnsupported != supported {
+		return
+	}
+	e.finalityTagUnsupported = !supported
+	if supported {
+		logging.Logger.Infof("use %s block to decide which BSC blocks are final", tag)
+	} else {
+		logging.Logger.Errorf("%s block is not supported by BSC node, fall back to %d confirmations", tag,
+			e.config.BSCConfig.NumberOfBlocksForFinality)
+	}
+}
@@ -0,0 +1,131 @@
+package executor
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/bnb-chain/greenfield-relayer/config"
+	"github.com/bnb-chain/greenfield-relayer/logging"
+)
+
+// bscClientPool is a set of clients of which the one of the highest weight not falling behind is used
+type bscClientPool struct {
+	mutex   sync.RWMutex
+	clients []*BSCClient
+	idx     int // -1 if no client is healthy
+}
+
+func newBSCClientPool(clients []*BSCClient) *bscClientPool {
+	idx := 0
+	if len(clients) == 0 {
+		idx = -1
+	}
+	return &bscClientPool{clients: clients, idx: idx}
+}
+
+// current returns the client in use, nil if there is none
+func (p *bscClientPool) current() *BSCClient {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	if p.idx < 0 {
+		return nil
+	}
+	return p.clients[p.idx]
+}
+
+func (p *bscClientPool) selectClient(highestHeight uint64) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	idx := selectClient(p.clients, p.idx, highestHeight)
+	if idx == p.idx {
+		return
+	}
+	if idx < 0 {
+		logging.Logger.Errorf("all BSC broadcast providers fall behind, send txs to the read provider")
+	} else {
+		logging.Logger.Infof("switch to broadcast provider: %s", p.clients[idx].provider)
+	}
+	p.idx = idx
+}
+
+// getBroadcastClient returns the client txs are sent to, the current read client if no broadcast provider is healthy
+func (e *BSCExecutor) getBroadcastClient() *BSCClient {
+	if c := e.broadcast.current(); c != nil {
+		return c
+	}
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	return e.bscClients[e.clientIdx]
+}
+
+// ForArchive returns the executor sending requests to the archive providers, which shares keys, caches and the gas
+// budget with e. e itself is returned if there is no archive provider.
+func (e *BSCExecutor) ForArchive() *BSCExecutor {
+	if len(e.archiveClients) == 0 {
+		return e
+	}
+	return &BSCExecutor{
+		GreenfieldExecutor: e.GreenfieldExecutor,
+		bscClients:         e.archiveClients,
+		broadcast:          e.broadcast,
+		archiveClients:     e.archiveClients,
+		config:             e.config,
+		privateKey:         e.privateKey,
+		txSender:           e.txSender,
+		gasPrice:           e.getGasPrice(),
+		relayerCache:       e.relayerCache,
+		rpcTimeout:         e.rpcTimeout,
+		gasBudget:          e.gasBudget,
+		feeCeiling:         e.feeCeiling,
+		txBuilder:          e.txBuilder,
+		role:               e.role,
+	}
+}
+
+func providerWeight(p config.BSCProvider) int {
+	if p.Weight == 0 {
+		return 1
+	}
+	return p.Weight
+}
+
+func sortByWeight(providers []config.BSCProvider) []config.BSCProvider {
+	sorted := append([]config.BSCProvider{}, providers...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return providerWeight(sorted[i]) > providerWeight(sorted[j])
+	})
+	return sorted
+}
+
+func highestClientHeight(clients []*BSCClient) uint64 {
+	highestHeight := uint64(0)
+	for _, c := range clients {
+		if c.height > highestHeight {
+			highestHeight = c.height
+		}
+	}
+	return highestHeight
+}
+
+// selectClient returns the index of the client of the highest weight among the ones within FallBehindThreshold of the
+// highest height, -1 if there is none. Among clients of the same weight, the current one is kept, otherwise the highest
+// one is preferred.
+func selectClient(clients []*BSCClient, current int, highestHeight uint64) int {
+	selected := -1
+	for idx, c := range clients {
+		if c.height+FallBehindThreshold < highestHeight {
+			continue
+		}
+		if selected < 0 || c.weight > clients[selected].weight {
+			selected = idx
+			continue
+		}
+		if c.weight < clients[selected].weight || selected == current {
+			continue
+		}
+		if idx == current || c.height > clients[selected].height {
+			selected = idx
+		}
+	}
+	return selected
+}
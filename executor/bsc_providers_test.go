@@ -0,0 +1,25 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectClient(t *testing.T) {
+	clients := []*BSCClient{
+		{provider: "a", weight: 1, height: 100},
+		{provider: "b", weight: 1, height: 98},
+		{provider: "c", weight: 3, height: 90},
+	}
+	// the heavy client falls behind, the current one is kept among the same weight
+	require.Equal(t, 1, selectClient(clients, 1, 100))
+	// the current client falls behind
+	clients[1].height = 90
+	require.Equal(t, 0, selectClient(clients, 1, 100))
+	// the heavy client catches up
+	clients[2].height = 99
+	require.Equal(t, 2, selectClient(clients, 0, 100))
+	// all clients fall behind
+	require.Equal(t, -1, selectClient(clients, 0, 200))
+}
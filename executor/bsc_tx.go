@@ -0,0 +1,106 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/bnb-chain/greenfield-relayer/config"
+)
+
+// BSCTxBuilder sets the fee fields of txs sent to BSC by their type, so that tx types introduced by BSC hard forks are
+// supported by adding a builder and a tx_type without touching the assemblers
+type BSCTxBuilder interface {
+	// Apply sets the fee fields of the transactor of a tx
+	Apply(ctx context.Context, client *ethclient.Client, txOpts *bind.TransactOpts) error
+	// EffectiveGasPrice returns the price per gas a tx sent now is expected to pay, for estimating fees
+	EffectiveGasPrice(ctx context.Context, client *ethclient.Client) (*big.Int, error)
+}
+
+func newBSCTxBuilder(cfg *config.BSCConfig, gasPrice func() *big.Int) BSCTxBuilder {
+	switch cfg.TxType {
+	case "", config.BSCTxTypeLegacy:
+		return &legacyTxBuilder{gasPrice: gasPrice}
+	case config.BSCTxTypeDynamicFee:
+		return &dynamicFeeTxBuilder{maxFeePerGas: cfg.MaxFeePerGas, maxPriorityFeePerGas: cfg.MaxPriorityFeePerGas}
+	default:
+		panic(fmt.Sprintf("unsupported BSC tx type %s", cfg.TxType))
+	}
+}
+
+// legacyTxBuilder builds legacy txs with the configured gas price
+type legacyTxBuilder struct {
+	gasPrice func() *big.Int
+}
+
+func (b *legacyTxBuilder) Apply(_ context.Context, _ *ethclient.Client, txOpts *bind.TransactOpts) error {
+	txOpts.GasPrice = b.gasPrice()
+	return nil
+}
+
+func (b *legacyTxBuilder) EffectiveGasPrice(_ context.Context, _ *ethclient.Client) (*big.Int, error) {
+	return b.gasPrice(), nil
+}
+
+// dynamicFeeTxBuilder builds EIP-1559 txs, caps which are not configured are derived from the latest block and the
+// priority fee suggested by the node
+type dynamicFeeTxBuilder struct {
+	maxFeePerGas         uint64
+	maxPriorityFeePerGas uint64
+}
+
+func (b *dynamicFeeTxBuilder) Apply(ctx context.Context, client *ethclient.Client, txOpts *bind.TransactOpts) error {
+	feeCap, tipCap, _, err := b.caps(ctx, client)
+	if err != nil {
+		return err
+	}
+	txOpts.GasPrice = nil
+	txOpts.GasFeeCap = feeCap
+	txOpts.GasTipCap = tipCap
+	return nil
+}
+
+func (b *dynamicFeeTxBuilder) EffectiveGasPrice(ctx context.Context, client *ethclient.Client) (*big.Int, error) {
+	feeCap, tipCap, baseFee, err := b.caps(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	return effectiveGasPrice(feeCap, tipCap, baseFee), nil
+}
+
+// caps returns the fee cap and tip cap of a tx sent now, and the base fee of the latest block
+func (b *dynamicFeeTxBuilder) caps(ctx context.Context, client *ethclient.Client) (*big.Int, *big.Int, *big.Int, error) {
+	header, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if header.BaseFee == nil {
+		return nil, nil, nil, fmt.Errorf("dynamic fee txs are not supported by BSC yet, the latest block has no base fee")
+	}
+	tipCap := new(big.Int).SetUint64(b.maxPriorityFeePerGas)
+	if b.maxPriorityFeePerGas == 0 {
+		if tipCap, err = client.SuggestGasTipCap(ctx); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	feeCap := new(big.Int).SetUint64(b.maxFeePerGas)
+	if b.maxFeePerGas == 0 {
+		feeCap = new(big.Int).Add(new(big.Int).Mul(header.BaseFee, big.NewInt(2)), tipCap)
+	}
+	if feeCap.Cmp(tipCap) < 0 {
+		tipCap = feeCap
+	}
+	return feeCap, tipCap, header.BaseFee, nil
+}
+
+// effectiveGasPrice is min(base fee + tip cap, fee cap)
+func effectiveGasPrice(feeCap, tipCap, baseFee *big.Int) *big.Int {
+	price := new(big.Int).Add(baseFee, tipCap)
+	if price.Cmp(feeCap) > 0 {
+		return new(big.Int).Set(feeCap)
+	}
+	return price
+}
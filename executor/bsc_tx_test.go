@@ -0,0 +1,14 @@
+package executor
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEffectiveGasPrice(t *testing.T) {
+	require.Equal(t, big.NewInt(6), effectiveGasPrice(big.NewInt(10), big.NewInt(1), big.NewInt(5)))
+	// capped by the fee cap when the base fee rises
+	require.Equal(t, big.NewInt(10), effectiveGasPrice(big.NewInt(10), big.NewInt(1), big.NewInt(12)))
+}
@@ -0,0 +1,59 @@
+package executor
+
+import (
+	"fmt"
+	"math/big"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	oracletypes "github.com/cosmos/cosmos-sdk/x/oracle/types"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// relayerFeeOfPayload sums the relayer fees in the headers of the packages in the claim payload, which the oracle module
+// distributes to the relayers once the claim is accepted
+func relayerFeeOfPayload(payloadBts []byte) (*big.Int, error) {
+	var pkgs oracletypes.Packages
+	if err := rlp.DecodeBytes(payloadBts, &pkgs); err != nil {
+		return nil, fmt.Errorf("decode claim payload error, err=%s", err.Error())
+	}
+	total := new(big.Int)
+	for _, pkg := range pkgs {
+		header, err := sdk.DecodePackageHeader(pkg.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("decode header of package with channel id %d and sequence %d error, err=%s", pkg.ChannelId, pkg.Sequence, err.Error())
+		}
+		total.Add(total, header.RelayerFee)
+	}
+	return total, nil
+}
+
+// EstimateClaimReward returns the least reward of the claim to the relayer, i.e. the relayer reward share of the oracle
+// module of the relayer fees in the payload. The rest is shared by the other relayers which signed the claim.
+func (s *GreenfieldSnapshot) EstimateClaimReward(payloadBts []byte) (*big.Int, error) {
+	relayerFee, err := relayerFeeOfPayload(payloadBts)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := s.executor.newRPCContext()
+	defer cancel()
+	res, err := s.client.OracleQueryClient.Params(ctx, &oracletypes.QueryParamsRequest{})
+	if err != nil {
+		return nil, err
+	}
+	reward := new(big.Int).Mul(relayerFee, big.NewInt(int64(res.Params.RelayerRewardShare)))
+	return reward.Quo(reward, big.NewInt(100)), nil
+}
+
+// EstimateClaimFee returns the fee of the claim decided by the configured fee strategy, in the configured fee denom
+func (s *GreenfieldSnapshot) EstimateClaimFee(payloadBts []byte, aggregatedSig []byte, voteAddressSet []uint64, claimTs int64, oracleSeq uint64, nonce uint64) (*big.Int, error) {
+	msgs := s.executor.claimMsgs(s.executor.newMsgClaim(payloadBts, aggregatedSig, voteAddressSet, claimTs, oracleSeq))
+	txOpt, err := s.executor.getTxOption(s.client, msgs, nonce)
+	if err != nil {
+		return nil, err
+	}
+	fee := new(big.Int)
+	for _, coin := range txOpt.FeeAmount {
+		fee.Add(fee, coin.Amount.BigInt())
+	}
+	return fee, nil
+}
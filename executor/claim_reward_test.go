@@ -0,0 +1,34 @@
+package executor
+
+import (
+	"math/big"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	oracletypes "github.com/cosmos/cosmos-sdk/x/oracle/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelayerFeeOfPayload(t *testing.T) {
+	encode := func(relayerFee int64) []byte {
+		return sdk.EncodePackageHeader(sdk.PackageHeader{
+			PackageType:   sdk.SynCrossChainPackageType,
+			Timestamp:     1000,
+			RelayerFee:    big.NewInt(relayerFee),
+			AckRelayerFee: big.NewInt(1),
+		})
+	}
+	payload, err := rlp.EncodeToBytes(oracletypes.Packages{
+		{ChannelId: 1, Sequence: 2, Payload: append(encode(300), 0x01)},
+		{ChannelId: 2, Sequence: 5, Payload: append(encode(200), 0x02)},
+	})
+	require.NoError(t, err)
+
+	fee, err := relayerFeeOfPayload(payload)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(500), fee)
+
+	_, err = relayerFeeOfPayload([]byte{0x01})
+	require.Error(t, err)
+}
@@ -0,0 +1,44 @@
+package executor
+
+import (
+	"fmt"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	oracletypes "github.com/cosmos/cosmos-sdk/x/oracle/types"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	relayercommon "github.com/bnb-chain/greenfield-relayer/common"
+)
+
+// validateClaimTimestamp checks claimTs against the header of each package in the payload, the oracle module rejects
+// claims whose timestamp differs from any header. The timestamp is signed by the votes as well, so a mismatched claim
+// can not be fixed by adjusting claimTs.
+func validateClaimTimestamp(payloadBts []byte, claimTs int64) error {
+	var pkgs oracletypes.Packages
+	if err := rlp.DecodeBytes(payloadBts, &pkgs); err != nil {
+		return fmt.Errorf("decode claim payload error, err=%s", err.Error())
+	}
+	for _, pkg := range pkgs {
+		header, err := sdk.DecodePackageHeader(pkg.Payload)
+		if err != nil {
+			return fmt.Errorf("decode header of package with channel id %d and sequence %d error, err=%s", pkg.ChannelId, pkg.Sequence, err.Error())
+		}
+		if int64(header.Timestamp) != claimTs {
+			return fmt.Errorf("%w, timestamp %d of claim is not the same as %d in header of package with channel id %d and sequence %d",
+				relayercommon.ErrClaimTimestampMismatch, claimTs, header.Timestamp, pkg.ChannelId, pkg.Sequence)
+		}
+	}
+	return nil
+}
+
+// OutturnClaimAllowed tells whether a claim of the timestamp by a non-inturn relayer would be accepted, i.e. the
+// in-turn relayer timeout has passed since claimTs by the block time of the node. The local clock is only used if the
+// block time is unknown, since it drifts apart from the block time during long backlogs or slow blocks.
+func (s *GreenfieldSnapshot) OutturnClaimAllowed(claimTs int64, inturnRelayerTimeout int64) bool {
+	now := s.blockTime
+	if now.IsZero() {
+		now = time.Now()
+	}
+	return now.Unix() >= claimTs+inturnRelayerTimeout
+}
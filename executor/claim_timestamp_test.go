@@ -0,0 +1,28 @@
+package executor
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	oracletypes "github.com/cosmos/cosmos-sdk/x/oracle/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/require"
+
+	relayercommon "github.com/bnb-chain/greenfield-relayer/common"
+)
+
+func TestValidateClaimTimestamp(t *testing.T) {
+	header := sdk.EncodePackageHeader(sdk.PackageHeader{
+		PackageType:   sdk.SynCrossChainPackageType,
+		Timestamp:     1000,
+		RelayerFee:    big.NewInt(0),
+		AckRelayerFee: big.NewInt(0),
+	})
+	payload, err := rlp.EncodeToBytes(oracletypes.Packages{{ChannelId: 1, Sequence: 2, Payload: append(header, 0x01)}})
+	require.NoError(t, err)
+
+	require.NoError(t, validateClaimTimestamp(payload, 1000))
+	require.True(t, errors.Is(validateClaimTimestamp(payload, 1001), relayercommon.ErrClaimTimestampMismatch))
+}
@@ -5,13 +5,16 @@ import (
 )
 
 const (
-	DefaultGasPrice                = 20000000000 // 20 GWei
-	FallBehindThreshold            = 5
-	SleepSecondForUpdateClient     = 10
-	DataSeedDenyServiceThreshold   = 60
-	RPCTimeout                     = 3 * time.Second
-	RelayerBytesLength             = 48
-	UpdateCachedValidatorsInterval = 1 * time.Minute
+	DefaultGasPrice              = 20000000000 // 20 GWei
+	FallBehindThreshold          = 5
+	SleepSecondForUpdateClient   = 10
+	DataSeedDenyServiceThreshold = 60
+	RPCTimeout                   = 3 * time.Second
+	RelayerBytesLength           = 48
+	ValidatorCacheMaxAge         = 1 * time.Minute  // cached validators are re-queried after this even if no change is observed
+	ClaimNodeStaleThreshold      = 30 * time.Second // a node whose latest block is older than this is not used for claims
+	FeePayerCooldown             = 1 * time.Minute  // a fee payer which failed to submit a claim is skipped within this time
+	FinalityTagRecheckInterval   = 5 * time.Minute  // a finality tag unsupported by the node is queried again after this
 
 	VotePoolBroadcastMethodName   = "broadcast_vote"
 	VotePoolBroadcastParameterKey = "vote"
@@ -19,4 +22,6 @@ const (
 	VotePoolQueryMethodName         = "query_vote"
 	VotePoolQueryParameterEventType = "event_type"
 	VotePoolQueryParameterEventHash = "event_hash"
+	VotePoolQueryParameterPage      = "page"
+	VotePoolQueryParameterPerPage   = "per_page"
 )
@@ -0,0 +1,84 @@
+package executor
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+
+	relayercommon "github.com/bnb-chain/greenfield-relayer/common"
+)
+
+// FeeCeiling caps the fee in wei of each BSC tx and of the txs sent within the current UTC hour, so that txs are deferred
+// instead of paying a spiking gas price. The fee of a tx is counted by its gas limit and the highest price it may pay.
+type FeeCeiling struct {
+	mtx         sync.Mutex
+	perTx       *big.Int // nil means unlimited
+	perHour     *big.Int // nil means unlimited
+	hour        string
+	spent       *big.Int
+	alertedHour string
+	exceeded    func(msg string) // called once an hour when a tx is deferred
+}
+
+func NewFeeCeiling(perTx, perHour uint64, exceeded func(msg string)) *FeeCeiling {
+	c := &FeeCeiling{spent: new(big.Int), exceeded: exceeded}
+	if perTx > 0 {
+		c.perTx = new(big.Int).SetUint64(perTx)
+	}
+	if perHour > 0 {
+		c.perHour = new(big.Int).SetUint64(perHour)
+	}
+	return c
+}
+
+// Check returns an error wrapping ErrFeeCeilingExceeded if a tx of the fee would exceed either ceiling
+func (c *FeeCeiling) Check(fee *big.Int, now time.Time) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.rollover(now)
+	var err error
+	if c.perTx != nil && fee.Cmp(c.perTx) > 0 {
+		err = fmt.Errorf("%w, fee %s wei of the tx is over the ceiling %s wei per tx", relayercommon.ErrFeeCeilingExceeded, fee, c.perTx)
+	} else if spent := new(big.Int).Add(c.spent, fee); c.perHour != nil && spent.Cmp(c.perHour) > 0 {
+		err = fmt.Errorf("%w, fee %s wei of the tx would bring the fee of this hour to %s wei, over the ceiling %s wei per hour",
+			relayercommon.ErrFeeCeilingExceeded, fee, spent, c.perHour)
+	}
+	if err != nil && c.alertedHour != c.hour && c.exceeded != nil {
+		c.alertedHour = c.hour
+		c.exceeded(err.Error())
+	}
+	return err
+}
+
+// Charge counts the fee of a sent tx
+func (c *FeeCeiling) Charge(fee *big.Int, now time.Time) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.rollover(now)
+	c.spent.Add(c.spent, fee)
+}
+
+// rollover resets the spent fee when a new UTC hour starts
+func (c *FeeCeiling) rollover(now time.Time) {
+	hour := now.UTC().Format("2006-01-02T15")
+	if hour == c.hour {
+		return
+	}
+	c.hour = hour
+	c.spent = new(big.Int)
+}
+
+// maxTxFee returns the highest fee a tx of the transactor may pay, gas limit * gas price or fee cap
+func maxTxFee(txOpts *bind.TransactOpts) *big.Int {
+	price := txOpts.GasPrice
+	if txOpts.GasFeeCap != nil {
+		price = txOpts.GasFeeCap
+	}
+	if price == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Mul(new(big.Int).SetUint64(txOpts.GasLimit), price)
+}
@@ -0,0 +1,44 @@
+package executor
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/stretchr/testify/require"
+
+	relayercommon "github.com/bnb-chain/greenfield-relayer/common"
+)
+
+func TestFeeCeiling(t *testing.T) {
+	alerts := 0
+	c := NewFeeCeiling(50, 100, func(msg string) { alerts++ })
+	hour := time.Date(2023, 5, 1, 10, 30, 0, 0, time.UTC)
+
+	err := c.Check(big.NewInt(51), hour)
+	require.True(t, errors.Is(err, relayercommon.ErrFeeCeilingExceeded))
+	require.NoError(t, c.Check(big.NewInt(50), hour))
+	c.Charge(big.NewInt(50), hour)
+	c.Charge(big.NewInt(40), hour)
+	require.NoError(t, c.Check(big.NewInt(10), hour))
+	err = c.Check(big.NewInt(11), hour)
+	require.True(t, errors.Is(err, relayercommon.ErrFeeCeilingExceeded))
+	require.Equal(t, 1, alerts)
+
+	// reset on the next UTC hour
+	nextHour := hour.Add(time.Hour)
+	require.NoError(t, c.Check(big.NewInt(50), nextHour))
+	require.Error(t, c.Check(big.NewInt(51), nextHour))
+	require.Equal(t, 2, alerts)
+
+	unlimited := NewFeeCeiling(0, 0, nil)
+	unlimited.Charge(big.NewInt(1<<40), hour)
+	require.NoError(t, unlimited.Check(big.NewInt(1<<40), hour))
+}
+
+func TestMaxTxFee(t *testing.T) {
+	require.Equal(t, big.NewInt(200), maxTxFee(&bind.TransactOpts{GasLimit: 10, GasPrice: big.NewInt(20)}))
+	require.Equal(t, big.NewInt(300), maxTxFee(&bind.TransactOpts{GasLimit: 10, GasFeeCap: big.NewInt(30), GasTipCap: big.NewInt(1)}))
+}
@@ -0,0 +1,153 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	"google.golang.org/grpc"
+
+	sdkclient "github.com/bnb-chain/greenfield-go-sdk/client/chain"
+	sdkkeys "github.com/bnb-chain/greenfield-go-sdk/keys"
+	relayercommon "github.com/bnb-chain/greenfield-relayer/common"
+	"github.com/bnb-chain/greenfield-relayer/config"
+	"github.com/bnb-chain/greenfield-relayer/logging"
+)
+
+// feePayer is a funded account which submits claims of the relayer wrapped in authz MsgExec, the relayer account stays
+// the signer of MsgClaim so that the binding to the validator is kept
+type feePayer struct {
+	mutex   sync.Mutex
+	address sdk.AccAddress
+	clients *sdkclient.GnfdCompositeClients
+	// clients of each node by its RPC address, so that a claim is sent to the node pinned by the snapshot
+	nodeClients map[string]*sdkclient.GnfdCompositeClients
+	nonce       uint64
+	nonceKnown  bool
+	failedAt    time.Time
+}
+
+// feePayerPool rotates fee payers round-robin, a payer which failed recently is skipped unless all payers failed
+type feePayerPool struct {
+	mutex  sync.Mutex
+	payers []*feePayer
+	next   int
+}
+
+func newFeePayerPool(cfg *config.GreenfieldConfig, grpcDialOptions []grpc.DialOption) *feePayerPool {
+	if len(cfg.FeePayerPrivateKeys) == 0 {
+		return nil
+	}
+	payers := make([]*feePayer, 0, len(cfg.FeePayerPrivateKeys))
+	for _, privKey := range cfg.FeePayerPrivateKeys {
+		km, err := sdkkeys.NewPrivateKeyManager(privKey)
+		if err != nil {
+			panic(err)
+		}
+		nodeClients := make(map[string]*sdkclient.GnfdCompositeClients, len(cfg.RPCAddrs))
+		for i := range cfg.RPCAddrs {
+			nodeClients[cfg.RPCAddrs[i]] = sdkclient.NewGnfdCompositClients(
+				[]string{cfg.GRPCAddrs[i]},
+				[]string{cfg.RPCAddrs[i]},
+				cfg.ChainIdString,
+				sdkclient.WithKeyManager(km),
+				sdkclient.WithGrpcDialOption(grpcDialOptions...),
+			)
+		}
+		payers = append(payers, &feePayer{
+			address: km.GetAddr(),
+			clients: sdkclient.NewGnfdCompositClients(
+				cfg.GRPCAddrs,
+				cfg.RPCAddrs,
+				cfg.ChainIdString,
+				sdkclient.WithKeyManager(km),
+				sdkclient.WithGrpcDialOption(grpcDialOptions...),
+			),
+			nodeClients: nodeClients,
+		})
+	}
+	return &feePayerPool{payers: payers}
+}
+
+func (p *feePayerPool) pick() *feePayer {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	for i := 0; i < len(p.payers); i++ {
+		payer := p.payers[(p.next+i)%len(p.payers)]
+		payer.mutex.Lock()
+		healthy := time.Since(payer.failedAt) > FeePayerCooldown
+		payer.mutex.Unlock()
+		if healthy {
+			p.next = (p.next + i + 1) % len(p.payers)
+			return payer
+		}
+	}
+	payer := p.payers[p.next]
+	p.next = (p.next + 1) % len(p.payers)
+	return payer
+}
+
+// client returns the client of the payer to the node, or the default one if the node is unknown to the payer, e.g. the
+// fallback client or a node of dedicated endpoints
+func (payer *feePayer) client(provider string) *sdkclient.GreenfieldClient {
+	if clients, ok := payer.nodeClients[provider]; ok {
+		return clients.GetClient().GreenfieldClient
+	}
+	return payer.clients.GetClient().GreenfieldClient
+}
+
+// claimByFeePayer submits the claim to the node of the provider by the next fee payer, and fails over to the other
+// payers if the payer fails to submit it. A claim rejected for itself, e.g. on sequence mismatch, would fail with any
+// payer and is returned at once.
+func (e *GreenfieldExecutor) claimByFeePayer(provider string, msgClaim sdk.Msg) (string, error) {
+	tried := make(map[*feePayer]bool, len(e.feePayers.payers))
+	var err error
+	for len(tried) < len(e.feePayers.payers) {
+		payer := e.feePayers.pick()
+		if tried[payer] {
+			break
+		}
+		tried[payer] = true
+		var txHash string
+		txHash, err = e.claimByPayer(payer, provider, msgClaim)
+		if err == nil {
+			return txHash, nil
+		}
+		if errors.Is(err, relayercommon.ErrSequenceMismatch) || errors.Is(err, relayercommon.ErrClaimSimulationFailed) {
+			return "", err
+		}
+	}
+	return "", err
+}
+
+// claimByPayer submits the claim by the payer, the nonce of each payer is tracked locally and re-fetched from chain
+// after a failure
+func (e *GreenfieldExecutor) claimByPayer(payer *feePayer, provider string, msgClaim sdk.Msg) (string, error) {
+	payer.mutex.Lock()
+	defer payer.mutex.Unlock()
+
+	client := payer.client(provider)
+	if !payer.nonceKnown {
+		nonce, err := client.GetNonce()
+		if err != nil {
+			payer.failedAt = time.Now()
+			return "", fmt.Errorf("failed to get nonce of fee payer %s, err=%s", payer.address.String(), err.Error())
+		}
+		payer.nonce = nonce
+		payer.nonceKnown = true
+	}
+	msgExec := authz.NewMsgExec(payer.address, []sdk.Msg{msgClaim})
+	msgs := []sdk.Msg{&msgExec}
+	txHash, err := e.broadcastClaim(client, msgs, payer.nonce)
+	if err != nil {
+		payer.nonceKnown = false
+		payer.failedAt = time.Now()
+		logging.Logger.Errorf("fee payer %s failed to submit claim, err=%s", payer.address.String(), err.Error())
+		return "", err
+	}
+	payer.nonce++
+	return txHash, nil
+}
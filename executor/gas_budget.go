@@ -0,0 +1,58 @@
+package executor
+
+import (
+	"sync"
+	"time"
+)
+
+// GasBudget tracks the gas of BSC txs sent within the current UTC day against a ceiling. Gas is counted by the gas
+// limit of each tx when it is sent, an upper bound of the gas used, so that the spend is bounded even if txs are
+// never mined.
+type GasBudget struct {
+	mtx      sync.Mutex
+	ceiling  uint64 // 0 means unlimited
+	day      string
+	spent    uint64
+	exceeded func(spent, ceiling uint64) // called once a day when the ceiling is exceeded
+	observer func(spent uint64)
+}
+
+func NewGasBudget(ceiling uint64, exceeded func(spent, ceiling uint64)) *GasBudget {
+	return &GasBudget{ceiling: ceiling, exceeded: exceeded}
+}
+
+// Charge counts the gas of a sent tx
+func (b *GasBudget) Charge(gas uint64, now time.Time) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	b.rollover(now)
+	before := b.spent
+	b.spent += gas
+	if b.observer != nil {
+		b.observer(b.spent)
+	}
+	if b.ceiling > 0 && before <= b.ceiling && b.spent > b.ceiling && b.exceeded != nil {
+		b.exceeded(b.spent, b.ceiling)
+	}
+}
+
+// Exceeded returns whether the gas spent today is over the ceiling
+func (b *GasBudget) Exceeded(now time.Time) bool {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	b.rollover(now)
+	return b.ceiling > 0 && b.spent > b.ceiling
+}
+
+// rollover resets the spent gas when a new UTC day starts
+func (b *GasBudget) rollover(now time.Time) {
+	day := now.UTC().Format("2006-01-02")
+	if day == b.day {
+		return
+	}
+	b.day = day
+	b.spent = 0
+	if b.observer != nil {
+		b.observer(0)
+	}
+}
@@ -0,0 +1,34 @@
+package executor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGasBudget(t *testing.T) {
+	alerts := 0
+	b := NewGasBudget(100, func(spent, ceiling uint64) { alerts++ })
+	day := time.Date(2023, 5, 1, 23, 0, 0, 0, time.UTC)
+
+	b.Charge(60, day)
+	require.False(t, b.Exceeded(day))
+	b.Charge(40, day)
+	require.False(t, b.Exceeded(day))
+	b.Charge(1, day)
+	require.True(t, b.Exceeded(day))
+	b.Charge(50, day)
+	require.Equal(t, 1, alerts)
+
+	// reset on the next UTC day
+	nextDay := day.Add(2 * time.Hour)
+	require.False(t, b.Exceeded(nextDay))
+	b.Charge(101, nextDay)
+	require.True(t, b.Exceeded(nextDay))
+	require.Equal(t, 2, alerts)
+
+	unlimited := NewGasBudget(0, nil)
+	unlimited.Charge(1<<40, day)
+	require.False(t, unlimited.Exceeded(day))
+}
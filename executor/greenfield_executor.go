@@ -1,20 +1,25 @@
 package executor
 
 import (
+	"bytes"
 	"context"
 	"encoding/hex"
 	"encoding/json"
 	_ "encoding/json"
 	"fmt"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/avast/retry-go/v4"
+	bridgetypes "github.com/bnb-chain/greenfield/x/bridge/types"
 	"github.com/cosmos/cosmos-sdk/codec"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	grpctypes "github.com/cosmos/cosmos-sdk/types/grpc"
+	"github.com/cosmos/cosmos-sdk/x/authz"
 	crosschaintypes "github.com/cosmos/cosmos-sdk/x/crosschain/types"
 	oracletypes "github.com/cosmos/cosmos-sdk/x/oracle/types"
 	ethcommon "github.com/ethereum/go-ethereum/common"
-	"github.com/prysmaticlabs/prysm/crypto/bls/blst"
 	"github.com/spf13/viper"
 	"github.com/tendermint/tendermint/rpc/client"
 	ctypes "github.com/tendermint/tendermint/rpc/core/types"
@@ -22,25 +27,52 @@ import (
 	"github.com/tendermint/tendermint/votepool"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 
 	sdkclient "github.com/bnb-chain/greenfield-go-sdk/client/chain"
 	sdkkeys "github.com/bnb-chain/greenfield-go-sdk/keys"
 	sdktypes "github.com/bnb-chain/greenfield-go-sdk/types"
+	"github.com/bnb-chain/greenfield-relayer/bls"
 	relayercommon "github.com/bnb-chain/greenfield-relayer/common"
 	"github.com/bnb-chain/greenfield-relayer/config"
 	"github.com/bnb-chain/greenfield-relayer/logging"
 	"github.com/bnb-chain/greenfield-relayer/types"
+	"github.com/bnb-chain/greenfield-relayer/util"
 )
 
+// gnfdNode is the client of a single Greenfield node, used to choose the node to send claims to
+type gnfdNode struct {
+	provider string
+	clients  *sdkclient.GnfdCompositeClients
+}
+
+type nodeStatus struct {
+	node       *gnfdNode
+	height     int64
+	blockTime  time.Time
+	catchingUp bool
+}
+
 type GreenfieldExecutor struct {
-	BscExecutor   *BSCExecutor
-	gnfdClients   *sdkclient.GnfdCompositeClients
-	config        *config.Config
-	address       string
-	validators    []*tmtypes.Validator // used to cache validators
-	cdc           *codec.ProtoCodec
-	BlsPrivateKey []byte
-	BlsPubKey     []byte
+	BscExecutor    *BSCExecutor
+	gnfdClients    *sdkclient.GnfdCompositeClients
+	nodes          []*gnfdNode
+	config         *config.Config
+	address        string
+	signer         sdk.AccAddress
+	relayerAddr    string // relayer address of the validator which claims are submitted on behalf of
+	validatorCache *validatorCache[*tmtypes.Validator]
+	cdc            *codec.ProtoCodec
+	BlsPrivateKey  []byte
+	BlsPubKey      []byte
+	rpcTimeout     time.Duration
+	rpcLimiter     *util.RateLimiter
+	feePayers      *feePayerPool // nil if claims are submitted by the relayer account
+	simObserver    ClaimSimulationObserver
+	// dial options of the clients, shared by the clients of other accounts
+	grpcDialOptions []grpc.DialOption
+	// newClients creates the clients of the endpoints, used by executors of roles with dedicated endpoints
+	newClients func(rpcAddrs, grpcAddrs []string) (*sdkclient.GnfdCompositeClients, []*gnfdNode)
 }
 
 func NewGreenfieldExecutor(cfg *config.Config) *GreenfieldExecutor {
@@ -59,24 +91,98 @@ func NewGreenfieldExecutor(cfg *config.Config) *GreenfieldExecutor {
 	}
 	blsPrivKeyBts := ethcommon.Hex2Bytes(blsPrivKeyStr)
 
-	blsPrivKey, err := blst.SecretKeyFromBytes(blsPrivKeyBts)
+	blsPrivKey, err := bls.SecretKeyFromBytes(blsPrivKeyBts)
 	if err != nil {
 		panic(err)
 	}
-	clients := sdkclient.NewGnfdCompositClients(
-		cfg.GreenfieldConfig.GRPCAddrs,
-		cfg.GreenfieldConfig.RPCAddrs,
-		cfg.GreenfieldConfig.ChainIdString,
-		sdkclient.WithKeyManager(km),
-		sdkclient.WithGrpcDialOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
-	)
-	return &GreenfieldExecutor{
+	interceptors := []grpc.UnaryClientInterceptor{tracingInterceptor(relayercommon.SubsystemGreenfieldExecutor)}
+	if grpcLimiter := util.NewRateLimiter(cfg.GreenfieldConfig.GRPCRateLimit); grpcLimiter != nil {
+		interceptors = append(interceptors, rateLimitInterceptor(grpcLimiter))
+	}
+	grpcDialOptions := []grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(interceptors...),
+	}
+	newClients := func(rpcAddrs, grpcAddrs []string) (*sdkclient.GnfdCompositeClients, []*gnfdNode) {
+		clients := sdkclient.NewGnfdCompositClients(
+			grpcAddrs,
+			rpcAddrs,
+			cfg.GreenfieldConfig.ChainIdString,
+			sdkclient.WithKeyManager(km),
+			sdkclient.WithGrpcDialOption(grpcDialOptions...),
+		)
+		nodes := make([]*gnfdNode, 0, len(rpcAddrs))
+		for i := range rpcAddrs {
+			nodes = append(nodes, &gnfdNode{
+				provider: rpcAddrs[i],
+				clients: sdkclient.NewGnfdCompositClients(
+					[]string{grpcAddrs[i]},
+					[]string{rpcAddrs[i]},
+					cfg.GreenfieldConfig.ChainIdString,
+					sdkclient.WithKeyManager(km),
+					sdkclient.WithGrpcDialOption(grpcDialOptions...),
+				),
+			})
+		}
+		return clients, nodes
+	}
+	clients, nodes := newClients(cfg.GreenfieldConfig.RPCAddrs, cfg.GreenfieldConfig.GRPCAddrs)
+	relayerAddr := km.GetAddr().String()
+	if cfg.GreenfieldConfig.RelayerAddress != "" {
+		relayerAddr = cfg.GreenfieldConfig.RelayerAddress
+	}
+	rpcTimeout := RPCTimeout
+	if cfg.GreenfieldConfig.RPCTimeoutInSecond > 0 {
+		rpcTimeout = time.Duration(cfg.GreenfieldConfig.RPCTimeoutInSecond) * time.Second
+	}
+	e := &GreenfieldExecutor{
+		rpcTimeout:    rpcTimeout,
+		rpcLimiter:    util.NewRateLimiter(cfg.GreenfieldConfig.RPCRateLimit),
 		gnfdClients:   clients,
+		nodes:         nodes,
 		address:       km.GetAddr().String(),
+		signer:        km.GetAddr(),
+		relayerAddr:   relayerAddr,
 		config:        cfg,
 		cdc:           Cdc(),
 		BlsPrivateKey: blsPrivKeyBts,
 		BlsPubKey:     blsPrivKey.PublicKey().Marshal(),
+		feePayers:     newFeePayerPool(&cfg.GreenfieldConfig, grpcDialOptions),
+		newClients:    newClients,
+
+		grpcDialOptions: grpcDialOptions,
+	}
+	e.validatorCache = newValidatorCache(ValidatorCacheMaxAge, e.queryLatestValidators)
+	return e
+}
+
+// ForRole returns the executor of the role, which sends requests to the dedicated endpoints of the role and shares
+// keys, caches and fee payers with e. e itself is returned if the role has no dedicated endpoints.
+func (e *GreenfieldExecutor) ForRole(role string) *GreenfieldExecutor {
+	endpoints := e.config.GreenfieldConfig.RoleEndpoints.Of(role)
+	if len(endpoints.RPCAddrs) == 0 {
+		return e
+	}
+	clients, nodes := e.newClients(endpoints.RPCAddrs, endpoints.GRPCAddrs)
+	return &GreenfieldExecutor{
+		BscExecutor:    e.BscExecutor,
+		gnfdClients:    clients,
+		nodes:          nodes,
+		config:         e.config,
+		address:        e.address,
+		signer:         e.signer,
+		relayerAddr:    e.relayerAddr,
+		validatorCache: e.validatorCache,
+		cdc:            e.cdc,
+		BlsPrivateKey:  e.BlsPrivateKey,
+		BlsPubKey:      e.BlsPubKey,
+		rpcTimeout:     e.rpcTimeout,
+		rpcLimiter:     e.rpcLimiter,
+		feePayers:      e.feePayers,
+		simObserver:    e.simObserver,
+		newClients:     e.newClients,
+
+		grpcDialOptions: e.grpcDialOptions,
 	}
 }
 
@@ -84,6 +190,10 @@ func (e *GreenfieldExecutor) SetBSCExecutor(be *BSCExecutor) {
 	e.BscExecutor = be
 }
 
+func (e *GreenfieldExecutor) SetClaimSimulationObserver(observer ClaimSimulationObserver) {
+	e.simObserver = observer
+}
+
 func getGreenfieldPrivateKey(cfg *config.GreenfieldConfig) string {
 	if cfg.KeyType == config.KeyTypeAWSPrivateKey {
 		result, err := config.GetSecret(cfg.AWSSecretName, cfg.AWSRegion)
@@ -122,20 +232,91 @@ func getGreenfieldBlsPrivateKey(cfg *config.GreenfieldConfig) string {
 	return cfg.BlsPrivateKey
 }
 
+func (e *GreenfieldExecutor) newRPCContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), e.rpcTimeout)
+}
+
 func (e *GreenfieldExecutor) getRpcClient() client.Client {
-	return e.gnfdClients.GetClient().TendermintClient.RpcClient.TmClient
+	return e.limitRpcClient(e.gnfdClients.GetClient().TendermintClient.RpcClient.TmClient)
+}
+
+// limitRpcClient applies the per endpoint rate limit of Tendermint RPC to the client
+func (e *GreenfieldExecutor) limitRpcClient(c client.Client) client.Client {
+	if e.rpcLimiter == nil {
+		return c
+	}
+	return &rateLimitedTmClient{Client: c, limiter: e.rpcLimiter, endpoint: tmEndpoint(c)}
+}
+
+// GetCodec returns the codec for decoding Greenfield types, including those added by RegisterInterfaces
+func (e *GreenfieldExecutor) GetCodec() *codec.ProtoCodec {
+	return e.cdc
 }
 
 func (e *GreenfieldExecutor) GetGnfdClient() *sdkclient.GreenfieldClient {
 	return e.gnfdClients.GetClient().GreenfieldClient
 }
 
+// GetClaimClient returns the client of the node to send claims to. Among nodes which are not catching up and whose
+// latest block is fresh, the one with the highest height is preferred, and the one with the latest block time if heights
+// are equal. Claims sent to a lagging node are likely to fail with sequence or nonce mismatch.
+func (e *GreenfieldExecutor) GetClaimClient() *sdkclient.GreenfieldClient {
+	client, _, _, _ := e.selectClaimNode()
+	return client
+}
+
+// selectClaimNode returns the client and RPC address of the node to send claims to with its latest height and block
+// time. The default client with an empty address and zero block time is returned if no node is fresh.
+func (e *GreenfieldExecutor) selectClaimNode() (*sdkclient.GreenfieldClient, string, int64, time.Time) {
+	statusCh := make(chan *nodeStatus, len(e.nodes))
+	wg := new(sync.WaitGroup)
+	for _, n := range e.nodes {
+		wg.Add(1)
+		go func(n *gnfdNode) {
+			defer wg.Done()
+			ctx, cancel := e.newRPCContext()
+			defer cancel()
+			status, err := e.limitRpcClient(n.clients.GetClient().TendermintClient.RpcClient.TmClient).Status(ctx)
+			if err != nil {
+				logging.Logger.Debugf("failed to get status of Greenfield node %s, err=%s", n.provider, err.Error())
+				return
+			}
+			statusCh <- &nodeStatus{
+				node:       n,
+				height:     status.SyncInfo.LatestBlockHeight,
+				blockTime:  status.SyncInfo.LatestBlockTime,
+				catchingUp: status.SyncInfo.CatchingUp,
+			}
+		}(n)
+	}
+	wg.Wait()
+	close(statusCh)
+
+	var best *nodeStatus
+	for s := range statusCh {
+		if s.catchingUp || time.Since(s.blockTime) > ClaimNodeStaleThreshold {
+			continue
+		}
+		if best == nil || s.height > best.height || (s.height == best.height && s.blockTime.After(best.blockTime)) {
+			best = s
+		}
+	}
+	if best == nil {
+		logging.Logger.Errorf("no fresh Greenfield node found for claims, fall back to the default client")
+		c := e.gnfdClients.GetClient()
+		return c.GreenfieldClient, "", c.Height, time.Time{}
+	}
+	return best.node.clients.GetClient().GreenfieldClient, best.node.provider, best.height, best.blockTime
+}
+
 func (e *GreenfieldExecutor) GetBlockAndBlockResultAtHeight(height int64) (*tmtypes.Block, *ctypes.ResultBlockResults, error) {
-	block, err := e.getRpcClient().Block(context.Background(), &height)
+	ctx, cancel := e.newRPCContext()
+	defer cancel()
+	block, err := e.getRpcClient().Block(ctx, &height)
 	if err != nil {
 		return nil, nil, err
 	}
-	blockResults, err := e.getRpcClient().BlockResults(context.Background(), &height)
+	blockResults, err := e.getRpcClient().BlockResults(ctx, &height)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -146,19 +327,34 @@ func (e *GreenfieldExecutor) GetLatestBlockHeight() (latestHeight uint64, err er
 	return uint64(e.gnfdClients.GetClient().Height), nil
 }
 
+// QueryTendermintLightBlock queries validators and commit at the height concurrently and returns the light block
 func (e *GreenfieldExecutor) QueryTendermintLightBlock(height int64) ([]byte, error) {
-	validators, err := e.getRpcClient().Validators(context.Background(), &height, nil, nil)
-	if err != nil {
-		return nil, err
+	ctx, cancel := e.newRPCContext()
+	defer cancel()
+	rpcClient := e.getRpcClient()
+	var (
+		validators               *ctypes.ResultValidators
+		commit                   *ctypes.ResultCommit
+		validatorsErr, commitErr error
+	)
+	wg := new(sync.WaitGroup)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		validators, validatorsErr = rpcClient.Validators(ctx, &height, nil, nil)
+	}()
+	go func() {
+		defer wg.Done()
+		commit, commitErr = rpcClient.Commit(ctx, &height)
+	}()
+	wg.Wait()
+	if validatorsErr != nil {
+		return nil, validatorsErr
 	}
-	commit, err := e.getRpcClient().Commit(context.Background(), &height)
-	if err != nil {
-		return nil, err
+	if commitErr != nil {
+		return nil, commitErr
 	}
 	validatorSet := tmtypes.NewValidatorSet(validators.Validators)
-	if err != nil {
-		return nil, err
-	}
 	lightBlock := tmtypes.LightBlock{
 		SignedHeader: &commit.SignedHeader,
 		ValidatorSet: validatorSet,
@@ -178,6 +374,7 @@ func (e *GreenfieldExecutor) GetNextDeliverySequenceForChannelWithRetry(channelI
 	}, relayercommon.RtyAttem,
 		relayercommon.RtyDelay,
 		relayercommon.RtyErr,
+		relayercommon.RtyBudget(relayercommon.SubsystemGreenfieldExecutor),
 		retry.OnRetry(func(n uint, err error) {
 			logging.Logger.Errorf("failed to query receive sequence for channel %d, attempt: %d times, max_attempts: %d", channelID, n+1, relayercommon.RtyAttNum)
 		}))
@@ -199,14 +396,17 @@ func (e *GreenfieldExecutor) GetNextSendSequenceForChannelWithRetry(channelID ty
 	}, relayercommon.RtyAttem,
 		relayercommon.RtyDelay,
 		relayercommon.RtyErr,
+		relayercommon.RtyBudget(relayercommon.SubsystemGreenfieldExecutor),
 		retry.OnRetry(func(n uint, err error) {
 			logging.Logger.Errorf("failed to query send sequence for channel %d, attempt: %d times, max_attempts: %d", channelID, n+1, relayercommon.RtyAttNum)
 		}))
 }
 
 func (e *GreenfieldExecutor) getNextSendSequenceForChannel(channelId types.ChannelId) (uint64, error) {
+	ctx, cancel := e.newRPCContext()
+	defer cancel()
 	res, err := e.GetGnfdClient().SendSequence(
-		context.Background(),
+		ctx,
 		&crosschaintypes.QuerySendSequenceRequest{ChannelId: uint32(channelId)},
 	)
 	if err != nil {
@@ -217,8 +417,19 @@ func (e *GreenfieldExecutor) getNextSendSequenceForChannel(channelId types.Chann
 
 // GetNextReceiveOracleSequence gets the next receive Oracle sequence from Greenfield
 func (e *GreenfieldExecutor) GetNextReceiveOracleSequence() (uint64, error) {
-	res, err := e.GetGnfdClient().CrosschainQueryClient.ReceiveSequence(
-		context.Background(),
+	return e.getNextReceiveOracleSequence(e.GetGnfdClient(), 0)
+}
+
+// getNextReceiveOracleSequence queries the next oracle sequence on the node of the client at the height, or at the
+// latest height if the height is 0
+func (e *GreenfieldExecutor) getNextReceiveOracleSequence(client *sdkclient.GreenfieldClient, height uint64) (uint64, error) {
+	ctx, cancel := e.newRPCContext()
+	defer cancel()
+	if height != 0 {
+		ctx = metadata.AppendToOutgoingContext(ctx, grpctypes.GRPCBlockHeightHeader, strconv.FormatUint(height, 10))
+	}
+	res, err := client.CrosschainQueryClient.ReceiveSequence(
+		ctx,
 		&crosschaintypes.QueryReceiveSequenceRequest{ChannelId: uint32(relayercommon.OracleChannelId)},
 	)
 	if err != nil {
@@ -229,8 +440,10 @@ func (e *GreenfieldExecutor) GetNextReceiveOracleSequence() (uint64, error) {
 
 // GetNextReceiveSequenceForChannel gets the sequence specifically for bsc -> gnfd package's channel from Greenfield
 func (e *GreenfieldExecutor) GetNextReceiveSequenceForChannel(channelId types.ChannelId) (uint64, error) {
+	ctx, cancel := e.newRPCContext()
+	defer cancel()
 	res, err := e.GetGnfdClient().ReceiveSequence(
-		context.Background(),
+		ctx,
 		&crosschaintypes.QueryReceiveSequenceRequest{ChannelId: uint32(channelId)},
 	)
 	if err != nil {
@@ -239,17 +452,37 @@ func (e *GreenfieldExecutor) GetNextReceiveSequenceForChannel(channelId types.Ch
 	return res.Sequence, nil
 }
 
-func (e *GreenfieldExecutor) queryLatestValidators() ([]*tmtypes.Validator, error) {
-	validators, err := e.getRpcClient().Validators(context.Background(), nil, nil, nil)
+// IsChannelSendAllowed returns whether Greenfield allows sending packages to BSC over the channel, the permission is
+// read from the store of the crosschain module since it is not exposed by queries
+func (e *GreenfieldExecutor) IsChannelSendAllowed(channelId types.ChannelId) (bool, error) {
+	ctx, cancel := e.newRPCContext()
+	defer cancel()
+	key := crosschaintypes.BuildChannelPermissionKey(sdk.ChainID(e.getSrcChainId()), sdk.ChannelID(channelId))
+	res, err := e.getRpcClient().ABCIQuery(ctx, fmt.Sprintf("/store/%s/key", crosschaintypes.StoreKey), key)
 	if err != nil {
-		return nil, err
+		return false, err
 	}
-	return validators.Validators, nil
+	if res.Response.Code != 0 {
+		return false, fmt.Errorf("query channel permission failed, code=%d, log=%s", res.Response.Code, res.Response.Log)
+	}
+	return len(res.Response.Value) != 0 && sdk.ChannelPermission(res.Response.Value[0]) == sdk.ChannelAllow, nil
+}
+
+func (e *GreenfieldExecutor) queryLatestValidators() ([]*tmtypes.Validator, uint64, error) {
+	ctx, cancel := e.newRPCContext()
+	defer cancel()
+	validators, err := e.getRpcClient().Validators(ctx, nil, nil, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	return validators.Validators, uint64(validators.BlockHeight), nil
 }
 
 func (e *GreenfieldExecutor) QueryValidatorsAtHeight(height uint64) ([]*tmtypes.Validator, error) {
+	ctx, cancel := e.newRPCContext()
+	defer cancel()
 	h := int64(height)
-	validators, err := e.getRpcClient().Validators(context.Background(), &h, nil, nil)
+	validators, err := e.getRpcClient().Validators(ctx, &h, nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -257,26 +490,12 @@ func (e *GreenfieldExecutor) QueryValidatorsAtHeight(height uint64) ([]*tmtypes.
 }
 
 func (e *GreenfieldExecutor) QueryCachedLatestValidators() ([]*tmtypes.Validator, error) {
-	if len(e.validators) != 0 {
-		return e.validators, nil
-	}
-	validators, err := e.queryLatestValidators()
-	if err != nil {
-		return nil, err
-	}
-	return validators, nil
+	return e.validatorCache.get(time.Now())
 }
 
-func (e *GreenfieldExecutor) UpdateCachedLatestValidatorsLoop() {
-	ticker := time.NewTicker(UpdateCachedValidatorsInterval)
-	for range ticker.C {
-		validators, err := e.queryLatestValidators()
-		if err != nil {
-			logging.Logger.Errorf("update latest greenfield validators error, err=%s", err)
-			continue
-		}
-		e.validators = validators
-	}
+// InvalidateCachedValidators drops cached validators queried before the height the validator set changes at
+func (e *GreenfieldExecutor) InvalidateCachedValidators(height uint64) {
+	e.validatorCache.invalidate(height)
 }
 
 func (e *GreenfieldExecutor) GetValidatorsBlsPublicKey() ([]string, error) {
@@ -291,13 +510,28 @@ func (e *GreenfieldExecutor) GetValidatorsBlsPublicKey() ([]string, error) {
 	return keys, nil
 }
 
+// GetNonce returns the nonce of the relayer account from the node claims are sent to
 func (e *GreenfieldExecutor) GetNonce() (uint64, error) {
-	return e.GetGnfdClient().GetNonce()
+	return e.GetClaimClient().GetNonce()
 }
 
-func (e *GreenfieldExecutor) ClaimPackages(client *sdkclient.GreenfieldClient, payloadBts []byte, aggregatedSig []byte, voteAddressSet []uint64, claimTs int64, oracleSeq uint64, nonce uint64) (string, error) {
-	msgClaim := oracletypes.NewMsgClaim(
-		e.address,
+// claimPackages submits the claim to the node of the provider by the signing account with the given nonce, or by the
+// fee payers if configured, in which case the nonce is ignored. Claims are on behalf of the validator's relayer address,
+// wrapped in authz MsgExec if it is delegated to another account.
+func (e *GreenfieldExecutor) claimPackages(client *sdkclient.GreenfieldClient, provider string, payloadBts []byte, aggregatedSig []byte, voteAddressSet []uint64, claimTs int64, oracleSeq uint64, nonce uint64) (string, error) {
+	if err := validateClaimTimestamp(payloadBts, claimTs); err != nil {
+		return "", err
+	}
+	msgClaim := e.newMsgClaim(payloadBts, aggregatedSig, voteAddressSet, claimTs, oracleSeq)
+	if e.feePayers != nil {
+		return e.claimByFeePayer(provider, msgClaim)
+	}
+	return e.broadcastClaim(client, e.claimMsgs(msgClaim), nonce)
+}
+
+func (e *GreenfieldExecutor) newMsgClaim(payloadBts []byte, aggregatedSig []byte, voteAddressSet []uint64, claimTs int64, oracleSeq uint64) *oracletypes.MsgClaim {
+	return oracletypes.NewMsgClaim(
+		e.relayerAddr,
 		e.getSrcChainId(),
 		e.getDestChainId(),
 		oracleSeq,
@@ -306,50 +540,314 @@ func (e *GreenfieldExecutor) ClaimPackages(client *sdkclient.GreenfieldClient, p
 		voteAddressSet,
 		aggregatedSig,
 	)
-	txRes, err := client.BroadcastTx(
-		[]sdk.Msg{msgClaim},
-		&sdktypes.TxOption{
-			NoSimulate: true,
-			GasLimit:   e.config.GreenfieldConfig.GasLimit,
-			FeeAmount:  sdk.NewCoins(sdk.NewCoin(sdktypes.Denom, sdk.NewInt(int64(e.config.GreenfieldConfig.FeeAmount)))),
-			Nonce:      nonce,
-		},
-	)
+}
+
+// claimMsgs returns the msgs of the claim sent by the signing account, the claim is wrapped in authz MsgExec if it is
+// delegated to the signing account
+func (e *GreenfieldExecutor) claimMsgs(msgClaim *oracletypes.MsgClaim) []sdk.Msg {
+	if e.isDelegated() {
+		msgExec := authz.NewMsgExec(e.signer, []sdk.Msg{msgClaim})
+		return []sdk.Msg{&msgExec}
+	}
+	return []sdk.Msg{msgClaim}
+}
+
+// ClaimsByFeePayers reports whether claims are sent by fee payers, in which case the nonce of the signing account is not used
+func (e *GreenfieldExecutor) ClaimsByFeePayers() bool {
+	return e.feePayers != nil
+}
+
+func (e *GreenfieldExecutor) isDelegated() bool {
+	return ethcommon.HexToAddress(e.relayerAddr) != ethcommon.HexToAddress(e.address)
+}
+
+// ValidateRelayerDelegation panics if claims are delegated by the relayer address to the signing account or to fee
+// payers, but the relayer address has not granted each of them to claim via authz
+func (e *GreenfieldExecutor) ValidateRelayerDelegation() {
+	if e.feePayers != nil {
+		for _, payer := range e.feePayers.payers {
+			e.validateClaimGrant(payer.address.String())
+		}
+		return
+	}
+	if !e.isDelegated() {
+		return
+	}
+	e.validateClaimGrant(e.address)
+}
+
+func (e *GreenfieldExecutor) validateClaimGrant(grantee string) {
+	ctx, cancel := e.newRPCContext()
+	defer cancel()
+	res, err := e.GetGnfdClient().AuthzQueryClient.Grants(ctx, &authz.QueryGrantsRequest{
+		Granter:    e.relayerAddr,
+		Grantee:    grantee,
+		MsgTypeUrl: sdk.MsgTypeURL(&oracletypes.MsgClaim{}),
+	})
+	if err != nil {
+		panic(fmt.Sprintf("failed to query delegation from relayer %s to %s, err=%s", e.relayerAddr, grantee, err.Error()))
+	}
+	if len(res.Grants) == 0 {
+		panic(fmt.Sprintf("relayer %s has not granted %s to claim", e.relayerAddr, grantee))
+	}
+}
+
+func (e *GreenfieldExecutor) broadcastClaim(client *sdkclient.GreenfieldClient, msgs []sdk.Msg, nonce uint64) (string, error) {
+	if e.config.GreenfieldConfig.SimulateClaims {
+		if err := e.simulateClaim(client, msgs, nonce); err != nil {
+			return "", err
+		}
+	}
+	txOpt, err := e.getTxOption(client, msgs, nonce)
+	if err != nil {
+		return "", err
+	}
+	txOpt.Memo = e.config.GreenfieldConfig.ClaimMemo
+	txRes, err := client.BroadcastTx(msgs, txOpt)
 	if err != nil {
 		return "", err
 	}
 	if txRes.TxResponse.Code != 0 {
-		return "", fmt.Errorf("claim error, code=%d, log=%s", txRes.TxResponse.Code, txRes.TxResponse.RawLog)
+		return "", &ClaimTxError{
+			Err:         classifyClaimError(txRes.TxResponse.Codespace, txRes.TxResponse.Code, txRes.TxResponse.RawLog),
+			RawResponse: txRes.TxResponse.String(),
+		}
 	}
 	return txRes.TxResponse.TxHash, nil
 }
 
+// simulateClaim simulates the claim against the node, so that an invalid claim is caught before paying fees
+func (e *GreenfieldExecutor) simulateClaim(client *sdkclient.GreenfieldClient, msgs []sdk.Msg, nonce uint64) error {
+	_, err := client.SimulateTx(msgs, &sdktypes.TxOption{Nonce: nonce})
+	if e.simObserver != nil {
+		e.simObserver(err == nil)
+	}
+	if err != nil {
+		logging.Logger.Errorf("claim simulation failed, nonce=%d, err=%s", nonce, err.Error())
+		return classifySimulationError(err)
+	}
+	return nil
+}
+
+// getTxOption decides the gas limit and fee of the tx by the configured fee strategy
+func (e *GreenfieldExecutor) getTxOption(client *sdkclient.GreenfieldClient, msgs []sdk.Msg, nonce uint64) (*sdktypes.TxOption, error) {
+	cfg := e.config.GreenfieldConfig
+	denom := sdktypes.Denom
+	if cfg.FeeDenom != "" {
+		denom = cfg.FeeDenom
+	}
+	gasLimit := cfg.GasLimit
+	fee := sdk.NewIntFromUint64(cfg.FeeAmount)
+	switch cfg.FeeStrategy {
+	case config.FeeStrategyGasPrice, config.FeeStrategySimulate:
+		if cfg.FeeStrategy == config.FeeStrategySimulate {
+			res, err := client.SimulateTx(msgs, &sdktypes.TxOption{Nonce: nonce})
+			if err != nil {
+				return nil, fmt.Errorf("failed to simulate tx, err=%s", err.Error())
+			}
+			adjustment := config.DefaultGasAdjustment
+			if cfg.GasAdjustment > 0 {
+				adjustment = cfg.GasAdjustment
+			}
+			gasLimit = uint64(float64(res.GasInfo.GasUsed) * adjustment)
+		}
+		gasPrice, err := sdk.NewDecFromStr(cfg.GasPrice)
+		if err != nil {
+			return nil, err
+		}
+		fee = gasPrice.MulInt64(int64(gasLimit)).Ceil().TruncateInt()
+	}
+	return &sdktypes.TxOption{
+		NoSimulate: true,
+		GasLimit:   gasLimit,
+		FeeAmount:  sdk.NewCoins(sdk.NewCoin(denom, fee)),
+		Nonce:      nonce,
+	}, nil
+}
+
+// transferOut transfers the amount of BNB from the account of the client to the BSC address by a cross-chain transfer,
+// and returns the hash of the tx
+func (e *GreenfieldExecutor) transferOut(client *sdkclient.GreenfieldClient, from, to string, amount sdk.Int) (string, error) {
+	coin := sdk.NewCoin(sdktypes.Denom, amount)
+	msgs := []sdk.Msg{bridgetypes.NewMsgTransferOut(from, to, &coin)}
+	nonce, err := client.GetNonce()
+	if err != nil {
+		return "", err
+	}
+	txOpt, err := e.getTxOption(client, msgs, nonce)
+	if err != nil {
+		return "", err
+	}
+	txRes, err := client.BroadcastTx(msgs, txOpt)
+	if err != nil {
+		return "", err
+	}
+	if txRes.TxResponse.Code != 0 {
+		return "", fmt.Errorf("transfer out tx failed, code=%d, log=%s", txRes.TxResponse.Code, txRes.TxResponse.RawLog)
+	}
+	return txRes.TxResponse.TxHash, nil
+}
+
+// GetTx returns the result of the committed tx
+func (e *GreenfieldExecutor) GetTx(txHash string) (*ctypes.ResultTx, error) {
+	hash, err := hex.DecodeString(txHash)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := e.newRPCContext()
+	defer cancel()
+	return e.getRpcClient().Tx(ctx, hash, false)
+}
+
 func (e *GreenfieldExecutor) GetInturnRelayer() (*oracletypes.QueryInturnRelayerResponse, error) {
-	return e.GetGnfdClient().OracleQueryClient.InturnRelayer(context.Background(), &oracletypes.QueryInturnRelayerRequest{})
+	return e.getInturnRelayer(e.GetGnfdClient())
+}
+
+func (e *GreenfieldExecutor) getInturnRelayer(client *sdkclient.GreenfieldClient) (*oracletypes.QueryInturnRelayerResponse, error) {
+	ctx, cancel := e.newRPCContext()
+	defer cancel()
+	return client.OracleQueryClient.InturnRelayer(ctx, &oracletypes.QueryInturnRelayerRequest{})
 }
 
+// QueryVotesByEventHashAndType queries votes of the event page by page, so that the votes of a large validator set are
+// not truncated by the response limit of the node. Nodes without pagination return all votes on every page.
 func (e *GreenfieldExecutor) QueryVotesByEventHashAndType(eventHash []byte, eventType votepool.EventType) ([]*votepool.Vote, error) {
-	queryMap := make(map[string]interface{})
-	queryMap[VotePoolQueryParameterEventType] = int(eventType)
-	queryMap[VotePoolQueryParameterEventHash] = eventHash
-	var queryVote ctypes.ResultQueryVote
-	_, err := e.gnfdClients.GetClient().JsonRpcClient.Call(context.Background(), VotePoolQueryMethodName, queryMap, &queryVote)
+	return e.queryVotes(e.gnfdClients.GetClient(), eventHash, eventType)
+}
+
+func (e *GreenfieldExecutor) queryVotes(c *sdkclient.GnfdCompositeClient, eventHash []byte, eventType votepool.EventType) ([]*votepool.Vote, error) {
+	perPage := relayercommon.DefaultVotePoolQueryPageSize
+	if e.config.VotePoolConfig.QueryPageSize > 0 {
+		perPage = e.config.VotePoolConfig.QueryPageSize
+	}
+	return collectVotePages(perPage, func(page int) ([]*votepool.Vote, error) {
+		ctx, cancel := e.newRPCContext()
+		defer cancel()
+		queryMap := make(map[string]interface{})
+		queryMap[VotePoolQueryParameterEventType] = int(eventType)
+		queryMap[VotePoolQueryParameterEventHash] = eventHash
+		queryMap[VotePoolQueryParameterPage] = page
+		queryMap[VotePoolQueryParameterPerPage] = perPage
+		var queryVote ctypes.ResultQueryVote
+		if err := e.rpcLimiter.Wait(ctx, tmEndpoint(c.TendermintClient.RpcClient.TmClient)); err != nil {
+			return nil, err
+		}
+		if _, err := c.JsonRpcClient.Call(ctx, VotePoolQueryMethodName, queryMap, &queryVote); err != nil {
+			return nil, err
+		}
+		return queryVote.Votes, nil
+	})
+}
+
+// CountVotesByEventHashAndType returns the number of votes of the event in the vote pool
+func (e *GreenfieldExecutor) CountVotesByEventHashAndType(eventHash []byte, eventType votepool.EventType) (int, error) {
+	votes, err := e.QueryVotesByEventHashAndType(eventHash, eventType)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
-	return queryVote.Votes, nil
+	return len(votes), nil
 }
 
 func (e *GreenfieldExecutor) BroadcastVote(v *votepool.Vote) error {
+	ctx, cancel := e.newRPCContext()
+	defer cancel()
 	broadcastMap := make(map[string]interface{})
 	broadcastMap[VotePoolBroadcastParameterKey] = *v
-	_, err := e.gnfdClients.GetClient().JsonRpcClient.Call(context.Background(), VotePoolBroadcastMethodName, broadcastMap, &ctypes.ResultBroadcastVote{})
+	c := e.gnfdClients.GetClient()
+	if err := e.rpcLimiter.Wait(ctx, tmEndpoint(c.TendermintClient.RpcClient.TmClient)); err != nil {
+		return err
+	}
+	_, err := c.JsonRpcClient.Call(ctx, VotePoolBroadcastMethodName, broadcastMap, &ctypes.ResultBroadcastVote{})
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// VotePoolProbe is the availability of the vote pool RPC methods of a Greenfield node
+type VotePoolProbe struct {
+	Provider     string
+	QueryErr     error
+	BroadcastErr error // nil if the broadcast is not probed
+}
+
+// ProbeVotePools calls the vote pool query method of every node, and re-broadcasts v to every node if it is not nil.
+// Broadcasting a vote already in the pool is accepted without side effects.
+func (e *GreenfieldExecutor) ProbeVotePools(v *votepool.Vote) []*VotePoolProbe {
+	eventHash := make([]byte, 32)
+	eventType := votepool.FromBscCrossChainEvent
+	if v != nil {
+		eventHash, eventType = v.EventHash, v.EventType
+	}
+	probes := make([]*VotePoolProbe, len(e.nodes))
+	wg := new(sync.WaitGroup)
+	for i, n := range e.nodes {
+		wg.Add(1)
+		go func(i int, n *gnfdNode) {
+			defer wg.Done()
+			probe := &VotePoolProbe{Provider: n.provider}
+			probes[i] = probe
+			c := n.clients.GetClient()
+			endpoint := tmEndpoint(c.TendermintClient.RpcClient.TmClient)
+
+			ctx, cancel := e.newRPCContext()
+			defer cancel()
+			if probe.QueryErr = e.rpcLimiter.Wait(ctx, endpoint); probe.QueryErr == nil {
+				queryMap := map[string]interface{}{
+					VotePoolQueryParameterEventType: int(eventType),
+					VotePoolQueryParameterEventHash: eventHash,
+				}
+				_, probe.QueryErr = c.JsonRpcClient.Call(ctx, VotePoolQueryMethodName, queryMap, &ctypes.ResultQueryVote{})
+			}
+			if v == nil {
+				return
+			}
+			broadcastCtx, broadcastCancel := e.newRPCContext()
+			defer broadcastCancel()
+			if probe.BroadcastErr = e.rpcLimiter.Wait(broadcastCtx, endpoint); probe.BroadcastErr == nil {
+				broadcastMap := map[string]interface{}{VotePoolBroadcastParameterKey: *v}
+				_, probe.BroadcastErr = c.JsonRpcClient.Call(broadcastCtx, VotePoolBroadcastMethodName, broadcastMap, &ctypes.ResultBroadcastVote{})
+			}
+		}(i, n)
+	}
+	wg.Wait()
+	return probes
+}
+
+// VoteGossipProbe tells whether a vote is queryable in the vote pool of a Greenfield node
+type VoteGossipProbe struct {
+	Provider string
+	Found    bool
+	Err      error
+}
+
+// ProbeVoteGossip queries the vote pool of every node for v, a vote broadcast to one node is found on the others once
+// it is gossiped to them
+func (e *GreenfieldExecutor) ProbeVoteGossip(v *votepool.Vote) []*VoteGossipProbe {
+	probes := make([]*VoteGossipProbe, len(e.nodes))
+	wg := new(sync.WaitGroup)
+	for i, n := range e.nodes {
+		wg.Add(1)
+		go func(i int, n *gnfdNode) {
+			defer wg.Done()
+			probe := &VoteGossipProbe{Provider: n.provider}
+			probes[i] = probe
+			var votes []*votepool.Vote
+			if votes, probe.Err = e.queryVotes(n.clients.GetClient(), v.EventHash, v.EventType); probe.Err != nil {
+				return
+			}
+			for _, queried := range votes {
+				if bytes.Equal(queried.PubKey, v.PubKey) {
+					probe.Found = true
+					return
+				}
+			}
+		}(i, n)
+	}
+	wg.Wait()
+	return probes
+}
+
 func (e *GreenfieldExecutor) getDestChainId() uint32 {
 	return uint32(e.config.GreenfieldConfig.ChainId)
 }
@@ -76,3 +76,8 @@ func TestGetLatestValidators(t *testing.T) {
 		t.Logf("relayer bls pub key %s", hex.EncodeToString(validator.BlsKey))
 	}
 }
+
+func TestValidateRelayerDelegation(t *testing.T) {
+	e := InitGnfdExecutor()
+	require.NotPanics(t, e.ValidateRelayerDelegation)
+}
@@ -0,0 +1,80 @@
+package executor
+
+import (
+	"time"
+
+	"github.com/avast/retry-go/v4"
+	oracletypes "github.com/cosmos/cosmos-sdk/x/oracle/types"
+
+	sdkclient "github.com/bnb-chain/greenfield-go-sdk/client/chain"
+	relayercommon "github.com/bnb-chain/greenfield-relayer/common"
+	"github.com/bnb-chain/greenfield-relayer/logging"
+)
+
+// GreenfieldSnapshot pins the node claims are sent to for one round of claims, so that the in-turn relayer, the nonce
+// and the claims of the round are all from the same node and are not mixed up by a node switch in the middle of it
+type GreenfieldSnapshot struct {
+	executor  *GreenfieldExecutor
+	client    *sdkclient.GreenfieldClient
+	provider  string // RPC address of the node, empty for the default client
+	height    uint64
+	blockTime time.Time // latest block time of the node, zero if unknown
+	nonce     *uint64
+}
+
+// NewSnapshot chooses the node to send claims to as GetClaimClient does, and pins it in the returned snapshot
+func (e *GreenfieldExecutor) NewSnapshot() *GreenfieldSnapshot {
+	client, provider, height, blockTime := e.selectClaimNode()
+	return &GreenfieldSnapshot{
+		executor:  e,
+		client:    client,
+		provider:  provider,
+		height:    uint64(height),
+		blockTime: blockTime,
+	}
+}
+
+// Height returns the latest height of the node when the snapshot is taken
+func (s *GreenfieldSnapshot) Height() uint64 {
+	return s.height
+}
+
+func (s *GreenfieldSnapshot) GetInturnRelayer() (*oracletypes.QueryInturnRelayerResponse, error) {
+	return s.executor.getInturnRelayer(s.client)
+}
+
+// GetNonce returns the nonce of the relayer account on the node, it is queried once per snapshot
+func (s *GreenfieldSnapshot) GetNonce() (uint64, error) {
+	if s.nonce != nil {
+		return *s.nonce, nil
+	}
+	nonce, err := s.client.GetNonce()
+	if err != nil {
+		return 0, err
+	}
+	s.nonce = &nonce
+	return nonce, nil
+}
+
+// GetNextReceiveOracleSequence returns the next oracle sequence on the node at the height of the snapshot, so that it is
+// consistent with the in-turn relayer and the nonce of the snapshot
+func (s *GreenfieldSnapshot) GetNextReceiveOracleSequence() (sequence uint64, err error) {
+	return sequence, retry.Do(func() error {
+		sequence, err = s.executor.getNextReceiveOracleSequence(s.client, s.height)
+		return err
+	}, relayercommon.RtyAttem,
+		relayercommon.RtyDelay,
+		relayercommon.RtyErr,
+		relayercommon.RtyBudget(relayercommon.SubsystemGreenfieldExecutor),
+		retry.OnRetry(func(n uint, err error) {
+			logging.Logger.Errorf("failed to query oracle sequence on node %s at height %d, attempt: %d times, max_attempts: %d",
+				s.provider, s.height, n+1, relayercommon.RtyAttNum)
+		}))
+}
+
+// ClaimPackages submits the claim to the node of the snapshot, by fee payers if configured, in which case the nonce is
+// ignored. Claims are on behalf of the validator's relayer address, wrapped in authz MsgExec if it is delegated to
+// another account.
+func (s *GreenfieldSnapshot) ClaimPackages(payloadBts []byte, aggregatedSig []byte, voteAddressSet []uint64, claimTs int64, oracleSeq uint64, nonce uint64) (string, error) {
+	return s.executor.claimPackages(s.client, s.provider, payloadBts, aggregatedSig, voteAddressSet, claimTs, oracleSeq, nonce)
+}
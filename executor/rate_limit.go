@@ -0,0 +1,98 @@
+package executor
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/tendermint/tendermint/rpc/client"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	"google.golang.org/grpc"
+
+	"github.com/bnb-chain/greenfield-relayer/util"
+)
+
+// rateLimitedTransport limits http requests to the endpoint, used for BSC JSON-RPC over http
+type rateLimitedTransport struct {
+	limiter  *util.RateLimiter
+	endpoint string
+	base     http.RoundTripper
+}
+
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.Wait(req.Context(), t.endpoint); err != nil {
+		return nil, err
+	}
+	return t.base.RoundTrip(req)
+}
+
+func isHTTPEndpoint(provider string) bool {
+	return strings.HasPrefix(provider, "http://") || strings.HasPrefix(provider, "https://")
+}
+
+// rateLimitInterceptor limits unary gRPC calls per target
+func rateLimitInterceptor(limiter *util.RateLimiter) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if err := limiter.Wait(ctx, cc.Target()); err != nil {
+			return err
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// rateLimitedTmClient limits the Tendermint RPC calls made by the executor
+type rateLimitedTmClient struct {
+	client.Client
+	limiter  *util.RateLimiter
+	endpoint string
+}
+
+// tmEndpoint returns the remote address of the Tendermint RPC client
+func tmEndpoint(c client.Client) string {
+	if r, ok := c.(interface{ Remote() string }); ok {
+		return r.Remote()
+	}
+	return ""
+}
+
+func (c *rateLimitedTmClient) Status(ctx context.Context) (*ctypes.ResultStatus, error) {
+	if err := c.limiter.Wait(ctx, c.endpoint); err != nil {
+		return nil, err
+	}
+	return c.Client.Status(ctx)
+}
+
+func (c *rateLimitedTmClient) Block(ctx context.Context, height *int64) (*ctypes.ResultBlock, error) {
+	if err := c.limiter.Wait(ctx, c.endpoint); err != nil {
+		return nil, err
+	}
+	return c.Client.Block(ctx, height)
+}
+
+func (c *rateLimitedTmClient) BlockResults(ctx context.Context, height *int64) (*ctypes.ResultBlockResults, error) {
+	if err := c.limiter.Wait(ctx, c.endpoint); err != nil {
+		return nil, err
+	}
+	return c.Client.BlockResults(ctx, height)
+}
+
+func (c *rateLimitedTmClient) Commit(ctx context.Context, height *int64) (*ctypes.ResultCommit, error) {
+	if err := c.limiter.Wait(ctx, c.endpoint); err != nil {
+		return nil, err
+	}
+	return c.Client.Commit(ctx, height)
+}
+
+func (c *rateLimitedTmClient) Validators(ctx context.Context, height *int64, page, perPage *int) (*ctypes.ResultValidators, error) {
+	if err := c.limiter.Wait(ctx, c.endpoint); err != nil {
+		return nil, err
+	}
+	return c.Client.Validators(ctx, height, page, perPage)
+}
+
+func (c *rateLimitedTmClient) Tx(ctx context.Context, hash []byte, prove bool) (*ctypes.ResultTx, error) {
+	if err := c.limiter.Wait(ctx, c.endpoint); err != nil {
+		return nil, err
+	}
+	return c.Client.Tx(ctx, hash, prove)
+}
@@ -0,0 +1,106 @@
+package executor
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	sdkclient "github.com/bnb-chain/greenfield-go-sdk/client/chain"
+	sdkkeys "github.com/bnb-chain/greenfield-go-sdk/keys"
+)
+
+// RelayerRegistration is the relayer address and BLS key registered for a validator on Greenfield
+type RelayerRegistration struct {
+	Validator      string
+	RelayerAddress string
+	BlsKey         string // hex encoded
+}
+
+// RelayerAddress returns the address claims of the relayer are sent for
+func (e *GreenfieldExecutor) RelayerAddress() string {
+	return e.relayerAddr
+}
+
+// newOperatorClient returns the client signing with the private key of the validator operator, which is only used to
+// send the txs of the validator and never kept by the relayer
+func (e *GreenfieldExecutor) newOperatorClient(operatorPrivKey string) (*sdkclient.GreenfieldClient, sdk.AccAddress, error) {
+	km, err := sdkkeys.NewPrivateKeyManager(operatorPrivKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid validator private key, err=%s", err.Error())
+	}
+	clients := sdkclient.NewGnfdCompositClients(
+		e.config.GreenfieldConfig.GRPCAddrs,
+		e.config.GreenfieldConfig.RPCAddrs,
+		e.config.GreenfieldConfig.ChainIdString,
+		sdkclient.WithKeyManager(km),
+		sdkclient.WithGrpcDialOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	return clients.GetClient().GreenfieldClient, km.GetAddr(), nil
+}
+
+// GetRelayerRegistration returns the relayer address and BLS key currently registered for the validator
+func (e *GreenfieldExecutor) GetRelayerRegistration(validator string) (*RelayerRegistration, error) {
+	ctx, cancel := e.newRPCContext()
+	defer cancel()
+	res, err := e.GetGnfdClient().StakingQueryClient.Validator(ctx, &stakingtypes.QueryValidatorRequest{ValidatorAddr: validator})
+	if err != nil {
+		return nil, err
+	}
+	return &RelayerRegistration{
+		Validator:      res.Validator.OperatorAddress,
+		RelayerAddress: res.Validator.RelayerAddress,
+		BlsKey:         hex.EncodeToString(res.Validator.BlsKey),
+	}, nil
+}
+
+// UpdateRelayerRegistration registers the relayer address and BLS key for the validator of the operator key by
+// MsgEditValidator, the tx validators send when rotating keys. The registration before the update is returned, no tx is
+// sent if it is unchanged.
+func (e *GreenfieldExecutor) UpdateRelayerRegistration(operatorPrivKey, relayerAddr, blsKey string) (*RelayerRegistration, string, error) {
+	client, operator, err := e.newOperatorClient(operatorPrivKey)
+	if err != nil {
+		return nil, "", err
+	}
+	relayer, err := sdk.AccAddressFromHexUnsafe(relayerAddr)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid relayer address %s, err=%s", relayerAddr, err.Error())
+	}
+	if bz, err := hex.DecodeString(blsKey); err != nil || len(bz) != sdk.BLSPubKeyLength {
+		return nil, "", fmt.Errorf("invalid bls public key %s", blsKey)
+	}
+	registered, err := e.GetRelayerRegistration(operator.String())
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to query validator %s, err=%s", operator.String(), err.Error())
+	}
+	if registered.RelayerAddress == relayer.String() && registered.BlsKey == blsKey {
+		return registered, "", nil
+	}
+
+	description := stakingtypes.NewDescription(stakingtypes.DoNotModifyDesc, stakingtypes.DoNotModifyDesc,
+		stakingtypes.DoNotModifyDesc, stakingtypes.DoNotModifyDesc, stakingtypes.DoNotModifyDesc)
+	msg := stakingtypes.NewMsgEditValidator(operator, description, nil, nil, relayer, nil, blsKey)
+	if err := msg.ValidateBasic(); err != nil {
+		return nil, "", err
+	}
+	msgs := []sdk.Msg{msg}
+	nonce, err := client.GetNonce()
+	if err != nil {
+		return nil, "", err
+	}
+	txOpt, err := e.getTxOption(client, msgs, nonce)
+	if err != nil {
+		return nil, "", err
+	}
+	txRes, err := client.BroadcastTx(msgs, txOpt)
+	if err != nil {
+		return nil, "", err
+	}
+	if txRes.TxResponse.Code != 0 {
+		return nil, "", fmt.Errorf("edit validator tx failed, code=%d, log=%s", txRes.TxResponse.Code, txRes.TxResponse.RawLog)
+	}
+	return registered, txRes.TxResponse.TxHash, nil
+}
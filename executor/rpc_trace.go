@@ -0,0 +1,137 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+
+	relayercommon "github.com/bnb-chain/greenfield-relayer/common"
+	"github.com/bnb-chain/greenfield-relayer/logging"
+)
+
+const MaxRPCTraceDuration = time.Hour
+
+// secretFieldPattern matches JSON fields carrying secrets, their values are redacted from traces
+var secretFieldPattern = regexp.MustCompile(`(?i)("[a-z_]*(private_?key|password|secret|api_?key|token)[a-z_]*"\s*:\s*)"[^"]*"`)
+
+// rpcTracer logs full RPC requests and responses of a subsystem until its trace expires
+type rpcTracer struct {
+	mutex sync.Mutex
+	until map[string]time.Time
+}
+
+var tracer = &rpcTracer{until: make(map[string]time.Time)}
+
+// EnableRPCTrace logs RPC requests and responses of the executor subsystem for the duration, a non-positive duration
+// stops tracing
+func EnableRPCTrace(subsystem string, duration time.Duration) error {
+	if subsystem != relayercommon.SubsystemBSCExecutor && subsystem != relayercommon.SubsystemGreenfieldExecutor {
+		return fmt.Errorf("unexpected subsystem %s, only %s and %s supported", subsystem,
+			relayercommon.SubsystemBSCExecutor, relayercommon.SubsystemGreenfieldExecutor)
+	}
+	if duration > MaxRPCTraceDuration {
+		return fmt.Errorf("trace duration should not exceed %s", MaxRPCTraceDuration)
+	}
+	tracer.mutex.Lock()
+	defer tracer.mutex.Unlock()
+	tracer.until[subsystem] = time.Now().Add(duration)
+	logging.Logger.Infof("rpc trace of %s enabled until %s", subsystem, tracer.until[subsystem].Format(time.RFC3339))
+	return nil
+}
+
+// RPCTraces returns the expiry of traces still enabled by subsystem
+func RPCTraces() map[string]time.Time {
+	tracer.mutex.Lock()
+	defer tracer.mutex.Unlock()
+	traces := make(map[string]time.Time)
+	now := time.Now()
+	for subsystem, until := range tracer.until {
+		if until.After(now) {
+			traces[subsystem] = until
+		}
+	}
+	return traces
+}
+
+func (t *rpcTracer) enabled(subsystem string, now time.Time) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.until[subsystem].After(now)
+}
+
+// redactSecrets removes secret JSON fields from a traced payload
+func redactSecrets(payload string) string {
+	return secretFieldPattern.ReplaceAllString(payload, `$1"***"`)
+}
+
+// redactURL keeps only the scheme and host of an endpoint, providers often embed api keys in paths and queries
+func redactURL(u *url.URL) string {
+	if u.Path == "" && u.RawQuery == "" {
+		return u.Scheme + "://" + u.Host
+	}
+	return u.Scheme + "://" + u.Host + "/***"
+}
+
+// tracingTransport logs http requests and responses of the subsystem while its trace is enabled, used for BSC JSON-RPC
+type tracingTransport struct {
+	subsystem string
+	base      http.RoundTripper
+}
+
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	if !tracer.enabled(t.subsystem, start) {
+		return t.base.RoundTrip(req)
+	}
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		if reqBody, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		logging.Logger.Infof("rpc trace %s: endpoint=%s, request=%s, error=%s, elapsed=%s", t.subsystem, redactURL(req.URL),
+			redactSecrets(string(reqBody)), err.Error(), time.Since(start))
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	if err != nil {
+		return nil, err
+	}
+	logging.Logger.Infof("rpc trace %s: endpoint=%s, request=%s, status=%d, response=%s, elapsed=%s", t.subsystem,
+		redactURL(req.URL), redactSecrets(string(reqBody)), resp.StatusCode, redactSecrets(string(respBody)), time.Since(start))
+	return resp, nil
+}
+
+// tracingInterceptor logs unary gRPC calls of the subsystem while its trace is enabled, used for Greenfield gRPC
+func tracingInterceptor(subsystem string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		if !tracer.enabled(subsystem, start) {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		if err != nil {
+			logging.Logger.Infof("rpc trace %s: target=%s, method=%s, request=%s, error=%s, elapsed=%s", subsystem, cc.Target(),
+				method, redactSecrets(fmt.Sprintf("%v", req)), err.Error(), time.Since(start))
+			return err
+		}
+		logging.Logger.Infof("rpc trace %s: target=%s, method=%s, request=%s, response=%s, elapsed=%s", subsystem, cc.Target(),
+			method, redactSecrets(fmt.Sprintf("%v", req)), redactSecrets(fmt.Sprintf("%v", reply)), time.Since(start))
+		return nil
+	}
+}
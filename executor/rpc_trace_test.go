@@ -0,0 +1,48 @@
+package executor
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	relayercommon "github.com/bnb-chain/greenfield-relayer/common"
+)
+
+func TestRedactSecrets(t *testing.T) {
+	require.Equal(t, `{"private_key": "***", "height":1}`, redactSecrets(`{"private_key": "0xabc", "height":1}`))
+	require.Equal(t, `{"apiKey":"***"}`, redactSecrets(`{"apiKey":"k"}`))
+
+	u, err := url.Parse("https://bsc.example.com/v1/secret-key?token=1")
+	require.NoError(t, err)
+	require.Equal(t, "https://bsc.example.com/***", redactURL(u))
+}
+
+func TestTracingTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+
+	require.Error(t, EnableRPCTrace("unknown", time.Minute))
+	require.Error(t, EnableRPCTrace(relayercommon.SubsystemBSCExecutor, 2*MaxRPCTraceDuration))
+	require.NoError(t, EnableRPCTrace(relayercommon.SubsystemBSCExecutor, time.Minute))
+	require.Contains(t, RPCTraces(), relayercommon.SubsystemBSCExecutor)
+
+	// request and response bodies are still readable after being traced
+	c := &http.Client{Transport: &tracingTransport{subsystem: relayercommon.SubsystemBSCExecutor, base: http.DefaultTransport}}
+	resp, err := c.Post(server.URL, "application/json", strings.NewReader(`{"method":"eth_blockNumber"}`))
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, `{"method":"eth_blockNumber"}`, string(body))
+
+	require.NoError(t, EnableRPCTrace(relayercommon.SubsystemBSCExecutor, 0))
+	require.NotContains(t, RPCTraces(), relayercommon.SubsystemBSCExecutor)
+}
@@ -1,15 +1,88 @@
 package executor
 
 import (
+	"fmt"
+	"strings"
+	"sync"
+
 	"github.com/cosmos/cosmos-sdk/codec"
 	"github.com/cosmos/cosmos-sdk/codec/types"
 	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
 	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
 	oracletypes "github.com/cosmos/cosmos-sdk/x/oracle/types"
 	"github.com/evmos/ethermint/crypto/ethsecp256k1"
+	"github.com/tendermint/tendermint/votepool"
+
+	relayercommon "github.com/bnb-chain/greenfield-relayer/common"
+	"github.com/bnb-chain/greenfield-relayer/logging"
+)
+
+// ClaimTxError is returned when a claim tx is rejected by the chain, it carries the raw tx response for diagnostics
+type ClaimTxError struct {
+	Err         error
+	RawResponse string
+}
+
+func (e *ClaimTxError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ClaimTxError) Unwrap() error {
+	return e.Err
+}
+
+// classifyClaimError wraps the failure of a claim tx with the shared error it stands for, so that callers can branch on
+// the error type
+func classifyClaimError(codespace string, code uint32, rawLog string) error {
+	switch {
+	case codespace == sdkerrors.ErrWrongSequence.Codespace() && code == sdkerrors.ErrWrongSequence.ABCICode():
+		return fmt.Errorf("%w, claim error, code=%d, log=%s", relayercommon.ErrNonceMismatch, code, rawLog)
+	case codespace == oracletypes.ErrInvalidReceiveSequence.Codespace() && code == oracletypes.ErrInvalidReceiveSequence.ABCICode():
+		return fmt.Errorf("%w, claim error, code=%d, log=%s", relayercommon.ErrSequenceMismatch, code, rawLog)
+	default:
+		return fmt.Errorf("claim error, code=%d, log=%s", code, rawLog)
+	}
+}
+
+// classifySimulationError wraps the failure of simulating a claim with the shared error it stands for
+func classifySimulationError(err error) error {
+	if strings.Contains(strings.ToLower(err.Error()), "account sequence mismatch") {
+		return fmt.Errorf("%w, claim simulation error, %s", relayercommon.ErrNonceMismatch, err.Error())
+	}
+	return fmt.Errorf("%w, %s", relayercommon.ErrClaimSimulationFailed, err.Error())
+}
+
+// classifyBSCTxError wraps the failure of sending a BSC tx with the shared error it stands for
+func classifyBSCTxError(err error) error {
+	msg := strings.ToLower(err.Error())
+	if strings.Contains(msg, "nonce too low") || strings.Contains(msg, "nonce too high") {
+		return fmt.Errorf("%w, %s", relayercommon.ErrNonceMismatch, err.Error())
+	}
+	return err
+}
+
+// ClaimSimulationObserver is notified of each claim simulation, passed is false if the claim is rejected
+type ClaimSimulationObserver func(passed bool)
+
+// InterfaceRegistrar registers extra protobuf interfaces and implementations into the codec built by Cdc
+type InterfaceRegistrar func(registry types.InterfaceRegistry)
+
+var (
+	registrarsMutex sync.Mutex
+	registrars      []InterfaceRegistrar
 )
 
+// RegisterInterfaces adds a registrar applied whenever the codec is built by Cdc, so that a custom build can decode
+// types of new Greenfield modules without forking the relayer. It should be called before executors are created, e.g.
+// in an init function of a package linked into the build.
+func RegisterInterfaces(registrar InterfaceRegistrar) {
+	registrarsMutex.Lock()
+	defer registrarsMutex.Unlock()
+	registrars = append(registrars, registrar)
+}
+
 func Cdc() *codec.ProtoCodec {
 	interfaceRegistry := types.NewInterfaceRegistry()
 	interfaceRegistry.RegisterInterface("AccountI", (*authtypes.AccountI)(nil))
@@ -20,5 +93,42 @@ func Cdc() *codec.ProtoCodec {
 	interfaceRegistry.RegisterInterface("cosmos.crypto.PubKey", (*cryptotypes.PubKey)(nil))
 	interfaceRegistry.RegisterImplementations((*cryptotypes.PubKey)(nil), &ethsecp256k1.PubKey{})
 	interfaceRegistry.RegisterImplementations((*sdk.Msg)(nil), &oracletypes.MsgClaim{})
+	registrarsMutex.Lock()
+	for _, registrar := range registrars {
+		registrar(interfaceRegistry)
+	}
+	registrarsMutex.Unlock()
 	return codec.NewProtoCodec(interfaceRegistry)
 }
+
+// collectVotePages fetches pages of votes from 1 until a page is not full, votes are deduplicated by public key. It also
+// stops once a page adds no new votes, which is the case of nodes ignoring pagination.
+func collectVotePages(perPage int, fetch func(page int) ([]*votepool.Vote, error)) ([]*votepool.Vote, error) {
+	votes := make([]*votepool.Vote, 0)
+	seen := make(map[string]struct{})
+	for page := 1; page <= relayercommon.MaxVotePoolQueryPages; page++ {
+		pageVotes, err := fetch(page)
+		if err != nil && page > 1 {
+			// nodes reject pages beyond the last one, e.g. when the last page was just full
+			logging.Logger.Debugf("stop querying votes at page %d, err=%s", page, err.Error())
+			return votes, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		added := 0
+		for _, v := range pageVotes {
+			key := string(v.PubKey)
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			votes = append(votes, v)
+			added++
+		}
+		if len(pageVotes) < perPage || added == 0 {
+			return votes, nil
+		}
+	}
+	return nil, fmt.Errorf("votes are not exhausted after %d pages of %d", relayercommon.MaxVotePoolQueryPages, perPage)
+}
@@ -0,0 +1,80 @@
+package executor
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	oracletypes "github.com/cosmos/cosmos-sdk/x/oracle/types"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/votepool"
+
+	relayercommon "github.com/bnb-chain/greenfield-relayer/common"
+)
+
+func TestRegisterInterfaces(t *testing.T) {
+	_, err := Cdc().InterfaceRegistry().Resolve(sdk.MsgTypeURL(&banktypes.MsgSend{}))
+	require.Error(t, err)
+
+	RegisterInterfaces(func(registry types.InterfaceRegistry) {
+		registry.RegisterImplementations((*sdk.Msg)(nil), &banktypes.MsgSend{})
+	})
+	_, err = Cdc().InterfaceRegistry().Resolve(sdk.MsgTypeURL(&banktypes.MsgSend{}))
+	require.NoError(t, err)
+}
+
+func TestClassifySimulationError(t *testing.T) {
+	err := classifySimulationError(errors.New("rpc error: account sequence mismatch, expected 5, got 4"))
+	require.ErrorIs(t, err, relayercommon.ErrNonceMismatch)
+
+	err = classifySimulationError(errors.New("rpc error: invalid bls signature"))
+	require.ErrorIs(t, err, relayercommon.ErrClaimSimulationFailed)
+}
+
+func TestClassifyClaimError(t *testing.T) {
+	err := classifyClaimError(oracletypes.ErrInvalidReceiveSequence.Codespace(), oracletypes.ErrInvalidReceiveSequence.ABCICode(),
+		"current sequence of channel 0 is 5")
+	require.ErrorIs(t, err, relayercommon.ErrSequenceMismatch)
+
+	// other oracle errors mentioning a sequence are not sequence mismatches
+	err = classifyClaimError(oracletypes.ErrInvalidBlsSignature.Codespace(), oracletypes.ErrInvalidBlsSignature.ABCICode(),
+		"bls signature is invalid, sequence 5")
+	require.NotErrorIs(t, err, relayercommon.ErrSequenceMismatch)
+}
+
+func TestCollectVotePages(t *testing.T) {
+	var all []*votepool.Vote
+	for i := 0; i < 5; i++ {
+		all = append(all, &votepool.Vote{PubKey: []byte{byte(i)}})
+	}
+	paged := func(page int) ([]*votepool.Vote, error) {
+		from := (page - 1) * 2
+		if from >= len(all) {
+			return nil, errors.New("page should be within [1, 3]")
+		}
+		to := from + 2
+		if to > len(all) {
+			to = len(all)
+		}
+		return all[from:to], nil
+	}
+	votes, err := collectVotePages(2, paged)
+	require.NoError(t, err)
+	require.Equal(t, all, votes)
+
+	// the last page is full, the page beyond it is rejected
+	all = all[:4]
+	votes, err = collectVotePages(2, paged)
+	require.NoError(t, err)
+	require.Len(t, votes, 4)
+
+	// pagination is ignored by the node
+	votes, err = collectVotePages(2, func(page int) ([]*votepool.Vote, error) { return all, nil })
+	require.NoError(t, err)
+	require.Equal(t, all, votes)
+
+	_, err = collectVotePages(2, func(page int) ([]*votepool.Vote, error) { return nil, errors.New("timeout") })
+	require.Error(t, err)
+}
@@ -0,0 +1,57 @@
+package executor
+
+import (
+	"sync"
+	"time"
+)
+
+// validatorCache caches the latest validators together with the height they are queried at. The cache is invalidated
+// once a validator set change at a later height is observed, so that validators are not stale while bitsets of votes
+// are built. Entries older than maxAge are re-queried as well, in case changes are not observed, e.g. the listener of
+// the chain is not running.
+type validatorCache[T any] struct {
+	mutex      sync.Mutex
+	validators []T
+	height     uint64    // height the cached validators are queried at
+	queriedAt  time.Time // when the cached validators are queried
+	changedAt  uint64    // latest height a validator set change is observed at
+	maxAge     time.Duration
+	query      func() ([]T, uint64, error) // queries the latest validators and the height they are at
+}
+
+func newValidatorCache[T any](maxAge time.Duration, query func() ([]T, uint64, error)) *validatorCache[T] {
+	return &validatorCache[T]{maxAge: maxAge, query: query}
+}
+
+// get returns the cached validators if they are not older than the latest observed change, otherwise queries them.
+// Validators queried at a height before the change, e.g. the change is not synced to the light client yet, are
+// returned but not cached.
+func (c *validatorCache[T]) get(now time.Time) ([]T, error) {
+	c.mutex.Lock()
+	if c.validators != nil && c.height >= c.changedAt && now.Sub(c.queriedAt) < c.maxAge {
+		validators := c.validators
+		c.mutex.Unlock()
+		return validators, nil
+	}
+	c.mutex.Unlock()
+
+	validators, height, err := c.query()
+	if err != nil {
+		return nil, err
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if height >= c.changedAt {
+		c.validators, c.height, c.queriedAt = validators, height, now
+	}
+	return validators, nil
+}
+
+// invalidate drops the cached validators if they are queried before the height a validator set change is observed at
+func (c *validatorCache[T]) invalidate(height uint64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if height > c.changedAt {
+		c.changedAt = height
+	}
+}
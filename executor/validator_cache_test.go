@@ -0,0 +1,45 @@
+package executor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatorCache(t *testing.T) {
+	queries := 0
+	validators, height := []string{"a"}, uint64(10)
+	c := newValidatorCache(time.Minute, func() ([]string, uint64, error) {
+		queries++
+		return validators, height, nil
+	})
+	now := time.Now()
+
+	v, err := c.get(now)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a"}, v)
+	_, _ = c.get(now)
+	require.Equal(t, 1, queries)
+
+	// a change at a height not later than the cached one keeps the cache
+	c.invalidate(10)
+	_, _ = c.get(now)
+	require.Equal(t, 1, queries)
+
+	// validators queried before the change are not cached
+	c.invalidate(12)
+	_, _ = c.get(now)
+	_, _ = c.get(now)
+	require.Equal(t, 3, queries)
+
+	validators, height = []string{"b"}, 12
+	v, _ = c.get(now)
+	require.Equal(t, []string{"b"}, v)
+	_, _ = c.get(now)
+	require.Equal(t, 4, queries)
+
+	// expired by max age
+	_, _ = c.get(now.Add(time.Minute))
+	require.Equal(t, 5, queries)
+}
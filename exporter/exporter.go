@@ -0,0 +1,223 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/bnb-chain/greenfield-relayer/common"
+	"github.com/bnb-chain/greenfield-relayer/config"
+	"github.com/bnb-chain/greenfield-relayer/db/dao"
+	"github.com/bnb-chain/greenfield-relayer/db/model"
+	"github.com/bnb-chain/greenfield-relayer/logging"
+	"github.com/bnb-chain/greenfield-relayer/metric"
+)
+
+const (
+	DefaultExportInterval  = 5 * time.Minute
+	DefaultExportBatchSize = 1000
+
+	RelayPackageTable = "relay_packages"
+
+	cursorNameBSCPackages        = "bsc_relay_package"
+	cursorNameGreenfieldPackages = "greenfield_relay_transaction"
+)
+
+// PackageRecord is a delivered cross-chain package as shipped to the data warehouse. Fees and costs are decimal amounts in
+// wei. The claim gas and cost are of the claim tx on BSC, so they are only known for packages from Greenfield once the
+// receipt is decoded, and packages claimed by the same tx share them, i.e. costs should be summed by claim tx hash.
+type PackageRecord struct {
+	Direction      string `json:"direction"`
+	ChannelId      uint8  `json:"channel_id"`
+	Sequence       uint64 `json:"sequence"`
+	OracleSequence uint64 `json:"oracle_sequence"`
+	PackageType    uint32 `json:"package_type"`
+	Height         uint64 `json:"height"`
+	TxHash         string `json:"tx_hash"`
+	ClaimTxHash    string `json:"claim_tx_hash"`
+	RelayerFee     string `json:"relayer_fee"`
+	AckRelayerFee  string `json:"ack_relayer_fee"`
+	ClaimGasUsed   uint64 `json:"claim_gas_used"`
+	ClaimGasPrice  string `json:"claim_gas_price"`
+	ClaimCost      string `json:"claim_cost"`
+	TxTime         int64  `json:"tx_time"`
+	DeliveredTime  int64  `json:"delivered_time"`
+	LatencySeconds int64  `json:"latency_seconds"`
+}
+
+// Exporter periodically ships delivered relay records to the configured sink. Records are exported at least once,
+// a record might be exported again if the relayer stops after writing the sink but before saving the cursor.
+type Exporter struct {
+	config     *config.Config
+	daoManager *dao.DaoManager
+	sink       Sink
+	interval   time.Duration
+	batchSize  int
+}
+
+func NewExporter(cfg *config.Config, daoManager *dao.DaoManager) *Exporter {
+	sink, err := NewSink(&cfg.ExportConfig)
+	if err != nil {
+		panic(err)
+	}
+	interval := DefaultExportInterval
+	if cfg.ExportConfig.IntervalInSecond > 0 {
+		interval = time.Duration(cfg.ExportConfig.IntervalInSecond) * time.Second
+	}
+	batchSize := DefaultExportBatchSize
+	if cfg.ExportConfig.BatchSize > 0 {
+		batchSize = cfg.ExportConfig.BatchSize
+	}
+	return &Exporter{
+		config:     cfg,
+		daoManager: daoManager,
+		sink:       sink,
+		interval:   interval,
+		batchSize:  batchSize,
+	}
+}
+
+func (e *Exporter) StartLoop() {
+	common.Schedule(common.TaskExporter, e.interval, func() {
+		if err := e.exportBSCPackages(); err != nil {
+			logging.Logger.Errorf("export BSC packages error, err=%s", err.Error())
+		}
+		if err := e.exportGreenfieldPackages(); err != nil {
+			logging.Logger.Errorf("export Greenfield packages error, err=%s", err.Error())
+		}
+	}).Run()
+}
+
+func (e *Exporter) exportBSCPackages() error {
+	return e.export(cursorNameBSCPackages, func(cursor *model.ExportCursor, before int64) ([]*PackageRecord, *model.ExportCursor, error) {
+		pkgs, err := e.daoManager.BSCDao.GetDeliveredPackagesUpdatedAfter(cursor.UpdatedTime, cursor.RecordId, before, e.batchSize)
+		if err != nil || len(pkgs) == 0 {
+			return nil, cursor, err
+		}
+		records := make([]*PackageRecord, 0, len(pkgs))
+		for _, p := range pkgs {
+			record := &PackageRecord{
+				Direction:      metric.DirectionBSCToGnfd,
+				ChannelId:      p.ChannelId,
+				Sequence:       p.PackageSequence,
+				OracleSequence: p.OracleSequence,
+				Height:         p.Height,
+				TxHash:         p.TxHash,
+				ClaimTxHash:    p.ClaimTxHash,
+				TxTime:         p.TxTime,
+				DeliveredTime:  p.UpdatedTime,
+				LatencySeconds: p.UpdatedTime - p.TxTime,
+			}
+			setPackageHeaderFields(record, p.PayLoad)
+			records = append(records, record)
+		}
+		last := pkgs[len(pkgs)-1]
+		return records, &model.ExportCursor{Name: cursor.Name, UpdatedTime: last.UpdatedTime, RecordId: last.Id}, nil
+	})
+}
+
+func (e *Exporter) exportGreenfieldPackages() error {
+	return e.export(cursorNameGreenfieldPackages, func(cursor *model.ExportCursor, before int64) ([]*PackageRecord, *model.ExportCursor, error) {
+		txs, err := e.daoManager.GreenfieldDao.GetDeliveredTransactionsUpdatedAfter(cursor.UpdatedTime, cursor.RecordId, before, e.batchSize)
+		if err != nil || len(txs) == 0 {
+			return nil, cursor, err
+		}
+		records := make([]*PackageRecord, 0, len(txs))
+		for _, tx := range txs {
+			records = append(records, &PackageRecord{
+				Direction:      metric.DirectionGnfdToBSC,
+				ChannelId:      tx.ChannelId,
+				Sequence:       tx.Sequence,
+				PackageType:    tx.PackageType,
+				Height:         tx.Height,
+				ClaimTxHash:    tx.ClaimedTxHash,
+				RelayerFee:     tx.RelayerFee,
+				AckRelayerFee:  tx.AckRelayerFee,
+				ClaimGasUsed:   tx.ClaimGasUsed,
+				ClaimGasPrice:  tx.ClaimGasPrice,
+				ClaimCost:      claimCost(tx.ClaimGasUsed, tx.ClaimGasPrice),
+				TxTime:         tx.TxTime,
+				DeliveredTime:  tx.UpdatedTime,
+				LatencySeconds: tx.UpdatedTime - tx.TxTime,
+			})
+		}
+		last := txs[len(txs)-1]
+		return records, &model.ExportCursor{Name: cursor.Name, UpdatedTime: last.UpdatedTime, RecordId: last.Id}, nil
+	})
+}
+
+type fetchFunc func(cursor *model.ExportCursor, before int64) ([]*PackageRecord, *model.ExportCursor, error)
+
+// export ships records batch by batch until caught up. Records updated within the last second are left to the next
+// round, since records sharing the same updated_time might still be being written.
+func (e *Exporter) export(cursorName string, fetch fetchFunc) error {
+	cursor, err := e.daoManager.ExportDao.GetCursor(cursorName)
+	if err != nil {
+		return err
+	}
+	before := time.Now().Unix() - 1
+	for {
+		records, next, err := fetch(cursor, before)
+		if err != nil {
+			return err
+		}
+		if len(records) == 0 {
+			return nil
+		}
+		if err := e.sink.Write(RelayPackageTable, records); err != nil {
+			return err
+		}
+		if err := e.daoManager.ExportDao.SaveCursor(next); err != nil {
+			return err
+		}
+		logging.Logger.Infof("exported %d records of %s", len(records), cursorName)
+		if len(records) < e.batchSize {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+// setPackageHeaderFields sets the package type and relayer fees of a BSC package from the header of its hex payload, which
+// are left empty if the payload can not be decoded
+func setPackageHeaderFields(record *PackageRecord, payloadHex string) {
+	payload, err := hex.DecodeString(payloadHex)
+	if err != nil {
+		return
+	}
+	header, err := sdk.DecodePackageHeader(payload)
+	if err != nil {
+		return
+	}
+	record.PackageType = uint32(header.PackageType)
+	if header.RelayerFee != nil {
+		record.RelayerFee = header.RelayerFee.String()
+	}
+	if header.AckRelayerFee != nil {
+		record.AckRelayerFee = header.AckRelayerFee.String()
+	}
+}
+
+// claimCost returns the fee in wei paid by the claim tx, empty if the gas price is not known yet
+func claimCost(gasUsed uint64, gasPrice string) string {
+	price, ok := new(big.Int).SetString(gasPrice, 10)
+	if !ok {
+		return ""
+	}
+	return new(big.Int).Mul(price, new(big.Int).SetUint64(gasUsed)).String()
+}
+
+func encodeJSONLines(records []*PackageRecord) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, r := range records {
+		if err := encoder.Encode(r); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
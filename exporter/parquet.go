@@ -0,0 +1,265 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// parquet files are written as a single row group of required columns, each a single uncompressed data page of PLAIN
+// encoded values, which every parquet reader supports. The file metadata is encoded with the thrift compact protocol,
+// see https://github.com/apache/parquet-format.
+
+const parquetMagic = "PAR1"
+
+// parquet physical types, converted types, encodings and page types used by the encoder
+const (
+	parquetTypeInt32     = 1
+	parquetTypeInt64     = 2
+	parquetTypeByteArray = 6
+
+	parquetConvertedNone   = -1
+	parquetConvertedUTF8   = 0
+	parquetConvertedUint8  = 11
+	parquetConvertedUint32 = 13
+	parquetConvertedUint64 = 14
+
+	parquetEncodingPlain = 0
+	parquetEncodingRLE   = 3
+
+	parquetPageData = 0
+
+	parquetRequired = 0
+
+	parquetCodecUncompressed = 0
+)
+
+// parquetColumn is a column of PackageRecord, named after its JSON field
+type parquetColumn struct {
+	name          string
+	physicalType  int32
+	convertedType int32
+	write         func(buf *bytes.Buffer, r *PackageRecord)
+}
+
+func stringColumn(name string, value func(r *PackageRecord) string) parquetColumn {
+	return parquetColumn{name, parquetTypeByteArray, parquetConvertedUTF8, func(buf *bytes.Buffer, r *PackageRecord) {
+		v := value(r)
+		_ = binary.Write(buf, binary.LittleEndian, uint32(len(v)))
+		buf.WriteString(v)
+	}}
+}
+
+func int32Column(name string, convertedType int32, value func(r *PackageRecord) uint32) parquetColumn {
+	return parquetColumn{name, parquetTypeInt32, convertedType, func(buf *bytes.Buffer, r *PackageRecord) {
+		_ = binary.Write(buf, binary.LittleEndian, value(r))
+	}}
+}
+
+func int64Column(name string, convertedType int32, value func(r *PackageRecord) uint64) parquetColumn {
+	return parquetColumn{name, parquetTypeInt64, convertedType, func(buf *bytes.Buffer, r *PackageRecord) {
+		_ = binary.Write(buf, binary.LittleEndian, value(r))
+	}}
+}
+
+var packageRecordColumns = []parquetColumn{
+	stringColumn("direction", func(r *PackageRecord) string { return r.Direction }),
+	int32Column("channel_id", parquetConvertedUint8, func(r *PackageRecord) uint32 { return uint32(r.ChannelId) }),
+	int64Column("sequence", parquetConvertedUint64, func(r *PackageRecord) uint64 { return r.Sequence }),
+	int64Column("oracle_sequence", parquetConvertedUint64, func(r *PackageRecord) uint64 { return r.OracleSequence }),
+	int32Column("package_type", parquetConvertedUint32, func(r *PackageRecord) uint32 { return r.PackageType }),
+	int64Column("height", parquetConvertedUint64, func(r *PackageRecord) uint64 { return r.Height }),
+	stringColumn("tx_hash", func(r *PackageRecord) string { return r.TxHash }),
+	stringColumn("claim_tx_hash", func(r *PackageRecord) string { return r.ClaimTxHash }),
+	stringColumn("relayer_fee", func(r *PackageRecord) string { return r.RelayerFee }),
+	stringColumn("ack_relayer_fee", func(r *PackageRecord) string { return r.AckRelayerFee }),
+	int64Column("claim_gas_used", parquetConvertedUint64, func(r *PackageRecord) uint64 { return r.ClaimGasUsed }),
+	stringColumn("claim_gas_price", func(r *PackageRecord) string { return r.ClaimGasPrice }),
+	stringColumn("claim_cost", func(r *PackageRecord) string { return r.ClaimCost }),
+	int64Column("tx_time", parquetConvertedNone, func(r *PackageRecord) uint64 { return uint64(r.TxTime) }),
+	int64Column("delivered_time", parquetConvertedNone, func(r *PackageRecord) uint64 { return uint64(r.DeliveredTime) }),
+	int64Column("latency_seconds", parquetConvertedNone, func(r *PackageRecord) uint64 { return uint64(r.LatencySeconds) }),
+}
+
+// parquetColumnChunk is where a column chunk is in the file, for the file metadata
+type parquetColumnChunk struct {
+	column *parquetColumn
+	offset int64
+	size   int64
+}
+
+func encodeParquet(records []*PackageRecord) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(parquetMagic)
+	chunks := make([]parquetColumnChunk, 0, len(packageRecordColumns))
+	var values bytes.Buffer
+	for i := range packageRecordColumns {
+		column := &packageRecordColumns[i]
+		values.Reset()
+		for _, r := range records {
+			column.write(&values, r)
+		}
+		header := newCompactWriter()
+		header.writeStruct(func() {
+			header.i32Field(1, parquetPageData)
+			header.i32Field(2, int32(values.Len()))
+			header.i32Field(3, int32(values.Len()))
+			header.structField(5, func() {
+				header.i32Field(1, int32(len(records)))
+				header.i32Field(2, parquetEncodingPlain)
+				header.i32Field(3, parquetEncodingRLE)
+				header.i32Field(4, parquetEncodingRLE)
+			})
+		})
+		chunk := parquetColumnChunk{column: column, offset: int64(buf.Len())}
+		buf.Write(header.buf.Bytes())
+		buf.Write(values.Bytes())
+		chunk.size = int64(buf.Len()) - chunk.offset
+		chunks = append(chunks, chunk)
+	}
+
+	meta := newCompactWriter()
+	meta.writeStruct(func() {
+		meta.i32Field(1, 1)
+		meta.listField(2, compactTypeStruct, len(chunks)+1, func(i int) {
+			meta.writeStruct(func() {
+				if i == 0 {
+					meta.binaryField(4, "schema")
+					meta.i32Field(5, int32(len(chunks)))
+					return
+				}
+				column := chunks[i-1].column
+				meta.i32Field(1, column.physicalType)
+				meta.i32Field(3, parquetRequired)
+				meta.binaryField(4, column.name)
+				if column.convertedType != parquetConvertedNone {
+					meta.i32Field(6, column.convertedType)
+				}
+			})
+		})
+		meta.i64Field(3, int64(len(records)))
+		meta.listField(4, compactTypeStruct, 1, func(int) {
+			meta.writeStruct(func() {
+				var total int64
+				meta.listField(1, compactTypeStruct, len(chunks), func(i int) {
+					chunk := chunks[i]
+					total += chunk.size
+					meta.writeStruct(func() {
+						meta.i64Field(2, chunk.offset)
+						meta.structField(3, func() {
+							meta.i32Field(1, chunk.column.physicalType)
+							meta.listField(2, compactTypeI32, 2, func(i int) {
+								meta.writeI32([]int32{parquetEncodingPlain, parquetEncodingRLE}[i])
+							})
+							meta.listField(3, compactTypeBinary, 1, func(int) {
+								meta.writeBinary(chunk.column.name)
+							})
+							meta.i32Field(4, parquetCodecUncompressed)
+							meta.i64Field(5, int64(len(records)))
+							meta.i64Field(6, chunk.size)
+							meta.i64Field(7, chunk.size)
+							meta.i64Field(9, chunk.offset)
+						})
+					})
+				})
+				meta.i64Field(2, total)
+				meta.i64Field(3, int64(len(records)))
+			})
+		})
+		meta.binaryField(6, "greenfield-relayer")
+	})
+	buf.Write(meta.buf.Bytes())
+	if err := binary.Write(&buf, binary.LittleEndian, uint32(meta.buf.Len())); err != nil {
+		return nil, err
+	}
+	buf.WriteString(parquetMagic)
+	return buf.Bytes(), nil
+}
+
+// thrift compact protocol types
+const (
+	compactTypeI32    = 5
+	compactTypeI64    = 6
+	compactTypeBinary = 8
+	compactTypeList   = 9
+	compactTypeStruct = 12
+)
+
+// compactWriter writes thrift structs with the compact protocol, fields of a struct must be written by ascending id
+type compactWriter struct {
+	buf         bytes.Buffer
+	lastFieldId int16
+}
+
+func newCompactWriter() *compactWriter {
+	return &compactWriter{}
+}
+
+func (w *compactWriter) writeStruct(fields func()) {
+	lastFieldId := w.lastFieldId
+	w.lastFieldId = 0
+	fields()
+	w.buf.WriteByte(0) // stop
+	w.lastFieldId = lastFieldId
+}
+
+func (w *compactWriter) fieldHeader(id int16, fieldType byte) {
+	if delta := id - w.lastFieldId; delta > 0 && delta <= 15 {
+		w.buf.WriteByte(byte(delta)<<4 | fieldType)
+	} else {
+		w.buf.WriteByte(fieldType)
+		w.writeI32(int32(id))
+	}
+	w.lastFieldId = id
+}
+
+func (w *compactWriter) i32Field(id int16, v int32) {
+	w.fieldHeader(id, compactTypeI32)
+	w.writeI32(v)
+}
+
+func (w *compactWriter) i64Field(id int16, v int64) {
+	w.fieldHeader(id, compactTypeI64)
+	w.writeI64(v)
+}
+
+func (w *compactWriter) binaryField(id int16, v string) {
+	w.fieldHeader(id, compactTypeBinary)
+	w.writeBinary(v)
+}
+
+func (w *compactWriter) structField(id int16, fields func()) {
+	w.fieldHeader(id, compactTypeStruct)
+	w.writeStruct(fields)
+}
+
+func (w *compactWriter) listField(id int16, elemType byte, size int, elem func(i int)) {
+	w.fieldHeader(id, compactTypeList)
+	if size < 15 {
+		w.buf.WriteByte(byte(size)<<4 | elemType)
+	} else {
+		w.buf.WriteByte(0xf0 | elemType)
+		w.writeVarint(uint64(size))
+	}
+	for i := 0; i < size; i++ {
+		elem(i)
+	}
+}
+
+func (w *compactWriter) writeI32(v int32) {
+	w.writeVarint(uint64(uint32((v << 1) ^ (v >> 31))))
+}
+
+func (w *compactWriter) writeI64(v int64) {
+	w.writeVarint(uint64((v << 1) ^ (v >> 63)))
+}
+
+func (w *compactWriter) writeBinary(v string) {
+	w.writeVarint(uint64(len(v)))
+	w.buf.WriteString(v)
+}
+
+func (w *compactWriter) writeVarint(v uint64) {
+	var bz [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(bz[:], v)
+	w.buf.Write(bz[:n])
+}
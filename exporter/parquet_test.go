@@ -0,0 +1,105 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// compactReader reads the thrift structs written by compactWriter, fields are returned by id, nested structs as
+// map[int16]interface{} and lists as []interface{}
+type compactReader struct {
+	r *bytes.Reader
+}
+
+func (c *compactReader) varint(t *testing.T) uint64 {
+	v, err := binary.ReadUvarint(c.r)
+	require.NoError(t, err)
+	return v
+}
+
+func (c *compactReader) value(t *testing.T, valueType byte) interface{} {
+	switch valueType {
+	case compactTypeI32, compactTypeI64:
+		v := c.varint(t)
+		return int64(v>>1) ^ -int64(v&1)
+	case compactTypeBinary:
+		bz := make([]byte, c.varint(t))
+		_, err := c.r.Read(bz)
+		require.NoError(t, err)
+		return string(bz)
+	case compactTypeList:
+		header, err := c.r.ReadByte()
+		require.NoError(t, err)
+		size := uint64(header >> 4)
+		if size == 15 {
+			size = c.varint(t)
+		}
+		list := make([]interface{}, 0, size)
+		for i := uint64(0); i < size; i++ {
+			list = append(list, c.value(t, header&0x0f))
+		}
+		return list
+	case compactTypeStruct:
+		fields := make(map[int16]interface{})
+		var id int16
+		for {
+			header, err := c.r.ReadByte()
+			require.NoError(t, err)
+			if header == 0 {
+				return fields
+			}
+			require.NotZero(t, header>>4, "long field headers are not written")
+			id += int16(header >> 4)
+			fields[id] = c.value(t, header&0x0f)
+		}
+	}
+	t.Fatalf("unexpected thrift type %d", valueType)
+	return nil
+}
+
+func TestEncodeParquet(t *testing.T) {
+	bz, err := encodeParquet(testRecords)
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(bz, []byte(parquetMagic)))
+	require.True(t, bytes.HasSuffix(bz, []byte(parquetMagic)))
+	metaLen := int(binary.LittleEndian.Uint32(bz[len(bz)-8:]))
+	reader := &compactReader{r: bytes.NewReader(bz[len(bz)-8-metaLen : len(bz)-8])}
+	meta := reader.value(t, compactTypeStruct).(map[int16]interface{})
+	require.Equal(t, int64(len(testRecords)), meta[3])
+
+	// every JSON field of the record is a column
+	var names []string
+	for _, s := range meta[2].([]interface{})[1:] {
+		names = append(names, s.(map[int16]interface{})[4].(string))
+	}
+	var tags []string
+	recordType := reflect.TypeOf(PackageRecord{})
+	for i := 0; i < recordType.NumField(); i++ {
+		tags = append(tags, strings.Split(recordType.Field(i).Tag.Get("json"), ",")[0])
+	}
+	require.Equal(t, tags, names)
+
+	// the page of a column holds the plain encoded values
+	chunks := meta[4].([]interface{})[0].(map[int16]interface{})[1].([]interface{})
+	require.Len(t, chunks, len(packageRecordColumns))
+	for i, column := range packageRecordColumns {
+		if column.name != "claim_gas_used" {
+			continue
+		}
+		chunk := chunks[i].(map[int16]interface{})[3].(map[int16]interface{})
+		require.Equal(t, int64(parquetTypeInt64), chunk[1])
+		require.Equal(t, int64(len(testRecords)), chunk[5])
+		page := &compactReader{r: bytes.NewReader(bz[chunk[9].(int64) : chunk[9].(int64)+chunk[7].(int64)])}
+		header := page.value(t, compactTypeStruct).(map[int16]interface{})
+		require.Equal(t, int64(parquetPageData), header[1])
+		values := make([]uint64, len(testRecords))
+		require.NoError(t, binary.Read(page.r, binary.LittleEndian, values))
+		require.Equal(t, []uint64{0, 21000}, values)
+		require.Zero(t, page.r.Len())
+	}
+}
@@ -0,0 +1,152 @@
+package exporter
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/bnb-chain/greenfield-relayer/config"
+)
+
+const sinkRequestTimeout = 30 * time.Second
+
+// Sink receives batches of records, which are written as JSON lines or parquet files that can be loaded by BigQuery,
+// ClickHouse, Athena etc. directly
+type Sink interface {
+	Write(table string, records []*PackageRecord) error
+}
+
+// objectFormat is how a batch of records is encoded into an object of the file and s3 sinks
+type objectFormat struct {
+	ext         string
+	contentType string
+	encode      func(records []*PackageRecord) ([]byte, error)
+}
+
+var (
+	jsonLinesFormat = objectFormat{ext: "jsonl", contentType: "application/x-ndjson", encode: encodeJSONLines}
+	parquetFormat   = objectFormat{ext: "parquet", contentType: "application/vnd.apache.parquet", encode: encodeParquet}
+)
+
+func NewSink(cfg *config.ExportConfig) (Sink, error) {
+	format := jsonLinesFormat
+	if cfg.Format == config.ExportFormatParquet {
+		format = parquetFormat
+	}
+	switch cfg.Sink {
+	case config.ExportSinkFile:
+		if err := os.MkdirAll(cfg.FileDir, 0755); err != nil {
+			return nil, err
+		}
+		return &fileSink{dir: cfg.FileDir, format: format}, nil
+	case config.ExportSinkS3:
+		sess, err := session.NewSession(&aws.Config{
+			Region: &cfg.S3Region,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &s3Sink{client: s3.New(sess), bucket: cfg.S3Bucket, prefix: cfg.S3Prefix, format: format}, nil
+	case config.ExportSinkClickHouse:
+		return &clickHouseSink{
+			client:   &http.Client{Timeout: sinkRequestTimeout},
+			url:      cfg.ClickHouseURL,
+			database: cfg.ClickHouseDatabase,
+			user:     cfg.ClickHouseUser,
+			password: cfg.ClickHousePassword,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unexpected export sink %s", cfg.Sink)
+	}
+}
+
+// objectName returns a unique name for a batch, partitioned by date so that it can be loaded incrementally
+func objectName(table string, now time.Time, ext string) string {
+	return path.Join(table, now.UTC().Format("2006/01/02"), fmt.Sprintf("%d.%s", now.UnixNano(), ext))
+}
+
+type fileSink struct {
+	dir    string
+	format objectFormat
+}
+
+func (s *fileSink) Write(table string, records []*PackageRecord) error {
+	payload, err := s.format.encode(records)
+	if err != nil {
+		return err
+	}
+	name := filepath.Join(s.dir, filepath.FromSlash(objectName(table, time.Now(), s.format.ext)))
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(name, payload, 0644)
+}
+
+type s3Sink struct {
+	client *s3.S3
+	bucket string
+	prefix string
+	format objectFormat
+}
+
+func (s *s3Sink) Write(table string, records []*PackageRecord) error {
+	payload, err := s.format.encode(records)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.PutObject(&s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(path.Join(s.prefix, objectName(table, time.Now(), s.format.ext))),
+		Body:        bytes.NewReader(payload),
+		ContentType: aws.String(s.format.contentType),
+	})
+	return err
+}
+
+// clickHouseSink inserts records via the ClickHouse HTTP interface, the table should be created in advance
+type clickHouseSink struct {
+	client   *http.Client
+	url      string
+	database string
+	user     string
+	password string
+}
+
+func (s *clickHouseSink) Write(table string, records []*PackageRecord) error {
+	payload, err := encodeJSONLines(records)
+	if err != nil {
+		return err
+	}
+	if s.database != "" {
+		table = fmt.Sprintf("%s.%s", s.database, table)
+	}
+	query := url.Values{"query": {fmt.Sprintf("INSERT INTO %s FORMAT JSONEachRow", table)}}
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/?%s", s.url, query.Encode()), bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	if s.user != "" {
+		req.Header.Set("X-ClickHouse-User", s.user)
+		req.Header.Set("X-ClickHouse-Key", s.password)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("clickhouse insert failed, status=%d, body=%s", resp.StatusCode, string(body))
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package exporter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testRecords = []*PackageRecord{
+	{Direction: "bsc_to_greenfield", ChannelId: 1, Sequence: 1, TxTime: 100, DeliveredTime: 110, LatencySeconds: 10},
+	{Direction: "greenfield_to_bsc", ChannelId: 1, Sequence: 2, RelayerFee: "300", ClaimGasUsed: 21000, ClaimGasPrice: "5",
+		ClaimCost: "105000", TxTime: 100, DeliveredTime: 120, LatencySeconds: 20},
+}
+
+func TestFileSinkWrite(t *testing.T) {
+	dir := t.TempDir()
+	s := &fileSink{dir: dir, format: jsonLinesFormat}
+	require.NoError(t, s.Write(RelayPackageTable, testRecords))
+
+	files, err := filepath.Glob(filepath.Join(dir, RelayPackageTable, "*", "*", "*", "*.jsonl"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	bz, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	payload, err := encodeJSONLines(testRecords)
+	require.NoError(t, err)
+	require.Equal(t, payload, bz)
+}
+
+func TestFileSinkWriteParquet(t *testing.T) {
+	dir := t.TempDir()
+	s := &fileSink{dir: dir, format: parquetFormat}
+	require.NoError(t, s.Write(RelayPackageTable, testRecords))
+
+	files, err := filepath.Glob(filepath.Join(dir, RelayPackageTable, "*", "*", "*", "*.parquet"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	bz, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	payload, err := encodeParquet(testRecords)
+	require.NoError(t, err)
+	require.Equal(t, payload, bz)
+}
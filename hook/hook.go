@@ -0,0 +1,161 @@
+package hook
+
+import (
+	"fmt"
+
+	"github.com/bnb-chain/greenfield-relayer/config"
+	"github.com/bnb-chain/greenfield-relayer/db/model"
+	"github.com/bnb-chain/greenfield-relayer/logging"
+	"github.com/bnb-chain/greenfield-relayer/metric"
+)
+
+// Point is a lifecycle point of packages where hooks are invoked
+type Point string
+
+const (
+	PointPackageObserved Point = config.HookPointPackageObserved // after a package is saved by a listener
+	PointBeforeVote      Point = config.HookPointBeforeVote      // before signing and broadcasting the vote of a package
+	PointBeforeClaim     Point = config.HookPointBeforeClaim     // before claiming a package on the destination chain
+	PointAfterDelivery   Point = config.HookPointAfterDelivery   // after a package is marked delivered
+)
+
+// vetoable returns whether an error of a hook at the point stops the action
+func (p Point) vetoable() bool {
+	return p == PointBeforeVote || p == PointBeforeClaim
+}
+
+// Event describes a package at a lifecycle point, scripts receive it as JSON in stdin
+type Event struct {
+	Point          Point  `json:"point"`
+	Direction      string `json:"direction"`
+	ChannelId      uint8  `json:"channel_id"`
+	Sequence       uint64 `json:"sequence"`
+	OracleSequence uint64 `json:"oracle_sequence,omitempty"` // of BSC -> Greenfield packages
+	Height         uint64 `json:"height,omitempty"`          // of the source chain block
+	Payload        string `json:"payload,omitempty"`         // hex encoded
+	ClaimTxHash    string `json:"claim_tx_hash,omitempty"`   // known after delivery by this relayer
+}
+
+// GreenfieldTxEvent returns the event of a Greenfield -> BSC package at the point
+func GreenfieldTxEvent(point Point, tx *model.GreenfieldRelayTransaction) *Event {
+	return &Event{
+		Point:       point,
+		Direction:   metric.DirectionGnfdToBSC,
+		ChannelId:   tx.ChannelId,
+		Sequence:    tx.Sequence,
+		Height:      tx.Height,
+		Payload:     tx.PayLoad,
+		ClaimTxHash: tx.ClaimedTxHash,
+	}
+}
+
+// BSCPackageEvent returns the event of a BSC -> Greenfield package at the point
+func BSCPackageEvent(point Point, pkg *model.BscRelayPackage) *Event {
+	return &Event{
+		Point:          point,
+		Direction:      metric.DirectionBSCToGnfd,
+		ChannelId:      pkg.ChannelId,
+		Sequence:       pkg.PackageSequence,
+		OracleSequence: pkg.OracleSequence,
+		Height:         pkg.Height,
+		Payload:        pkg.PayLoad,
+		ClaimTxHash:    pkg.ClaimTxHash,
+	}
+}
+
+// Hook is implemented by Go plugins and scripts to add custom policies without forking the relayer. An error returned
+// before a vote or claim vetoes it, the package is offered to hooks again in the next round. Errors at other points are
+// only logged.
+type Hook interface {
+	Handle(event *Event) error
+}
+
+type entry struct {
+	name   string
+	hook   Hook
+	points map[Point]bool // all points if empty
+}
+
+// Hooks invokes hooks in the configured order
+type Hooks struct {
+	entries []entry
+}
+
+func NewHooks(cfgs []config.Hook) (*Hooks, error) {
+	h := &Hooks{}
+	for _, cfg := range cfgs {
+		var (
+			hk  Hook
+			err error
+		)
+		switch cfg.Type {
+		case config.HookTypePlugin:
+			hk, err = loadPlugin(cfg.Path)
+		case config.HookTypeScript:
+			hk = newScript(cfg.Path, cfg.TimeoutInMillisecond)
+		default:
+			err = fmt.Errorf("unexpected hook type %s", cfg.Type)
+		}
+		if err != nil {
+			return nil, err
+		}
+		points := make(map[Point]bool, len(cfg.Points))
+		for _, p := range cfg.Points {
+			points[Point(p)] = true
+		}
+		h.entries = append(h.entries, entry{name: cfg.Path, hook: hk, points: points})
+	}
+	return h, nil
+}
+
+// Run invokes hooks registered at the point of the event, it returns the error of the first hook vetoing the event
+func (h *Hooks) Run(event *Event) error {
+	for _, e := range h.entries {
+		if len(e.points) != 0 && !e.points[event.Point] {
+			continue
+		}
+		if err := e.hook.Handle(event); err != nil {
+			if event.Point.vetoable() {
+				return fmt.Errorf("vetoed by hook %s, err=%s", e.name, err.Error())
+			}
+			logging.Logger.Errorf("hook %s failed at %s of channel id %d and sequence %d, err=%s",
+				e.name, event.Point, event.ChannelId, event.Sequence, err.Error())
+		}
+	}
+	return nil
+}
+
+var hooks = &Hooks{}
+
+// SetHooks sets the hooks invoked by Run
+func SetHooks(h *Hooks) {
+	hooks = h
+}
+
+// Run invokes the hooks set by SetHooks
+func Run(event *Event) error {
+	return hooks.Run(event)
+}
+
+// Notify invokes the hooks set by SetHooks at a point which can not be vetoed
+func Notify(event *Event) {
+	_ = hooks.Run(event)
+}
+
+// NotifyBSCPackages invokes the hooks set by SetHooks for packages of an oracle sequence at a point which can not be vetoed
+func NotifyBSCPackages(point Point, pkgs []*model.BscRelayPackage) {
+	for _, pkg := range pkgs {
+		Notify(BSCPackageEvent(point, pkg))
+	}
+}
+
+// RunBSCPackages invokes the hooks set by SetHooks for packages of an oracle sequence, which are voted and claimed
+// together, it returns the error of the first package vetoed
+func RunBSCPackages(point Point, pkgs []*model.BscRelayPackage) error {
+	for _, pkg := range pkgs {
+		if err := hooks.Run(BSCPackageEvent(point, pkg)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
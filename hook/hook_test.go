@@ -0,0 +1,26 @@
+package hook
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-relayer/config"
+)
+
+func TestScriptHook(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hook.sh")
+	// vetoes channel 2 only
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\ngrep -q '\"channel_id\":2,' && echo denied && exit 1\nexit 0\n"), 0755))
+	h, err := NewHooks([]config.Hook{{Type: config.HookTypeScript, Path: path, Points: []string{config.HookPointBeforeClaim}}})
+	require.NoError(t, err)
+
+	require.NoError(t, h.Run(&Event{Point: PointBeforeClaim, ChannelId: 1}))
+	err = h.Run(&Event{Point: PointBeforeClaim, ChannelId: 2})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "denied")
+	// not registered at the point
+	require.NoError(t, h.Run(&Event{Point: PointBeforeVote, ChannelId: 2}))
+}
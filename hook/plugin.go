@@ -0,0 +1,29 @@
+package hook
+
+import (
+	"fmt"
+	"plugin"
+)
+
+// pluginSymbol is the exported variable of Go plugins implementing Hook
+const pluginSymbol = "Hook"
+
+// loadPlugin opens a Go plugin built with `go build -buildmode=plugin` against the same version of the relayer
+func loadPlugin(path string) (Hook, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	sym, err := p.Lookup(pluginSymbol)
+	if err != nil {
+		return nil, err
+	}
+	switch h := sym.(type) {
+	case *Hook:
+		return *h, nil
+	case Hook:
+		return h, nil
+	default:
+		return nil, fmt.Errorf("symbol %s of plugin %s does not implement Hook", pluginSymbol, path)
+	}
+}
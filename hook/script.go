@@ -0,0 +1,45 @@
+package hook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const defaultScriptTimeout = 5 * time.Second
+
+// script runs an executable with the event as JSON in stdin, a non-zero exit code is an error
+type script struct {
+	path    string
+	timeout time.Duration
+}
+
+func newScript(path string, timeoutInMillisecond int64) *script {
+	timeout := defaultScriptTimeout
+	if timeoutInMillisecond > 0 {
+		timeout = time.Duration(timeoutInMillisecond) * time.Millisecond
+	}
+	return &script{path: path, timeout: timeout}
+}
+
+func (s *script) Handle(event *Event) error {
+	input, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, s.path)
+	cmd.Stdin = bytes.NewReader(input)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %s", err.Error(), strings.TrimSpace(output.String()))
+	}
+	return nil
+}
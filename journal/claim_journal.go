@@ -0,0 +1,144 @@
+package journal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+// ClaimEntry records a claim tx broadcast by the relayer
+type ClaimEntry struct {
+	Direction string `json:"direction"`
+	ChannelId uint8  `json:"channel_id"`
+	Sequence  uint64 `json:"sequence"` // oracle sequence of BSC -> Greenfield claims
+	Nonce     uint64 `json:"nonce"`
+	TxHash    string `json:"tx_hash"`
+	Inturn    bool   `json:"inturn"`
+	Time      int64  `json:"time"`
+}
+
+// ClaimJournal is a local append-only file of broadcast claims, in addition to the DB, so that in-flight claims are
+// known after a restart even if the DB writes are lost. A nil journal is disabled.
+type ClaimJournal struct {
+	mutex sync.Mutex
+	path  string
+	file  *os.File
+}
+
+func NewClaimJournal(path string) (*ClaimJournal, error) {
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	// terminate a partial line written before a crash, so that it is not joined with the next entry
+	if len(content) > 0 && content[len(content)-1] != '\n' {
+		if _, err := file.Write([]byte{'\n'}); err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+	}
+	return &ClaimJournal{path: path, file: file}, nil
+}
+
+// Append writes the entry as a JSON line and flushes it to disk
+func (j *ClaimJournal) Append(entry *ClaimEntry) error {
+	if j == nil {
+		return nil
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	if _, err := j.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return j.file.Sync()
+}
+
+// Entries returns entries in the order written, a partial line written before a crash is ignored
+func (j *ClaimJournal) Entries() ([]*ClaimEntry, error) {
+	if j == nil {
+		return nil, nil
+	}
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	return readEntries(j.path)
+}
+
+// Compact drops entries written before the time, it is called at startup after in-flight claims are recovered
+func (j *ClaimJournal) Compact(before time.Time) error {
+	if j == nil {
+		return nil
+	}
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	entries, err := readEntries(j.path)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	for _, e := range entries {
+		if e.Time < before.Unix() {
+			continue
+		}
+		line, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		buf.Write(append(line, '\n'))
+	}
+	// replace the file atomically so that a crash keeps either the old or the new journal
+	tmp := j.path + ".tmp"
+	if err := writeFileSync(tmp, buf.Bytes()); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, j.path); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	_ = j.file.Close()
+	j.file = file
+	return nil
+}
+
+func readEntries(path string) ([]*ClaimEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var entries []*ClaimEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var e ClaimEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		entries = append(entries, &e)
+	}
+	return entries, scanner.Err()
+}
+
+func writeFileSync(path string, content []byte) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if _, err := file.Write(content); err != nil {
+		return err
+	}
+	return file.Sync()
+}
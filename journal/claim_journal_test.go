@@ -0,0 +1,49 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClaimJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "claims.jsonl")
+	j, err := NewClaimJournal(path)
+	require.NoError(t, err)
+	now := time.Now()
+	require.NoError(t, j.Append(&ClaimEntry{Direction: "greenfield_to_bsc", ChannelId: 1, Sequence: 1, Nonce: 10, TxHash: "0x1", Time: now.Add(-2 * time.Hour).Unix()}))
+	require.NoError(t, j.Append(&ClaimEntry{Direction: "greenfield_to_bsc", ChannelId: 1, Sequence: 2, Nonce: 11, TxHash: "0x2", Time: now.Unix()}))
+
+	// a partial line written before a crash
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"direction":"greenfield_to_bsc","chan`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	// reopened after a restart
+	j, err = NewClaimJournal(path)
+	require.NoError(t, err)
+	entries, err := j.Entries()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, uint64(11), entries[1].Nonce)
+	require.NoError(t, j.Append(&ClaimEntry{Direction: "greenfield_to_bsc", ChannelId: 1, Sequence: 3, Nonce: 12, TxHash: "0x4", Time: now.Unix()}))
+	entries, err = j.Entries()
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	require.NoError(t, j.Compact(now.Add(-time.Hour)))
+	require.NoError(t, j.Append(&ClaimEntry{Direction: "bsc_to_greenfield", Sequence: 5, TxHash: "0x3", Time: now.Unix()}))
+	entries, err = j.Entries()
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	require.Equal(t, "0x2", entries[0].TxHash)
+	require.Equal(t, "0x3", entries[2].TxHash)
+
+	var disabled *ClaimJournal
+	require.NoError(t, disabled.Append(&ClaimEntry{}))
+}
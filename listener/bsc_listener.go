@@ -1,7 +1,7 @@
 package listener
 
 import (
-	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -10,18 +10,23 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/spf13/viper"
 
 	"github.com/bnb-chain/greenfield-relayer/common"
 	"github.com/bnb-chain/greenfield-relayer/config"
 	"github.com/bnb-chain/greenfield-relayer/db/dao"
 	"github.com/bnb-chain/greenfield-relayer/db/model"
+	"github.com/bnb-chain/greenfield-relayer/events"
 	"github.com/bnb-chain/greenfield-relayer/executor"
 	"github.com/bnb-chain/greenfield-relayer/executor/crosschain"
+	"github.com/bnb-chain/greenfield-relayer/hook"
 	"github.com/bnb-chain/greenfield-relayer/logging"
 	"github.com/bnb-chain/greenfield-relayer/metric"
 	rtypes "github.com/bnb-chain/greenfield-relayer/types"
 )
 
+const receivedPackageEventName = "ReceivedPackage"
+
 type BSCListener struct {
 	config             *config.Config
 	bscExecutor        *executor.BSCExecutor
@@ -29,9 +34,15 @@ type BSCListener struct {
 	DaoManager         *dao.DaoManager
 	crossChainAbi      abi.ABI
 	monitorService     *metric.MetricService
+	failAckMonitor     *FailAckMonitor
+	reprocessUntil     uint64 // blocks up to this height are re-processed due to forced start height
+	pollInterval       *pollIntervalAdjuster
+	eventBus           *events.Bus
+	shadowBlocks       chan *shadowBlock // blocks to be verified by the shadow listener, nil if it is disabled
 }
 
-func NewBSCListener(cfg *config.Config, bscExecutor *executor.BSCExecutor, gnfdExecutor *executor.GreenfieldExecutor, dao *dao.DaoManager, ms *metric.MetricService) *BSCListener {
+func NewBSCListener(cfg *config.Config, bscExecutor *executor.BSCExecutor, gnfdExecutor *executor.GreenfieldExecutor, dao *dao.DaoManager, ms *metric.MetricService,
+	eventBus *events.Bus) *BSCListener {
 	crossChainAbi, err := abi.JSON(strings.NewReader(crosschain.CrosschainMetaData.ABI))
 	if err != nil {
 		panic("marshal abi error")
@@ -43,19 +54,52 @@ func NewBSCListener(cfg *config.Config, bscExecutor *executor.BSCExecutor, gnfdE
 		DaoManager:         dao,
 		crossChainAbi:      crossChainAbi,
 		monitorService:     ms,
+		failAckMonitor:     NewFailAckMonitor(cfg, ms),
+		pollInterval:       newPollIntervalAdjuster(),
+		eventBus:           eventBus,
+		shadowBlocks:       newShadowBlocks(cfg),
 	}
 }
 
 func (l *BSCListener) StartLoop() {
+	if err := l.applyForcedStartHeight(); err != nil {
+		panic(fmt.Sprintf("failed to apply forced start height for BSC, err=%s", err.Error()))
+	}
+	if l.shadowBlocks != nil {
+		go l.shadowVerifyLoop()
+	}
 	for {
 		err := l.poll()
 		if err != nil {
-			time.Sleep(common.ErrorRetryInterval)
+			time.Sleep(errorRetryInterval(l.config.BSCConfig.Upgrades, l.bscExecutor.GetCachedLatestHeight()))
 			continue
 		}
 	}
 }
 
+// applyForcedStartHeight makes the listener start from the configured start height even if higher blocks have been
+// processed. Blocks at or above the start height are deleted from DB so that they get re-processed, while the packages
+// are kept as they are, and only missing packages are saved when re-processing those blocks.
+func (l *BSCListener) applyForcedStartHeight() error {
+	if !l.config.BSCConfig.ForceStartHeight && !viper.GetBool(config.FlagForceFromHeight) {
+		return nil
+	}
+	startHeight := l.config.BSCConfig.StartHeight
+	latestPolledBlock, err := l.getLatestPolledBlock()
+	if err != nil {
+		return err
+	}
+	if latestPolledBlock.Height < startHeight {
+		return nil
+	}
+	if err = l.DaoManager.BSCDao.DeleteBlocksFromHeight(startHeight); err != nil {
+		return err
+	}
+	l.reprocessUntil = latestPolledBlock.Height
+	logging.Logger.Infof("BSC listener is forced to start from height %d, blocks up to %d will be re-processed", startHeight, l.reprocessUntil)
+	return nil
+}
+
 func (l *BSCListener) poll() error {
 	latestPolledBlock, err := l.getLatestPolledBlock()
 	if err != nil {
@@ -75,11 +119,16 @@ func (l *BSCListener) poll() error {
 			return err
 		}
 		if int64(latestPolledBlockHeight) >= int64(latestBlockHeight)-1 {
-			time.Sleep(common.ListenerPauseTime)
+			time.Sleep(l.pollInterval.Interval(0))
 			return nil
 		}
+		time.Sleep(l.pollInterval.Interval(latestBlockHeight - 1 - latestPolledBlockHeight))
 	}
 	if err = l.monitorCrossChainPkgAt(nextHeight, latestPolledBlock); err != nil {
+		if errors.Is(err, common.ErrNodeStale) {
+			logging.Logger.Debugf("BSC block at height=%d is not available yet, err=%s", nextHeight, err.Error())
+			return err
+		}
 		logging.Logger.Errorf("encounter error when monitor cross-chain packages at blockHeight=%d, err=%s", nextHeight, err.Error())
 		return err
 	}
@@ -108,15 +157,75 @@ func (l *BSCListener) monitorCrossChainPkgAt(nextHeight uint64, latestPolledBloc
 	if isForked {
 		return fmt.Errorf("there is fork at block height=%d", latestPolledBlock.Height)
 	}
-	logs, err := l.queryCrossChainLogs(nextHeightBlockHeader.Hash())
+	relayPkgs, err := l.getRelayPackagesFromBlock(l.bscExecutor, nextHeightBlockHeader)
 	if err != nil {
-		return fmt.Errorf("failed to get logs from block at height=%d, err=%s", nextHeight, err.Error())
+		return err
+	}
+
+	saveBlockAndPackages := l.DaoManager.BSCDao.SaveBlockAndBatchPackages
+	if nextHeight <= l.reprocessUntil {
+		saveBlockAndPackages = l.DaoManager.BSCDao.SaveBlockAndMissingPackages
+	}
+	if err := saveBlockAndPackages(
+		&model.BscBlock{
+			BlockHash:  nextHeightBlockHeader.Hash().String(),
+			ParentHash: nextHeightBlockHeader.ParentHash.String(),
+			Height:     nextHeight,
+			BlockTime:  int64(nextHeightBlockHeader.Time),
+		}, relayPkgs); err != nil {
+		return err
+	}
+	l.monitorService.SetSavedBlockHeight(metric.ChainBSC, nextHeight)
+	l.shadowVerify(nextHeightBlockHeader, relayPkgs)
+	if retention := l.config.BSCConfig.BlockRetention; shouldPruneBlocks(nextHeight, retention) {
+		if err := l.DaoManager.BSCDao.DeleteBlocksBelowHeight(nextHeight - retention); err != nil {
+			logging.Logger.Errorf("failed to prune BSC blocks below height %d, err=%s", nextHeight-retention, err.Error())
+		}
+	}
+	l.observeDeliveries(nextHeightBlockHeader)
+	l.pollInterval.ObserveBlock(nextHeight, int64(nextHeightBlockHeader.Time))
+	for _, pkg := range relayPkgs {
+		if isFailAckPackage(pkg) {
+			l.failAckMonitor.Observe(metric.DirectionBSCToGnfd, pkg.ChannelId, time.Unix(pkg.TxTime, 0))
+		}
+		l.eventBus.Publish(&events.Event{
+			Type:           events.EventTypePackage,
+			Direction:      metric.DirectionBSCToGnfd,
+			ChannelId:      pkg.ChannelId,
+			Sequence:       pkg.PackageSequence,
+			OracleSequence: pkg.OracleSequence,
+			Height:         pkg.Height,
+			TxHash:         pkg.TxHash,
+			Time:           pkg.TxTime,
+		})
+		hook.Notify(hook.BSCPackageEvent(hook.PointPackageObserved, pkg))
+	}
+	return nil
+}
+
+func (l *BSCListener) getRelayPackagesFromBlock(e *executor.BSCExecutor, header *types.Header) ([]*model.BscRelayPackage, error) {
+	logs, err := l.queryCrossChainLogs(e, header.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get logs from block at height=%d, err=%s", header.Number.Uint64(), err.Error())
 	}
-	relayPkgs := make([]*model.BscRelayPackage, 0)
 	for _, log := range logs {
 		logging.Logger.Infof("get log: %d, %s, %s", log.BlockNumber, log.Topics[0].String(), log.TxHash.String())
+	}
+	relayPkgs := l.parseRelayPackages(header, logs)
+	for _, relayPkg := range relayPkgs {
+		logging.Logger.Debugf("found package cid=%s, channel=%d, package sequence=%d, txHash=%s",
+			common.CorrelationId(metric.DirectionBSCToGnfd, uint8(common.OracleChannelId), relayPkg.OracleSequence),
+			relayPkg.ChannelId, relayPkg.PackageSequence, relayPkg.TxHash)
+	}
+	return relayPkgs, nil
+}
+
+// parseRelayPackages parses the cross-chain package events of the block, events failed to be parsed are skipped
+func (l *BSCListener) parseRelayPackages(header *types.Header, logs []types.Log) []*model.BscRelayPackage {
+	relayPkgs := make([]*model.BscRelayPackage, 0)
+	for _, log := range logs {
 		relayPkg, err := ParseRelayPackage(&l.crossChainAbi,
-			&log, nextHeightBlockHeader.Time,
+			&log, header.Time,
 			rtypes.ChainId(l.config.GreenfieldConfig.ChainId),
 			rtypes.ChainId(l.config.BSCConfig.ChainId),
 			&l.config.RelayConfig,
@@ -131,27 +240,39 @@ func (l *BSCListener) monitorCrossChainPkgAt(nextHeight uint64, latestPolledBloc
 		}
 		relayPkgs = append(relayPkgs, relayPkg)
 	}
+	return relayPkgs
+}
 
-	if err := l.DaoManager.BSCDao.SaveBlockAndBatchPackages(
-		&model.BscBlock{
-			BlockHash:  nextHeightBlockHeader.Hash().String(),
-			ParentHash: nextHeightBlockHeader.ParentHash.String(),
-			Height:     nextHeight,
-			BlockTime:  int64(nextHeightBlockHeader.Time),
-		}, relayPkgs); err != nil {
-		return err
+// Backfill re-scans BSC blocks within [from, to] and saves the cross-chain packages missing in DB, blocks are not
+// saved so that the live listener is not affected. Archive providers are used if there are any.
+func (l *BSCListener) Backfill(from, to uint64) error {
+	e := l.bscExecutor.ForArchive()
+	for height := from; height <= to; height++ {
+		header, err := e.GetBlockHeaderAtHeight(height)
+		if err != nil {
+			return fmt.Errorf("failed to get BSC block header at height=%d, err=%s", height, err.Error())
+		}
+		relayPkgs, err := l.getRelayPackagesFromBlock(e, header)
+		if err != nil {
+			return err
+		}
+		savedCnt, err := l.DaoManager.BSCDao.SaveMissingPackages(relayPkgs)
+		if err != nil {
+			return err
+		}
+		if savedCnt != 0 {
+			logging.Logger.Infof("backfilled %d packages at BSC height=%d", savedCnt, height)
+		}
 	}
-	l.monitorService.SetBSCSavedBlockHeight(nextHeight)
 	return nil
 }
 
-func (l *BSCListener) queryCrossChainLogs(blockHash ethcommon.Hash) ([]types.Log, error) {
-	client := l.bscExecutor.GetRpcClient()
+func (l *BSCListener) queryCrossChainLogs(e *executor.BSCExecutor, blockHash ethcommon.Hash) ([]types.Log, error) {
 	topics := [][]ethcommon.Hash{{l.getCrossChainPackageEventHash()}}
-	logs, err := client.FilterLogs(context.Background(), ethereum.FilterQuery{
+	logs, err := e.FilterLogs(ethereum.FilterQuery{
 		BlockHash: &blockHash,
 		Topics:    topics,
-		Addresses: []ethcommon.Address{l.getCrossChainContractAddress()},
+		Addresses: l.config.RelayConfig.GetMonitorContractAddrs(),
 	})
 	if err != nil {
 		return nil, err
@@ -159,6 +280,60 @@ func (l *BSCListener) queryCrossChainLogs(blockHash ethcommon.Hash) ([]types.Log
 	return logs, nil
 }
 
+// observeDeliveries records which relayer delivered Greenfield packages by the ReceivedPackage events of the CrossChain
+// contract in the block. It is best-effort, failures are only logged so that they do not block listening.
+func (l *BSCListener) observeDeliveries(header *types.Header) {
+	blockHash := header.Hash()
+	logs, err := l.bscExecutor.FilterLogs(ethereum.FilterQuery{
+		BlockHash: &blockHash,
+		Topics:    [][]ethcommon.Hash{{l.crossChainAbi.Events[receivedPackageEventName].ID}},
+		Addresses: []ethcommon.Address{ethcommon.HexToAddress(l.config.RelayConfig.CrossChainContractAddr)},
+	})
+	if err != nil {
+		logging.Logger.Errorf("failed to get received package logs at height=%d, err=%s", header.Number.Uint64(), err.Error())
+		return
+	}
+	deliveries := make([]*model.PeerDelivery, 0, len(logs))
+	senders := make(map[ethcommon.Hash]string)
+	for _, log := range logs {
+		if len(log.Topics) < 3 {
+			continue
+		}
+		sender, ok := senders[log.TxHash]
+		if !ok {
+			addr, err := l.bscExecutor.GetTransactionSender(log.BlockHash, log.TxIndex)
+			if err != nil {
+				logging.Logger.Errorf("failed to get sender of tx %s, err=%s", log.TxHash.String(), err.Error())
+				continue
+			}
+			sender = addr.String()
+			senders[log.TxHash] = sender
+		}
+		d := &model.PeerDelivery{
+			Direction:     metric.DirectionGnfdToBSC,
+			ChannelId:     uint8(log.Topics[2].Big().Uint64()),
+			Sequence:      log.Topics[1].Big().Uint64(),
+			Relayer:       sender,
+			ClaimTxHash:   log.TxHash.String(),
+			Height:        log.BlockNumber,
+			DeliveredTime: int64(header.Time),
+			Latency:       -1,
+		}
+		tx, err := l.DaoManager.GreenfieldDao.GetTransactionByChannelIdAndSequence(rtypes.ChannelId(d.ChannelId), d.Sequence)
+		if err == nil && tx.Id != 0 {
+			d.Latency = d.DeliveredTime - tx.TxTime
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := l.DaoManager.PeerDao.SavePeerDeliveries(deliveries); err != nil {
+		logging.Logger.Errorf("failed to save peer deliveries at height=%d, err=%s", header.Number.Uint64(), err.Error())
+		return
+	}
+	for _, d := range deliveries {
+		l.monitorService.ObservePeerDelivery(d.Direction, d.Relayer, d.ChannelId, d.Sequence, d.Latency)
+	}
+}
+
 func (l *BSCListener) isForkedBlockAndDelete(latestPolledBlock *model.BscBlock, nextHeight uint64, parentHash ethcommon.Hash) (bool, error) {
 	if latestPolledBlock.Height != 0 &&
 		latestPolledBlock.Height+1 == nextHeight &&
@@ -176,7 +351,3 @@ func (l *BSCListener) isForkedBlockAndDelete(latestPolledBlock *model.BscBlock,
 func (l *BSCListener) getCrossChainPackageEventHash() ethcommon.Hash {
 	return ethcommon.HexToHash(l.config.RelayConfig.CrossChainPackageEventHex)
 }
-
-func (l *BSCListener) getCrossChainContractAddress() ethcommon.Address {
-	return ethcommon.HexToAddress(l.config.RelayConfig.CrossChainContractAddr)
-}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"math/big"
 
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/core/types"
 
@@ -48,3 +49,12 @@ func parseCrossChainPackageEvent(abi *abi.ABI, log *types.Log, config *config.Re
 	ev.ChannelId = uint8(big.NewInt(0).SetBytes(log.Topics[3].Bytes()).Uint64())
 	return &ev, nil
 }
+
+// isFailAckPackage tells whether the package is a FAIL_ACK package, the package type is the first byte of the payload
+func isFailAckPackage(pkg *model.BscRelayPackage) bool {
+	payload, err := hex.DecodeString(pkg.PayLoad)
+	if err != nil || len(payload) == 0 {
+		return false
+	}
+	return sdk.CrossChainPackageType(payload[0]) == sdk.FailAckCrossChainPackageType
+}
@@ -0,0 +1,83 @@
+package listener
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bnb-chain/greenfield-relayer/common"
+	"github.com/bnb-chain/greenfield-relayer/config"
+	"github.com/bnb-chain/greenfield-relayer/logging"
+	"github.com/bnb-chain/greenfield-relayer/metric"
+)
+
+type failAckKey struct {
+	direction string
+	channelId uint8
+}
+
+// FailAckMonitor tracks FAIL_ACK packages per direction and channel within a sliding window, and alerts when the number
+// of FAIL_ACK packages within the window reaches the configured threshold. A burst of FAIL_ACK packages usually means
+// the application on the destination chain is failing to handle packages.
+type FailAckMonitor struct {
+	mutex         sync.Mutex
+	config        *config.Config
+	metricService *metric.MetricService
+	window        time.Duration
+	threshold     int
+	observed      map[failAckKey][]time.Time
+	lastAlertedAt map[failAckKey]time.Time
+}
+
+func NewFailAckMonitor(cfg *config.Config, ms *metric.MetricService) *FailAckMonitor {
+	window := common.DefaultFailAckSurgeWindow
+	if cfg.AlertConfig.FailAckSurgeWindow > 0 {
+		window = time.Duration(cfg.AlertConfig.FailAckSurgeWindow) * time.Second
+	}
+	threshold := common.DefaultFailAckSurgeThreshold
+	if cfg.AlertConfig.FailAckSurgeThreshold > 0 {
+		threshold = int(cfg.AlertConfig.FailAckSurgeThreshold)
+	}
+	return &FailAckMonitor{
+		config:        cfg,
+		metricService: ms,
+		window:        window,
+		threshold:     threshold,
+		observed:      make(map[failAckKey][]time.Time),
+		lastAlertedAt: make(map[failAckKey]time.Time),
+	}
+}
+
+// Observe records a FAIL_ACK package of the channel, the alert is sent at most once per window for each channel.
+func (m *FailAckMonitor) Observe(direction string, channelId uint8, at time.Time) {
+	m.metricService.IncFailAckPackages(direction, channelId)
+
+	key := failAckKey{direction: direction, channelId: channelId}
+	m.mutex.Lock()
+	count := m.record(key, at)
+	isSurge := count >= m.threshold && at.Sub(m.lastAlertedAt[key]) >= m.window
+	if isSurge {
+		m.lastAlertedAt[key] = at
+	}
+	m.mutex.Unlock()
+
+	if !isSurge {
+		return
+	}
+	msg := fmt.Sprintf("FAIL_ACK surge detected, direction=%s, channel_id=%d, %d FAIL_ACK packages within %s",
+		direction, channelId, count, m.window)
+	logging.Logger.Error(msg)
+	config.SendTelegramMessage(m.config.AlertConfig.Identity, m.config.AlertConfig.TelegramBotId,
+		m.config.AlertConfig.TelegramChatId, msg)
+}
+
+// record appends the observation and drops the ones out of the window, returns the count within the window
+func (m *FailAckMonitor) record(key failAckKey, at time.Time) int {
+	observed := append(m.observed[key], at)
+	idx := 0
+	for idx < len(observed) && at.Sub(observed[idx]) > m.window {
+		idx++
+	}
+	m.observed[key] = observed[idx:]
+	return len(m.observed[key])
+}
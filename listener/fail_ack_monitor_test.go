@@ -0,0 +1,27 @@
+package listener
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailAckMonitorRecord(t *testing.T) {
+	m := &FailAckMonitor{
+		window:        time.Minute,
+		threshold:     3,
+		observed:      make(map[failAckKey][]time.Time),
+		lastAlertedAt: make(map[failAckKey]time.Time),
+	}
+	key := failAckKey{direction: "greenfield_to_bsc", channelId: 1}
+	now := time.Now()
+
+	require.Equal(t, 1, m.record(key, now))
+	require.Equal(t, 2, m.record(key, now.Add(30*time.Second)))
+	require.Equal(t, 3, m.record(key, now.Add(50*time.Second)))
+	// the first observation falls out of the window
+	require.Equal(t, 3, m.record(key, now.Add(70*time.Second)))
+	require.Equal(t, 1, m.record(key, now.Add(5*time.Minute)))
+	require.Equal(t, 1, m.record(failAckKey{direction: "bsc_to_greenfield", channelId: 1}, now))
+}
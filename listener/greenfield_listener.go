@@ -3,10 +3,13 @@ package listener
 import (
 	"bytes"
 	"encoding/hex"
+	"fmt"
 	"strconv"
 	"sync"
 	"time"
 
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/spf13/viper"
 	abci "github.com/tendermint/tendermint/abci/types"
 	ctypes "github.com/tendermint/tendermint/rpc/core/types"
 	tmtypes "github.com/tendermint/tendermint/types"
@@ -16,41 +19,83 @@ import (
 	"github.com/bnb-chain/greenfield-relayer/db"
 	"github.com/bnb-chain/greenfield-relayer/db/dao"
 	"github.com/bnb-chain/greenfield-relayer/db/model"
+	"github.com/bnb-chain/greenfield-relayer/events"
 	"github.com/bnb-chain/greenfield-relayer/executor"
+	"github.com/bnb-chain/greenfield-relayer/hook"
 	"github.com/bnb-chain/greenfield-relayer/logging"
 	"github.com/bnb-chain/greenfield-relayer/metric"
 	"github.com/bnb-chain/greenfield-relayer/util"
 )
 
+const (
+	greenfieldEventTypePackageClaim = "greenfield.oracle.EventPackageClaim"
+	eventTypeMessage                = "message"
+)
+
 type GreenfieldListener struct {
 	config             *config.Config
 	greenfieldExecutor *executor.GreenfieldExecutor
 	bscExecutor        *executor.BSCExecutor
 	DaoManager         *dao.DaoManager
 	metricService      *metric.MetricService
+	failAckMonitor     *FailAckMonitor
+	reprocessUntil     uint64 // blocks up to this height are re-processed due to forced start height
+	pollInterval       *pollIntervalAdjuster
+	eventBus           *events.Bus
+	lastValidatorsHash []byte // validators hash of the last polled block
+	latestHeight       uint64 // latest block height of Greenfield when the next height is calculated
 }
 
 func NewGreenfieldListener(cfg *config.Config, gnfdExecutor *executor.GreenfieldExecutor, bscExecutor *executor.BSCExecutor,
-	dao *dao.DaoManager, ms *metric.MetricService) *GreenfieldListener {
+	dao *dao.DaoManager, ms *metric.MetricService, eventBus *events.Bus) *GreenfieldListener {
 	return &GreenfieldListener{
 		config:             cfg,
 		greenfieldExecutor: gnfdExecutor,
 		bscExecutor:        bscExecutor,
 		DaoManager:         dao,
 		metricService:      ms,
+		failAckMonitor:     NewFailAckMonitor(cfg, ms),
+		pollInterval:       newPollIntervalAdjuster(),
+		eventBus:           eventBus,
 	}
 }
 
 func (l *GreenfieldListener) StartLoop() {
+	if err := l.applyForcedStartHeight(); err != nil {
+		panic(fmt.Sprintf("failed to apply forced start height for Greenfield, err=%s", err.Error()))
+	}
 	for {
 		err := l.poll()
 		if err != nil {
-			time.Sleep(common.ErrorRetryInterval)
+			time.Sleep(errorRetryInterval(l.config.GreenfieldConfig.Upgrades, l.latestHeight))
 			continue
 		}
 	}
 }
 
+// applyForcedStartHeight makes the listener start from the configured start height even if higher blocks have been
+// processed. Blocks at or above the start height are deleted from DB so that they get re-processed, while the
+// transactions are kept as they are, and only missing transactions are saved when re-processing those blocks.
+func (l *GreenfieldListener) applyForcedStartHeight() error {
+	if !l.config.GreenfieldConfig.ForceStartHeight && !viper.GetBool(config.FlagForceFromHeight) {
+		return nil
+	}
+	startHeight := l.config.GreenfieldConfig.StartHeight
+	latestPolledBlock, err := l.getLatestPolledBlock()
+	if err != nil {
+		return err
+	}
+	if latestPolledBlock.Height < startHeight {
+		return nil
+	}
+	if err = l.DaoManager.GreenfieldDao.DeleteBlocksFromHeight(startHeight); err != nil {
+		return err
+	}
+	l.reprocessUntil = latestPolledBlock.Height
+	logging.Logger.Infof("Greenfield listener is forced to start from height %d, blocks up to %d will be re-processed", startHeight, l.reprocessUntil)
+	return nil
+}
+
 func (l *GreenfieldListener) poll() error {
 	nextHeight, err := l.calNextHeight()
 	if err != nil {
@@ -60,6 +105,7 @@ func (l *GreenfieldListener) poll() error {
 	if err != nil {
 		return err
 	}
+	l.observeValidatorsHash(block)
 	txs := make([]*model.GreenfieldRelayTransaction, 0)
 	wg := new(sync.WaitGroup)
 	wg.Add(3)
@@ -81,17 +127,46 @@ func (l *GreenfieldListener) poll() error {
 			logging.Logger.Errorf("encounter error when monitoring block at Height=%d, err=%s", nextHeight, err.Error())
 			return err
 		case tx := <-relayTxCh:
+			logging.Logger.Debugf("found transaction cid=%s at height=%d",
+				common.CorrelationId(metric.DirectionGnfdToBSC, tx.ChannelId, tx.Sequence), tx.Height)
 			txs = append(txs, tx)
 		case <-waitCh:
 			b := &model.GreenfieldBlock{
 				Chain:     block.ChainID,
 				Height:    uint64(block.Height),
 				BlockTime: block.Time.Unix(),
+				BlockHash: block.Hash().String(),
 			}
-			if err := l.DaoManager.GreenfieldDao.SaveBlockAndBatchTransactions(b, txs); err != nil {
+			saveBlockAndTxs := l.DaoManager.GreenfieldDao.SaveBlockAndBatchTransactions
+			if b.Height <= l.reprocessUntil {
+				saveBlockAndTxs = l.DaoManager.GreenfieldDao.SaveBlockAndMissingTransactions
+			}
+			if err := saveBlockAndTxs(b, txs); err != nil {
 				return err
 			}
-			l.metricService.SetGnfdSavedBlockHeight(uint64(block.Height))
+			l.metricService.SetSavedBlockHeight(metric.ChainGreenfield, uint64(block.Height))
+			if retention := l.config.GreenfieldConfig.BlockRetention; shouldPruneBlocks(b.Height, retention) {
+				if err := l.DaoManager.GreenfieldDao.DeleteBlocksBelowHeight(b.Height - retention); err != nil {
+					logging.Logger.Errorf("failed to prune Greenfield blocks below height %d, err=%s", b.Height-retention, err.Error())
+				}
+			}
+			l.pollInterval.ObserveBlock(uint64(block.Height), block.Time.Unix())
+			for _, tx := range txs {
+				if tx.PackageType == uint32(sdk.FailAckCrossChainPackageType) {
+					l.failAckMonitor.Observe(metric.DirectionGnfdToBSC, tx.ChannelId, block.Time)
+				}
+				l.eventBus.Publish(&events.Event{
+					Type:        events.EventTypePackage,
+					Direction:   metric.DirectionGnfdToBSC,
+					ChannelId:   tx.ChannelId,
+					Sequence:    tx.Sequence,
+					PackageType: tx.PackageType,
+					Height:      tx.Height,
+					Time:        tx.TxTime,
+				})
+				hook.Notify(hook.GreenfieldTxEvent(hook.PointPackageObserved, tx))
+			}
+			l.observeDeliveries(block, blockResults)
 			return nil
 		}
 	}
@@ -141,6 +216,16 @@ func (l *GreenfieldListener) monitorEndBlockEvents(height uint64, endBlockEvents
 	}
 }
 
+// observeValidatorsHash invalidates validators cached by both executors once the validator set changes at the block
+func (l *GreenfieldListener) observeValidatorsHash(block *tmtypes.Block) {
+	if l.lastValidatorsHash != nil && !bytes.Equal(l.lastValidatorsHash, block.ValidatorsHash) {
+		logging.Logger.Infof("validator set changes at height %d, invalidate cached validators", block.Height)
+		l.greenfieldExecutor.InvalidateCachedValidators(uint64(block.Height))
+		l.bscExecutor.InvalidateCachedValidators(uint64(block.Height))
+	}
+	l.lastValidatorsHash = block.ValidatorsHash
+}
+
 func (l *GreenfieldListener) monitorValidators(block *tmtypes.Block, errChan chan error, wg *sync.WaitGroup) {
 	defer wg.Done()
 	if err := l.monitorValidatorsHelper(block); err != nil {
@@ -204,6 +289,138 @@ func (l *GreenfieldListener) monitorValidatorsHelper(block *tmtypes.Block) error
 	return nil
 }
 
+// Backfill re-scans Greenfield blocks within [from, to] and saves the cross-chain transactions missing in DB, blocks
+// are not saved and validators are not synced so that the live listener is not affected.
+func (l *GreenfieldListener) Backfill(from, to uint64) error {
+	for height := from; height <= to; height++ {
+		txs, err := l.getRelayTxsAtHeight(height)
+		if err != nil {
+			return err
+		}
+		savedCnt, err := l.DaoManager.GreenfieldDao.SaveMissingTransactions(txs)
+		if err != nil {
+			return err
+		}
+		if savedCnt != 0 {
+			logging.Logger.Infof("backfilled %d transactions at Greenfield height=%d", savedCnt, height)
+		}
+	}
+	return nil
+}
+
+// getRelayTxsAtHeight re-fetches the Greenfield block at the height and extracts the cross-chain txs from the events
+// of txs and end block
+func (l *GreenfieldListener) getRelayTxsAtHeight(height uint64) ([]*model.GreenfieldRelayTransaction, error) {
+	blockResults, _, err := l.getBlockAndBlockResult(height)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Greenfield block at height=%d, err=%s", height, err.Error())
+	}
+	txs := make([]*model.GreenfieldRelayTransaction, 0)
+	events := make([]abci.Event, 0)
+	for _, tx := range blockResults.TxsResults {
+		events = append(events, tx.Events...)
+	}
+	events = append(events, blockResults.EndBlockEvents...)
+	for _, e := range events {
+		if e.Type != l.config.RelayConfig.GreenfieldEventTypeCrossChain {
+			continue
+		}
+		relayTx, err := constructRelayTx(e, height)
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, relayTx)
+	}
+	return txs, nil
+}
+
+// observeDeliveries records which relayer delivered BSC packages by the claim txs in the block. It is best-effort,
+// failures are only logged so that they do not block listening.
+func (l *GreenfieldListener) observeDeliveries(block *tmtypes.Block, blockResults *ctypes.ResultBlockResults) {
+	deliveries := make([]*model.PeerDelivery, 0)
+	for i, txRes := range blockResults.TxsResults {
+		if txRes.Code != 0 || i >= len(block.Txs) {
+			continue
+		}
+		sender := ""
+		claims := make([]abci.Event, 0)
+		for _, e := range txRes.Events {
+			switch e.Type {
+			case eventTypeMessage:
+				for _, attr := range e.Attributes {
+					if string(attr.Key) == "sender" && sender == "" {
+						sender = string(attr.Value)
+					}
+				}
+			case greenfieldEventTypePackageClaim:
+				claims = append(claims, e)
+			}
+		}
+		if sender == "" || len(claims) == 0 {
+			continue
+		}
+		for _, e := range claims {
+			d, err := l.parsePackageClaim(e)
+			if err != nil {
+				logging.Logger.Errorf("failed to parse package claim event at height=%d, err=%s", block.Height, err.Error())
+				continue
+			}
+			if d == nil {
+				continue
+			}
+			d.Relayer = sender
+			d.ClaimTxHash = fmt.Sprintf("%X", block.Txs[i].Hash())
+			d.Height = uint64(block.Height)
+			d.DeliveredTime = block.Time.Unix()
+			d.Latency = -1
+			pkg, err := l.DaoManager.BSCDao.GetPackageByChannelIdAndSequence(d.ChannelId, d.Sequence)
+			if err == nil && pkg.Id != 0 {
+				d.Latency = d.DeliveredTime - pkg.TxTime
+			}
+			deliveries = append(deliveries, d)
+		}
+	}
+	if err := l.DaoManager.PeerDao.SavePeerDeliveries(deliveries); err != nil {
+		logging.Logger.Errorf("failed to save peer deliveries at height=%d, err=%s", block.Height, err.Error())
+		return
+	}
+	for _, d := range deliveries {
+		l.metricService.ObservePeerDelivery(d.Direction, d.Relayer, d.ChannelId, d.Sequence, d.Latency)
+	}
+}
+
+// parsePackageClaim parses the claim event of a package, nil is returned if the package is not from BSC
+func (l *GreenfieldListener) parsePackageClaim(event abci.Event) (*model.PeerDelivery, error) {
+	d := &model.PeerDelivery{Direction: metric.DirectionBSCToGnfd}
+	var srcChainId uint64
+	for _, attr := range event.Attributes {
+		switch string(attr.Key) {
+		case "src_chain_id":
+			id, err := strconv.ParseUint(string(attr.Value), 10, 32)
+			if err != nil {
+				return nil, err
+			}
+			srcChainId = id
+		case "channel_id":
+			channelId, err := strconv.ParseUint(string(attr.Value), 10, 8)
+			if err != nil {
+				return nil, err
+			}
+			d.ChannelId = uint8(channelId)
+		case "receive_sequence":
+			seq, err := util.QuotedStrToIntWithBitSize(string(attr.Value), 64)
+			if err != nil {
+				return nil, err
+			}
+			d.Sequence = seq
+		}
+	}
+	if srcChainId != uint64(l.config.BSCConfig.ChainId) {
+		return nil, nil
+	}
+	return d, nil
+}
+
 func (l *GreenfieldListener) calNextHeight() (uint64, error) {
 	latestPolledBlock, err := l.getLatestPolledBlock()
 	if err != nil {
@@ -222,15 +439,22 @@ func (l *GreenfieldListener) calNextHeight() (uint64, error) {
 		logging.Logger.Errorf("failed to get latest block height, error: %s", err.Error())
 		return 0, err
 	}
-	// pauses relayer for a bit since it already caught the newest block
+	l.latestHeight = latestBlockHeight
+	// pauses relayer for a bit since it already caught the newest block, shorter the more it is behind
 	if int64(nextHeight) == int64(latestBlockHeight) {
-		time.Sleep(common.ListenerPauseTime)
+		time.Sleep(l.pollInterval.Interval(0))
 		return nextHeight, nil
 	}
+	if latestBlockHeight > nextHeight {
+		time.Sleep(l.pollInterval.Interval(latestBlockHeight - nextHeight))
+	}
 	return nextHeight, nil
 }
 
 func (l *GreenfieldListener) sync(nextHeight uint64, validatorsHash string) error {
+	if l.bscExecutor.GasBudgetExceeded() {
+		return fmt.Errorf("daily gas budget of BSC is exceeded, light block sync at height %d is paused", nextHeight)
+	}
 	logging.Logger.Infof("syncing tendermint light block at height %d", nextHeight)
 	txHash, err := l.bscExecutor.SyncTendermintLightBlock(nextHeight)
 	if err != nil {
@@ -311,6 +535,7 @@ func constructRelayTx(event abci.Event, height uint64) (*model.GreenfieldRelayTr
 			logging.Logger.Errorf("unexpected attr, key is %s", attr.Key)
 		}
 	}
+	relayTx.ResourceType, relayTx.ResourceId = parseResource(relayTx.ChannelId, relayTx.PackageType, relayTx.PayLoad)
 	relayTx.Status = db.Saved
 	relayTx.Height = height
 	relayTx.UpdatedTime = time.Now().Unix()
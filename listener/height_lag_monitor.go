@@ -0,0 +1,125 @@
+package listener
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bnb-chain/greenfield-relayer/common"
+	"github.com/bnb-chain/greenfield-relayer/config"
+	"github.com/bnb-chain/greenfield-relayer/db/dao"
+	"github.com/bnb-chain/greenfield-relayer/executor"
+	"github.com/bnb-chain/greenfield-relayer/logging"
+	"github.com/bnb-chain/greenfield-relayer/metric"
+)
+
+// HeightLagMonitor measures how far the listeners are behind the chain heads by the latest heights saved in DB, and
+// alerts when the lag exceeds the threshold of the chain, so that falling behind is caught before sequences back up.
+type HeightLagMonitor struct {
+	config             *config.Config
+	daoManager         *dao.DaoManager
+	greenfieldExecutor *executor.GreenfieldExecutor
+	bscExecutor        *executor.BSCExecutor
+	metricService      *metric.MetricService
+	thresholds         map[string]int64
+	lastAlertedAt      map[string]time.Time
+}
+
+func NewHeightLagMonitor(cfg *config.Config, dao *dao.DaoManager, greenfieldExecutor *executor.GreenfieldExecutor,
+	bscExecutor *executor.BSCExecutor, ms *metric.MetricService) *HeightLagMonitor {
+	gnfdThreshold := int64(common.DefaultGreenfieldHeightLagThreshold)
+	if cfg.AlertConfig.GreenfieldHeightLagThreshold > 0 {
+		gnfdThreshold = cfg.AlertConfig.GreenfieldHeightLagThreshold
+	}
+	bscThreshold := int64(common.DefaultBSCHeightLagThreshold)
+	if cfg.AlertConfig.BSCHeightLagThreshold > 0 {
+		bscThreshold = cfg.AlertConfig.BSCHeightLagThreshold
+	}
+	return &HeightLagMonitor{
+		config:             cfg,
+		daoManager:         dao,
+		greenfieldExecutor: greenfieldExecutor,
+		bscExecutor:        bscExecutor,
+		metricService:      ms,
+		thresholds: map[string]int64{
+			metric.ChainGreenfield: gnfdThreshold,
+			metric.ChainBSC:        bscThreshold,
+		},
+		lastAlertedAt: make(map[string]time.Time),
+	}
+}
+
+func (m *HeightLagMonitor) StartLoop() {
+	common.Schedule(common.TaskHeightLagMonitor, common.HeightLagCheckInterval, func() {
+		if err := m.check(); err != nil {
+			logging.Logger.Errorf("failed to check listener height lag, err=%s", err.Error())
+		}
+	}).Run()
+}
+
+// check observes the listeners of enabled directions only, the listener of a disabled direction is not running
+func (m *HeightLagMonitor) check() error {
+	if m.config.RelayConfig.GreenfieldToBSCEnabled() {
+		if err := m.checkGreenfield(); err != nil {
+			return err
+		}
+	}
+	if m.config.RelayConfig.BSCToGreenfieldEnabled() {
+		return m.checkBSC()
+	}
+	return nil
+}
+
+func (m *HeightLagMonitor) checkGreenfield() error {
+	gnfdHead, err := m.greenfieldExecutor.GetLatestBlockHeight()
+	if err != nil {
+		return err
+	}
+	gnfdBlock, err := m.daoManager.GreenfieldDao.GetLatestBlock()
+	if err != nil {
+		return err
+	}
+	m.observe(metric.ChainGreenfield, gnfdHead, gnfdBlock.Height, time.Now())
+	return nil
+}
+
+func (m *HeightLagMonitor) checkBSC() error {
+	bscHead, err := m.bscExecutor.GetLatestBlockHeight()
+	if err != nil {
+		return err
+	}
+	bscBlock, err := m.daoManager.BSCDao.GetLatestBlock()
+	if err != nil {
+		return err
+	}
+	m.observe(metric.ChainBSC, bscHead, bscBlock.Height, time.Now())
+	return nil
+}
+
+// observe updates the metric of the chain, the alert is sent at most once per HeightLagAlertInterval for each chain
+func (m *HeightLagMonitor) observe(chain string, head, processed uint64, now time.Time) {
+	// nothing is processed by the listener yet
+	if processed == 0 {
+		return
+	}
+	lag := heightLag(head, processed)
+	m.metricService.SetListenerHeightLag(chain, lag)
+
+	if lag <= m.thresholds[chain] || now.Sub(m.lastAlertedAt[chain]) < common.HeightLagAlertInterval {
+		return
+	}
+	m.lastAlertedAt[chain] = now
+	msg := fmt.Sprintf("listener lags behind the chain head, chain=%s, head=%d, processed=%d, lag=%d blocks",
+		chain, head, processed, lag)
+	logging.Logger.Error(msg)
+	config.SendTelegramMessage(m.config.AlertConfig.Identity, m.config.AlertConfig.TelegramBotId,
+		m.config.AlertConfig.TelegramChatId, msg)
+}
+
+// heightLag returns the number of blocks processed is behind head, a head behind processed, e.g. of a lagging node, is
+// treated as no lag
+func heightLag(head, processed uint64) int64 {
+	if head <= processed {
+		return 0
+	}
+	return int64(head - processed)
+}
@@ -0,0 +1,13 @@
+package listener
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeightLag(t *testing.T) {
+	require.Equal(t, int64(10), heightLag(110, 100))
+	require.Equal(t, int64(0), heightLag(100, 100))
+	require.Equal(t, int64(0), heightLag(90, 100))
+}
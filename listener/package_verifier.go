@@ -0,0 +1,66 @@
+package listener
+
+import (
+	"fmt"
+
+	"github.com/bnb-chain/greenfield-relayer/common"
+	"github.com/bnb-chain/greenfield-relayer/db/model"
+)
+
+// VerifyPackages re-fetches the BSC block at the height and checks that every package exists in it with the same
+// content, so that votes are never signed for packages which are only found in DB
+func (l *BSCListener) VerifyPackages(height uint64, pkgs []*model.BscRelayPackage) error {
+	header, err := l.bscExecutor.GetBlockHeaderAtHeight(height)
+	if err != nil {
+		return fmt.Errorf("failed to get BSC block header at height=%d, err=%s", height, err.Error())
+	}
+	onChainPkgs, err := l.getRelayPackagesFromBlock(l.bscExecutor, header)
+	if err != nil {
+		return err
+	}
+	onChain := make(map[string]*model.BscRelayPackage, len(onChainPkgs))
+	for _, p := range onChainPkgs {
+		onChain[packageKey(p.ChannelId, p.PackageSequence)] = p
+	}
+	for _, pkg := range pkgs {
+		expected, ok := onChain[packageKey(pkg.ChannelId, pkg.PackageSequence)]
+		if !ok || expected.OracleSequence != pkg.OracleSequence || expected.PayLoad != pkg.PayLoad ||
+			expected.TxHash != pkg.TxHash || expected.TxIndex != pkg.TxIndex || expected.TxTime != pkg.TxTime {
+			return fmt.Errorf("%w, package with channel id %d and sequence %d at BSC height %d",
+				common.ErrPackageMismatch, pkg.ChannelId, pkg.PackageSequence, height)
+		}
+	}
+	return nil
+}
+
+// VerifyTransactions re-fetches the Greenfield blocks of the txs and checks that every tx exists in the cross-chain
+// events of its block with the same content, so that votes are never signed for txs which are only found in DB
+func (l *GreenfieldListener) VerifyTransactions(txs []*model.GreenfieldRelayTransaction) error {
+	onChainByHeight := make(map[uint64]map[string]*model.GreenfieldRelayTransaction)
+	for _, tx := range txs {
+		onChain, ok := onChainByHeight[tx.Height]
+		if !ok {
+			relayTxs, err := l.getRelayTxsAtHeight(tx.Height)
+			if err != nil {
+				return err
+			}
+			onChain = make(map[string]*model.GreenfieldRelayTransaction, len(relayTxs))
+			for _, t := range relayTxs {
+				onChain[packageKey(t.ChannelId, t.Sequence)] = t
+			}
+			onChainByHeight[tx.Height] = onChain
+		}
+		expected, ok := onChain[packageKey(tx.ChannelId, tx.Sequence)]
+		if !ok || expected.PayLoad != tx.PayLoad || expected.PackageType != tx.PackageType || expected.TxTime != tx.TxTime ||
+			expected.SrcChainId != tx.SrcChainId || expected.DestChainId != tx.DestChainId ||
+			expected.RelayerFee != tx.RelayerFee || expected.AckRelayerFee != tx.AckRelayerFee {
+			return fmt.Errorf("%w, tx with channel id %d and sequence %d at Greenfield height %d",
+				common.ErrPackageMismatch, tx.ChannelId, tx.Sequence, tx.Height)
+		}
+	}
+	return nil
+}
+
+func packageKey(channelId uint8, sequence uint64) string {
+	return fmt.Sprintf("%d-%d", channelId, sequence)
+}
@@ -0,0 +1,74 @@
+package listener
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bnb-chain/greenfield-relayer/common"
+	"github.com/bnb-chain/greenfield-relayer/config"
+)
+
+// errorRetryInterval backs off longer while the chain is around a known upgrade, when its nodes are expected to halt or
+// behave differently, instead of spamming failing requests
+func errorRetryInterval(upgrades []config.ChainUpgrade, latestHeight uint64) time.Duration {
+	if latestHeight != 0 && config.UpgradeAt(upgrades, latestHeight) != nil {
+		return common.UpgradeRetryInterval
+	}
+	return common.ErrorRetryInterval
+}
+
+// pollIntervalAdjuster decides how long a listener pauses before polling again. A listener caught up with the chain waits
+// about half of the observed block interval, so that new blocks are picked up quickly without hammering the node. The
+// pause is halved for every block the listener is behind, since nodes behind a load balancer may not serve the latest
+// blocks yet, and a listener ListenerNoPauseLag blocks or more behind polls without pause.
+type pollIntervalAdjuster struct {
+	mutex            sync.Mutex
+	lastHeight       uint64
+	lastBlockTime    int64         // in second
+	avgBlockInterval time.Duration // exponential moving average of block intervals
+}
+
+func newPollIntervalAdjuster() *pollIntervalAdjuster {
+	return &pollIntervalAdjuster{}
+}
+
+// ObserveBlock records the block time of a processed block, only consecutive blocks are used to measure block interval
+func (a *pollIntervalAdjuster) ObserveBlock(height uint64, blockTime int64) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if a.lastHeight != 0 && height == a.lastHeight+1 && blockTime >= a.lastBlockTime {
+		interval := time.Duration(blockTime-a.lastBlockTime) * time.Second
+		if a.avgBlockInterval == 0 {
+			a.avgBlockInterval = interval
+		} else {
+			a.avgBlockInterval = (a.avgBlockInterval*7 + interval) / 8
+		}
+	}
+	a.lastHeight = height
+	a.lastBlockTime = blockTime
+}
+
+// Interval returns the pause before next poll given how many blocks the listener is behind the chain. The pause is
+// clamped after halving it by the lag, so that it never falls below MinListenerPauseTime.
+func (a *pollIntervalAdjuster) Interval(blocksBehind uint64) time.Duration {
+	if blocksBehind >= common.ListenerNoPauseLag {
+		return 0
+	}
+	interval := a.caughtUpInterval() >> blocksBehind
+	if interval < common.MinListenerPauseTime {
+		return common.MinListenerPauseTime
+	}
+	if interval > common.MaxListenerPauseTime {
+		return common.MaxListenerPauseTime
+	}
+	return interval
+}
+
+func (a *pollIntervalAdjuster) caughtUpInterval() time.Duration {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if a.avgBlockInterval == 0 {
+		return common.ListenerPauseTime
+	}
+	return a.avgBlockInterval / 2
+}
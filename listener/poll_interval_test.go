@@ -0,0 +1,63 @@
+package listener
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-relayer/common"
+)
+
+func TestPollIntervalAdjuster(t *testing.T) {
+	a := newPollIntervalAdjuster()
+	require.Equal(t, common.ListenerPauseTime, a.Interval(0))
+
+	a.ObserveBlock(100, 1000)
+	a.ObserveBlock(101, 1003)
+	require.Equal(t, 1500*time.Millisecond, a.Interval(0))
+
+	// non-consecutive blocks are not used to measure block interval
+	a.ObserveBlock(110, 1100)
+	require.Equal(t, 1500*time.Millisecond, a.Interval(0))
+
+	a.ObserveBlock(111, 1100)
+	require.Equal(t, 1312500*time.Microsecond, a.Interval(0))
+}
+
+func TestPollIntervalByLag(t *testing.T) {
+	a := newPollIntervalAdjuster()
+	a.ObserveBlock(100, 1000)
+	a.ObserveBlock(101, 1004)
+	for _, c := range []struct {
+		blocksBehind uint64
+		interval     time.Duration
+	}{
+		{0, 2 * time.Second},
+		{1, time.Second},
+		{2, 500 * time.Millisecond},
+		{3, 250 * time.Millisecond},
+		{common.ListenerNoPauseLag, 0},
+		{1000, 0},
+	} {
+		require.Equal(t, c.interval, a.Interval(c.blocksBehind), "blocks behind %d", c.blocksBehind)
+	}
+}
+
+func TestPollIntervalFloor(t *testing.T) {
+	a := newPollIntervalAdjuster()
+	a.ObserveBlock(100, 1000)
+	a.ObserveBlock(101, 1001)
+	for _, c := range []struct {
+		blocksBehind uint64
+		interval     time.Duration
+	}{
+		{0, 500 * time.Millisecond},
+		{1, 250 * time.Millisecond},
+		{2, common.MinListenerPauseTime},
+		{3, common.MinListenerPauseTime},
+		{common.ListenerNoPauseLag, 0},
+	} {
+		require.Equal(t, c.interval, a.Interval(c.blocksBehind), "blocks behind %d", c.blocksBehind)
+	}
+}
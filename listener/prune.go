@@ -0,0 +1,9 @@
+package listener
+
+import "github.com/bnb-chain/greenfield-relayer/common"
+
+// shouldPruneBlocks tells whether block rows below height-retention should be pruned after saving the block at height,
+// listeners resume from their checkpoints so only recent blocks are needed for fork detection
+func shouldPruneBlocks(height, retention uint64) bool {
+	return retention != 0 && height > retention && height%common.BlockPruneInterval == 0
+}
@@ -0,0 +1,52 @@
+package listener
+
+import (
+	"encoding/hex"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/bnb-chain/greenfield-relayer/payload"
+)
+
+const (
+	ResourceTypeBucket = "bucket"
+	ResourceTypeObject = "object"
+	ResourceTypeGroup  = "group"
+
+	bucketChannelId = payload.ChannelBucket
+	objectChannelId = payload.ChannelObject
+	groupChannelId  = payload.ChannelGroup
+)
+
+var resourceTypeByChannel = map[uint8]string{
+	bucketChannelId: ResourceTypeBucket,
+	objectChannelId: ResourceTypeObject,
+	groupChannelId:  ResourceTypeGroup,
+}
+
+// parseResource decodes the resource type and id from the rlp encoded application payload of a storage channel
+// package by the registered payload decoder. Empty strings are returned for other channels, FAIL_ACK packages and
+// undecodable payloads.
+func parseResource(channelId uint8, packageType uint32, payloadStr string) (string, string) {
+	resourceType, ok := resourceTypeByChannel[channelId]
+	if !ok {
+		return "", ""
+	}
+	pkgType := sdk.CrossChainPackageType(packageType)
+	if pkgType != sdk.SynCrossChainPackageType && pkgType != sdk.AckCrossChainPackageType {
+		return "", ""
+	}
+	bz, err := hex.DecodeString(payloadStr)
+	if err != nil {
+		return "", ""
+	}
+	decoded, err := payload.Decode(channelId, pkgType, bz)
+	if err != nil {
+		return "", ""
+	}
+	id := decoded.Field("id")
+	if id == "" {
+		return "", ""
+	}
+	return resourceType, id
+}
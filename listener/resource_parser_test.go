@@ -0,0 +1,34 @@
+package listener
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseResource(t *testing.T) {
+	syn, err := rlp.EncodeToBytes([]interface{}{big.NewInt(42), common.HexToAddress("0x01")})
+	require.NoError(t, err)
+	resourceType, id := parseResource(bucketChannelId, uint32(sdk.SynCrossChainPackageType), hex.EncodeToString(syn))
+	require.Equal(t, ResourceTypeBucket, resourceType)
+	require.Equal(t, "42", id)
+
+	ack, err := rlp.EncodeToBytes([]interface{}{uint8(0), big.NewInt(7)})
+	require.NoError(t, err)
+	resourceType, id = parseResource(groupChannelId, uint32(sdk.AckCrossChainPackageType), hex.EncodeToString(ack))
+	require.Equal(t, ResourceTypeGroup, resourceType)
+	require.Equal(t, "7", id)
+
+	resourceType, id = parseResource(1, uint32(sdk.SynCrossChainPackageType), hex.EncodeToString(syn))
+	require.Empty(t, resourceType)
+	require.Empty(t, id)
+
+	resourceType, id = parseResource(objectChannelId, uint32(sdk.SynCrossChainPackageType), "zz")
+	require.Empty(t, resourceType)
+	require.Empty(t, id)
+}
@@ -0,0 +1,129 @@
+package listener
+
+import (
+	"fmt"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/bnb-chain/greenfield-relayer/common"
+	"github.com/bnb-chain/greenfield-relayer/config"
+	"github.com/bnb-chain/greenfield-relayer/db/model"
+	"github.com/bnb-chain/greenfield-relayer/logging"
+	"github.com/bnb-chain/greenfield-relayer/metric"
+)
+
+// mismatches between the packages extracted by the listener and the shadow listener
+const (
+	mismatchMissing   = "missing"   // found by the shadow listener only, the package would never be relayed
+	mismatchExtra     = "extra"     // found by the listener only
+	mismatchDifferent = "different" // found by both with different content
+)
+
+type shadowBlock struct {
+	header *types.Header
+	pkgs   []*model.BscRelayPackage
+}
+
+type packageMismatch struct {
+	mismatch  string
+	channelId uint8
+	sequence  uint64
+}
+
+func newShadowBlocks(cfg *config.Config) chan *shadowBlock {
+	if !cfg.BSCConfig.ShadowListenerEnabled {
+		return nil
+	}
+	return make(chan *shadowBlock, common.ShadowListenerQueueSize)
+}
+
+// shadowVerify queues the block for the shadow listener, the block is skipped if the shadow listener falls behind so
+// that listening is never blocked
+func (l *BSCListener) shadowVerify(header *types.Header, pkgs []*model.BscRelayPackage) {
+	if l.shadowBlocks == nil {
+		return
+	}
+	select {
+	case l.shadowBlocks <- &shadowBlock{header: header, pkgs: pkgs}:
+	default:
+		logging.Logger.Infof("shadow listener falls behind, skip BSC block at height=%d", header.Number.Uint64())
+	}
+}
+
+// shadowVerifyLoop extracts packages of the queued blocks from the receipts of their txs, independent of the logs
+// filtered by the node which the listener relies on, and reports the packages the two disagree on
+func (l *BSCListener) shadowVerifyLoop() {
+	var lastAlertedAt time.Time
+	for b := range l.shadowBlocks {
+		height := b.header.Number.Uint64()
+		shadowPkgs, err := l.getRelayPackagesFromReceipts(b.header)
+		if err != nil {
+			logging.Logger.Errorf("shadow listener failed to get packages at BSC height=%d, err=%s", height, err.Error())
+			continue
+		}
+		mismatches := diffRelayPackages(b.pkgs, shadowPkgs)
+		for _, m := range mismatches {
+			l.monitorService.IncListenerShadowMismatches(metric.ChainBSC, m.mismatch)
+			logging.Logger.Errorf("shadow listener disagrees on package with channel id %d and sequence %d at BSC height=%d, mismatch=%s",
+				m.channelId, m.sequence, height, m.mismatch)
+		}
+		if len(mismatches) == 0 || time.Since(lastAlertedAt) < common.ShadowListenerAlertInterval {
+			continue
+		}
+		lastAlertedAt = time.Now()
+		config.SendTelegramMessage(l.config.AlertConfig.Identity, l.config.AlertConfig.TelegramBotId, l.config.AlertConfig.TelegramChatId,
+			fmt.Sprintf("shadow listener disagrees on %d packages at BSC height %d", len(mismatches), height))
+	}
+}
+
+func (l *BSCListener) getRelayPackagesFromReceipts(header *types.Header) ([]*model.BscRelayPackage, error) {
+	receipts, err := l.bscExecutor.GetBlockReceipts(header.Hash())
+	if err != nil {
+		return nil, err
+	}
+	eventHash := l.getCrossChainPackageEventHash()
+	contracts := make(map[ethcommon.Address]bool)
+	for _, addr := range l.config.RelayConfig.GetMonitorContractAddrs() {
+		contracts[addr] = true
+	}
+	logs := make([]types.Log, 0)
+	for _, receipt := range receipts {
+		for _, log := range receipt.Logs {
+			if len(log.Topics) != 0 && log.Topics[0] == eventHash && contracts[log.Address] {
+				logs = append(logs, *log)
+			}
+		}
+	}
+	return l.parseRelayPackages(header, logs), nil
+}
+
+// diffRelayPackages returns the packages the listener and the shadow listener disagree on, in the order of the listener
+// and then the shadow listener
+func diffRelayPackages(pkgs, shadowPkgs []*model.BscRelayPackage) []*packageMismatch {
+	shadow := make(map[string]*model.BscRelayPackage, len(shadowPkgs))
+	for _, p := range shadowPkgs {
+		shadow[packageKey(p.ChannelId, p.PackageSequence)] = p
+	}
+	mismatches := make([]*packageMismatch, 0)
+	found := make(map[string]bool, len(pkgs))
+	for _, p := range pkgs {
+		key := packageKey(p.ChannelId, p.PackageSequence)
+		found[key] = true
+		s, ok := shadow[key]
+		switch {
+		case !ok:
+			mismatches = append(mismatches, &packageMismatch{mismatch: mismatchExtra, channelId: p.ChannelId, sequence: p.PackageSequence})
+		case s.OracleSequence != p.OracleSequence || s.PayLoad != p.PayLoad || s.TxHash != p.TxHash ||
+			s.TxIndex != p.TxIndex || s.Height != p.Height:
+			mismatches = append(mismatches, &packageMismatch{mismatch: mismatchDifferent, channelId: p.ChannelId, sequence: p.PackageSequence})
+		}
+	}
+	for _, s := range shadowPkgs {
+		if !found[packageKey(s.ChannelId, s.PackageSequence)] {
+			mismatches = append(mismatches, &packageMismatch{mismatch: mismatchMissing, channelId: s.ChannelId, sequence: s.PackageSequence})
+		}
+	}
+	return mismatches
+}
@@ -0,0 +1,29 @@
+package listener
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-relayer/db/model"
+)
+
+func TestDiffRelayPackages(t *testing.T) {
+	pkgs := []*model.BscRelayPackage{
+		{ChannelId: 1, PackageSequence: 1, OracleSequence: 1, PayLoad: "aa", TxHash: "0x1"},
+		{ChannelId: 1, PackageSequence: 2, OracleSequence: 1, PayLoad: "bb", TxHash: "0x1"},
+		{ChannelId: 2, PackageSequence: 1, OracleSequence: 1, PayLoad: "cc", TxHash: "0x2"},
+	}
+	require.Empty(t, diffRelayPackages(pkgs, pkgs))
+
+	shadowPkgs := []*model.BscRelayPackage{
+		pkgs[0],
+		{ChannelId: 1, PackageSequence: 2, OracleSequence: 1, PayLoad: "bc", TxHash: "0x1"},
+		{ChannelId: 3, PackageSequence: 5, OracleSequence: 2, PayLoad: "dd", TxHash: "0x3"},
+	}
+	require.Equal(t, []*packageMismatch{
+		{mismatch: mismatchDifferent, channelId: 1, sequence: 2},
+		{mismatch: mismatchExtra, channelId: 2, sequence: 1},
+		{mismatch: mismatchMissing, channelId: 3, sequence: 5},
+	}, diffRelayPackages(pkgs, shadowPkgs))
+}
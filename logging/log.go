@@ -1,6 +1,7 @@
 package logging
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/op/go-logging"
@@ -9,6 +10,8 @@ import (
 	"github.com/bnb-chain/greenfield-relayer/config"
 )
 
+const defaultSyslogTag = "greenfield-relayer"
+
 var (
 	// Logger instance for quick declarative logging levels
 	Logger = logging.MustGetLogger("greenfield-relayer")
@@ -21,6 +24,7 @@ var (
 		"INFO":     logging.INFO,
 		"DEBUG":    logging.DEBUG,
 	}
+	logFormat = logging.MustStringFormatter(`%{time:2006-01-02 15:04:05} %{level} %{shortfunc} %{message}`)
 )
 
 // InitLogger initialises the logger.
@@ -28,12 +32,8 @@ func InitLogger(config *config.LogConfig) {
 	backends := make([]logging.Backend, 0)
 
 	if config.UseConsoleLogger {
-		consoleFormat := logging.MustStringFormatter(`%{time:2006-01-02 15:04:05} %{level} %{shortfunc} %{message}`)
 		consoleLogger := logging.NewLogBackend(os.Stdout, "", 0)
-		consoleFormatter := logging.NewBackendFormatter(consoleLogger, consoleFormat)
-		consoleLoggerLeveled := logging.AddModuleLevel(consoleFormatter)
-		consoleLoggerLeveled.SetLevel(levels[config.Level], "")
-		backends = append(backends, consoleLoggerLeveled)
+		backends = append(backends, leveled(consoleLogger, logFormat, config.Level))
 	}
 
 	if config.UseFileLogger {
@@ -44,12 +44,33 @@ func InitLogger(config *config.LogConfig) {
 			MaxAge:     config.MaxAgeToRetainLogFilesInDays, // MaxAge is the maximum number of days to retain old log files
 			Compress:   config.Compress,
 		}, "", 0)
-		fileFormat := logging.MustStringFormatter(`%{time:2006-01-02 15:04:05} %{level} %{shortfunc} %{message}`)
-		fileFormatter := logging.NewBackendFormatter(fileLogger, fileFormat)
-		fileLoggerLeveled := logging.AddModuleLevel(fileFormatter)
-		fileLoggerLeveled.SetLevel(levels[config.Level], "")
-		backends = append(backends, fileLoggerLeveled)
+		backends = append(backends, leveled(fileLogger, logFormat, config.Level))
+	}
+
+	if config.UseSyslogLogger {
+		tag := config.SyslogTag
+		if tag == "" {
+			tag = defaultSyslogTag
+		}
+		writer, err := newSyslogWriter(config.SyslogNetwork, config.SyslogAddr, tag)
+		if err != nil {
+			panic(fmt.Sprintf("failed to connect to syslog, err=%s", err.Error()))
+		}
+		// syslog adds the time itself
+		syslogFormat := logging.MustStringFormatter(`%{level} %{shortfunc} %{message}`)
+		backends = append(backends, leveled(logging.NewLogBackend(writer, "", 0), syslogFormat, config.Level))
+	}
+
+	if config.UseLokiLogger {
+		lokiLogger := logging.NewLogBackend(newLokiWriter(config.LokiPushUrl, config.LokiLabels), "", 0)
+		backends = append(backends, leveled(lokiLogger, logFormat, config.Level))
 	}
 
 	logging.SetBackend(backends...)
 }
+
+func leveled(backend logging.Backend, format logging.Formatter, level string) logging.LeveledBackend {
+	l := logging.AddModuleLevel(logging.NewBackendFormatter(backend, format))
+	l.SetLevel(levels[level], "")
+	return l
+}
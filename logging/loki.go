@@ -0,0 +1,96 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	lokiFlushInterval   = 1 * time.Second
+	lokiPushTimeout     = 5 * time.Second
+	lokiMaxBufferedLogs = 10000 // the oldest logs are dropped if Loki can not keep up
+)
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"` // pairs of unix nano timestamp and log line
+}
+
+type lokiPushRequest struct {
+	Streams []lokiStream `json:"streams"`
+}
+
+// lokiWriter buffers written log lines and pushes them to Loki in batches. Failures are reported to stderr rather than
+// the logger, since they would be written back to the writer.
+type lokiWriter struct {
+	url     string
+	labels  map[string]string
+	client  *http.Client
+	mtx     sync.Mutex
+	entries [][2]string
+}
+
+func newLokiWriter(url string, labels map[string]string) *lokiWriter {
+	if len(labels) == 0 {
+		labels = map[string]string{"job": "greenfield-relayer"}
+	}
+	w := &lokiWriter{
+		url:    url,
+		labels: labels,
+		client: &http.Client{Timeout: lokiPushTimeout},
+	}
+	go w.flushLoop()
+	return w
+}
+
+func (w *lokiWriter) Write(p []byte) (int, error) {
+	line := strings.TrimRight(string(p), "\n")
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if len(w.entries) >= lokiMaxBufferedLogs {
+		w.entries = w.entries[1:]
+	}
+	w.entries = append(w.entries, [2]string{strconv.FormatInt(time.Now().UnixNano(), 10), line})
+	return len(p), nil
+}
+
+func (w *lokiWriter) flushLoop() {
+	ticker := time.NewTicker(lokiFlushInterval)
+	for range ticker.C {
+		if err := w.flush(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to push logs to loki, err=%s\n", err.Error())
+		}
+	}
+}
+
+// flush pushes the buffered logs, they are dropped if the push fails
+func (w *lokiWriter) flush() error {
+	w.mtx.Lock()
+	entries := w.entries
+	w.entries = nil
+	w.mtx.Unlock()
+	if len(entries) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(lokiPushRequest{Streams: []lokiStream{{Stream: w.labels, Values: entries}}})
+	if err != nil {
+		return err
+	}
+	resp, err := w.client.Post(w.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected status %d from loki", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package logging
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLokiWriterFlush(t *testing.T) {
+	var pushed lokiPushRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&pushed))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	w := &lokiWriter{url: server.URL, labels: map[string]string{"job": "relayer"}, client: server.Client()}
+	_, err := w.Write([]byte("first line\n"))
+	require.NoError(t, err)
+	_, err = w.Write([]byte("second line\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.flush())
+
+	require.Len(t, pushed.Streams, 1)
+	require.Equal(t, "relayer", pushed.Streams[0].Stream["job"])
+	require.Len(t, pushed.Streams[0].Values, 2)
+	require.Equal(t, "first line", pushed.Streams[0].Values[0][1])
+	require.Empty(t, w.entries)
+}
@@ -0,0 +1,13 @@
+//go:build windows || plan9
+
+package logging
+
+import (
+	"errors"
+	"io"
+)
+
+// newSyslogWriter returns an error since log/syslog is not implemented on the platform
+func newSyslogWriter(network, addr, tag string) (io.Writer, error) {
+	return nil, errors.New("syslog output is not supported on this platform")
+}
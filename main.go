@@ -1,15 +1,26 @@
 package main
 
 import (
+	"encoding/hex"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"math/big"
+	"os"
+	"strings"
 
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 
 	"github.com/bnb-chain/greenfield-relayer/app"
+	"github.com/bnb-chain/greenfield-relayer/bls"
 	"github.com/bnb-chain/greenfield-relayer/config"
+	"github.com/bnb-chain/greenfield-relayer/executor"
 	"github.com/bnb-chain/greenfield-relayer/logging"
+	"github.com/bnb-chain/greenfield-relayer/payload"
+	"github.com/bnb-chain/greenfield-relayer/version"
+	"github.com/bnb-chain/greenfield-relayer/vote"
 )
 
 func initFlags() {
@@ -20,6 +31,26 @@ func initFlags() {
 	flag.String(config.FlagConfigPrivateKey, "", "relayer private key")
 	flag.String(config.FlagConfigBlsPrivateKey, "", "relayer bls private key")
 	flag.String(config.FlagConfigDbPass, "", "relayer db password")
+	flag.Bool(config.FlagForceFromHeight, false, "start listeners from the configured start heights regardless of blocks in db")
+	flag.String(config.FlagBackfillChain, "", "chain to backfill, greenfield or bsc")
+	flag.Uint64(config.FlagBackfillFrom, 0, "start height of the range to backfill")
+	flag.Uint64(config.FlagBackfillTo, 0, "end height of the range to backfill")
+	flag.String(config.FlagEncryptOutput, "", "output path of the encrypted config file")
+	flag.String(config.FlagKMSKeyId, "", "aws kms key id used to encrypt config, the key in env is used if empty")
+	flag.String(config.FlagPayload, "", "hex encoded payload, the aggregated payload of the claim to BSC for verify-claim or the package payload for decode-payload")
+	flag.String(config.FlagClaimEventHash, "", "hex encoded event hash of the claim, used instead of the payload")
+	flag.Uint(config.FlagEventHashVersion, config.EventHashVersionV1, "version of the event hash computed from the payload")
+	flag.String(config.FlagClaimSignature, "", "hex encoded aggregated bls signature of the claim")
+	flag.String(config.FlagClaimBitSet, "", "validator bitset of the claim, decimal or 0x prefixed hex")
+	flag.String(config.FlagValidatorsFile, "", "path of a json file of hex encoded bls keys of validators in order")
+	flag.Uint64(config.FlagValidatorsHeight, 0, "greenfield height to query validators at if no validators file is given")
+	flag.String(config.FlagProofChain, "", "source chain of the message to export the proof of, greenfield or bsc")
+	flag.String(config.FlagProofOutput, "", "output path of the exported proof, stdout if empty")
+	flag.Uint(config.FlagChannelId, 0, "channel id of the message, ignored by export-proof for bsc")
+	flag.Uint64(config.FlagProofSequence, 0, "sequence of the message to export the proof of, oracle sequence for bsc")
+	flag.String(config.FlagRelayerAddress, "", "relayer address to register for the validator, the one of the relayer by default")
+	flag.String(config.FlagBlsPublicKey, "", "hex encoded bls public key to register for the validator, the one of the relayer by default")
+	flag.Int(config.FlagPackageType, -1, "type of the package to decode, 0 for SYN, 1 for ACK and 2 for FAIL_ACK, the payload is a package with header if not set")
 
 	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
 	pflag.Parse()
@@ -32,10 +63,28 @@ func initFlags() {
 func printUsage() {
 	fmt.Print("usage: ./greenfield-relayer --config-type local --config-path configFile\n")
 	fmt.Print("usage: ./greenfield-relayer --config-type aws --aws-region awsRegin --aws-secret-key awsSecretKey\n")
+	fmt.Print("usage: ./greenfield-relayer backfill --chain [greenfield or bsc] --from fromHeight --to toHeight --config-type local --config-path configFile\n")
+	fmt.Print("usage: ./greenfield-relayer encrypt-config --config-path configFile --output encryptedConfigFile [--kms-key-id kmsKeyId --aws-region awsRegion]\n")
+	fmt.Print("usage: ./greenfield-relayer verify-claim [--payload payload | --event-hash eventHash] --signature signature --bitset bitset [--validators validatorsFile | --height height --config-type local --config-path configFile]\n")
+	fmt.Print("usage: ./greenfield-relayer export-proof --proof-chain [greenfield or bsc] --channel-id channelId --sequence sequence [--proof-output proofFile] --config-type local --config-path configFile\n")
+	fmt.Print("usage: ./greenfield-relayer decode-payload --channel-id channelId --payload payload [--package-type packageType]\n")
+	fmt.Printf("usage: %s=validatorPrivateKey ./greenfield-relayer update-relayer [--relayer-address relayerAddress] [--bls-public-key blsPublicKey] --config-type local --config-path configFile\n", config.EnvValidatorPrivateKey)
 }
 
 func main() {
 	initFlags()
+	if pflag.Arg(0) == config.CmdEncryptConfig {
+		encryptConfig()
+		return
+	}
+	if pflag.Arg(0) == config.CmdDecodePayload {
+		decodePayload()
+		return
+	}
+	if pflag.Arg(0) == config.CmdVerifyClaim && viper.GetString(config.FlagValidatorsFile) != "" {
+		exitOnError(verifyClaim(nil))
+		return
+	}
 	configType := viper.GetString(config.FlagConfigType)
 	if configType != config.AWSConfig && configType != config.LocalConfig {
 		printUsage()
@@ -76,7 +125,222 @@ func main() {
 	}
 
 	logging.InitLogger(&cfg.LogConfig)
+	logging.Logger.Infof("greenfield-relayer %s", version.GetInfo())
+	if err := bls.SetBackend(cfg.VotePoolConfig.BlsBackend); err != nil {
+		panic(err)
+	}
+
+	if pflag.Arg(0) == config.CmdVerifyClaim {
+		exitOnError(verifyClaim(cfg))
+		return
+	}
+
+	if pflag.Arg(0) == config.CmdBackfill {
+		err := app.NewBackfiller(cfg).Backfill(viper.GetString(config.FlagBackfillChain),
+			viper.GetUint64(config.FlagBackfillFrom), viper.GetUint64(config.FlagBackfillTo))
+		if err != nil {
+			exitOnError(fmt.Errorf("backfill error, err=%s", err.Error()))
+		}
+		return
+	}
+
+	if pflag.Arg(0) == config.CmdExportProof {
+		exportProof(cfg)
+		return
+	}
+
+	if pflag.Arg(0) == config.CmdUpdateRelayer {
+		updateRelayer(cfg)
+		return
+	}
 
 	app.NewApp(cfg).Start()
 	select {}
 }
+
+// exportProof writes the proof bundle of a relayed message to the output file, or stdout if no output is given
+func exportProof(cfg *config.Config) {
+	bundle, err := app.NewProofExporter(cfg).ExportProof(viper.GetString(config.FlagProofChain),
+		uint8(viper.GetUint(config.FlagChannelId)), viper.GetUint64(config.FlagProofSequence))
+	if err != nil {
+		fmt.Printf("export proof error, err=%s\n", err.Error())
+		return
+	}
+	content, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		fmt.Printf("marshal proof error, err=%s\n", err.Error())
+		return
+	}
+	output := viper.GetString(config.FlagProofOutput)
+	if output == "" {
+		fmt.Println(string(content))
+		return
+	}
+	if err := os.WriteFile(output, content, 0644); err != nil {
+		fmt.Printf("write proof error, err=%s\n", err.Error())
+	}
+}
+
+// updateRelayer registers the relayer address and BLS key for the validator on Greenfield, by default the ones of this
+// relayer, so that key rotation needs no separate script. The tx is signed by the validator operator key from env.
+func updateRelayer(cfg *config.Config) {
+	operatorPrivKey := os.Getenv(config.EnvValidatorPrivateKey)
+	if operatorPrivKey == "" {
+		fmt.Printf("private key of the validator operator should be set by env %s\n", config.EnvValidatorPrivateKey)
+		return
+	}
+	greenfieldExecutor := executor.NewGreenfieldExecutor(cfg)
+	relayerAddr := viper.GetString(config.FlagRelayerAddress)
+	if relayerAddr == "" {
+		relayerAddr = greenfieldExecutor.RelayerAddress()
+	}
+	blsKey := strings.TrimPrefix(viper.GetString(config.FlagBlsPublicKey), "0x")
+	if blsKey == "" {
+		blsKey = hex.EncodeToString(greenfieldExecutor.BlsPubKey)
+	}
+	registered, txHash, err := greenfieldExecutor.UpdateRelayerRegistration(operatorPrivKey, relayerAddr, blsKey)
+	if err != nil {
+		fmt.Printf("update relayer error, err=%s\n", err.Error())
+		return
+	}
+	if txHash == "" {
+		fmt.Printf("relayer address %s and bls key %s are already registered for validator %s\n", relayerAddr, blsKey, registered.Validator)
+		return
+	}
+	fmt.Printf("validator %s registered relayer address %s and bls key %s, replacing relayer address %s and bls key %s, tx hash=%s\n",
+		registered.Validator, relayerAddr, blsKey, registered.RelayerAddress, registered.BlsKey, txHash)
+}
+
+// decodePayload prints the fields of a package payload decoded by the decoder registered for the channel
+func decodePayload() {
+	bz, err := decodeHex(viper.GetString(config.FlagPayload))
+	if err != nil || len(bz) == 0 {
+		printUsage()
+		return
+	}
+	channelId := uint8(viper.GetUint(config.FlagChannelId))
+	var decoded *payload.Decoded
+	if packageType := viper.GetInt(config.FlagPackageType); packageType >= 0 {
+		decoded, err = payload.Decode(channelId, sdk.CrossChainPackageType(packageType), bz)
+	} else {
+		decoded, err = payload.DecodePackage(channelId, bz)
+	}
+	if err != nil {
+		fmt.Printf("decode payload error, err=%s\n", err.Error())
+		return
+	}
+	content, err := json.MarshalIndent(decoded, "", "  ")
+	if err != nil {
+		fmt.Printf("marshal payload error, err=%s\n", err.Error())
+		return
+	}
+	fmt.Println(string(content))
+}
+
+func encryptConfig() {
+	configFilePath := viper.GetString(config.FlagConfigPath)
+	output := viper.GetString(config.FlagEncryptOutput)
+	if configFilePath == "" || output == "" {
+		printUsage()
+		return
+	}
+	plain, err := os.ReadFile(configFilePath)
+	if err != nil {
+		fmt.Printf("read config error, err=%s\n", err.Error())
+		return
+	}
+	encrypted, err := config.EncryptConfig(plain, viper.GetString(config.FlagKMSKeyId), viper.GetString(config.FlagConfigAwsRegion))
+	if err != nil {
+		fmt.Printf("encrypt config error, err=%s\n", err.Error())
+		return
+	}
+	if err := os.WriteFile(output, encrypted, 0600); err != nil {
+		fmt.Printf("write encrypted config error, err=%s\n", err.Error())
+	}
+}
+
+// verifyClaim verifies the aggregated bls signature of a claim offline, against validators from a file or queried from
+// Greenfield at a height. An error is returned for invalid input and for an invalid claim alike.
+func verifyClaim(cfg *config.Config) error {
+	eventHash, err := claimEventHash()
+	if err != nil {
+		return fmt.Errorf("invalid event hash, err=%s", err.Error())
+	}
+	signature, err := decodeHex(viper.GetString(config.FlagClaimSignature))
+	if err != nil {
+		return fmt.Errorf("invalid signature, err=%s", err.Error())
+	}
+	bitSet, ok := new(big.Int).SetString(viper.GetString(config.FlagClaimBitSet), 0)
+	if !ok {
+		return fmt.Errorf("invalid bitset %s", viper.GetString(config.FlagClaimBitSet))
+	}
+	blsKeys, err := claimValidatorBlsKeys(cfg)
+	if err != nil {
+		return fmt.Errorf("get validators error, err=%s", err.Error())
+	}
+	if err := vote.VerifyAggregatedSignature(eventHash, signature, bitSet, blsKeys); err != nil {
+		return fmt.Errorf("claim is invalid, event hash=%x, err=%s", eventHash, err.Error())
+	}
+	fmt.Printf("claim is valid, event hash=%x\n", eventHash)
+	return nil
+}
+
+// exitOnError prints the error and exits with a non-zero code, so that scripts can tell a subcommand failed
+func exitOnError(err error) {
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+}
+
+func claimEventHash() ([]byte, error) {
+	if eventHash := viper.GetString(config.FlagClaimEventHash); eventHash != "" {
+		return decodeHex(eventHash)
+	}
+	payload, err := decodeHex(viper.GetString(config.FlagPayload))
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) == 0 {
+		return nil, fmt.Errorf("either payload or event hash is required")
+	}
+	return vote.GreenfieldEventHash(uint32(viper.GetUint(config.FlagEventHashVersion)), payload)
+}
+
+func claimValidatorBlsKeys(cfg *config.Config) ([][]byte, error) {
+	var blsKeys [][]byte
+	if path := viper.GetString(config.FlagValidatorsFile); path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var hexKeys []string
+		if err := json.Unmarshal(content, &hexKeys); err != nil {
+			return nil, err
+		}
+		for _, k := range hexKeys {
+			key, err := decodeHex(k)
+			if err != nil {
+				return nil, err
+			}
+			blsKeys = append(blsKeys, key)
+		}
+		return blsKeys, nil
+	}
+	height := viper.GetUint64(config.FlagValidatorsHeight)
+	if height == 0 {
+		return nil, fmt.Errorf("either validators file or height is required")
+	}
+	validators, err := executor.NewGreenfieldExecutor(cfg).QueryValidatorsAtHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range validators {
+		blsKeys = append(blsKeys, v.BlsKey)
+	}
+	return blsKeys, nil
+}
+
+func decodeHex(s string) ([]byte, error) {
+	return hex.DecodeString(strings.TrimPrefix(s, "0x"))
+}
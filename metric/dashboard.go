@@ -0,0 +1,110 @@
+package metric
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bnb-chain/greenfield-relayer/version"
+)
+
+const (
+	DashboardUid = "greenfield-relayer"
+	// DashboardRateInterval is the range of rates of counters and histograms in dashboard panels
+	DashboardRateInterval = "5m"
+	// DashboardQuantile is the quantile of histograms shown in dashboard panels
+	DashboardQuantile = 0.95
+
+	dashboardPanelWidth  = 12
+	dashboardPanelHeight = 8
+)
+
+// dashboardDatasource refers to the Prometheus data source picked by the datasource variable on import
+var dashboardDatasource = map[string]interface{}{"type": "prometheus", "uid": "${datasource}"}
+
+// Dashboard generates a Grafana dashboard with a time series panel of each family, two panels per row in the order
+// of families
+func Dashboard(families []Family) map[string]interface{} {
+	panels := make([]map[string]interface{}, 0, len(families))
+	for i, f := range families {
+		panels = append(panels, dashboardPanel(i, f))
+	}
+	return map[string]interface{}{
+		"uid":           DashboardUid,
+		"title":         "Greenfield Relayer",
+		"description":   fmt.Sprintf("Generated by greenfield-relayer %s", version.GetInfo().Version),
+		"tags":          []string{DashboardUid},
+		"timezone":      "browser",
+		"schemaVersion": 36,
+		"refresh":       "30s",
+		"time":          map[string]string{"from": "now-6h", "to": "now"},
+		"templating": map[string]interface{}{
+			"list": []map[string]interface{}{
+				{"name": "datasource", "label": "Data source", "type": "datasource", "query": "prometheus"},
+			},
+		},
+		"panels": panels,
+	}
+}
+
+func dashboardPanel(i int, f Family) map[string]interface{} {
+	target := map[string]interface{}{
+		"refId":        "A",
+		"datasource":   dashboardDatasource,
+		"expr":         dashboardExpr(f),
+		"legendFormat": dashboardLegend(f.Labels),
+	}
+	// histograms carry correlation ids of packages as exemplars
+	if f.Type == FamilyTypeHistogram {
+		target["exemplar"] = true
+	}
+	unit := "short"
+	if strings.HasSuffix(f.Name, "_seconds") {
+		unit = "s"
+	}
+	return map[string]interface{}{
+		"id":          i + 1,
+		"type":        "timeseries",
+		"title":       f.Name,
+		"description": f.Help,
+		"datasource":  dashboardDatasource,
+		"gridPos": map[string]int{
+			"x": i % 2 * dashboardPanelWidth,
+			"y": i / 2 * dashboardPanelHeight,
+			"w": dashboardPanelWidth,
+			"h": dashboardPanelHeight,
+		},
+		"fieldConfig": map[string]interface{}{
+			"defaults":  map[string]string{"unit": unit},
+			"overrides": []interface{}{},
+		},
+		"targets": []map[string]interface{}{target},
+	}
+}
+
+// dashboardExpr shows gauges as is, rates of counters, and the quantile of histograms
+func dashboardExpr(f Family) string {
+	switch f.Type {
+	case FamilyTypeCounter:
+		return fmt.Sprintf("sum%s (rate(%s[%s]))", dashboardBy(f.Labels), f.Name, DashboardRateInterval)
+	case FamilyTypeHistogram:
+		return fmt.Sprintf("histogram_quantile(%g, sum%s (rate(%s_bucket[%s])))", DashboardQuantile,
+			dashboardBy(append([]string{"le"}, f.Labels...)), f.Name, DashboardRateInterval)
+	default:
+		return f.Name
+	}
+}
+
+func dashboardBy(labels []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" by (%s)", strings.Join(labels, ", "))
+}
+
+func dashboardLegend(labels []string) string {
+	legends := make([]string, 0, len(labels))
+	for _, l := range labels {
+		legends = append(legends, fmt.Sprintf("{{%s}}", l))
+	}
+	return strings.Join(legends, " ")
+}
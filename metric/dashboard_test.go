@@ -0,0 +1,26 @@
+package metric
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-relayer/config"
+)
+
+func TestDashboard(t *testing.T) {
+	r := NewRegistry(prometheus.NewRegistry())
+	newMetricService(&config.Config{}, r)
+	families := r.Families()
+	panels := Dashboard(families)["panels"].([]map[string]interface{})
+	require.Equal(t, len(families), len(panels))
+
+	exprs := make(map[string]string)
+	for _, p := range panels {
+		exprs[p["title"].(string)] = p["targets"].([]map[string]interface{})[0]["expr"].(string)
+	}
+	require.Equal(t, "saved_block_height", exprs[MetricNameSavedBlock])
+	require.Equal(t, "sum by (direction, failure_class) (rate(claim_failures[5m]))", exprs[MetricNameClaimFailures])
+	require.Equal(t, "histogram_quantile(0.95, sum by (le, direction) (rate(claim_latency_seconds_bucket[5m])))", exprs[MetricNameClaimLatency])
+}
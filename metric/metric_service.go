@@ -1,224 +1,422 @@
 package metric
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
+	"github.com/bnb-chain/greenfield-relayer/common"
 	"github.com/bnb-chain/greenfield-relayer/config"
+	"github.com/bnb-chain/greenfield-relayer/payload"
+	"github.com/bnb-chain/greenfield-relayer/version"
 )
 
 const (
-	MetricNameGnfdSavedBlock       = "Greenfield_saved_block_height"
-	MetricNameGnfdProcessedBlock   = "Greenfield_processed_block_height"
-	MetricNameIsGnfdInturnRelayer  = "is_Greenfield_inturn_relayer"
-	MetricNameGnfdRelayerStartTime = "Greenfield_relayer_start_time" // inturn relayer start time
-	MetricNameGnfdRelayerEndTime   = "Greenfield_relayer_end_time"   // inturn relayer end time
-
-	MetricNameBSCSavedBlock       = "BSC_saved_block_height"
-	MetricNameBSCProcessedBlock   = "BSC_processed_block_height"
-	MetricNameIsBSCInturnRelayer  = "is_BSC_inturn_relayer"
-	MetricNameBSCRelayerStartTime = "BSC_relayer_start_time" // inturn relayer start time
-	MetricNameBSCRelayerEndTime   = "BSC_relayer_end_time"   // inturn relayer end time
-
-	MetricNameNextSendSequenceForChannel    = "next_send_seq_for_channel"
-	MetricNameNextReceiveSequenceForChannel = "next_receive_seq_for_channel"
-)
+	MetricNameSavedBlock          = "saved_block_height"
+	MetricNameProcessedBlock      = "processed_block_height"
+	MetricNameIsInturnRelayer     = "is_inturn_relayer"
+	MetricNameRelayerStartTime    = "relayer_start_time" // inturn relayer start time
+	MetricNameRelayerEndTime      = "relayer_end_time"   // inturn relayer end time
+	MetricNameNextSendSequence    = "next_send_seq"
+	MetricNameNextReceiveSequence = "next_receive_seq"
 
-type MetricService struct {
-	MetricsMap map[string]prometheus.Metric
-	cfg        *config.Config
-}
+	MetricNameFailAckPackages = "fail_ack_packages"
 
-func NewMetricService(config *config.Config) *MetricService {
-	ms := make(map[string]prometheus.Metric, 0)
+	MetricNamePeerDeliveries      = "peer_relayer_deliveries"
+	MetricNamePeerDeliveryLatency = "peer_relayer_delivery_latency_seconds"
 
-	// Greenfield
-	gnfdSavedBlockMetric := prometheus.NewGauge(prometheus.GaugeOpts{
-		Name: MetricNameGnfdSavedBlock,
-		Help: "Saved block height for Greenfield in Database",
-	})
-	ms[MetricNameGnfdSavedBlock] = gnfdSavedBlockMetric
-	prometheus.MustRegister(gnfdSavedBlockMetric)
+	MetricNameCanaryLatency     = "canary_latency_seconds"
+	MetricNameCanarySLABreached = "canary_sla_breached"
+	MetricNameCanaryFailures    = "canary_failures"
 
-	gnfdProcessedBlockMetric := prometheus.NewGauge(prometheus.GaugeOpts{
-		Name: MetricNameGnfdProcessedBlock,
-		Help: "Processed block height for Greenfield in Database",
-	})
-	ms[MetricNameGnfdProcessedBlock] = gnfdProcessedBlockMetric
-	prometheus.MustRegister(gnfdProcessedBlockMetric)
+	MetricNameBuildInfo = "build_info"
 
-	gnfdIsInturnRelayerMetric := prometheus.NewGauge(prometheus.GaugeOpts{
-		Name: MetricNameIsGnfdInturnRelayer,
-		Help: "Whether relayer is inturn to relay transaction from BSC to Greenfield",
-	})
-	ms[MetricNameIsGnfdInturnRelayer] = gnfdIsInturnRelayerMetric
-	prometheus.MustRegister(gnfdIsInturnRelayerMetric)
+	MetricNameVoteLagUnvoted   = "vote_lag_unvoted"
+	MetricNameVoteLagOldestAge = "vote_lag_oldest_age_seconds"
 
-	// Greenfield relayer(BSC -> Greenfield) relay interval metrics
-	gnfdRelayerStartTimeMetric := prometheus.NewGauge(prometheus.GaugeOpts{
-		Name: MetricNameGnfdRelayerStartTime,
-		Help: "inturn gnfd relayer start time or out-turn relayer previous start time",
-	})
-	ms[MetricNameGnfdRelayerStartTime] = gnfdRelayerStartTimeMetric
-	prometheus.MustRegister(gnfdRelayerStartTimeMetric)
+	MetricNameListenerHeightLag        = "listener_height_lag"
+	MetricNameListenerShadowMismatches = "listener_shadow_mismatches"
 
-	gnfdRelayerEndTimeMetric := prometheus.NewGauge(prometheus.GaugeOpts{
-		Name: MetricNameGnfdRelayerEndTime,
-		Help: "inturn gnfd relayer end time or out-turn relayer previous end time",
-	})
-	ms[MetricNameGnfdRelayerEndTime] = gnfdRelayerEndTimeMetric
-	prometheus.MustRegister(gnfdRelayerEndTimeMetric)
+	MetricNameClaimSimulations = "claim_simulations"
+	MetricNameClaimFailures    = "claim_failures"
 
-	// BSC
-	bscSavedBlockMetric := prometheus.NewGauge(prometheus.GaugeOpts{
-		Name: MetricNameBSCSavedBlock,
-		Help: "Saved block height for BSC in Database",
-	})
-	ms[MetricNameBSCSavedBlock] = bscSavedBlockMetric
-	prometheus.MustRegister(bscSavedBlockMetric)
+	MetricNameVotePoolAvailable = "votepool_available"
+	MetricNameVoteGossipLatency = "votepool_gossip_latency_seconds"
+	MetricNameVoteGossipHealthy = "votepool_gossip_healthy"
 
-	bscProcessedBlockMetric := prometheus.NewGauge(prometheus.GaugeOpts{
-		Name: MetricNameBSCProcessedBlock,
-		Help: "Processed block height for BSC in Database",
-	})
-	ms[MetricNameBSCProcessedBlock] = bscProcessedBlockMetric
-	prometheus.MustRegister(bscProcessedBlockMetric)
+	MetricNameVoteLatency        = "vote_latency_seconds"
+	MetricNameVoteCollectionTime = "vote_collection_seconds"
+	MetricNameClaimLatency       = "claim_latency_seconds"
 
-	bscIsInturnRelayerMetric := prometheus.NewGauge(prometheus.GaugeOpts{
-		Name: MetricNameIsBSCInturnRelayer,
-		Help: "Whether relayer is inturn to relay transaction from Greenfield to BSC",
-	})
-	ms[MetricNameIsBSCInturnRelayer] = bscIsInturnRelayerMetric
-	prometheus.MustRegister(bscIsInturnRelayerMetric)
+	MetricNameBSCGasSpent = "bsc_gas_spent_today"
 
-	// BSC relayer(Greenfield -> BSC) relay interval metrics
-	bscRelayerStartTimeMetric := prometheus.NewGauge(prometheus.GaugeOpts{
-		Name: MetricNameBSCRelayerStartTime,
-		Help: "inturn BSC relayer start time or out-turn relayer previous start time",
-	})
-	ms[MetricNameBSCRelayerStartTime] = bscRelayerStartTimeMetric
-	prometheus.MustRegister(bscRelayerStartTimeMetric)
+	MetricNameValidatorActive = "validator_active"
+	MetricNameChannelEnabled  = "channel_enabled"
+	MetricNameChannelInfo     = "channel_info"
 
-	bscRelayerEndTimeMetric := prometheus.NewGauge(prometheus.GaugeOpts{
-		Name: MetricNameBSCRelayerEndTime,
-		Help: "inturn BSC relayer end time or out-turn relayer previous end time",
-	})
-	ms[MetricNameBSCRelayerEndTime] = bscRelayerEndTimeMetric
-	prometheus.MustRegister(bscRelayerEndTimeMetric)
+	MetricNameScheduledTaskRuns     = "scheduled_task_runs"
+	MetricNameScheduledTaskSkips    = "scheduled_task_skips"
+	MetricNameScheduledTaskDuration = "scheduled_task_duration_seconds"
 
-	// register greenfield oracle channel
-	nextSendOracleSeq := prometheus.NewGauge(prometheus.GaugeOpts{
-		Name: fmt.Sprintf("%s_%d", MetricNameNextSendSequenceForChannel, 0),
-		Help: "Next Send Oracle sequence",
-	})
-	ms[fmt.Sprintf("%s_%d", MetricNameNextSendSequenceForChannel, 0)] = nextSendOracleSeq
-	prometheus.MustRegister(nextSendOracleSeq)
+	MetricNameRetries               = "retries"
+	MetricNameRetryBudgetRejections = "retry_budget_rejections"
 
-	nextReceiveOracleSeq := prometheus.NewGauge(prometheus.GaugeOpts{
-		Name: fmt.Sprintf("%s_%d", MetricNameNextReceiveSequenceForChannel, 0),
-		Help: "Next Delivery Oracle sequence",
+	MetricNameStatusTransitions = "package_status_transitions"
+
+	MetricNameInturnTransitions    = "inturn_transitions"
+	MetricNameSequenceStatusResets = "sequence_status_resets"
+
+	ChainGreenfield = "greenfield"
+	ChainBSC        = "bsc"
+
+	DirectionBSCToGnfd = "bsc_to_greenfield"
+	DirectionGnfdToBSC = "greenfield_to_bsc"
+
+	// MaxRelayerLabelValues bounds the number of relayer addresses reported in peer delivery metrics
+	MaxRelayerLabelValues = 64
+	// MaxValidatorLabelValues bounds the number of validator bls public keys reported in vote latency metrics
+	MaxValidatorLabelValues = 256
+)
+
+type MetricService struct {
+	savedBlock        *prometheus.GaugeVec
+	processedBlock    *prometheus.GaugeVec
+	isInturnRelayer   *prometheus.GaugeVec
+	relayerStartTime  *prometheus.GaugeVec
+	relayerEndTime    *prometheus.GaugeVec
+	nextSendSeq       *prometheus.GaugeVec
+	nextReceiveSeq    *prometheus.GaugeVec
+	failAckPkgCounter *prometheus.CounterVec
+	peerDeliveries    *prometheus.CounterVec
+	peerLatency       *prometheus.HistogramVec
+	voteLagUnvoted    *prometheus.GaugeVec
+	voteLagOldestAge  *prometheus.GaugeVec
+	listenerHeightLag *prometheus.GaugeVec
+	claimSimulations  *prometheus.CounterVec
+	claimFailures     *prometheus.CounterVec
+	votePoolAvailable *prometheus.GaugeVec
+	voteGossipLatency *prometheus.HistogramVec
+	voteGossipHealthy *prometheus.GaugeVec
+	voteLatency       *prometheus.HistogramVec
+	voteCollection    *prometheus.HistogramVec
+	claimLatency      *prometheus.HistogramVec
+	bscGasSpent       prometheus.Gauge
+	validatorActive   prometheus.Gauge
+	channelEnabled    *prometheus.GaugeVec
+	shadowMismatches  *prometheus.CounterVec
+	taskRuns          *prometheus.CounterVec
+	taskSkips         *prometheus.CounterVec
+	taskDuration      *prometheus.HistogramVec
+	retries           *prometheus.CounterVec
+	retryRejections   *prometheus.CounterVec
+	statusTransitions *prometheus.CounterVec
+	turnTransitions   *prometheus.CounterVec
+	sequenceResets    *prometheus.CounterVec
+	canaryLatency     prometheus.Gauge
+	canarySLABreached prometheus.Gauge
+	canaryFailures    prometheus.Counter
+	channels          *labelLimiter // only the oracle channel and monitored channels are reported
+	relayers          *labelLimiter
+	validators        *labelLimiter
+	cfg               *config.Config
+}
+
+// defaultRegistry is the registry of the metrics exported at /metrics
+var defaultRegistry *Registry
+
+func NewMetricService(config *config.Config) *MetricService {
+	defaultRegistry = NewRegistry(prometheus.DefaultRegisterer)
+	return newMetricService(config, defaultRegistry)
+}
+
+// Families returns the metric families exported at /metrics, nil if the metric service is not created
+func Families() []Family {
+	if defaultRegistry == nil {
+		return nil
+	}
+	return defaultRegistry.Families()
+}
+
+func newMetricService(config *config.Config, r *Registry) *MetricService {
+	// the oracle channel is used for BSC -> Greenfield, and the monitored channels for Greenfield -> BSC
+	channels := []string{channelLabel(0)}
+	for _, c := range config.GreenfieldConfig.MonitorChannelList {
+		channels = append(channels, channelLabel(c))
+	}
+
+	// build info, the value is always 1 and the version is in labels
+	info := version.GetInfo()
+	buildInfo := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: MetricNameBuildInfo,
+		Help: "Build information of the relayer",
+		ConstLabels: prometheus.Labels{
+			"version":         info.Version,
+			"git_commit":      info.GitCommit,
+			"git_commit_date": info.GitCommitDate,
+			"build_time":      info.BuildTime,
+			"go_version":      info.GoVersion,
+		},
 	})
-	ms[fmt.Sprintf("%s_%d", MetricNameNextReceiveSequenceForChannel, 0)] = nextReceiveOracleSeq
-	prometheus.MustRegister(nextReceiveOracleSeq)
+	buildInfo.Set(1)
+	r.register(buildInfo, MetricNameBuildInfo, "Build information of the relayer", FamilyTypeGauge, []string{"version", "git_commit"})
 
-	// register gnfd -> bsc channels
+	// names of the monitored channels, the value is always 1 and can be joined on channel_id
+	channelInfo := r.GaugeVec(MetricNameChannelInfo, "Name of the channel by the registered payload decoder", LabelChannelId, LabelChannelName)
 	for _, c := range config.GreenfieldConfig.MonitorChannelList {
-		nextSendSeq := prometheus.NewGauge(prometheus.GaugeOpts{
-			Name: fmt.Sprintf("%s_%d", MetricNameNextSendSequenceForChannel, c),
-			Help: fmt.Sprintf("Next Send sequence for channel %d", c),
-		})
-		ms[fmt.Sprintf("%s_%d", MetricNameNextSendSequenceForChannel, c)] = nextSendSeq
-		prometheus.MustRegister(nextSendSeq)
-
-		nextReceiveSeq := prometheus.NewGauge(prometheus.GaugeOpts{
-			Name: fmt.Sprintf("%s_%d", MetricNameNextReceiveSequenceForChannel, c),
-			Help: fmt.Sprintf("Next delivery sequence for channel %d", c),
-		})
-		ms[fmt.Sprintf("%s_%d", MetricNameNextReceiveSequenceForChannel, c)] = nextReceiveSeq
-		prometheus.MustRegister(nextReceiveSeq)
+		channelInfo.WithLabelValues(channelLabel(c), payload.ChannelName(c)).Set(1)
 	}
 
 	return &MetricService{
-		MetricsMap: ms,
-		cfg:        config,
+		savedBlock:       r.GaugeVec(MetricNameSavedBlock, "Saved block height in Database per chain", LabelChain),
+		processedBlock:   r.GaugeVec(MetricNameProcessedBlock, "Processed block height in Database per chain", LabelChain),
+		isInturnRelayer:  r.GaugeVec(MetricNameIsInturnRelayer, "Whether relayer is inturn to relay per relay direction", LabelDirection),
+		relayerStartTime: r.GaugeVec(MetricNameRelayerStartTime, "inturn relayer start time or out-turn relayer previous start time per relay direction", LabelDirection),
+		relayerEndTime:   r.GaugeVec(MetricNameRelayerEndTime, "inturn relayer end time or out-turn relayer previous end time per relay direction", LabelDirection),
+		nextSendSeq:      r.GaugeVec(MetricNameNextSendSequence, "Next send sequence per relay direction and channel", LabelDirection, LabelChannelId),
+		nextReceiveSeq:   r.GaugeVec(MetricNameNextReceiveSequence, "Next delivery sequence per relay direction and channel", LabelDirection, LabelChannelId),
+		// FAIL_ACK packages observed by listeners
+		failAckPkgCounter: r.CounterVec(MetricNameFailAckPackages, "Number of FAIL_ACK packages observed per relay direction and channel", LabelDirection, LabelChannelId),
+		// packages delivered by each relayer, observed from claim txs on destination chains
+		peerDeliveries: r.CounterVec(MetricNamePeerDeliveries, "Number of packages delivered per relay direction and relayer address", LabelDirection, LabelRelayer),
+		peerLatency: r.HistogramVec(MetricNamePeerDeliveryLatency, "Seconds from a package sent on the source chain to delivered, per relay direction and relayer address",
+			[]float64{5, 10, 20, 30, 60, 120, 300, 600, 1800}, LabelDirection, LabelRelayer),
+		// packages saved by listeners but not voted by the vote processor yet
+		voteLagUnvoted:   r.GaugeVec(MetricNameVoteLagUnvoted, "Number of saved packages not voted yet per relay direction", LabelDirection),
+		voteLagOldestAge: r.GaugeVec(MetricNameVoteLagOldestAge, "Age in second of the oldest saved package not voted yet per relay direction", LabelDirection),
+		// chain head height minus the latest height processed by the listener
+		listenerHeightLag: r.GaugeVec(MetricNameListenerHeightLag, "Number of blocks the listener is behind the chain head per chain", LabelChain),
+		// claims simulated before broadcast, labeled by whether the simulation passed
+		claimSimulations: r.CounterVec(MetricNameClaimSimulations, "Number of claims simulated before broadcast per result", LabelResult),
+		// failed claims, labeled by the root cause classified from the raw log
+		claimFailures: r.CounterVec(MetricNameClaimFailures, "Number of failed claims per relay direction and failure class", LabelDirection, LabelFailureClass),
+		// whether the vote pool RPC methods of each Greenfield node answered the latest probe
+		votePoolAvailable: r.GaugeVec(MetricNameVotePoolAvailable, "Whether the vote pool method of the Greenfield node is available", LabelNode, LabelMethod),
+		// votes broadcast by this relayer, observed when first queryable on each node
+		voteGossipLatency: r.HistogramVec(MetricNameVoteGossipLatency, "Seconds from a vote signed by this relayer to queryable in the vote pool, per Greenfield node",
+			[]float64{1, 2, 3, 5, 10, 20, 30}, LabelNode),
+		voteGossipHealthy: r.GaugeVec(MetricNameVoteGossipHealthy, "Whether the latest tracked vote of this relayer became queryable on the Greenfield node in time", LabelNode),
+		// votes of each validator, observed when first seen in the vote pool
+		voteLatency: r.HistogramVec(MetricNameVoteLatency, "Seconds from a package sent on the source chain to the vote first seen in the vote pool, per relay direction and validator bls public key",
+			[]float64{2, 5, 10, 20, 30, 60, 120, 300, 600}, LabelDirection, LabelValidator),
+		// packages getting enough votes, and claims broadcast by this relayer
+		voteCollection: r.HistogramVec(MetricNameVoteCollectionTime, "Seconds from a package sent on the source chain to enough votes collected, per relay direction",
+			[]float64{2, 5, 10, 20, 30, 60, 120, 300, 600}, LabelDirection),
+		claimLatency: r.HistogramVec(MetricNameClaimLatency, "Seconds from a package sent on the source chain to the claim broadcast by this relayer, per relay direction",
+			[]float64{5, 10, 20, 30, 60, 120, 300, 600, 1800}, LabelDirection),
+		// gas of BSC txs sent within the current UTC day, counted by gas limit
+		bscGasSpent: r.Gauge(MetricNameBSCGasSpent, "Gas of BSC txs sent within the current UTC day, counted by the gas limit of each tx"),
+		// whether the validator of this relayer is in the active validator set of Greenfield
+		validatorActive: r.Gauge(MetricNameValidatorActive, "Whether the validator of this relayer is in the active validator set of Greenfield"),
+		// whether the monitored channels are allowed by governance of both chains
+		channelEnabled: r.GaugeVec(MetricNameChannelEnabled, "Whether the Greenfield -> BSC channel is enabled on both chains", LabelChannelId),
+		// packages the shadow listener disagrees with the listener on
+		shadowMismatches: r.CounterVec(MetricNameListenerShadowMismatches, "Number of packages the shadow listener disagrees with the listener on", LabelChain, LabelMismatch),
+		// runs of periodic tasks, ticks skipped while paused, and durations of runs
+		taskRuns:     r.CounterVec(MetricNameScheduledTaskRuns, "Number of runs per periodic task", LabelTask),
+		taskSkips:    r.CounterVec(MetricNameScheduledTaskSkips, "Number of ticks skipped while a periodic task is paused", LabelTask),
+		taskDuration: r.HistogramVec(MetricNameScheduledTaskDuration, "Duration of runs per periodic task", prometheus.DefBuckets, LabelTask),
+		// retries consumed per subsystem, and retries given up since the global retry budget is exhausted
+		retries:         r.CounterVec(MetricNameRetries, "Number of retries consumed per subsystem", LabelSubsystem),
+		retryRejections: r.CounterVec(MetricNameRetryBudgetRejections, "Number of retries rejected by the global retry budget per subsystem", LabelSubsystem),
+		// packages moved between statuses of the relay pipeline
+		statusTransitions: r.CounterVec(MetricNameStatusTransitions, "Number of packages moved to the status per source chain", LabelChain, LabelStatus),
+		// turn boundaries of this relayer, and resets of the next delivery sequence cached while in turn
+		turnTransitions: r.CounterVec(MetricNameInturnTransitions, "Number of times this relayer enters or leaves in-turn status per relay direction", LabelDirection, LabelTransition),
+		sequenceResets:  r.CounterVec(MetricNameSequenceStatusResets, "Number of resets of the cached next delivery sequence per relay direction and reason", LabelDirection, LabelReason),
+		// canary transfer metrics
+		canaryLatency:     r.Gauge(MetricNameCanaryLatency, "End-to-end latency of the latest delivered canary transfer in second"),
+		canarySLABreached: r.Gauge(MetricNameCanarySLABreached, "Whether the pending canary transfer is not delivered within the SLA"),
+		canaryFailures:    r.Counter(MetricNameCanaryFailures, "Number of canary transfers which failed to be sent"),
+		channels:          newLabelLimiter(len(channels), channels...),
+		relayers:          newLabelLimiter(MaxRelayerLabelValues),
+		validators:        newLabelLimiter(MaxValidatorLabelValues),
+		cfg:               config,
 	}
 }
 
+func channelLabel(channel uint8) string {
+	return fmt.Sprintf("%d", channel)
+}
+
 func (m *MetricService) Start() {
-	http.Handle("/metrics", promhttp.Handler())
+	// exemplars are only exposed in the OpenMetrics format
+	http.Handle("/metrics", promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer,
+		promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{EnableOpenMetrics: true})))
+	http.HandleFunc("/version", handleVersion)
 	err := http.ListenAndServe(fmt.Sprintf(":%d", m.cfg.AdminConfig.Port), nil)
 	if err != nil {
 		panic(err)
 	}
 }
 
-func (m *MetricService) SetGnfdSavedBlockHeight(height uint64) {
-	m.MetricsMap[MetricNameGnfdSavedBlock].(prometheus.Gauge).Set(float64(height))
+func handleVersion(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(version.GetInfo()); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func (m *MetricService) SetSavedBlockHeight(chain string, height uint64) {
+	m.savedBlock.WithLabelValues(chain).Set(float64(height))
 }
 
-func (m *MetricService) SetGnfdProcessedBlockHeight(height uint64) {
-	m.MetricsMap[MetricNameGnfdProcessedBlock].(prometheus.Gauge).Set(float64(height))
+func (m *MetricService) SetProcessedBlockHeight(chain string, height uint64) {
+	m.processedBlock.WithLabelValues(chain).Set(float64(height))
 }
 
-func (m *MetricService) SetBSCSavedBlockHeight(height uint64) {
-	m.MetricsMap[MetricNameBSCSavedBlock].(prometheus.Gauge).Set(float64(height))
+func (m *MetricService) SetInturnRelayerMetrics(direction string, isInturn bool, start, end uint64) {
+	m.isInturnRelayer.WithLabelValues(direction).Set(boolToFloat(isInturn))
+	m.relayerStartTime.WithLabelValues(direction).Set(float64(start))
+	m.relayerEndTime.WithLabelValues(direction).Set(float64(end))
 }
 
-func (m *MetricService) SetBSCProcessedBlockHeight(height uint64) {
-	m.MetricsMap[MetricNameBSCProcessedBlock].(prometheus.Gauge).Set(float64(height))
+func (m *MetricService) IncInturnTransitions(direction, transition string) {
+	m.turnTransitions.WithLabelValues(direction, transition).Inc()
 }
 
-func (m *MetricService) SetBSCInturnRelayerMetrics(isInturn bool, start, end uint64) {
-	m.setIsBSCInturnRelayer(isInturn)
-	m.setBSCInturnRelayerStartTime(start)
-	m.setBSCInturnRelayerEndTime(end)
+func (m *MetricService) IncSequenceStatusResets(direction, reason string) {
+	m.sequenceResets.WithLabelValues(direction, reason).Inc()
 }
 
-func (m *MetricService) setIsBSCInturnRelayer(isInturn bool) {
-	var flag float64
-	if isInturn {
-		flag = 1
+func (m *MetricService) SetNextSendSequence(direction string, channel uint8, seq uint64) {
+	m.nextSendSeq.WithLabelValues(direction, m.channels.value(channelLabel(channel))).Set(float64(seq))
+}
+
+func (m *MetricService) SetNextReceiveSequence(direction string, channel uint8, seq uint64) {
+	m.nextReceiveSeq.WithLabelValues(direction, m.channels.value(channelLabel(channel))).Set(float64(seq))
+}
+
+func (m *MetricService) IncFailAckPackages(direction string, channel uint8) {
+	m.failAckPkgCounter.WithLabelValues(direction, m.channels.value(channelLabel(channel))).Inc()
+}
+
+// ObservePeerDelivery records a package delivered by the relayer, a negative latency means it is unknown. The
+// correlation id of the package is attached as an exemplar.
+func (m *MetricService) ObservePeerDelivery(direction, relayer string, channelId uint8, sequence uint64, latency int64) {
+	relayer = m.relayers.value(relayer)
+	exemplar := prometheus.Labels{LabelCorrelationId: common.CorrelationId(direction, channelId, sequence)}
+	m.peerDeliveries.WithLabelValues(direction, relayer).(prometheus.ExemplarAdder).AddWithExemplar(1, exemplar)
+	if latency >= 0 {
+		m.peerLatency.WithLabelValues(direction, relayer).(prometheus.ExemplarObserver).ObserveWithExemplar(float64(latency), exemplar)
+	}
+}
+
+func (m *MetricService) SetCanaryLatency(latency float64) {
+	m.canaryLatency.Set(latency)
+}
+
+func (m *MetricService) SetCanarySLABreached(breached bool) {
+	m.canarySLABreached.Set(boolToFloat(breached))
+}
+
+func (m *MetricService) IncCanaryFailures() {
+	m.canaryFailures.Inc()
+}
+
+func (m *MetricService) SetVoteLag(direction string, unvoted int64, oldestAge int64) {
+	m.voteLagUnvoted.WithLabelValues(direction).Set(float64(unvoted))
+	m.voteLagOldestAge.WithLabelValues(direction).Set(float64(oldestAge))
+}
+
+func (m *MetricService) SetListenerHeightLag(chain string, lag int64) {
+	m.listenerHeightLag.WithLabelValues(chain).Set(float64(lag))
+}
+
+func (m *MetricService) ObserveClaimSimulation(passed bool) {
+	result := "passed"
+	if !passed {
+		result = "failed"
 	}
-	m.MetricsMap[MetricNameIsBSCInturnRelayer].(prometheus.Gauge).Set(flag)
+	m.claimSimulations.WithLabelValues(result).Inc()
 }
 
-func (m *MetricService) setBSCInturnRelayerStartTime(start uint64) {
-	m.MetricsMap[MetricNameBSCRelayerStartTime].(prometheus.Gauge).Set(float64(start))
+func (m *MetricService) IncClaimFailures(direction, class string) {
+	m.claimFailures.WithLabelValues(direction, class).Inc()
 }
 
-func (m *MetricService) setBSCInturnRelayerEndTime(end uint64) {
-	m.MetricsMap[MetricNameBSCRelayerEndTime].(prometheus.Gauge).Set(float64(end))
+func (m *MetricService) SetBSCGasSpent(spent uint64) {
+	m.bscGasSpent.Set(float64(spent))
 }
 
-func (m *MetricService) SetGnfdInturnRelayerMetrics(isInturn bool, start, end uint64) {
-	m.setIsGnfdInturnRelayer(isInturn)
-	m.setGnfdInturnRelayerStartTime(start)
-	m.setGnfdInturnRelayerEndTime(end)
+func (m *MetricService) SetValidatorActive(active bool) {
+	m.validatorActive.Set(boolToFloat(active))
 }
 
-func (m *MetricService) setIsGnfdInturnRelayer(isInturn bool) {
-	var flag float64
-	if isInturn {
-		flag = 1
+func (m *MetricService) SetChannelEnabled(channel uint8, enabled bool) {
+	m.channelEnabled.WithLabelValues(m.channels.value(channelLabel(channel))).Set(boolToFloat(enabled))
+}
+
+func (m *MetricService) IncListenerShadowMismatches(chain, mismatch string) {
+	m.shadowMismatches.WithLabelValues(chain, mismatch).Inc()
+}
+
+func (m *MetricService) SetVotePoolAvailable(node, method string, available bool) {
+	m.votePoolAvailable.WithLabelValues(node, method).Set(boolToFloat(available))
+}
+
+func (m *MetricService) ObserveVoteGossipLatency(node string, latency float64) {
+	m.voteGossipLatency.WithLabelValues(node).Observe(latency)
+}
+
+func (m *MetricService) SetVoteGossipHealthy(node string, healthy bool) {
+	m.voteGossipHealthy.WithLabelValues(node).Set(boolToFloat(healthy))
+}
+
+// ObserveVoteLatency records a vote of the validator for the package first seen in the vote pool, latency is in seconds
+func (m *MetricService) ObserveVoteLatency(direction, validator string, channelId uint8, sequence uint64, latency int64) {
+	observeWithCorrelationId(m.voteLatency.WithLabelValues(direction, m.validators.value(validator)), direction, channelId, sequence, latency)
+}
+
+// ObserveVoteCollection records the package getting enough votes, latency is in seconds
+func (m *MetricService) ObserveVoteCollection(direction string, channelId uint8, sequence uint64, latency int64) {
+	observeWithCorrelationId(m.voteCollection.WithLabelValues(direction), direction, channelId, sequence, latency)
+}
+
+// ObserveClaimLatency records the claim of the package broadcast by this relayer, latency is in seconds
+func (m *MetricService) ObserveClaimLatency(direction string, channelId uint8, sequence uint64, latency int64) {
+	observeWithCorrelationId(m.claimLatency.WithLabelValues(direction), direction, channelId, sequence, latency)
+}
+
+// observeWithCorrelationId attaches the correlation id of the package as an exemplar, so that a spike links to the logs
+// of the package
+func observeWithCorrelationId(o prometheus.Observer, direction string, channelId uint8, sequence uint64, latency int64) {
+	if latency < 0 {
+		latency = 0
 	}
-	m.MetricsMap[MetricNameIsGnfdInturnRelayer].(prometheus.Gauge).Set(flag)
+	exemplar := prometheus.Labels{LabelCorrelationId: common.CorrelationId(direction, channelId, sequence)}
+	o.(prometheus.ExemplarObserver).ObserveWithExemplar(float64(latency), exemplar)
 }
 
-func (m *MetricService) setGnfdInturnRelayerStartTime(start uint64) {
-	m.MetricsMap[MetricNameGnfdRelayerStartTime].(prometheus.Gauge).Set(float64(start))
+// ObserveScheduledTask records a tick of a periodic task, ran is false if the task is paused
+func (m *MetricService) ObserveScheduledTask(task string, ran bool, duration time.Duration) {
+	if !ran {
+		m.taskSkips.WithLabelValues(task).Inc()
+		return
+	}
+	m.taskRuns.WithLabelValues(task).Inc()
+	m.taskDuration.WithLabelValues(task).Observe(duration.Seconds())
 }
 
-func (m *MetricService) setGnfdInturnRelayerEndTime(end uint64) {
-	m.MetricsMap[MetricNameGnfdRelayerEndTime].(prometheus.Gauge).Set(float64(end))
+// ObserveRetry counts a retry of the subsystem, allowed is false if it is rejected by the retry budget
+func (m *MetricService) AddStatusTransitions(chain, status string, count int64) {
+	m.statusTransitions.WithLabelValues(chain, status).Add(float64(count))
 }
 
-func (m *MetricService) SetNextSendSequenceForChannel(channel uint8, seq uint64) {
-	m.MetricsMap[fmt.Sprintf("%s_%d", MetricNameNextSendSequenceForChannel, channel)].(prometheus.Gauge).Set(float64(seq))
+func (m *MetricService) ObserveRetry(subsystem string, allowed bool) {
+	if !allowed {
+		m.retryRejections.WithLabelValues(subsystem).Inc()
+		return
+	}
+	m.retries.WithLabelValues(subsystem).Inc()
 }
 
-func (m *MetricService) SetNextReceiveSequenceForChannel(channel uint8, seq uint64) {
-	m.MetricsMap[fmt.Sprintf("%s_%d", MetricNameNextReceiveSequenceForChannel, channel)].(prometheus.Gauge).Set(float64(seq))
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
 }
@@ -0,0 +1,132 @@
+package metric
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	LabelChain       = "chain"
+	LabelDirection   = "direction"
+	LabelChannelId   = "channel_id"
+	LabelChannelName = "channel_name"
+	LabelRelayer     = "relayer"
+	LabelSubsystem   = "subsystem"
+	LabelResult      = "result"
+	LabelNode        = "node"
+	LabelMethod      = "method"
+	LabelValidator   = "validator"
+	LabelTask        = "task"
+	LabelStatus      = "status"
+	LabelTransition  = "transition"
+	LabelReason      = "reason"
+
+	LabelFailureClass = "failure_class"
+	LabelMismatch     = "mismatch"
+
+	// LabelCorrelationId is the exemplar label of the correlation id of a package
+	LabelCorrelationId = "correlation_id"
+
+	// LabelValueOther replaces label values exceeding the cardinality limit of a label
+	LabelValueOther = "other"
+)
+
+const (
+	FamilyTypeGauge     = "gauge"
+	FamilyTypeCounter   = "counter"
+	FamilyTypeHistogram = "histogram"
+)
+
+// Family describes a metric family created by a Registry
+type Family struct {
+	Name   string   `json:"name"`
+	Help   string   `json:"help"`
+	Type   string   `json:"type"`
+	Labels []string `json:"labels"`
+}
+
+// Registry creates labeled metric families and registers them to the underlying prometheus registerer, the families
+// are recorded so that dashboards can be generated from them
+type Registry struct {
+	registerer prometheus.Registerer
+	mtx        sync.Mutex
+	families   []Family
+}
+
+func NewRegistry(registerer prometheus.Registerer) *Registry {
+	return &Registry{registerer: registerer}
+}
+
+// Families returns the families created by the registry in the order they are created
+func (r *Registry) Families() []Family {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return append([]Family(nil), r.families...)
+}
+
+func (r *Registry) register(c prometheus.Collector, name, help, typ string, labels []string) {
+	r.registerer.MustRegister(c)
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.families = append(r.families, Family{Name: name, Help: help, Type: typ, Labels: labels})
+}
+
+func (r *Registry) Gauge(name, help string) prometheus.Gauge {
+	g := prometheus.NewGauge(prometheus.GaugeOpts{Name: name, Help: help})
+	r.register(g, name, help, FamilyTypeGauge, nil)
+	return g
+}
+
+func (r *Registry) Counter(name, help string) prometheus.Counter {
+	c := prometheus.NewCounter(prometheus.CounterOpts{Name: name, Help: help})
+	r.register(c, name, help, FamilyTypeCounter, nil)
+	return c
+}
+
+func (r *Registry) GaugeVec(name, help string, labels ...string) *prometheus.GaugeVec {
+	g := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: name, Help: help}, labels)
+	r.register(g, name, help, FamilyTypeGauge, labels)
+	return g
+}
+
+func (r *Registry) CounterVec(name, help string, labels ...string) *prometheus.CounterVec {
+	c := prometheus.NewCounterVec(prometheus.CounterOpts{Name: name, Help: help}, labels)
+	r.register(c, name, help, FamilyTypeCounter, labels)
+	return c
+}
+
+func (r *Registry) HistogramVec(name, help string, buckets []float64, labels ...string) *prometheus.HistogramVec {
+	h := prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: name, Help: help, Buckets: buckets}, labels)
+	r.register(h, name, help, FamilyTypeHistogram, labels)
+	return h
+}
+
+// labelLimiter bounds the number of distinct values of a label, values seen after the limit is reached are reported
+// as LabelValueOther
+type labelLimiter struct {
+	mtx    sync.Mutex
+	max    int
+	values map[string]struct{}
+}
+
+func newLabelLimiter(max int, preset ...string) *labelLimiter {
+	l := &labelLimiter{max: max, values: make(map[string]struct{}, max)}
+	for _, v := range preset {
+		l.values[v] = struct{}{}
+	}
+	return l
+}
+
+func (l *labelLimiter) value(v string) string {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if _, ok := l.values[v]; ok {
+		return v
+	}
+	if len(l.values) >= l.max {
+		return LabelValueOther
+	}
+	l.values[v] = struct{}{}
+	return v
+}
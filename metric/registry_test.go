@@ -0,0 +1,50 @@
+package metric
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-relayer/config"
+)
+
+func TestLabelLimiter(t *testing.T) {
+	l := newLabelLimiter(2, "0")
+	require.Equal(t, "0", l.value("0"))
+	require.Equal(t, "1", l.value("1"))
+	require.Equal(t, LabelValueOther, l.value("2"))
+	require.Equal(t, "1", l.value("1"))
+}
+
+func TestMetricServiceChannelCardinality(t *testing.T) {
+	cfg := &config.Config{GreenfieldConfig: config.GreenfieldConfig{MonitorChannelList: []uint8{1, 2}}}
+	ms := newMetricService(cfg, NewRegistry(prometheus.NewRegistry()))
+	for c := uint8(0); c < 10; c++ {
+		ms.SetNextSendSequence(DirectionGnfdToBSC, c, uint64(c))
+	}
+	ch := make(chan prometheus.Metric, 16)
+	ms.nextSendSeq.Collect(ch)
+	close(ch)
+	require.Equal(t, 4, len(ch)) // channels 0, 1, 2 and other
+}
+
+func TestClaimLatencyExemplar(t *testing.T) {
+	ms := newMetricService(&config.Config{}, NewRegistry(prometheus.NewRegistry()))
+	ms.ObserveClaimLatency(DirectionGnfdToBSC, 2, 1024, 15)
+
+	m := &dto.Metric{}
+	require.NoError(t, ms.claimLatency.WithLabelValues(DirectionGnfdToBSC).(prometheus.Metric).Write(m))
+	var exemplar *dto.Exemplar
+	for _, b := range m.GetHistogram().GetBucket() {
+		if b.GetExemplar() != nil {
+			exemplar = b.GetExemplar()
+			break
+		}
+	}
+	require.NotNil(t, exemplar)
+	require.Equal(t, float64(15), exemplar.GetValue())
+	require.Equal(t, LabelCorrelationId, exemplar.GetLabel()[0].GetName())
+	require.Equal(t, "greenfield_to_bsc-2-1024", exemplar.GetLabel()[0].GetValue())
+}
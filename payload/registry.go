@@ -0,0 +1,90 @@
+package payload
+
+import (
+	"fmt"
+	"sync"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Field is a decoded field of a package payload
+type Field struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Decoded is the decoded payload of a package
+type Decoded struct {
+	ChannelId   uint8                     `json:"channel_id"`
+	Channel     string                    `json:"channel"`
+	PackageType sdk.CrossChainPackageType `json:"package_type"`
+	Fields      []Field                   `json:"fields"`
+}
+
+// Field returns the value of the field, empty if there is no such field
+func (d *Decoded) Field(name string) string {
+	for _, f := range d.Fields {
+		if f.Name == name {
+			return f.Value
+		}
+	}
+	return ""
+}
+
+// Decoder decodes the application payloads of a channel, i.e. payloads without the package header
+type Decoder interface {
+	// Name is the name of the channel, used in metric labels and APIs
+	Name() string
+	Decode(packageType sdk.CrossChainPackageType, payload []byte) ([]Field, error)
+}
+
+var (
+	mutex    sync.RWMutex
+	decoders = make(map[uint8]Decoder)
+)
+
+// Register registers the decoder of the channel, replacing the existing one. Channels of other cross-chain apps can be
+// registered before the relayer starts.
+func Register(channelId uint8, d Decoder) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	decoders[channelId] = d
+}
+
+// Lookup returns the decoder of the channel
+func Lookup(channelId uint8) (Decoder, bool) {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	d, ok := decoders[channelId]
+	return d, ok
+}
+
+// ChannelName returns the name of the channel, "channel_<id>" if it has no decoder
+func ChannelName(channelId uint8) string {
+	if d, ok := Lookup(channelId); ok {
+		return d.Name()
+	}
+	return fmt.Sprintf("channel_%d", channelId)
+}
+
+// Decode decodes the application payload of a package of the channel
+func Decode(channelId uint8, packageType sdk.CrossChainPackageType, payload []byte) (*Decoded, error) {
+	d, ok := Lookup(channelId)
+	if !ok {
+		return nil, fmt.Errorf("no decoder registered for channel %d", channelId)
+	}
+	fields, err := d.Decode(packageType, payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode payload of channel %s, err=%s", d.Name(), err.Error())
+	}
+	return &Decoded{ChannelId: channelId, Channel: d.Name(), PackageType: packageType, Fields: fields}, nil
+}
+
+// DecodePackage decodes a package with the header, as emitted by the CrossChain contract on BSC
+func DecodePackage(channelId uint8, pkg []byte) (*Decoded, error) {
+	header, err := sdk.DecodePackageHeader(pkg)
+	if err != nil {
+		return nil, err
+	}
+	return Decode(channelId, header.PackageType, pkg[sdk.GetPackageHeaderLength(header.PackageType):])
+}
@@ -0,0 +1,33 @@
+package payload
+
+import (
+	"math/big"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecode(t *testing.T) {
+	syn, err := rlp.EncodeToBytes([]interface{}{big.NewInt(42), common.HexToAddress("0x01")})
+	require.NoError(t, err)
+	decoded, err := Decode(ChannelBucket, sdk.SynCrossChainPackageType, syn)
+	require.NoError(t, err)
+	require.Equal(t, "bucket", decoded.Channel)
+	require.Equal(t, "42", decoded.Field("id"))
+	require.Equal(t, common.HexToAddress("0x01").Hex(), decoded.Field("owner"))
+
+	_, err = Decode(200, sdk.SynCrossChainPackageType, syn)
+	require.Error(t, err)
+	require.Equal(t, "channel_200", ChannelName(200))
+
+	// channels of other apps are registered by code
+	Register(200, &Schema{Channel: "custom", Syn: []SchemaField{{"amount", KindUint}}})
+	decoded, err = Decode(200, sdk.SynCrossChainPackageType, syn)
+	require.NoError(t, err)
+	require.Equal(t, "42", decoded.Field("amount"))
+	require.Equal(t, "0000000000000000000000000000000000000001", decoded.Field("field_1"))
+	require.Equal(t, "custom", ChannelName(200))
+}
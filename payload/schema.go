@@ -0,0 +1,120 @@
+package payload
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// channels of the cross-chain apps of Greenfield
+const (
+	ChannelTransferOut uint8 = 1
+	ChannelTransferIn  uint8 = 2
+	ChannelSyncParams  uint8 = 3
+	ChannelBucket      uint8 = 4
+	ChannelObject      uint8 = 5
+	ChannelGroup       uint8 = 6
+)
+
+// kinds of rlp encoded fields
+const (
+	KindUint    = "uint"    // decimal
+	KindAddress = "address" // 0x prefixed hex
+	KindString  = "string"
+	KindBytes   = "bytes" // hex
+)
+
+// SchemaField is a field of a payload in the rlp list
+type SchemaField struct {
+	Name string
+	Kind string
+}
+
+// Schema decodes payloads which are rlp lists of fields. FAIL_ACK packages carry the SYN payload. Fields not in the
+// schema are decoded as bytes.
+type Schema struct {
+	Channel string
+	Syn     []SchemaField
+	Ack     []SchemaField
+}
+
+func (s *Schema) Name() string {
+	return s.Channel
+}
+
+func (s *Schema) Decode(packageType sdk.CrossChainPackageType, payload []byte) ([]Field, error) {
+	schema := s.Syn
+	if packageType == sdk.AckCrossChainPackageType {
+		schema = s.Ack
+	}
+	var raws []rlp.RawValue
+	if err := rlp.DecodeBytes(payload, &raws); err != nil {
+		return nil, err
+	}
+	fields := make([]Field, 0, len(raws))
+	for i, raw := range raws {
+		f := SchemaField{Name: fmt.Sprintf("field_%d", i), Kind: KindBytes}
+		if i < len(schema) {
+			f = schema[i]
+		}
+		value, err := decodeField(f.Kind, raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid field %s, err=%s", f.Name, err.Error())
+		}
+		fields = append(fields, Field{Name: f.Name, Value: value})
+	}
+	return fields, nil
+}
+
+func decodeField(kind string, raw rlp.RawValue) (string, error) {
+	k, content, _, err := rlp.Split(raw)
+	if err != nil {
+		return "", err
+	}
+	if k == rlp.List {
+		return hex.EncodeToString(raw), nil
+	}
+	switch kind {
+	case KindUint:
+		id := new(big.Int)
+		if err := rlp.DecodeBytes(raw, id); err != nil {
+			return "", err
+		}
+		return id.String(), nil
+	case KindAddress:
+		return common.BytesToAddress(content).Hex(), nil
+	case KindString:
+		return string(content), nil
+	default:
+		return hex.EncodeToString(content), nil
+	}
+}
+
+func init() {
+	Register(ChannelTransferOut, &Schema{
+		Channel: "transfer_out",
+		Syn:     []SchemaField{{"amount", KindUint}, {"recipient", KindAddress}, {"refund_address", KindAddress}},
+		Ack:     []SchemaField{{"refund_amount", KindUint}, {"refund_address", KindAddress}, {"refund_reason", KindUint}},
+	})
+	Register(ChannelTransferIn, &Schema{
+		Channel: "transfer_in",
+		Syn:     []SchemaField{{"amount", KindUint}, {"receiver", KindAddress}, {"refund_address", KindAddress}},
+		Ack:     []SchemaField{{"refund_amount", KindUint}, {"refund_address", KindAddress}, {"status", KindUint}},
+	})
+	Register(ChannelSyncParams, &Schema{
+		Channel: "sync_params",
+		Syn:     []SchemaField{{"key", KindString}, {"value", KindBytes}, {"target", KindBytes}},
+	})
+	// mirror packages of storage resources, the id is the first field of SYN packages and follows the status in ACKs
+	for channelId, name := range map[uint8]string{ChannelBucket: "bucket", ChannelObject: "object", ChannelGroup: "group"} {
+		Register(channelId, &Schema{
+			Channel: name,
+			Syn:     []SchemaField{{"id", KindUint}, {"owner", KindAddress}},
+			Ack:     []SchemaField{{"status", KindUint}, {"id", KindUint}},
+		})
+	}
+}
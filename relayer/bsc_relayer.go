@@ -32,7 +32,6 @@ func (r *BSCRelayer) Start() {
 	go r.SignAndBroadcastVoteLoop()
 	go r.CollectVotesLoop()
 	go r.AssemblePackagesLoop()
-	go r.UpdateCachedLatestValidatorsLoop()
 	go r.UpdateClientLoop()
 }
 
@@ -53,10 +52,10 @@ func (r *BSCRelayer) AssemblePackagesLoop() {
 	r.assembler.AssemblePackagesAndClaimLoop()
 }
 
-func (r *BSCRelayer) UpdateCachedLatestValidatorsLoop() {
-	r.bscExecutor.UpdateCachedLatestValidatorsLoop() // cache validators queried from greenfield, update it every 1 minute
-}
-
 func (r *BSCRelayer) UpdateClientLoop() {
 	r.bscExecutor.UpdateClientLoop()
 }
+
+func (r *BSCRelayer) HasPendingInturnClaims() (bool, error) {
+	return r.assembler.HasPendingInturnClaims()
+}
@@ -31,7 +31,7 @@ func (r *GreenfieldRelayer) Start() {
 	go r.SignAndBroadcastLoop()
 	go r.CollectVotesLoop()
 	go r.AssembleTransactionsLoop()
-	go r.UpdateCachedLatestValidatorsLoop()
+	go r.DecodeClaimReceiptsLoop()
 }
 
 // MonitorEventsLoop will monitor cross chain events for every block and persist into DB
@@ -51,6 +51,10 @@ func (r *GreenfieldRelayer) AssembleTransactionsLoop() {
 	r.greenfieldAssembler.AssembleTransactionsLoop()
 }
 
-func (r *GreenfieldRelayer) UpdateCachedLatestValidatorsLoop() {
-	r.GreenfieldExecutor.UpdateCachedLatestValidatorsLoop() // cache validators queried from greenfield, update it every 1 minute
+func (r *GreenfieldRelayer) DecodeClaimReceiptsLoop() {
+	r.greenfieldAssembler.DecodeClaimReceiptsLoop()
+}
+
+func (r *GreenfieldRelayer) HasPendingInturnClaims() (bool, error) {
+	return r.greenfieldAssembler.HasPendingInturnClaims()
 }
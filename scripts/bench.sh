@@ -0,0 +1,16 @@
+#!/bin/bash
+
+# runs the benchmarks of vote aggregation and DB paths, results are saved per commit under build/bench so that they can
+# be compared over time, e.g. BENCH_BASE=<commit> compares with the results of the commit by benchstat
+set -e
+
+out=build/bench
+mkdir -p $out
+result=$out/$(git rev-parse --short HEAD).txt
+
+go test -run '^$' -bench . -benchmem -count ${BENCH_COUNT:-5} ./bls ./vote ./db/dao | tee $result
+
+if [ -n "$BENCH_BASE" ]; then
+    which benchstat || go install golang.org/x/perf/cmd/benchstat@latest
+    benchstat $out/$BENCH_BASE.txt $result
+fi
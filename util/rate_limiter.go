@@ -0,0 +1,71 @@
+package util
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimiter limits calls to each endpoint to qps with token buckets, bursts up to one second of calls are allowed.
+// A nil RateLimiter does not limit.
+type RateLimiter struct {
+	mutex   sync.Mutex
+	qps     float64
+	burst   float64
+	buckets map[string]*tokenBucket
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter returns nil if qps is not positive
+func NewRateLimiter(qps float64) *RateLimiter {
+	if qps <= 0 {
+		return nil
+	}
+	return &RateLimiter{
+		qps:     qps,
+		burst:   math.Max(1, math.Ceil(qps)),
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// Wait blocks until a call to the endpoint is allowed or the context is done
+func (l *RateLimiter) Wait(ctx context.Context, endpoint string) error {
+	if l == nil {
+		return nil
+	}
+	delay := l.reserve(endpoint, time.Now())
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// reserve takes a token of the endpoint and returns how long to wait until the token is available
+func (l *RateLimiter) reserve(endpoint string, now time.Time) time.Duration {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	b, ok := l.buckets[endpoint]
+	if !ok {
+		b = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[endpoint] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.qps)
+	b.last = now
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / l.qps * float64(time.Second))
+}
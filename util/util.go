@@ -6,8 +6,9 @@ import (
 	"strconv"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/prysmaticlabs/prysm/crypto/bls/blst"
 	"github.com/willf/bitset"
+
+	"github.com/bnb-chain/greenfield-relayer/bls"
 )
 
 func IndexOf(element string, data []string) int {
@@ -20,7 +21,7 @@ func IndexOf(element string, data []string) int {
 }
 
 func BlsPubKeyFromPrivKeyStr(privKeyStr string) []byte {
-	privKey, err := blst.SecretKeyFromBytes(common.Hex2Bytes(privKeyStr))
+	privKey, err := bls.SecretKeyFromBytes(common.Hex2Bytes(privKeyStr))
 	if err != nil {
 		panic(err)
 	}
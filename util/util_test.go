@@ -1,8 +1,10 @@
 package util
 
 import (
+	"context"
 	"encoding/hex"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"github.com/willf/bitset"
@@ -41,3 +43,18 @@ func TestBitSetToBigInt(t *testing.T) {
 	require.EqualValues(t, 1, bigint.Bit(255))
 	require.NotEqual(t, 1, bigint.Bit(3))
 }
+
+func TestRateLimiter(t *testing.T) {
+	require.Nil(t, NewRateLimiter(0))
+	require.NoError(t, (*RateLimiter)(nil).Wait(context.Background(), "a"))
+
+	l := NewRateLimiter(2)
+	now := time.Now()
+	require.Equal(t, time.Duration(0), l.reserve("a", now))
+	require.Equal(t, time.Duration(0), l.reserve("a", now))
+	require.Equal(t, 500*time.Millisecond, l.reserve("a", now))
+	// endpoints are limited separately
+	require.Equal(t, time.Duration(0), l.reserve("b", now))
+	// tokens are refilled over time
+	require.Equal(t, time.Duration(0), l.reserve("a", now.Add(time.Second)))
+}
@@ -0,0 +1,37 @@
+package version
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// set at link time by ldflags, see Makefile
+var (
+	AppVersion    = "unknown"
+	GitCommit     = "unknown"
+	GitCommitDate = "unknown"
+	BuildTime     = "unknown"
+)
+
+type Info struct {
+	Version       string `json:"version"`
+	GitCommit     string `json:"git_commit"`
+	GitCommitDate string `json:"git_commit_date"`
+	BuildTime     string `json:"build_time"`
+	GoVersion     string `json:"go_version"`
+}
+
+func GetInfo() Info {
+	return Info{
+		Version:       AppVersion,
+		GitCommit:     GitCommit,
+		GitCommitDate: GitCommitDate,
+		BuildTime:     BuildTime,
+		GoVersion:     runtime.Version(),
+	}
+}
+
+func (i Info) String() string {
+	return fmt.Sprintf("version=%s, git_commit=%s, git_commit_date=%s, build_time=%s, go_version=%s",
+		i.Version, i.GitCommit, i.GitCommitDate, i.BuildTime, i.GoVersion)
+}
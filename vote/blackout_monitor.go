@@ -0,0 +1,68 @@
+package vote
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bnb-chain/greenfield-relayer/common"
+	"github.com/bnb-chain/greenfield-relayer/config"
+	"github.com/bnb-chain/greenfield-relayer/logging"
+)
+
+// BlackoutMonitor pauses vote broadcasting and claims during the blackout windows configured by operators, and resumes
+// them once a window ends. Listeners and vote collection keep running, so that packages of the window are relayed
+// afterwards. Tasks paused by others are left untouched.
+type BlackoutMonitor struct {
+	config    *config.Config
+	scheduler *common.Scheduler
+	paused    []string // tasks paused by the monitor
+	window    *config.BlackoutWindow
+}
+
+func NewBlackoutMonitor(cfg *config.Config) *BlackoutMonitor {
+	return &BlackoutMonitor{
+		config:    cfg,
+		scheduler: common.GetScheduler(),
+	}
+}
+
+// StartLoop checks the windows from the first tick on, when the tasks to pause are already scheduled
+func (m *BlackoutMonitor) StartLoop() {
+	common.Schedule(common.TaskBlackoutMonitor, common.BlackoutCheckInterval, func() {
+		m.observe(time.Now())
+	}).Run()
+}
+
+// observe pauses tasks when a blackout window starts and resumes them when it ends, an alert is sent on every change
+func (m *BlackoutMonitor) observe(now time.Time) {
+	window := config.BlackoutAt(m.config.RelayConfig.BlackoutWindows, now)
+	if window == m.window {
+		return
+	}
+	if m.window != nil {
+		for _, name := range m.paused {
+			m.scheduler.Task(name).Resume()
+		}
+		m.alert(fmt.Sprintf("blackout window %s ended, resumed %s", m.window.Name, strings.Join(m.paused, ", ")))
+		m.paused = nil
+	}
+	m.window = window
+	if window != nil {
+		for _, name := range broadcastTasks {
+			task := m.scheduler.Task(name)
+			if task == nil || task.Status().Paused {
+				continue
+			}
+			task.Pause()
+			m.paused = append(m.paused, name)
+		}
+		m.alert(fmt.Sprintf("blackout window %s started, paused %s until %s", window.Name, strings.Join(m.paused, ", "), window.End))
+	}
+}
+
+func (m *BlackoutMonitor) alert(msg string) {
+	logging.Logger.Info(msg)
+	config.SendTelegramMessage(m.config.AlertConfig.Identity, m.config.AlertConfig.TelegramBotId,
+		m.config.AlertConfig.TelegramChatId, msg)
+}
@@ -0,0 +1,53 @@
+package vote
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-relayer/common"
+	"github.com/bnb-chain/greenfield-relayer/config"
+)
+
+func TestBlackoutMonitorObserve(t *testing.T) {
+	s := common.NewScheduler(0, nil)
+	for _, name := range broadcastTasks {
+		s.Schedule(name, time.Second, func() {})
+	}
+	// paused by others, it is not resumed by the monitor
+	s.Task(common.TaskBSCAssembler).Pause()
+	cfg := &config.Config{RelayConfig: config.RelayConfig{BlackoutWindows: []config.BlackoutWindow{
+		{Name: "bsc upgrade", Start: "2023-06-01T08:00:00Z", End: "2023-06-01T09:00:00Z"},
+		{Name: "greenfield upgrade", Start: "2023-06-01T09:00:00Z", End: "2023-06-01T10:00:00Z"},
+	}}}
+	m := &BlackoutMonitor{config: cfg, scheduler: s}
+	at := func(clock string) time.Time {
+		tm, err := time.Parse(time.RFC3339, "2023-06-01T"+clock+"Z")
+		require.NoError(t, err)
+		return tm
+	}
+
+	m.observe(at("07:59:59"))
+	require.Empty(t, m.paused)
+	require.False(t, s.Task(common.TaskGreenfieldAssembler).Status().Paused)
+
+	m.observe(at("08:00:00"))
+	require.Len(t, m.paused, 3)
+	for _, name := range broadcastTasks {
+		require.True(t, s.Task(name).Status().Paused)
+	}
+
+	// the next window starts right after the first one
+	m.observe(at("09:00:00"))
+	require.Equal(t, "greenfield upgrade", m.window.Name)
+	require.Len(t, m.paused, 3)
+	require.True(t, s.Task(common.TaskGreenfieldAssembler).Status().Paused)
+
+	m.observe(at("10:00:00"))
+	require.Nil(t, m.window)
+	require.Empty(t, m.paused)
+	require.True(t, s.Task(common.TaskBSCAssembler).Status().Paused)
+	require.False(t, s.Task(common.TaskGreenfieldAssembler).Status().Paused)
+	require.False(t, s.Task(common.TaskBSCVoteBroadcast).Status().Paused)
+}
@@ -16,7 +16,6 @@ import (
 	"github.com/ethereum/go-ethereum/rlp"
 	tmtypes "github.com/tendermint/tendermint/types"
 	"github.com/tendermint/tendermint/votepool"
-	"gorm.io/gorm"
 
 	"github.com/bnb-chain/greenfield-relayer/common"
 	"github.com/bnb-chain/greenfield-relayer/config"
@@ -24,44 +23,66 @@ import (
 	"github.com/bnb-chain/greenfield-relayer/db/dao"
 	"github.com/bnb-chain/greenfield-relayer/db/model"
 	"github.com/bnb-chain/greenfield-relayer/executor"
+	"github.com/bnb-chain/greenfield-relayer/hook"
 	"github.com/bnb-chain/greenfield-relayer/logging"
+	"github.com/bnb-chain/greenfield-relayer/metric"
 	"github.com/bnb-chain/greenfield-relayer/types"
 )
 
 type BSCVoteProcessor struct {
-	daoManager   *dao.DaoManager
-	config       *config.Config
-	signer       *VoteSigner
-	bscExecutor  *executor.BSCExecutor
-	blsPublicKey []byte
+	daoManager     *dao.DaoManager
+	config         *config.Config
+	signer         *VoteSigner
+	bscExecutor    *executor.BSCExecutor
+	blsPublicKey   []byte
+	verifier       BSCPackageVerifier
+	hashNegotiator *eventHashNegotiator
+	metricService  *metric.MetricService
 }
 
-func NewBSCVoteProcessor(cfg *config.Config, dao *dao.DaoManager, signer *VoteSigner, bscExecutor *executor.BSCExecutor) *BSCVoteProcessor {
+func NewBSCVoteProcessor(cfg *config.Config, dao *dao.DaoManager, signer *VoteSigner, bscExecutor *executor.BSCExecutor,
+	verifier BSCPackageVerifier, ms *metric.MetricService) *BSCVoteProcessor {
 	return &BSCVoteProcessor{
-		config:       cfg,
-		daoManager:   dao,
-		signer:       signer,
-		bscExecutor:  bscExecutor,
-		blsPublicKey: bscExecutor.GreenfieldExecutor.BlsPubKey,
+		config:         cfg,
+		daoManager:     dao,
+		signer:         signer,
+		bscExecutor:    bscExecutor,
+		blsPublicKey:   bscExecutor.GreenfieldExecutor.BlsPubKey,
+		verifier:       verifier,
+		hashNegotiator: newEventHashNegotiator(&cfg.VotePoolConfig, bscExecutor.GreenfieldExecutor.CountVotesByEventHashAndType),
+		metricService:  ms,
 	}
 }
 
 func (p *BSCVoteProcessor) SignAndBroadcastVoteLoop() {
-	ticker := time.NewTicker(time.Duration(p.config.VotePoolConfig.BroadcastIntervalInMillisecond) * time.Millisecond)
-	for range ticker.C {
+	interval := time.Duration(p.config.VotePoolConfig.BroadcastIntervalInMillisecond) * time.Millisecond
+	common.Schedule(common.TaskBSCVoteBroadcast, interval, func() {
 		if err := p.signAndBroadcast(); err != nil {
 			logging.Logger.Errorf("encounter error, err: %s", err.Error())
 		}
-	}
+	}).Run()
 }
 
 // SignAndBroadcastVoteLoop signs using the bls private key, and broadcast the vote to votepool
-func (p *BSCVoteProcessor) signAndBroadcast() error {
-	latestHeight, err := p.bscExecutor.GetLatestBlockHeightWithRetry()
+func (p *BSCVoteProcessor) signAndBroadcast() (err error) {
+	// votes broadcast before an error are still persisted
+	batch := &selfVotedBatch{}
+	defer func() {
+		flushErr := batch.flush(p.daoManager, func(txManager *dao.DaoManager, ids []int64) error {
+			return txManager.BSCDao.UpdateBatchPackagesStatus(ids, db.SelfVoted)
+		})
+		if flushErr != nil && err == nil {
+			err = flushErr
+		}
+	}()
+	finalizedHeight, ok, err := p.bscExecutor.GetFinalizedBlockHeightWithRetry()
 	if err != nil {
-		logging.Logger.Errorf("failed to get latest block height, error: %s", err.Error())
+		logging.Logger.Errorf("failed to get finalized block height, error: %s", err.Error())
 		return err
 	}
+	if !ok {
+		return nil
+	}
 
 	leastSavedPkgHeight, err := p.daoManager.BSCDao.GetLeastSavedPackagesHeight()
 	if err != nil {
@@ -69,7 +90,7 @@ func (p *BSCVoteProcessor) signAndBroadcast() error {
 		return err
 	}
 
-	if leastSavedPkgHeight+p.config.BSCConfig.NumberOfBlocksForFinality > latestHeight {
+	if leastSavedPkgHeight > finalizedHeight {
 		return nil
 	}
 	pkgs, err := p.daoManager.BSCDao.GetPackagesByHeightAndStatus(db.Saved, leastSavedPkgHeight)
@@ -81,6 +102,11 @@ func (p *BSCVoteProcessor) signAndBroadcast() error {
 	if len(pkgs) == 0 {
 		return nil
 	}
+	// never trust DB rows only, packages are voted after verified against BSC
+	if err = p.verifier.VerifyPackages(leastSavedPkgHeight, pkgs); err != nil {
+		logging.Logger.Errorf("failed to verify packages at height %d against BSC, error: %s", leastSavedPkgHeight, err.Error())
+		return err
+	}
 
 	// For packages with same oracle sequence, aggregate their payload and make single vote to votepool
 	pkgsGroupByOracleSeq := make(map[uint64][]*model.BscRelayPackage)
@@ -89,25 +115,12 @@ func (p *BSCVoteProcessor) signAndBroadcast() error {
 	}
 
 	for seq, pkgsForSeq := range pkgsGroupByOracleSeq {
-		aggPkgs := make(oracletypes.Packages, 0)
-		var pkgIds []int64
-
-		sort.Slice(pkgsForSeq, func(i, j int) bool {
-			return pkgsForSeq[i].TxIndex < pkgsForSeq[j].TxIndex
-		})
+		blsClaim, err := bscClaimForPackages(uint32(p.config.BSCConfig.ChainId), uint32(p.config.GreenfieldConfig.ChainId), seq, pkgsForSeq)
+		if err != nil {
+			return err
+		}
+		pkgIds := make([]int64, 0, len(pkgsForSeq))
 		for _, pkg := range pkgsForSeq {
-			// aggregate pkgs with same oracle seq
-			payload, err := hex.DecodeString(pkg.PayLoad)
-			if err != nil {
-				return fmt.Errorf("decode payload error, payload=%s, err=%s", pkg.PayLoad, err.Error())
-			}
-
-			pack := oracletypes.Package{
-				ChannelId: sdk.ChannelID(pkg.ChannelId),
-				Sequence:  pkg.PackageSequence,
-				Payload:   payload,
-			}
-			aggPkgs = append(aggPkgs, pack)
 			pkgIds = append(pkgIds, pkg.Id)
 		}
 
@@ -120,70 +133,98 @@ func (p *BSCVoteProcessor) signAndBroadcast() error {
 			if err = p.daoManager.BSCDao.UpdateBatchPackagesStatus(pkgIds, db.Delivered); err != nil {
 				return err
 			}
-			logging.Logger.Infof("oracle sequence %d has already been filled", seq)
+			hook.NotifyBSCPackages(hook.PointAfterDelivery, pkgsForSeq)
+			logging.Logger.Infof("oracle sequence %d has already been filled, cid=%s", seq, common.CorrelationId(metric.DirectionBSCToGnfd, uint8(common.OracleChannelId), seq))
 			continue
 		}
-		encodedPayload, err := rlp.EncodeToBytes(aggPkgs)
-		if err != nil {
-			return fmt.Errorf("encode packages error, err=%s", err.Error())
-		}
-		blsClaim := oracletypes.BlsClaim{
-			// chain ids are validated when packages persisted into DB, non-matched ones would be omitted
-			SrcChainId:  uint32(p.config.BSCConfig.ChainId),
-			DestChainId: uint32(p.config.GreenfieldConfig.ChainId),
-			Timestamp:   uint64(pkgsForSeq[0].TxTime),
-			Sequence:    seq,
-			Payload:     encodedPayload,
+		if err = hook.RunBSCPackages(hook.PointBeforeVote, pkgsForSeq); err != nil {
+			logging.Logger.Infof("skip voting for oracle sequence %d, err=%s", seq, err.Error())
+			continue
 		}
-		eventHash := blsClaim.GetSignBytes()
+		eventHashes := p.hashNegotiator.hashes(func(version uint32) []byte {
+			return bscEventHashes[version](blsClaim)
+		})
 		channelId := common.OracleChannelId
-		v := p.constructSignedVote(eventHash[:])
 
-		// broadcast v
-		if err = retry.Do(func() error {
-			err = p.bscExecutor.GreenfieldExecutor.BroadcastVote(v)
-			if err != nil {
-				return fmt.Errorf("failed to submit vote for events with channel id %d and sequence %d", channelId, seq)
-			}
-			return nil
-		}, retry.Context(context.Background()), common.RtyAttem, common.RtyDelay, common.RtyErr); err != nil {
+		// the event might have been signed and broadcast before a restart, the vote is neither re-signed nor re-broadcast,
+		// it is re-broadcast when collecting votes if missing in the vote pool
+		v, err := p.hashNegotiator.loadOwnVote(p.daoManager.VoteDao, votepool.FromBscCrossChainEvent, eventHashes, p.blsPublicKey)
+		if err != nil {
 			return err
 		}
-
-		err = p.daoManager.BSCDao.DB.Transaction(func(dbTx *gorm.DB) error {
-			e := dao.UpdateBatchPackagesStatus(dbTx, pkgIds, db.SelfVoted)
-			if e != nil {
-				return e
-			}
-			exist, e := dao.IsVoteExist(dbTx, uint8(channelId), seq, hex.EncodeToString(v.PubKey[:]))
-			if e != nil {
-				return e
+		signed := v != nil
+		if !signed {
+			eventHash, err := p.hashNegotiator.negotiate(eventHashes, votepool.FromBscCrossChainEvent)
+			if err != nil {
+				return err
 			}
-			if !exist {
-				e = dao.SaveVote(dbTx, EntityToDto(v, uint8(channelId), seq, encodedPayload))
-				if e != nil {
-					return e
+			v = p.constructSignedVote(eventHash)
+
+			// broadcast v
+			if err = retry.Do(func() error {
+				err = p.bscExecutor.GreenfieldExecutor.BroadcastVote(v)
+				if err != nil {
+					return fmt.Errorf("failed to submit vote for events with channel id %d and sequence %d", channelId, seq)
 				}
+				return nil
+			}, retry.Context(context.Background()), common.RtyAttem, common.RtyDelay, common.RtyErr,
+				common.RtyBudget(common.SubsystemBSCVoteProcessor)); err != nil {
+				return err
 			}
-			return nil
-		})
-		if err != nil {
-			return err
 		}
+
+		// packages are updated to 'SELF_VOTED' and the vote is persisted at the end of the tick
+		var ownVote *model.OwnVote
+		if !signed {
+			ownVote = toOwnVote(v)
+		}
+		batch.add(pkgIds, ownVote, EntityToDto(v, uint8(channelId), seq, blsClaim.Payload))
 	}
 	return nil
 }
 
+// bscClaimForPackages builds the claim of packages with the same oracle sequence, the packages are sorted by tx index and
+// aggregated by rlp encoding, the claim is signed by validators and submitted to Greenfield
+func bscClaimForPackages(srcChainId, destChainId uint32, seq uint64, pkgs []*model.BscRelayPackage) (*oracletypes.BlsClaim, error) {
+	sort.Slice(pkgs, func(i, j int) bool {
+		return pkgs[i].TxIndex < pkgs[j].TxIndex
+	})
+	aggPkgs := make(oracletypes.Packages, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		payload, err := hex.DecodeString(pkg.PayLoad)
+		if err != nil {
+			return nil, fmt.Errorf("decode payload error, payload=%s, err=%s", pkg.PayLoad, err.Error())
+		}
+		aggPkgs = append(aggPkgs, oracletypes.Package{
+			ChannelId: sdk.ChannelID(pkg.ChannelId),
+			Sequence:  pkg.PackageSequence,
+			Payload:   payload,
+		})
+	}
+	encodedPayload, err := rlp.EncodeToBytes(aggPkgs)
+	if err != nil {
+		return nil, fmt.Errorf("encode packages error, err=%s", err.Error())
+	}
+	return &oracletypes.BlsClaim{
+		// chain ids are validated when packages persisted into DB, non-matched ones would be omitted
+		SrcChainId:  srcChainId,
+		DestChainId: destChainId,
+		Timestamp:   uint64(pkgs[0].TxTime),
+		Sequence:    seq,
+		Payload:     encodedPayload,
+	}, nil
+}
+
 func (p *BSCVoteProcessor) CollectVotesLoop() {
-	ticker := time.NewTicker(time.Duration(p.config.VotePoolConfig.QueryIntervalInMillisecond) * time.Millisecond)
-	for range ticker.C {
+	interval := time.Duration(p.config.VotePoolConfig.QueryIntervalInMillisecond) * time.Millisecond
+	common.Schedule(common.TaskBSCVoteCollect, interval, func() {
 		if err := p.collectVotes(); err != nil {
 			logging.Logger.Errorf("encounter error, err: %s", err.Error())
 		}
-	}
+	}).Run()
 }
 
-func (p *BSCVoteProcessor) collectVotes() error {
+func (p *BSCVoteProcessor) collectVotes() (err error) {
 	pkgs, err := p.daoManager.BSCDao.GetPackagesByStatus(db.SelfVoted)
 	if err != nil {
 		logging.Logger.Errorf("failed to get voted packages from db, error: %s", err.Error())
@@ -193,13 +234,23 @@ func (p *BSCVoteProcessor) collectVotes() error {
 	for _, pkg := range pkgs {
 		pkgsGroupByOracleSeq[pkg.OracleSequence] = append(pkgsGroupByOracleSeq[pkg.OracleSequence], pkg)
 	}
+	// packages with enough votes are updated to 'ALL_VOTED' together
+	allVoted := &idBatch{}
+	defer func() {
+		flushErr := allVoted.flush(func(ids []int64) error {
+			return p.daoManager.BSCDao.UpdateBatchPackagesStatus(ids, db.AllVoted)
+		})
+		if flushErr != nil && err == nil {
+			err = flushErr
+		}
+	}()
 	wg := new(sync.WaitGroup)
 	errCh := make(chan error)
 	waitCh := make(chan struct{})
 	go func() {
 		for seq, pkgsForSeq := range pkgsGroupByOracleSeq {
 			wg.Add(1)
-			go p.collectVoteForPackages(pkgsForSeq, seq, errCh, wg)
+			go p.collectVoteForPackages(pkgsForSeq, seq, allVoted, errCh, wg)
 		}
 		wg.Wait()
 		close(waitCh)
@@ -214,7 +265,7 @@ func (p *BSCVoteProcessor) collectVotes() error {
 	}
 }
 
-func (p *BSCVoteProcessor) collectVoteForPackages(pkgsForSeq []*model.BscRelayPackage, seq uint64, errChan chan error, wg *sync.WaitGroup) {
+func (p *BSCVoteProcessor) collectVoteForPackages(pkgsForSeq []*model.BscRelayPackage, seq uint64, allVoted *idBatch, errChan chan error, wg *sync.WaitGroup) {
 	defer wg.Done()
 	var pkgIds []int64
 	for _, tx := range pkgsForSeq {
@@ -230,21 +281,34 @@ func (p *BSCVoteProcessor) collectVoteForPackages(pkgsForSeq []*model.BscRelayPa
 			errChan <- err
 			return
 		}
-		logging.Logger.Infof("oracle sequence %d has already been filled", seq)
+		hook.NotifyBSCPackages(hook.PointAfterDelivery, pkgsForSeq)
+		logging.Logger.Infof("oracle sequence %d has already been filled, cid=%s", seq, common.CorrelationId(metric.DirectionBSCToGnfd, uint8(common.OracleChannelId), seq))
 		return
 	}
-	if err := p.prepareEnoughValidVotesForPackages(common.OracleChannelId, seq, pkgIds); err != nil {
-		errChan <- err
-		return
-	}
-	if err = p.daoManager.BSCDao.UpdateBatchPackagesStatus(pkgIds, db.AllVoted); err != nil {
-		errChan <- err
+	if err := p.prepareEnoughValidVotesForPackages(common.OracleChannelId, seq, pkgsForSeq[0].TxTime); err != nil {
+		if errors.Is(err, common.ErrRecordNotFound) {
+			err = p.resignPackages(pkgIds, seq)
+		}
+		if err != nil {
+			errChan <- err
+		}
 		return
 	}
+	recordVoteCollection(p.metricService, metric.DirectionBSCToGnfd, uint8(common.OracleChannelId), seq, pkgsForSeq[0].TxTime)
+	allVoted.add(pkgIds...)
+}
+
+// resignPackages sends the packages of the oracle sequence back to be signed if its own vote is missing, e.g. dropped
+// as stale while the BLS key of the relayer was not registered. The vote is restored from the own votes or signed
+// again, and re-broadcast if missing in the vote pool, instead of the packages staying self voted forever.
+func (p *BSCVoteProcessor) resignPackages(pkgIds []int64, seq uint64) error {
+	logging.Logger.Infof("own vote for oracle sequence %d is missing, it is signed again, cid=%s",
+		seq, common.CorrelationId(metric.DirectionBSCToGnfd, uint8(common.OracleChannelId), seq))
+	return p.daoManager.BSCDao.UpdateBatchPackagesStatus(pkgIds, db.Saved)
 }
 
 // prepareEnoughValidVotesForPackages will prepare fetch and validate votes result, store in votes
-func (p *BSCVoteProcessor) prepareEnoughValidVotesForPackages(channelId types.ChannelId, sequence uint64, pkgIds []int64) error {
+func (p *BSCVoteProcessor) prepareEnoughValidVotesForPackages(channelId types.ChannelId, sequence uint64, txTime int64) error {
 	localVote, err := p.daoManager.VoteDao.GetVoteByChannelIdAndSequenceAndPubKey(uint8(channelId), sequence, hex.EncodeToString(p.blsPublicKey))
 	if err != nil {
 		return err
@@ -262,25 +326,26 @@ func (p *BSCVoteProcessor) prepareEnoughValidVotesForPackages(channelId types.Ch
 		return nil
 	}
 	// Query from votePool until there are more than 2/3 votes
-	if err = p.queryMoreThanTwoThirdValidVotes(localVote, validators); err != nil {
+	if err = p.queryMoreThanTwoThirdValidVotes(localVote, validators, txTime); err != nil {
 		return err
 	}
 	return nil
 }
 
-// queryMoreThanTwoThirdValidVotes queries votes from votePool
-func (p *BSCVoteProcessor) queryMoreThanTwoThirdValidVotes(localVote *model.Vote, validators []*tmtypes.Validator) error {
+// queryMoreThanTwoThirdValidVotes queries votes from votePool, txTime is the time packages are sent on BSC
+func (p *BSCVoteProcessor) queryMoreThanTwoThirdValidVotes(localVote *model.Vote, validators []*tmtypes.Validator, txTime int64) error {
 	triedTimes := 0
 	validVotesTotalCnt := 1
 	channelId := localVote.ChannelId
 	seq := localVote.Sequence
 	ticker := time.NewTicker(VotePoolQueryRetryInterval)
-	logging.Logger.Debugf("queries votes for for channel %d and seq %d", channelId, seq)
+	logging.Logger.Debugf("queries votes for channel %d and seq %d, cid=%s", channelId, seq,
+		common.CorrelationId(metric.DirectionBSCToGnfd, channelId, seq))
 
 	for range ticker.C {
 		triedTimes++
 		if triedTimes > QueryVotepoolMaxRetryTimes {
-			return errors.New("exceed max retry")
+			return fmt.Errorf("%w, exceed max retry for channel %d and sequence %d", common.ErrNotEnoughVotes, channelId, seq)
 		}
 		queriedVotes, err := p.bscExecutor.GreenfieldExecutor.QueryVotesByEventHashAndType(localVote.EventHash, votepool.FromBscCrossChainEvent)
 		if err != nil {
@@ -298,6 +363,13 @@ func (p *BSCVoteProcessor) queryMoreThanTwoThirdValidVotes(localVote *model.Vote
 		}
 		isLocalVoteIncluded := false
 
+		// votes of the request are saved in one batch
+		pubKeys, err := p.daoManager.VoteDao.GetVotePubKeysByChannelIdAndSequence(channelId, seq)
+		if err != nil {
+			return err
+		}
+		savedPubKeys := toSet(pubKeys)
+		newVotes := make([]*model.Vote, 0)
 		for _, v := range queriedVotes {
 			if !p.isVotePubKeyValid(v, validators) {
 				validVotesCntPerReq--
@@ -315,17 +387,21 @@ func (p *BSCVoteProcessor) queryMoreThanTwoThirdValidVotes(localVote *model.Vote
 				continue
 			}
 
-			exist, err := p.daoManager.VoteDao.IsVoteExist(channelId, seq, hex.EncodeToString(v.PubKey[:]))
-			if err != nil {
-				return err
-			}
-			if exist {
+			pubKey := hex.EncodeToString(v.PubKey[:])
+			if savedPubKeys[pubKey] {
 				validVotesCntPerReq--
 				continue
 			}
-			if err = p.daoManager.VoteDao.SaveVote(EntityToDto(v, channelId, seq, localVote.ClaimPayload)); err != nil {
+			savedPubKeys[pubKey] = true
+			newVotes = append(newVotes, EntityToDto(v, channelId, seq, localVote.ClaimPayload))
+		}
+		if len(newVotes) != 0 {
+			if err = p.daoManager.VoteDao.SaveBatchVotes(newVotes); err != nil {
 				return err
 			}
+			if err = recordVoteLatencies(p.daoManager.VoteDao, p.metricService, metric.DirectionBSCToGnfd, txTime, newVotes); err != nil {
+				logging.Logger.Errorf("failed to record vote latencies, err=%s", err.Error())
+			}
 		}
 
 		validVotesTotalCnt += validVotesCntPerReq
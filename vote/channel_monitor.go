@@ -0,0 +1,81 @@
+package vote
+
+import (
+	"fmt"
+
+	"github.com/bnb-chain/greenfield-relayer/common"
+	"github.com/bnb-chain/greenfield-relayer/config"
+	"github.com/bnb-chain/greenfield-relayer/executor"
+	"github.com/bnb-chain/greenfield-relayer/logging"
+	"github.com/bnb-chain/greenfield-relayer/metric"
+	"github.com/bnb-chain/greenfield-relayer/types"
+)
+
+// ChannelMonitor watches the permissions of the monitored Greenfield -> BSC channels on both chains, a channel is
+// disabled once governance forbids sending over it on Greenfield or unregisters it in the CrossChain contract on BSC.
+// Packages of disabled channels are neither voted nor claimed until the channel is enabled again, since their claims
+// would keep failing.
+type ChannelMonitor struct {
+	config             *config.Config
+	greenfieldExecutor *executor.GreenfieldExecutor
+	bscExecutor        *executor.BSCExecutor
+	metricService      *metric.MetricService
+	disabled           map[uint8]bool
+}
+
+func NewChannelMonitor(cfg *config.Config, greenfieldExecutor *executor.GreenfieldExecutor, bscExecutor *executor.BSCExecutor,
+	ms *metric.MetricService) *ChannelMonitor {
+	return &ChannelMonitor{
+		config:             cfg,
+		greenfieldExecutor: greenfieldExecutor,
+		bscExecutor:        bscExecutor,
+		metricService:      ms,
+		disabled:           make(map[uint8]bool),
+	}
+}
+
+func (m *ChannelMonitor) StartLoop() {
+	common.Schedule(common.TaskChannelMonitor, common.ChannelStatusCheckInterval, func() {
+		for _, c := range m.config.GreenfieldConfig.MonitorChannelList {
+			if err := m.check(c); err != nil {
+				logging.Logger.Errorf("failed to check permission of channel %d, err=%s", c, err.Error())
+			}
+		}
+	}).Run()
+}
+
+func (m *ChannelMonitor) check(channelId uint8) error {
+	enabled, err := m.greenfieldExecutor.IsChannelSendAllowed(types.ChannelId(channelId))
+	if err != nil {
+		return err
+	}
+	reason := "sending is forbidden on Greenfield"
+	if enabled {
+		if enabled, err = m.bscExecutor.IsChannelEnabled(types.ChannelId(channelId)); err != nil {
+			return err
+		}
+		reason = "the channel is not registered in the CrossChain contract on BSC"
+	}
+	m.metricService.SetChannelEnabled(channelId, enabled)
+	m.observe(channelId, enabled, reason)
+	return nil
+}
+
+// observe enables or disables the channel when its permission changes, an alert is sent on every change
+func (m *ChannelMonitor) observe(channelId uint8, enabled bool, reason string) {
+	if enabled == !m.disabled[channelId] {
+		return
+	}
+	common.SetChannelEnabled(channelId, enabled)
+	var msg string
+	if enabled {
+		delete(m.disabled, channelId)
+		msg = fmt.Sprintf("channel %d is enabled again, resumed voting and claiming its packages", channelId)
+	} else {
+		m.disabled[channelId] = true
+		msg = fmt.Sprintf("channel %d is disabled by governance, %s, stopped voting and claiming its packages", channelId, reason)
+	}
+	logging.Logger.Error(msg)
+	config.SendTelegramMessage(m.config.AlertConfig.Identity, m.config.AlertConfig.TelegramBotId,
+		m.config.AlertConfig.TelegramChatId, msg)
+}
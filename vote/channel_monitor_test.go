@@ -0,0 +1,30 @@
+package vote
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-relayer/common"
+	"github.com/bnb-chain/greenfield-relayer/config"
+)
+
+func TestChannelMonitorObserve(t *testing.T) {
+	defer common.SetChannelEnabled(3, true)
+	defer common.SetChannelEnabled(4, true)
+	m := &ChannelMonitor{config: &config.Config{}, disabled: make(map[uint8]bool)}
+
+	m.observe(3, true, "")
+	require.True(t, common.IsChannelEnabled(3))
+	require.Empty(t, common.DisabledChannels())
+
+	m.observe(4, false, "forbidden")
+	m.observe(3, false, "forbidden")
+	require.False(t, common.IsChannelEnabled(3))
+	require.Equal(t, []uint8{3, 4}, common.DisabledChannels())
+
+	m.observe(3, true, "")
+	require.True(t, common.IsChannelEnabled(3))
+	require.Equal(t, []uint8{4}, common.DisabledChannels())
+	require.Len(t, m.disabled, 1)
+}
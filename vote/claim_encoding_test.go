@@ -0,0 +1,237 @@
+package vote
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	oracletypes "github.com/cosmos/cosmos-sdk/x/oracle/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-relayer/bls"
+	"github.com/bnb-chain/greenfield-relayer/config"
+	"github.com/bnb-chain/greenfield-relayer/db/model"
+	"github.com/bnb-chain/greenfield-relayer/types"
+	"github.com/bnb-chain/greenfield-relayer/util"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files of claim encodings")
+
+const claimVectorsFile = "claim_vectors.json"
+
+// claimVector pins the encoding of a claim, any change of it makes claims be rejected by the destination chain
+type claimVector struct {
+	ClaimPayload        string   `json:"claim_payload"`
+	EventHash           string   `json:"event_hash"`
+	Signatures          []string `json:"signatures"`
+	AggregatedSignature string   `json:"aggregated_signature"`
+	ValidatorBitSet     string   `json:"validator_bitset"`
+}
+
+// secret keys of validators from the BLS test vectors of the Ethereum consensus specs, validators 0 and 2 vote
+var goldenValidatorKeys = []string{
+	"263dbd792f5b1be47ed85f8938c0f29586af0d3ac7b977f21c278fe1462040e3",
+	"47b8192d77bf871b62e87859d653922725724a5c031afeabc60bcef5ff665138",
+	"328388aff0d4a5b7dc9205abd374e7e98f3cd9f3418edb4eafda5fb16473d216",
+}
+
+var goldenGreenfieldTxs = map[string]*model.GreenfieldRelayTransaction{
+	"greenfield_syn_package": {
+		SrcChainId: 1, DestChainId: 714, ChannelId: 1, Sequence: 42, PackageType: uint32(sdk.SynCrossChainPackageType),
+		TxTime: 1680000000, RelayerFee: "250000000000000", AckRelayerFee: "130000000000000", PayLoad: "0a0b0c0d",
+	},
+	// the ack relayer fee is not encoded for ack packages
+	"greenfield_ack_package": {
+		SrcChainId: 1, DestChainId: 714, ChannelId: 2, Sequence: 7, PackageType: uint32(sdk.AckCrossChainPackageType),
+		TxTime: 1680000060, RelayerFee: "0", AckRelayerFee: "130000000000000",
+	},
+}
+
+var goldenBSCPackages = map[string][]*model.BscRelayPackage{
+	// packages are encoded in the order of tx index
+	"bsc_oracle_packages": {
+		{ChannelId: 2, OracleSequence: 5, PackageSequence: 3, PayLoad: "ccddee", TxIndex: 4, TxTime: 1680000120},
+		{ChannelId: 1, OracleSequence: 5, PackageSequence: 10, PayLoad: "aabb", TxIndex: 1, TxTime: 1680000120},
+	},
+}
+
+// TestBlsClaimSignBytes pins the event hash of BSC -> Greenfield claims against the one pinned by the oracle module of
+// Greenfield
+func TestBlsClaimSignBytes(t *testing.T) {
+	claim := &oracletypes.BlsClaim{SrcChainId: 1, DestChainId: 2, Sequence: 1, Timestamp: 1000, Payload: []byte("test payload")}
+	require.Equal(t, "0a0b49ef40324d4c511d7a81e1edeeccaa10b768e55cece473b5cd99137f05f6",
+		hex.EncodeToString(bscEventHashes[config.EventHashVersionV1](claim)))
+}
+
+// acceptedClaims are claims accepted by the destination chain. Unlike the golden file they are not generated by the
+// relayer, so -update never rewrites them
+type acceptedClaims struct {
+	BscToGreenfield []*acceptedBSCClaim        `json:"bsc_to_greenfield"`
+	GreenfieldToBsc []*acceptedGreenfieldClaim `json:"greenfield_to_bsc"`
+}
+
+type acceptedBSCClaim struct {
+	Source      string `json:"source"`
+	SrcChainId  uint32 `json:"src_chain_id"`
+	DestChainId uint32 `json:"dest_chain_id"`
+	Sequence    uint64 `json:"sequence"`
+	Packages    []struct {
+		ChannelId       uint8  `json:"channel_id"`
+		PackageSequence uint64 `json:"package_sequence"`
+		TxTime          int64  `json:"tx_time"`
+		Payload         string `json:"payload"`
+	} `json:"packages"`
+	ClaimPayload string `json:"claim_payload"`
+	EventHash    string `json:"event_hash"`
+}
+
+type acceptedGreenfieldClaim struct {
+	Source              string   `json:"source"`
+	SrcChainId          uint32   `json:"src_chain_id"`
+	DestChainId         uint32   `json:"dest_chain_id"`
+	ChannelId           uint8    `json:"channel_id"`
+	Sequence            uint64   `json:"sequence"`
+	PackageType         uint32   `json:"package_type"`
+	TxTime              int64    `json:"tx_time"`
+	RelayerFee          string   `json:"relayer_fee"`
+	AckRelayerFee       string   `json:"ack_relayer_fee"`
+	Payload             string   `json:"payload"`
+	ClaimPayload        string   `json:"claim_payload"`
+	EventHash           string   `json:"event_hash"`
+	AggregatedSignature string   `json:"aggregated_signature"`
+	ValidatorBitSet     string   `json:"validator_bitset"`
+	ValidatorBlsKeys    []string `json:"validator_bls_keys"`
+}
+
+const acceptedClaimsFile = "accepted_claims.json"
+
+func loadAcceptedClaims(t *testing.T) *acceptedClaims {
+	bz, err := os.ReadFile(filepath.Join("testdata", acceptedClaimsFile))
+	require.NoError(t, err)
+	var claims acceptedClaims
+	require.NoError(t, json.Unmarshal(bz, &claims))
+	return &claims
+}
+
+// TestAcceptedBSCClaims checks the payload and event hash of claims accepted by the oracle module of Greenfield
+func TestAcceptedBSCClaims(t *testing.T) {
+	claims := loadAcceptedClaims(t).BscToGreenfield
+	require.NotEmpty(t, claims)
+	for _, c := range claims {
+		t.Run(c.Source, func(t *testing.T) {
+			pkgs := make([]*model.BscRelayPackage, 0, len(c.Packages))
+			for i, p := range c.Packages {
+				pkgs = append(pkgs, &model.BscRelayPackage{
+					ChannelId: p.ChannelId, OracleSequence: c.Sequence, PackageSequence: p.PackageSequence,
+					PayLoad: p.Payload, TxIndex: uint(i), TxTime: p.TxTime,
+				})
+			}
+			claim, err := bscClaimForPackages(c.SrcChainId, c.DestChainId, c.Sequence, pkgs)
+			require.NoError(t, err)
+			require.Equal(t, c.ClaimPayload, hex.EncodeToString(claim.Payload))
+			require.Equal(t, c.EventHash, hex.EncodeToString(bscEventHashes[config.EventHashVersionV1](claim)))
+		})
+	}
+}
+
+// TestAcceptedGreenfieldClaims checks the payload, event hash and aggregated signature of claims accepted by the
+// CrossChain contract of BSC
+func TestAcceptedGreenfieldClaims(t *testing.T) {
+	claims := loadAcceptedClaims(t).GreenfieldToBsc
+	if len(claims) == 0 {
+		t.Skipf("no Greenfield -> BSC claim accepted by the CrossChain contract is pinned in testdata/%s yet", acceptedClaimsFile)
+	}
+	for _, c := range claims {
+		t.Run(c.Source, func(t *testing.T) {
+			payload, err := aggregatePayloadForTx(&model.GreenfieldRelayTransaction{
+				SrcChainId: c.SrcChainId, DestChainId: c.DestChainId, ChannelId: c.ChannelId, Sequence: c.Sequence,
+				PackageType: c.PackageType, TxTime: c.TxTime, RelayerFee: c.RelayerFee, AckRelayerFee: c.AckRelayerFee,
+				PayLoad: c.Payload,
+			})
+			require.NoError(t, err)
+			require.Equal(t, c.ClaimPayload, hex.EncodeToString(payload))
+			eventHash, err := GreenfieldEventHash(config.EventHashVersionV1, payload)
+			require.NoError(t, err)
+			require.Equal(t, c.EventHash, hex.EncodeToString(eventHash))
+
+			valBitSet, ok := new(big.Int).SetString(c.ValidatorBitSet, 0)
+			require.True(t, ok, "invalid validator bitset %s", c.ValidatorBitSet)
+			blsKeys := make([][]byte, 0, len(c.ValidatorBlsKeys))
+			for _, key := range c.ValidatorBlsKeys {
+				blsKeys = append(blsKeys, decodeHex(t, key))
+			}
+			require.NoError(t, VerifyAggregatedSignature(eventHash, decodeHex(t, c.AggregatedSignature), valBitSet, blsKeys))
+		})
+	}
+}
+
+// TestClaimEncodingGolden checks claim payloads, event hashes and signatures against the golden file, run with -update
+// to regenerate it after an intended change of the encoding
+func TestClaimEncodingGolden(t *testing.T) {
+	got := make(map[string]*claimVector)
+	for name, tx := range goldenGreenfieldTxs {
+		payload, err := aggregatePayloadForTx(tx)
+		require.NoError(t, err)
+		eventHash, err := GreenfieldEventHash(config.EventHashVersionV1, payload)
+		require.NoError(t, err)
+		got[name] = signClaimVector(t, payload, eventHash)
+	}
+	for name, pkgs := range goldenBSCPackages {
+		claim, err := bscClaimForPackages(714, 1, pkgs[0].OracleSequence, pkgs)
+		require.NoError(t, err)
+		got[name] = signClaimVector(t, claim.Payload, bscEventHashes[config.EventHashVersionV1](claim))
+	}
+
+	path := filepath.Join("testdata", claimVectorsFile)
+	if *updateGolden {
+		bz, err := json.MarshalIndent(got, "", "  ")
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, append(bz, '\n'), 0o644))
+	}
+	bz, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var want map[string]*claimVector
+	require.NoError(t, json.Unmarshal(bz, &want))
+	require.Equal(t, want, got)
+}
+
+func signClaimVector(t *testing.T, payload, eventHash []byte) *claimVector {
+	var (
+		validators []types.Validator
+		votes      []*model.Vote
+		signatures []string
+	)
+	for i, key := range goldenValidatorKeys {
+		privKey, err := bls.SecretKeyFromBytes(decodeHex(t, key))
+		require.NoError(t, err)
+		pubKey := privKey.PublicKey().Marshal()
+		validators = append(validators, types.Validator{BlsPublicKey: pubKey})
+		if i == 1 {
+			continue
+		}
+		signature := hex.EncodeToString(privKey.Sign(eventHash).Marshal())
+		signatures = append(signatures, signature)
+		votes = append(votes, &model.Vote{PubKey: hex.EncodeToString(pubKey), Signature: signature})
+	}
+	aggregated, valBitSet, err := AggregateSignatureAndValidatorBitSet(votes, validators)
+	require.NoError(t, err)
+	return &claimVector{
+		ClaimPayload:        hex.EncodeToString(payload),
+		EventHash:           hex.EncodeToString(eventHash),
+		Signatures:          signatures,
+		AggregatedSignature: hex.EncodeToString(aggregated),
+		ValidatorBitSet:     fmt.Sprintf("0x%x", util.BitSetToBigInt(valBitSet)),
+	}
+}
+
+func decodeHex(t *testing.T, s string) []byte {
+	bz, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return bz
+}
@@ -0,0 +1,99 @@
+package vote
+
+import (
+	"fmt"
+
+	oracletypes "github.com/cosmos/cosmos-sdk/x/oracle/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tendermint/tendermint/votepool"
+
+	"github.com/bnb-chain/greenfield-relayer/config"
+	"github.com/bnb-chain/greenfield-relayer/db/dao"
+	"github.com/bnb-chain/greenfield-relayer/logging"
+)
+
+// greenfieldEventHashes computes the event hash of Greenfield -> BSC packages from the aggregated payload, by version
+var greenfieldEventHashes = map[uint32]func(aggregatedPayload []byte) []byte{
+	config.EventHashVersionV1: func(aggregatedPayload []byte) []byte {
+		return crypto.Keccak256Hash(aggregatedPayload).Bytes()
+	},
+}
+
+// bscEventHashes computes the event hash of BSC -> Greenfield packages from the claim, by version
+var bscEventHashes = map[uint32]func(claim *oracletypes.BlsClaim) []byte{
+	config.EventHashVersionV1: func(claim *oracletypes.BlsClaim) []byte {
+		hash := claim.GetSignBytes()
+		return hash[:]
+	},
+}
+
+// GreenfieldEventHash returns the event hash of the aggregated payload of Greenfield -> BSC packages of the version
+func GreenfieldEventHash(version uint32, aggregatedPayload []byte) ([]byte, error) {
+	hash, ok := greenfieldEventHashes[version]
+	if !ok {
+		return nil, fmt.Errorf("unknown event hash version %d", version)
+	}
+	return hash(aggregatedPayload), nil
+}
+
+// eventHashNegotiator picks the event hash version to sign an event with. The configured version is used unless more
+// votes of the event in the vote pool are signed with an accepted version, so that a new encoding can be rolled out by
+// configuring validators one by one, and the rest follow once it is used by the majority.
+type eventHashNegotiator struct {
+	versions   []uint32 // the configured version first, then accepted ones
+	countVotes func(eventHash []byte, eventType votepool.EventType) (int, error)
+}
+
+func newEventHashNegotiator(cfg *config.VotePoolConfig, countVotes func(eventHash []byte, eventType votepool.EventType) (int, error)) *eventHashNegotiator {
+	versions := []uint32{cfg.GetEventHashVersion()}
+	for _, v := range cfg.AcceptedEventHashVersions {
+		if v != versions[0] {
+			versions = append(versions, v)
+		}
+	}
+	return &eventHashNegotiator{versions: versions, countVotes: countVotes}
+}
+
+// hashes returns the event hashes of all configured and accepted versions, in the order of versions
+func (n *eventHashNegotiator) hashes(hash func(version uint32) []byte) [][]byte {
+	hashes := make([][]byte, 0, len(n.versions))
+	for _, v := range n.versions {
+		hashes = append(hashes, hash(v))
+	}
+	return hashes
+}
+
+// negotiate returns the event hash to sign among hashes of the versions, the vote pool is only queried when other
+// versions are accepted
+func (n *eventHashNegotiator) negotiate(hashes [][]byte, eventType votepool.EventType) ([]byte, error) {
+	if len(hashes) == 1 {
+		return hashes[0], nil
+	}
+	chosen, maxVotes := 0, -1
+	for i := range hashes {
+		cnt, err := n.countVotes(hashes[i], eventType)
+		if err != nil {
+			return nil, err
+		}
+		if cnt > maxVotes {
+			chosen, maxVotes = i, cnt
+		}
+	}
+	if chosen != 0 {
+		logging.Logger.Infof("follow event hash version %d used by %d votes in the vote pool instead of version %d",
+			n.versions[chosen], maxVotes, n.versions[0])
+	}
+	return hashes[chosen], nil
+}
+
+// loadOwnVote returns the vote of the event signed by this relayer before with any of the hashes, nil if the event
+// has not been signed
+func (n *eventHashNegotiator) loadOwnVote(voteDao *dao.VoteDao, eventType votepool.EventType, hashes [][]byte, pubKey []byte) (*votepool.Vote, error) {
+	for _, h := range hashes {
+		v, err := loadOwnVote(voteDao, eventType, h, pubKey)
+		if err != nil || v != nil {
+			return v, err
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,35 @@
+package vote
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/votepool"
+
+	"github.com/bnb-chain/greenfield-relayer/config"
+)
+
+func TestEventHashNegotiate(t *testing.T) {
+	votes := map[string]int{"v1": 1, "v2": 3, "v3": 3}
+	queried := 0
+	countVotes := func(eventHash []byte, eventType votepool.EventType) (int, error) {
+		queried++
+		return votes[string(eventHash)], nil
+	}
+
+	n := newEventHashNegotiator(&config.VotePoolConfig{}, countVotes)
+	hash, err := n.negotiate([][]byte{[]byte("v1")}, votepool.ToBscCrossChainEvent)
+	require.NoError(t, err)
+	require.Equal(t, []byte("v1"), hash)
+	require.Equal(t, 0, queried)
+
+	// the version with most votes is followed, the configured one on ties
+	n = newEventHashNegotiator(&config.VotePoolConfig{EventHashVersion: 1, AcceptedEventHashVersions: []uint32{1, 2}}, countVotes)
+	require.Equal(t, []uint32{1, 2}, n.versions)
+	hash, err = n.negotiate([][]byte{[]byte("v1"), []byte("v2"), []byte("v3")}, votepool.ToBscCrossChainEvent)
+	require.NoError(t, err)
+	require.Equal(t, []byte("v2"), hash)
+	hash, err = n.negotiate([][]byte{[]byte("v3"), []byte("v2")}, votepool.ToBscCrossChainEvent)
+	require.NoError(t, err)
+	require.Equal(t, []byte("v3"), hash)
+}
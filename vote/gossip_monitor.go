@@ -0,0 +1,118 @@
+package vote
+
+import (
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/tendermint/tendermint/votepool"
+
+	"github.com/bnb-chain/greenfield-relayer/common"
+	"github.com/bnb-chain/greenfield-relayer/config"
+	"github.com/bnb-chain/greenfield-relayer/db/dao"
+	"github.com/bnb-chain/greenfield-relayer/executor"
+	"github.com/bnb-chain/greenfield-relayer/logging"
+	"github.com/bnb-chain/greenfield-relayer/metric"
+)
+
+// GossipMonitor measures how long votes signed by this relayer take to become queryable in the vote pool of each
+// Greenfield node. A vote is broadcast to one node and gossiped to the others, so nodes on which the latest own vote is
+// still missing after VoteGossipTimeout are reported unhealthy, as their vote gossip is likely broken.
+type GossipMonitor struct {
+	config             *config.Config
+	daoManager         *dao.DaoManager
+	greenfieldExecutor *executor.GreenfieldExecutor
+	metricService      *metric.MetricService
+	lastVoteId         int64
+	tracked            *trackedVote
+}
+
+// trackedVote is an own vote being looked for on the nodes
+type trackedVote struct {
+	vote     *votepool.Vote
+	signedAt time.Time
+	found    map[string]bool
+}
+
+func NewGossipMonitor(cfg *config.Config, dao *dao.DaoManager, greenfieldExecutor *executor.GreenfieldExecutor, ms *metric.MetricService) *GossipMonitor {
+	return &GossipMonitor{
+		config:             cfg,
+		daoManager:         dao,
+		greenfieldExecutor: greenfieldExecutor,
+		metricService:      ms,
+	}
+}
+
+func (m *GossipMonitor) StartLoop() {
+	common.Schedule(common.TaskVoteGossipMonitor, common.VoteGossipProbeInterval, func() {
+		if err := m.probe(time.Now()); err != nil {
+			logging.Logger.Errorf("failed to probe vote gossip, err=%s", err.Error())
+		}
+	}).Run()
+}
+
+func (m *GossipMonitor) probe(now time.Time) error {
+	if m.tracked == nil {
+		ownVote, err := m.daoManager.VoteDao.GetLatestOwnVote(hex.EncodeToString(m.greenfieldExecutor.BlsPubKey))
+		if err != nil {
+			return err
+		}
+		if ownVote == nil || ownVote.Id == m.lastVoteId {
+			return nil
+		}
+		m.lastVoteId = ownVote.Id
+		signedAt := time.Unix(ownVote.CreatedTime, 0)
+		// votes signed long ago, e.g. before a restart, tell nothing about the gossip now
+		if now.Sub(signedAt) >= common.VoteGossipTimeout {
+			return nil
+		}
+		v, err := fromOwnVote(ownVote)
+		if err != nil {
+			return err
+		}
+		m.tracked = &trackedVote{vote: v, signedAt: signedAt, found: make(map[string]bool)}
+	}
+
+	found, missing, done := m.tracked.update(m.greenfieldExecutor.ProbeVoteGossip(m.tracked.vote), now)
+	for node, latency := range found {
+		m.metricService.ObserveVoteGossipLatency(node, latency)
+		m.metricService.SetVoteGossipHealthy(node, true)
+	}
+	for _, node := range missing {
+		m.metricService.SetVoteGossipHealthy(node, false)
+	}
+	if len(missing) > 0 {
+		logging.Logger.Errorf("own vote is not queryable on Greenfield nodes %s %s after signed, vote gossip of the nodes may be broken",
+			strings.Join(missing, ", "), common.VoteGossipTimeout)
+	}
+	if done {
+		m.tracked = nil
+	}
+	return nil
+}
+
+// update returns the latencies in second of nodes the vote is newly found on, the nodes the vote is still missing on
+// once the timeout passes, and whether tracking the vote is done
+func (t *trackedVote) update(probes []*executor.VoteGossipProbe, now time.Time) (map[string]float64, []string, bool) {
+	found := make(map[string]float64)
+	for _, p := range probes {
+		if p.Err != nil || !p.Found || t.found[p.Provider] {
+			continue
+		}
+		t.found[p.Provider] = true
+		found[p.Provider] = now.Sub(t.signedAt).Seconds()
+	}
+	if len(t.found) == len(probes) {
+		return found, nil, true
+	}
+	if now.Sub(t.signedAt) < common.VoteGossipTimeout {
+		return found, nil, false
+	}
+	missing := make([]string, 0)
+	for _, p := range probes {
+		if !t.found[p.Provider] {
+			missing = append(missing, p.Provider)
+		}
+	}
+	return found, missing, true
+}
@@ -0,0 +1,44 @@
+package vote
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-relayer/executor"
+)
+
+func TestTrackedVoteUpdate(t *testing.T) {
+	signedAt := time.Unix(1700000000, 0)
+	tracked := &trackedVote{signedAt: signedAt, found: make(map[string]bool)}
+
+	found, missing, done := tracked.update([]*executor.VoteGossipProbe{
+		{Provider: "a", Found: true},
+		{Provider: "b"},
+		{Provider: "c", Err: errors.New("timeout")},
+	}, signedAt.Add(2*time.Second))
+	require.Equal(t, map[string]float64{"a": 2}, found)
+	require.Empty(t, missing)
+	require.False(t, done)
+
+	// found on a node once is not observed again
+	found, missing, done = tracked.update([]*executor.VoteGossipProbe{
+		{Provider: "a", Found: true},
+		{Provider: "b", Found: true},
+		{Provider: "c", Err: errors.New("timeout")},
+	}, signedAt.Add(5*time.Second))
+	require.Equal(t, map[string]float64{"b": 5}, found)
+	require.Empty(t, missing)
+	require.False(t, done)
+
+	found, missing, done = tracked.update([]*executor.VoteGossipProbe{
+		{Provider: "a", Found: true},
+		{Provider: "b", Found: true},
+		{Provider: "c"},
+	}, signedAt.Add(31*time.Second))
+	require.Empty(t, found)
+	require.Equal(t, []string{"c"}, missing)
+	require.True(t, done)
+}
@@ -13,9 +13,7 @@ import (
 	"github.com/avast/retry-go/v4"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/tendermint/tendermint/votepool"
-	"gorm.io/gorm"
 
 	rcommon "github.com/bnb-chain/greenfield-relayer/common"
 	"github.com/bnb-chain/greenfield-relayer/config"
@@ -23,7 +21,9 @@ import (
 	"github.com/bnb-chain/greenfield-relayer/db/dao"
 	"github.com/bnb-chain/greenfield-relayer/db/model"
 	"github.com/bnb-chain/greenfield-relayer/executor"
+	"github.com/bnb-chain/greenfield-relayer/hook"
 	"github.com/bnb-chain/greenfield-relayer/logging"
+	"github.com/bnb-chain/greenfield-relayer/metric"
 	"github.com/bnb-chain/greenfield-relayer/types"
 	"github.com/bnb-chain/greenfield-relayer/util"
 )
@@ -34,30 +34,46 @@ type GreenfieldVoteProcessor struct {
 	signer             *VoteSigner
 	greenfieldExecutor *executor.GreenfieldExecutor
 	blsPublicKey       []byte
+	verifier           GreenfieldTxVerifier
+	hashNegotiator     *eventHashNegotiator
+	metricService      *metric.MetricService
 }
 
 func NewGreenfieldVoteProcessor(cfg *config.Config, dao *dao.DaoManager, signer *VoteSigner,
-	greenfieldExecutor *executor.GreenfieldExecutor) *GreenfieldVoteProcessor {
+	greenfieldExecutor *executor.GreenfieldExecutor, verifier GreenfieldTxVerifier, ms *metric.MetricService) *GreenfieldVoteProcessor {
 	return &GreenfieldVoteProcessor{
 		config:             cfg,
 		daoManager:         dao,
 		signer:             signer,
 		greenfieldExecutor: greenfieldExecutor,
 		blsPublicKey:       greenfieldExecutor.BlsPubKey,
+		verifier:           verifier,
+		hashNegotiator:     newEventHashNegotiator(&cfg.VotePoolConfig, greenfieldExecutor.CountVotesByEventHashAndType),
+		metricService:      ms,
 	}
 }
 
 // SignAndBroadcastLoop signs tx using the relayer's bls private key, then broadcasts the vote to Greenfield votepool
 func (p *GreenfieldVoteProcessor) SignAndBroadcastLoop() {
-	ticker := time.NewTicker(time.Duration(p.config.VotePoolConfig.BroadcastIntervalInMillisecond) * time.Millisecond)
-	for range ticker.C {
+	interval := time.Duration(p.config.VotePoolConfig.BroadcastIntervalInMillisecond) * time.Millisecond
+	rcommon.Schedule(rcommon.TaskGreenfieldVoteBroadcast, interval, func() {
 		if err := p.signAndBroadcast(); err != nil {
 			logging.Logger.Errorf("encounter error, err: %s", err.Error())
 		}
-	}
+	}).Run()
 }
 
-func (p *GreenfieldVoteProcessor) signAndBroadcast() error {
+func (p *GreenfieldVoteProcessor) signAndBroadcast() (err error) {
+	// votes broadcast before an error are still persisted
+	batch := &selfVotedBatch{}
+	defer func() {
+		flushErr := batch.flush(p.daoManager, func(txManager *dao.DaoManager, ids []int64) error {
+			return txManager.GreenfieldDao.UpdateBatchTransactionStatus(ids, db.SelfVoted)
+		})
+		if flushErr != nil && err == nil {
+			err = flushErr
+		}
+	}()
 	latestHeight, err := p.greenfieldExecutor.GetLatestBlockHeight()
 	if err != nil {
 		logging.Logger.Errorf("failed to get latest block height, error: %s", err.Error())
@@ -72,7 +88,9 @@ func (p *GreenfieldVoteProcessor) signAndBroadcast() error {
 	if leastSavedTxHeight+p.config.GreenfieldConfig.NumberOfBlocksForFinality > latestHeight {
 		return nil
 	}
-	txs, err := p.daoManager.GreenfieldDao.GetTransactionsByStatusWithLimit(db.Saved, p.config.VotePoolConfig.VotesBatchMaxSizePerInterval)
+	// packages of channels disabled by governance are not voted, their claims would be rejected
+	txs, err := p.daoManager.GreenfieldDao.GetTransactionsByStatusWithPriority(db.Saved, p.config.GreenfieldConfig.PriorityChannelList,
+		rcommon.DisabledChannels(), p.config.VotePoolConfig.VotesBatchMaxSizePerInterval)
 	if err != nil {
 		logging.Logger.Errorf("failed to get transactions from db, error: %s", err.Error())
 		return err
@@ -80,6 +98,11 @@ func (p *GreenfieldVoteProcessor) signAndBroadcast() error {
 	if len(txs) == 0 {
 		return nil
 	}
+	// never trust DB rows only, txs are voted after verified against Greenfield
+	if err = p.verifier.VerifyTransactions(txs); err != nil {
+		logging.Logger.Errorf("failed to verify transactions against Greenfield, error: %s", err.Error())
+		return err
+	}
 	// for every tx, we are going to sign it and broadcast vote of it.
 	for _, tx := range txs {
 
@@ -93,74 +116,91 @@ func (p *GreenfieldVoteProcessor) signAndBroadcast() error {
 			if err = p.daoManager.GreenfieldDao.UpdateTransactionStatus(tx.Id, db.Delivered); err != nil {
 				return err
 			}
-			logging.Logger.Infof("sequence %d for channel %d has already been filled ", tx.Sequence, tx.ChannelId)
+			hook.Notify(hook.GreenfieldTxEvent(hook.PointAfterDelivery, tx))
+			logging.Logger.Infof("sequence %d for channel %d has already been filled, cid=%s", tx.Sequence, tx.ChannelId, rcommon.CorrelationId(metric.DirectionGnfdToBSC, tx.ChannelId, tx.Sequence))
+			continue
+		}
+		if err = hook.Run(hook.GreenfieldTxEvent(hook.PointBeforeVote, tx)); err != nil {
+			logging.Logger.Infof("skip voting for sequence %d of channel %d, err=%s", tx.Sequence, tx.ChannelId, err.Error())
 			continue
 		}
 
-		aggregatedPayload, err := p.aggregatePayloadForTx(tx)
+		aggregatedPayload, err := aggregatePayloadForTx(tx)
 		if err != nil {
 			return err
 		}
-		v := p.constructVoteAndSign(aggregatedPayload)
-
-		// broadcast v
-		if err = retry.Do(func() error {
-			logging.Logger.Debugf("broadcasting vote with c %d and seq %d", tx.ChannelId, tx.Sequence)
-
-			err = p.greenfieldExecutor.BroadcastVote(v)
-			if err != nil {
-				return fmt.Errorf("failed to submit vote for event with channel id %d and sequence %d, err=%s", tx.ChannelId, tx.Sequence, err.Error())
-			}
-			return nil
-		}, retry.Context(context.Background()), rcommon.RtyAttem, rcommon.RtyDelay, rcommon.RtyErr); err != nil {
+		// the event might have been signed and broadcast before a restart, the vote is neither re-signed nor re-broadcast,
+		// it is re-broadcast when collecting votes if missing in the vote pool
+		eventHashes := p.getEventHashes(aggregatedPayload)
+		v, err := p.hashNegotiator.loadOwnVote(p.daoManager.VoteDao, votepool.ToBscCrossChainEvent, eventHashes, p.blsPublicKey)
+		if err != nil {
 			return err
 		}
-
-		// After vote submitted to vote pool, persist vote Data and update the status of tx to 'SELF_VOTED'.
-		err = p.daoManager.GreenfieldDao.DB.Transaction(func(dbTx *gorm.DB) error {
-			if e := dao.UpdateTransactionStatus(dbTx, tx.Id, db.SelfVoted); e != nil {
-				return e
-			}
-			exist, e := dao.IsVoteExist(dbTx, tx.ChannelId, tx.Sequence, hex.EncodeToString(v.PubKey[:]))
-			if e != nil {
-				return e
+		signed := v != nil
+		if !signed {
+			eventHash, err := p.hashNegotiator.negotiate(eventHashes, votepool.ToBscCrossChainEvent)
+			if err != nil {
+				return err
 			}
-			if !exist {
-				if e = dao.SaveVote(dbTx, EntityToDto(v, tx.ChannelId, tx.Sequence, aggregatedPayload)); e != nil {
-					return e
+			v = p.constructVoteAndSign(eventHash)
+
+			// broadcast v
+			if err = retry.Do(func() error {
+				logging.Logger.Debugf("broadcasting vote with c %d and seq %d, cid=%s", tx.ChannelId, tx.Sequence, rcommon.CorrelationId(metric.DirectionGnfdToBSC, tx.ChannelId, tx.Sequence))
+
+				err = p.greenfieldExecutor.BroadcastVote(v)
+				if err != nil {
+					return fmt.Errorf("failed to submit vote for event with channel id %d and sequence %d, err=%s", tx.ChannelId, tx.Sequence, err.Error())
 				}
+				return nil
+			}, retry.Context(context.Background()), rcommon.RtyAttem, rcommon.RtyDelay, rcommon.RtyErr,
+				rcommon.RtyBudget(rcommon.SubsystemGreenfieldVoteProcessor)); err != nil {
+				return err
 			}
-			return nil
-		})
-		if err != nil {
-			return err
 		}
+
+		// After vote submitted to vote pool, persist vote Data and update the status of tx to 'SELF_VOTED' at the end of the tick.
+		var ownVote *model.OwnVote
+		if !signed {
+			ownVote = toOwnVote(v)
+		}
+		batch.add([]int64{tx.Id}, ownVote, EntityToDto(v, tx.ChannelId, tx.Sequence, aggregatedPayload))
 	}
 	return nil
 }
 
 func (p *GreenfieldVoteProcessor) CollectVotesLoop() {
-	ticker := time.NewTicker(time.Duration(p.config.VotePoolConfig.QueryIntervalInMillisecond) * time.Millisecond)
-	for range ticker.C {
+	interval := time.Duration(p.config.VotePoolConfig.QueryIntervalInMillisecond) * time.Millisecond
+	rcommon.Schedule(rcommon.TaskGreenfieldVoteCollect, interval, func() {
 		if err := p.collectVotes(); err != nil {
 			logging.Logger.Errorf("encounter error, err: %s", err.Error())
 		}
-	}
+	}).Run()
 }
 
-func (p *GreenfieldVoteProcessor) collectVotes() error {
+func (p *GreenfieldVoteProcessor) collectVotes() (err error) {
 	txs, err := p.daoManager.GreenfieldDao.GetTransactionsByStatusWithLimit(db.SelfVoted, p.config.VotePoolConfig.VotesBatchMaxSizePerInterval)
 	if err != nil {
 		logging.Logger.Errorf("failed to get voted transactions from db, error: %s", err.Error())
 		return err
 	}
+	// transactions with enough votes are updated to 'ALL_VOTED' together
+	allVoted := &idBatch{}
+	defer func() {
+		flushErr := allVoted.flush(func(ids []int64) error {
+			return p.daoManager.GreenfieldDao.UpdateBatchTransactionStatus(ids, db.AllVoted)
+		})
+		if flushErr != nil && err == nil {
+			err = flushErr
+		}
+	}()
 	wg := new(sync.WaitGroup)
 	errCh := make(chan error)
 	waitCh := make(chan struct{})
 	go func() {
 		for _, tx := range txs {
 			wg.Add(1)
-			go p.collectVoteForTx(tx, errCh, wg)
+			go p.collectVoteForTx(tx, allVoted, errCh, wg)
 		}
 		wg.Wait()
 		close(waitCh)
@@ -175,7 +215,7 @@ func (p *GreenfieldVoteProcessor) collectVotes() error {
 	}
 }
 
-func (p *GreenfieldVoteProcessor) collectVoteForTx(tx *model.GreenfieldRelayTransaction, errChan chan error, wg *sync.WaitGroup) {
+func (p *GreenfieldVoteProcessor) collectVoteForTx(tx *model.GreenfieldRelayTransaction, allVoted *idBatch, errChan chan error, wg *sync.WaitGroup) {
 	defer wg.Done()
 	isFilled, err := p.isTxSequenceFilled(tx)
 	if err != nil {
@@ -187,18 +227,31 @@ func (p *GreenfieldVoteProcessor) collectVoteForTx(tx *model.GreenfieldRelayTran
 			errChan <- err
 			return
 		}
-		logging.Logger.Infof("sequence %d for channel %d has already been filled ", tx.Sequence, tx.ChannelId)
+		hook.Notify(hook.GreenfieldTxEvent(hook.PointAfterDelivery, tx))
+		logging.Logger.Infof("sequence %d for channel %d has already been filled, cid=%s", tx.Sequence, tx.ChannelId, rcommon.CorrelationId(metric.DirectionGnfdToBSC, tx.ChannelId, tx.Sequence))
 		return
 	}
 
 	if err = p.prepareEnoughValidVotesForTx(tx); err != nil {
-		errChan <- err
-		return
-	}
-	if err = p.daoManager.GreenfieldDao.UpdateTransactionStatus(tx.Id, db.AllVoted); err != nil {
-		errChan <- err
+		if errors.Is(err, rcommon.ErrRecordNotFound) {
+			err = p.resignTx(tx)
+		}
+		if err != nil {
+			errChan <- err
+		}
 		return
 	}
+	recordVoteCollection(p.metricService, metric.DirectionGnfdToBSC, tx.ChannelId, tx.Sequence, tx.TxTime)
+	allVoted.add(tx.Id)
+}
+
+// resignTx sends the tx back to be signed if its own vote is missing, e.g. dropped as stale while the BLS key of the
+// relayer was not registered. The vote is restored from the own votes or signed again, and re-broadcast if missing in
+// the vote pool, instead of the tx staying self voted forever.
+func (p *GreenfieldVoteProcessor) resignTx(tx *model.GreenfieldRelayTransaction) error {
+	logging.Logger.Infof("own vote for channel %d and sequence %d is missing, it is signed again, cid=%s",
+		tx.ChannelId, tx.Sequence, rcommon.CorrelationId(metric.DirectionGnfdToBSC, tx.ChannelId, tx.Sequence))
+	return p.daoManager.GreenfieldDao.UpdateTransactionStatus(tx.Id, db.Saved)
 }
 
 // prepareEnoughValidVotesForTx fetches and validate votes result, store in vote table
@@ -221,14 +274,14 @@ func (p *GreenfieldVoteProcessor) prepareEnoughValidVotesForTx(tx *model.Greenfi
 		return nil
 	}
 
-	if err = p.queryMoreThanTwoThirdVotesForTx(localVote, validators); err != nil {
+	if err = p.queryMoreThanTwoThirdVotesForTx(localVote, validators, tx.TxTime); err != nil {
 		return err
 	}
 	return nil
 }
 
-// queryMoreThanTwoThirdVotesForTx queries votes from votePool
-func (p *GreenfieldVoteProcessor) queryMoreThanTwoThirdVotesForTx(localVote *model.Vote, validators []types.Validator) error {
+// queryMoreThanTwoThirdVotesForTx queries votes from votePool, txTime is the time the tx is sent on Greenfield
+func (p *GreenfieldVoteProcessor) queryMoreThanTwoThirdVotesForTx(localVote *model.Vote, validators []types.Validator, txTime int64) error {
 	triedTimes := 0
 	validVotesTotalCount := 1 // assume local vote is valid
 	channelId := localVote.ChannelId
@@ -238,10 +291,10 @@ func (p *GreenfieldVoteProcessor) queryMoreThanTwoThirdVotesForTx(localVote *mod
 	for range ticker.C {
 		triedTimes++
 		if triedTimes > QueryVotepoolMaxRetryTimes {
-			return errors.New("exceed max retry")
+			return fmt.Errorf("%w, exceed max retry for channel %d and sequence %d", rcommon.ErrNotEnoughVotes, channelId, seq)
 		}
 
-		logging.Logger.Debugf("query vote for c %d and s %d", channelId, seq)
+		logging.Logger.Debugf("query vote for c %d and s %d, cid=%s", channelId, seq, rcommon.CorrelationId(metric.DirectionGnfdToBSC, channelId, seq))
 		queriedVotes, err := p.greenfieldExecutor.QueryVotesByEventHashAndType(localVote.EventHash, votepool.ToBscCrossChainEvent)
 		if err != nil {
 			return err
@@ -255,6 +308,13 @@ func (p *GreenfieldVoteProcessor) queryMoreThanTwoThirdVotesForTx(localVote *mod
 		}
 		isLocalVoteIncluded := false
 
+		// votes of the request are saved in one batch, the vote might have been saved in previous request.
+		pubKeys, err := p.daoManager.VoteDao.GetVotePubKeysByChannelIdAndSequence(channelId, seq)
+		if err != nil {
+			return err
+		}
+		savedPubKeys := toSet(pubKeys)
+		newVotes := make([]*model.Vote, 0)
 		for _, v := range queriedVotes {
 
 			if !p.isVotePubKeyValid(v, validators) {
@@ -274,19 +334,23 @@ func (p *GreenfieldVoteProcessor) queryMoreThanTwoThirdVotesForTx(localVote *mod
 				continue
 			}
 
-			// check duplicate, the vote might have been saved in previous request.
-			exist, err := p.daoManager.VoteDao.IsVoteExist(channelId, seq, hex.EncodeToString(v.PubKey[:]))
-			if err != nil {
-				return err
-			}
-			if exist {
+			// check duplicate
+			pubKey := hex.EncodeToString(v.PubKey[:])
+			if savedPubKeys[pubKey] {
 				validVotesCountPerReq--
 				continue
 			}
+			savedPubKeys[pubKey] = true
 			// a vote result persisted into DB should be valid, unique.
-			if err = p.daoManager.VoteDao.SaveVote(EntityToDto(v, channelId, seq, localVote.ClaimPayload)); err != nil {
+			newVotes = append(newVotes, EntityToDto(v, channelId, seq, localVote.ClaimPayload))
+		}
+		if len(newVotes) != 0 {
+			if err = p.daoManager.VoteDao.SaveBatchVotes(newVotes); err != nil {
 				return err
 			}
+			if err = recordVoteLatencies(p.daoManager.VoteDao, p.metricService, metric.DirectionGnfdToBSC, txTime, newVotes); err != nil {
+				logging.Logger.Errorf("failed to record vote latencies, err=%s", err.Error())
+			}
 		}
 
 		validVotesTotalCount += validVotesCountPerReq
@@ -305,16 +369,19 @@ func (p *GreenfieldVoteProcessor) queryMoreThanTwoThirdVotesForTx(localVote *mod
 	return nil
 }
 
-func (p *GreenfieldVoteProcessor) constructVoteAndSign(aggregatedPayload []byte) *votepool.Vote {
+func (p *GreenfieldVoteProcessor) constructVoteAndSign(eventHash []byte) *votepool.Vote {
 	var v votepool.Vote
 	v.EventType = votepool.ToBscCrossChainEvent
-	v.EventHash = p.getEventHash(aggregatedPayload)
+	v.EventHash = eventHash
 	p.signer.SignVote(&v)
 	return &v
 }
 
-func (p *GreenfieldVoteProcessor) getEventHash(aggregatedPayload []byte) []byte {
-	return crypto.Keccak256Hash(aggregatedPayload).Bytes()
+// getEventHashes returns the event hashes of the aggregated payload, of all versions the relayer signs with or accepts
+func (p *GreenfieldVoteProcessor) getEventHashes(aggregatedPayload []byte) [][]byte {
+	return p.hashNegotiator.hashes(func(version uint32) []byte {
+		return greenfieldEventHashes[version](aggregatedPayload)
+	})
 }
 
 func (p *GreenfieldVoteProcessor) isVotePubKeyValid(v *votepool.Vote, validators []types.Validator) bool {
@@ -328,7 +395,7 @@ func (p *GreenfieldVoteProcessor) isVotePubKeyValid(v *votepool.Vote, validators
 
 // aggregatePayloadForTx aggregate required fields by concatenating their bytes, this will be used as payload when
 // calling BSC smart contract, and also used to generate eventHash for broadcasting vote
-func (p *GreenfieldVoteProcessor) aggregatePayloadForTx(tx *model.GreenfieldRelayTransaction) ([]byte, error) {
+func aggregatePayloadForTx(tx *model.GreenfieldRelayTransaction) ([]byte, error) {
 	var aggregatedPayload []byte
 
 	aggregatedPayload = append(aggregatedPayload, util.Uint16ToBytes(uint16(tx.SrcChainId))...)
@@ -339,7 +406,7 @@ func (p *GreenfieldVoteProcessor) aggregatePayloadForTx(tx *model.GreenfieldRela
 	aggregatedPayload = append(aggregatedPayload, util.Uint64ToBytes(uint64(tx.TxTime))...)
 
 	// relayerfee big.Int
-	relayerFeeBts, err := p.txFeeToBytes(tx.RelayerFee)
+	relayerFeeBts, err := txFeeToBytes(tx.RelayerFee)
 	if err != nil {
 		logging.Logger.Errorf("failed to convert tx relayerFee %s from string to big.Int", tx.AckRelayerFee)
 		return nil, err
@@ -347,7 +414,7 @@ func (p *GreenfieldVoteProcessor) aggregatePayloadForTx(tx *model.GreenfieldRela
 	aggregatedPayload = append(aggregatedPayload, relayerFeeBts...)
 
 	if tx.PackageType == uint32(sdk.SynCrossChainPackageType) {
-		ackRelayerFeeBts, err := p.txFeeToBytes(tx.AckRelayerFee)
+		ackRelayerFeeBts, err := txFeeToBytes(tx.AckRelayerFee)
 		if err != nil {
 			logging.Logger.Errorf("failed to convert tx ackRelayerFee %s from string to big.Int", tx.AckRelayerFee)
 			return nil, err
@@ -358,7 +425,7 @@ func (p *GreenfieldVoteProcessor) aggregatePayloadForTx(tx *model.GreenfieldRela
 	return aggregatedPayload, nil
 }
 
-func (p *GreenfieldVoteProcessor) txFeeToBytes(txFee string) ([]byte, error) {
+func txFeeToBytes(txFee string) ([]byte, error) {
 	fee, ok := new(big.Int).SetString(txFee, 10)
 	if !ok {
 		return nil, errors.New("failed to convert tx fee")
@@ -377,7 +444,8 @@ func (p *GreenfieldVoteProcessor) isTxSequenceFilled(tx *model.GreenfieldRelayTr
 }
 
 func (p *GreenfieldVoteProcessor) reBroadcastVote(localVote *model.Vote) error {
-	logging.Logger.Debugf("broadcasting vote with c %d and seq %d", localVote.ChannelId, localVote.Sequence)
+	logging.Logger.Debugf("broadcasting vote with c %d and seq %d, cid=%s", localVote.ChannelId, localVote.Sequence,
+		rcommon.CorrelationId(metric.DirectionGnfdToBSC, localVote.ChannelId, localVote.Sequence))
 
 	v, err := DtoToEntity(localVote)
 	if err != nil {
@@ -0,0 +1,83 @@
+package vote
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bnb-chain/greenfield-relayer/common"
+	"github.com/bnb-chain/greenfield-relayer/config"
+	"github.com/bnb-chain/greenfield-relayer/db"
+	"github.com/bnb-chain/greenfield-relayer/db/dao"
+	"github.com/bnb-chain/greenfield-relayer/logging"
+	"github.com/bnb-chain/greenfield-relayer/metric"
+)
+
+// LagMonitor measures how far the vote processors are behind the listeners by the packages saved but not voted yet,
+// and alerts when the oldest of them is older than the threshold, since voting lag delays every downstream stage.
+type LagMonitor struct {
+	config        *config.Config
+	daoManager    *dao.DaoManager
+	metricService *metric.MetricService
+	threshold     time.Duration
+	lastAlertedAt map[string]time.Time
+}
+
+func NewLagMonitor(cfg *config.Config, dao *dao.DaoManager, ms *metric.MetricService) *LagMonitor {
+	threshold := common.DefaultVoteLagThreshold
+	if cfg.AlertConfig.VoteLagThreshold > 0 {
+		threshold = time.Duration(cfg.AlertConfig.VoteLagThreshold) * time.Second
+	}
+	return &LagMonitor{
+		config:        cfg,
+		daoManager:    dao,
+		metricService: ms,
+		threshold:     threshold,
+		lastAlertedAt: make(map[string]time.Time),
+	}
+}
+
+func (m *LagMonitor) StartLoop() {
+	common.Schedule(common.TaskVoteLagMonitor, common.VoteLagCheckInterval, func() {
+		if err := m.check(); err != nil {
+			logging.Logger.Errorf("failed to check vote lag, err=%s", err.Error())
+		}
+	}).Run()
+}
+
+func (m *LagMonitor) check() error {
+	if m.config.RelayConfig.BSCToGreenfieldEnabled() {
+		bscStat, err := m.daoManager.BSCDao.GetStatByStatus(db.Saved)
+		if err != nil {
+			return err
+		}
+		m.observe(metric.DirectionBSCToGnfd, bscStat, time.Now())
+	}
+
+	if m.config.RelayConfig.GreenfieldToBSCEnabled() {
+		gnfdStat, err := m.daoManager.GreenfieldDao.GetStatByStatus(db.Saved)
+		if err != nil {
+			return err
+		}
+		m.observe(metric.DirectionGnfdToBSC, gnfdStat, time.Now())
+	}
+	return nil
+}
+
+// observe updates the metrics of the direction, the alert is sent at most once per threshold for each direction
+func (m *LagMonitor) observe(direction string, stat *dao.StatusStat, now time.Time) {
+	var oldestAge time.Duration
+	if stat.Count > 0 && stat.OldestTxTime > 0 {
+		oldestAge = now.Sub(time.Unix(stat.OldestTxTime, 0))
+	}
+	m.metricService.SetVoteLag(direction, stat.Count, int64(oldestAge.Seconds()))
+
+	if oldestAge <= m.threshold || now.Sub(m.lastAlertedAt[direction]) < m.threshold {
+		return
+	}
+	m.lastAlertedAt[direction] = now
+	msg := fmt.Sprintf("vote processing lags behind, direction=%s, %d packages not voted, the oldest is %s old",
+		direction, stat.Count, oldestAge.Truncate(time.Second))
+	logging.Logger.Error(msg)
+	config.SendTelegramMessage(m.config.AlertConfig.Identity, m.config.AlertConfig.TelegramBotId,
+		m.config.AlertConfig.TelegramChatId, msg)
+}
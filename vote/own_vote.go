@@ -0,0 +1,51 @@
+package vote
+
+import (
+	"encoding/hex"
+	"time"
+
+	"github.com/tendermint/tendermint/votepool"
+
+	"github.com/bnb-chain/greenfield-relayer/db/dao"
+	"github.com/bnb-chain/greenfield-relayer/db/model"
+)
+
+// loadOwnVote returns the vote of the event signed by this relayer before, nil if the event has not been signed
+func loadOwnVote(voteDao *dao.VoteDao, eventType votepool.EventType, eventHash []byte, pubKey []byte) (*votepool.Vote, error) {
+	ownVote, err := voteDao.GetOwnVote(uint32(eventType), hex.EncodeToString(eventHash), hex.EncodeToString(pubKey))
+	if err != nil || ownVote == nil {
+		return nil, err
+	}
+	return fromOwnVote(ownVote)
+}
+
+func fromOwnVote(ownVote *model.OwnVote) (*votepool.Vote, error) {
+	eventHash, err := hex.DecodeString(ownVote.EventHash)
+	if err != nil {
+		return nil, err
+	}
+	pubKey, err := hex.DecodeString(ownVote.PubKey)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := hex.DecodeString(ownVote.Signature)
+	if err != nil {
+		return nil, err
+	}
+	return &votepool.Vote{
+		EventType: votepool.EventType(ownVote.EventType),
+		EventHash: eventHash,
+		PubKey:    pubKey,
+		Signature: signature,
+	}, nil
+}
+
+func toOwnVote(v *votepool.Vote) *model.OwnVote {
+	return &model.OwnVote{
+		EventType:   uint32(v.EventType),
+		EventHash:   hex.EncodeToString(v.EventHash),
+		PubKey:      hex.EncodeToString(v.PubKey),
+		Signature:   hex.EncodeToString(v.Signature),
+		CreatedTime: time.Now().Unix(),
+	}
+}
@@ -0,0 +1,17 @@
+package vote
+
+import (
+	"github.com/bnb-chain/greenfield-relayer/db/model"
+)
+
+// BSCPackageVerifier re-verifies packages saved in DB against BSC before they are voted, implemented by
+// listener.BSCListener
+type BSCPackageVerifier interface {
+	VerifyPackages(height uint64, pkgs []*model.BscRelayPackage) error
+}
+
+// GreenfieldTxVerifier re-verifies txs saved in DB against Greenfield before they are voted, implemented by
+// listener.GreenfieldListener
+type GreenfieldTxVerifier interface {
+	VerifyTransactions(txs []*model.GreenfieldRelayTransaction) error
+}
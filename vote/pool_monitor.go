@@ -0,0 +1,101 @@
+package vote
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tendermint/tendermint/votepool"
+
+	"github.com/bnb-chain/greenfield-relayer/common"
+	"github.com/bnb-chain/greenfield-relayer/config"
+	"github.com/bnb-chain/greenfield-relayer/db/dao"
+	"github.com/bnb-chain/greenfield-relayer/executor"
+	"github.com/bnb-chain/greenfield-relayer/logging"
+	"github.com/bnb-chain/greenfield-relayer/metric"
+)
+
+const (
+	votePoolMethodQuery     = "query"
+	votePoolMethodBroadcast = "broadcast"
+)
+
+// PoolMonitor probes the vote pool RPC methods of all Greenfield nodes, and alerts when no node answers queries or
+// accepts broadcasts, since broadcast failures otherwise only show up as generic errors of the vote processors.
+// Broadcasts are probed by re-broadcasting the latest vote signed by this relayer.
+type PoolMonitor struct {
+	config             *config.Config
+	daoManager         *dao.DaoManager
+	greenfieldExecutor *executor.GreenfieldExecutor
+	metricService      *metric.MetricService
+	lastAlertedAt      time.Time
+}
+
+func NewPoolMonitor(cfg *config.Config, dao *dao.DaoManager, greenfieldExecutor *executor.GreenfieldExecutor, ms *metric.MetricService) *PoolMonitor {
+	return &PoolMonitor{
+		config:             cfg,
+		daoManager:         dao,
+		greenfieldExecutor: greenfieldExecutor,
+		metricService:      ms,
+	}
+}
+
+func (m *PoolMonitor) StartLoop() {
+	common.Schedule(common.TaskVotePoolMonitor, common.VotePoolProbeInterval, func() {
+		if err := m.probe(); err != nil {
+			logging.Logger.Errorf("failed to probe vote pools, err=%s", err.Error())
+		}
+	}).Run()
+}
+
+func (m *PoolMonitor) probe() error {
+	var v *votepool.Vote
+	ownVote, err := m.daoManager.VoteDao.GetLatestOwnVote(hex.EncodeToString(m.greenfieldExecutor.BlsPubKey))
+	if err != nil {
+		return err
+	}
+	if ownVote != nil {
+		if v, err = fromOwnVote(ownVote); err != nil {
+			return err
+		}
+	}
+	m.observe(m.greenfieldExecutor.ProbeVotePools(v), v != nil, time.Now())
+	return nil
+}
+
+// observe updates the metrics of each node, the alert is sent at most once per VotePoolAlertInterval
+func (m *PoolMonitor) observe(probes []*executor.VotePoolProbe, broadcastProbed bool, now time.Time) {
+	queryAvailable, broadcastAvailable := false, false
+	failures := make([]string, 0)
+	for _, p := range probes {
+		m.metricService.SetVotePoolAvailable(p.Provider, votePoolMethodQuery, p.QueryErr == nil)
+		if p.QueryErr == nil {
+			queryAvailable = true
+		} else {
+			failures = append(failures, fmt.Sprintf("%s %s: %s", p.Provider, votePoolMethodQuery, p.QueryErr.Error()))
+		}
+		if !broadcastProbed {
+			continue
+		}
+		m.metricService.SetVotePoolAvailable(p.Provider, votePoolMethodBroadcast, p.BroadcastErr == nil)
+		if p.BroadcastErr == nil {
+			broadcastAvailable = true
+		} else {
+			failures = append(failures, fmt.Sprintf("%s %s: %s", p.Provider, votePoolMethodBroadcast, p.BroadcastErr.Error()))
+		}
+	}
+	if len(failures) > 0 {
+		logging.Logger.Errorf("vote pool probe failures: %s", strings.Join(failures, "; "))
+	}
+
+	if (queryAvailable && (broadcastAvailable || !broadcastProbed)) || now.Sub(m.lastAlertedAt) < common.VotePoolAlertInterval {
+		return
+	}
+	m.lastAlertedAt = now
+	msg := fmt.Sprintf("vote pool is unavailable on all Greenfield nodes, query available=%t, broadcast available=%t",
+		queryAvailable, broadcastAvailable)
+	logging.Logger.Error(msg)
+	config.SendTelegramMessage(m.config.AlertConfig.Identity, m.config.AlertConfig.TelegramBotId,
+		m.config.AlertConfig.TelegramChatId, msg)
+}
@@ -0,0 +1,44 @@
+package vote
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-relayer/common"
+	"github.com/bnb-chain/greenfield-relayer/db"
+	"github.com/bnb-chain/greenfield-relayer/db/model"
+	"github.com/bnb-chain/greenfield-relayer/types"
+)
+
+func TestResignOnMissingOwnVote(t *testing.T) {
+	daoManager := newTestDaoManager(t)
+	blsPublicKey := []byte{0x01}
+
+	// the own vote of the self voted tx was dropped as stale
+	tx := &model.GreenfieldRelayTransaction{ChannelId: 1, Sequence: 7, Status: db.SelfVoted, RelayerFee: "0", AckRelayerFee: "0"}
+	require.NoError(t, daoManager.GreenfieldDao.DB.Create(tx).Error)
+	gnfdProcessor := &GreenfieldVoteProcessor{daoManager: daoManager, blsPublicKey: blsPublicKey}
+	err := gnfdProcessor.prepareEnoughValidVotesForTx(tx)
+	require.ErrorIs(t, err, common.ErrRecordNotFound)
+	require.NoError(t, gnfdProcessor.resignTx(tx))
+	tx, err = daoManager.GreenfieldDao.GetTransactionByChannelIdAndSequence(types.ChannelId(1), 7)
+	require.NoError(t, err)
+	require.Equal(t, db.Saved, tx.Status)
+
+	pkgs := []*model.BscRelayPackage{
+		{ChannelId: 1, OracleSequence: 3, PackageSequence: 10, TxHash: "0x", Status: db.SelfVoted},
+		{ChannelId: 2, OracleSequence: 3, PackageSequence: 11, TxHash: "0x", Status: db.SelfVoted},
+	}
+	require.NoError(t, daoManager.BSCDao.DB.Create(pkgs).Error)
+	bscProcessor := &BSCVoteProcessor{daoManager: daoManager, blsPublicKey: blsPublicKey}
+	err = bscProcessor.prepareEnoughValidVotesForPackages(common.OracleChannelId, 3, 0)
+	require.ErrorIs(t, err, common.ErrRecordNotFound)
+	require.NoError(t, bscProcessor.resignPackages([]int64{pkgs[0].Id, pkgs[1].Id}, 3))
+	pkgs, err = daoManager.BSCDao.GetPackagesByOracleSequence(3)
+	require.NoError(t, err)
+	require.Len(t, pkgs, 2)
+	for _, p := range pkgs {
+		require.Equal(t, db.Saved, p.Status)
+	}
+}
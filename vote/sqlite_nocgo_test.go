@@ -0,0 +1,15 @@
+//go:build !cgo
+
+package vote
+
+import (
+	"testing"
+
+	"github.com/bnb-chain/greenfield-relayer/db/dao"
+)
+
+// newTestDaoManager skips the test since the sqlite driver requires cgo
+func newTestDaoManager(t testing.TB) *dao.DaoManager {
+	t.Skip("sqlite requires cgo")
+	return nil
+}
@@ -0,0 +1,25 @@
+//go:build cgo
+
+package vote
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/bnb-chain/greenfield-relayer/db/dao"
+	"github.com/bnb-chain/greenfield-relayer/db/model"
+)
+
+func newTestDaoManager(t testing.TB) *dao.DaoManager {
+	gormDB, err := gorm.Open(sqlite.Open(t.TempDir()+"/relayer.db"), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	model.InitBSCTables(gormDB)
+	model.InitGreenfieldTables(gormDB)
+	model.InitVoteTables(gormDB)
+	model.InitWatermarkTables(gormDB)
+	return dao.NewDaoManager(dao.NewGreenfieldDao(gormDB), dao.NewBSCDao(gormDB), dao.NewVoteDao(gormDB), nil, nil, nil, nil, nil, nil)
+}
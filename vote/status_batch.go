@@ -0,0 +1,73 @@
+package vote
+
+import (
+	"sync"
+
+	"github.com/bnb-chain/greenfield-relayer/db/dao"
+	"github.com/bnb-chain/greenfield-relayer/db/model"
+)
+
+// selfVotedBatch collects the votes signed in a tick, they are persisted together with the status transitions to
+// SelfVoted in one transaction at the end of the tick, rather than one transaction per sequence
+type selfVotedBatch struct {
+	ids      []int64
+	ownVotes []*model.OwnVote
+	votes    []*model.Vote
+}
+
+// add adds the rows of ids voted by the vote, ownVote is nil if the event was signed before
+func (b *selfVotedBatch) add(ids []int64, ownVote *model.OwnVote, vote *model.Vote) {
+	b.ids = append(b.ids, ids...)
+	if ownVote != nil {
+		b.ownVotes = append(b.ownVotes, ownVote)
+	}
+	b.votes = append(b.votes, vote)
+}
+
+// flush persists the batch, updateStatus updates the rows of ids to SelfVoted in a grouped statement
+func (b *selfVotedBatch) flush(daoManager *dao.DaoManager, updateStatus func(txManager *dao.DaoManager, ids []int64) error) error {
+	if len(b.ids) == 0 {
+		return nil
+	}
+	err := daoManager.ExecTx(func(txManager *dao.DaoManager) error {
+		if err := updateStatus(txManager, b.ids); err != nil {
+			return err
+		}
+		for _, v := range b.ownVotes {
+			if err := txManager.VoteDao.SaveOwnVote(v); err != nil {
+				return err
+			}
+		}
+		// votes saved before, e.g. before a restart, are skipped
+		return txManager.VoteDao.SaveBatchVotes(b.votes)
+	})
+	if err != nil {
+		return err
+	}
+	*b = selfVotedBatch{}
+	return nil
+}
+
+// idBatch collects ids of rows whose status transits in a tick, from goroutines collecting votes concurrently
+type idBatch struct {
+	mutex sync.Mutex
+	ids   []int64
+}
+
+func (b *idBatch) add(ids ...int64) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.ids = append(b.ids, ids...)
+}
+
+// flush updates the status of the collected rows by update in a grouped statement
+func (b *idBatch) flush(update func(ids []int64) error) error {
+	b.mutex.Lock()
+	ids := b.ids
+	b.ids = nil
+	b.mutex.Unlock()
+	if len(ids) == 0 {
+		return nil
+	}
+	return update(ids)
+}
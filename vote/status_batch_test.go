@@ -0,0 +1,34 @@
+package vote
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdBatchFlush(t *testing.T) {
+	b := &idBatch{}
+	wg := new(sync.WaitGroup)
+	for i := int64(0); i < 10; i++ {
+		wg.Add(1)
+		go func(id int64) {
+			defer wg.Done()
+			b.add(id)
+		}(i)
+	}
+	wg.Wait()
+
+	var flushed []int64
+	require.NoError(t, b.flush(func(ids []int64) error {
+		flushed = ids
+		return nil
+	}))
+	require.ElementsMatch(t, []int64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, flushed)
+
+	// nothing left to update
+	require.NoError(t, b.flush(func(ids []int64) error {
+		t.Fatal("unexpected update of empty batch")
+		return nil
+	}))
+}
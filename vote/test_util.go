@@ -5,9 +5,9 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/prysmaticlabs/prysm/crypto/bls/blst"
 	"github.com/tendermint/tendermint/votepool"
 
+	"github.com/bnb-chain/greenfield-relayer/bls"
 	"github.com/bnb-chain/greenfield-relayer/db/dao"
 	"github.com/bnb-chain/greenfield-relayer/db/model"
 	"github.com/bnb-chain/greenfield-relayer/executor"
@@ -43,7 +43,7 @@ func BroadcastVotesFromOtherRelayers(
 	}
 
 	for _, pk := range privateKeysList {
-		secretKey, err := blst.SecretKeyFromBytes(common.Hex2Bytes(pk))
+		secretKey, err := bls.SecretKeyFromBytes(common.Hex2Bytes(pk))
 		if err != nil {
 			panic(err)
 		}
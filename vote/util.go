@@ -2,15 +2,16 @@ package vote
 
 import (
 	"encoding/hex"
+	"math/big"
 	"reflect"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
-	"github.com/prysmaticlabs/prysm/crypto/bls"
 	tmtypes "github.com/tendermint/tendermint/types"
 	"github.com/tendermint/tendermint/votepool"
 	"github.com/willf/bitset"
 
+	"github.com/bnb-chain/greenfield-relayer/bls"
 	"github.com/bnb-chain/greenfield-relayer/db/model"
 	"github.com/bnb-chain/greenfield-relayer/types"
 )
@@ -31,6 +32,51 @@ func VerifySignature(vote *votepool.Vote, eventHash []byte) error {
 	return nil
 }
 
+// VerifyBlsSignature verifies a bls signature of the message
+func VerifyBlsSignature(pubKey, signature, msg []byte) error {
+	blsPubKey, err := bls.PublicKeyFromBytes(pubKey)
+	if err != nil {
+		return errors.Wrap(err, "convert public key from bytes to bls failed")
+	}
+	sig, err := bls.SignatureFromBytes(signature)
+	if err != nil {
+		return errors.Wrap(err, "invalid signature")
+	}
+	if !sig.Verify(blsPubKey, msg) {
+		return errors.New("verify bls signature failed.")
+	}
+	return nil
+}
+
+// VerifyAggregatedSignature verifies an aggregated bls signature of the event hash against the bls keys of validators
+// marked in the bitset, bit i of the bitset marks the i-th validator
+func VerifyAggregatedSignature(eventHash, signature []byte, valBitSet *big.Int, blsKeys [][]byte) error {
+	if valBitSet.BitLen() > len(blsKeys) {
+		return errors.Errorf("bitset marks validator %d while there are %d validators", valBitSet.BitLen()-1, len(blsKeys))
+	}
+	signers := make([][]byte, 0, len(blsKeys))
+	for idx, key := range blsKeys {
+		if valBitSet.Bit(idx) == 1 {
+			signers = append(signers, key)
+		}
+	}
+	if len(signers) == 0 {
+		return errors.New("no validator is marked in the bitset")
+	}
+	aggregatedPubKey, err := bls.AggregatePublicKeys(signers)
+	if err != nil {
+		return errors.Wrap(err, "aggregate bls public keys failed")
+	}
+	sig, err := bls.SignatureFromBytes(signature)
+	if err != nil {
+		return errors.Wrap(err, "invalid signature")
+	}
+	if !sig.Verify(aggregatedPubKey, eventHash) {
+		return errors.New("verify aggregated bls signature failed.")
+	}
+	return nil
+}
+
 // AggregateSignatureAndValidatorBitSet aggregates signature from multiple votes, and marks the bitset of validators who contribute votes
 func AggregateSignatureAndValidatorBitSet(votes []*model.Vote, validators interface{}) ([]byte, *bitset.BitSet, error) {
 	signatures := make([][]byte, 0, len(votes))
@@ -40,17 +86,9 @@ func AggregateSignatureAndValidatorBitSet(votes []*model.Vote, validators interf
 		voteAddrSet[v.PubKey] = struct{}{}
 		signatures = append(signatures, common.Hex2Bytes(v.Signature))
 	}
-	if reflect.TypeOf(validators).Elem() == reflect.TypeOf(types.Validator{}) {
-		for idx, valInfo := range validators.([]types.Validator) {
-			if _, ok := voteAddrSet[hex.EncodeToString(valInfo.BlsPublicKey[:])]; ok {
-				valBitSet.Set(uint(idx))
-			}
-		}
-	} else {
-		for idx, valInfo := range validators.([]*tmtypes.Validator) {
-			if _, ok := voteAddrSet[hex.EncodeToString(valInfo.BlsKey[:])]; ok {
-				valBitSet.Set(uint(idx))
-			}
+	for idx, blsKey := range ValidatorBlsKeys(validators) {
+		if _, ok := voteAddrSet[blsKey]; ok {
+			valBitSet.Set(uint(idx))
 		}
 	}
 	sigs, err := bls.MultipleSignaturesFromBytes(signatures)
@@ -59,3 +97,52 @@ func AggregateSignatureAndValidatorBitSet(votes []*model.Vote, validators interf
 	}
 	return bls.AggregateSignatures(sigs).Marshal(), valBitSet, nil
 }
+
+// SplitVotesByBlsKeys splits votes into the ones signed by the registered BLS keys of validators and the stale ones,
+// whose keys are not registered any more since the validator has changed its BLS key after the votes were collected
+func SplitVotesByBlsKeys(votes []*model.Vote, blsKeys []string) (valid []*model.Vote, stale []*model.Vote) {
+	registered := toSet(blsKeys)
+	for _, v := range votes {
+		if registered[v.PubKey] {
+			valid = append(valid, v)
+		} else {
+			stale = append(stale, v)
+		}
+	}
+	return valid, stale
+}
+
+// ValidatorBlsKeys returns hex encoded BLS keys of validators in order, validators are either relayers of BSC or
+// validators of Greenfield
+func ValidatorBlsKeys(validators interface{}) []string {
+	if reflect.TypeOf(validators).Elem() == reflect.TypeOf(types.Validator{}) {
+		return BSCRelayerBlsKeys(validators.([]types.Validator))
+	}
+	return GreenfieldValidatorBlsKeys(validators.([]*tmtypes.Validator))
+}
+
+// BSCRelayerBlsKeys returns hex encoded BLS keys of the relayers of BSC in order
+func BSCRelayerBlsKeys(validators []types.Validator) []string {
+	keys := make([]string, 0, len(validators))
+	for _, valInfo := range validators {
+		keys = append(keys, hex.EncodeToString(valInfo.BlsPublicKey[:]))
+	}
+	return keys
+}
+
+// GreenfieldValidatorBlsKeys returns hex encoded BLS keys of the validators of Greenfield in order
+func GreenfieldValidatorBlsKeys(validators []*tmtypes.Validator) []string {
+	keys := make([]string, 0, len(validators))
+	for _, valInfo := range validators {
+		keys = append(keys, hex.EncodeToString(valInfo.BlsKey[:]))
+	}
+	return keys
+}
+
+func toSet(list []string) map[string]bool {
+	set := make(map[string]bool, len(list))
+	for _, e := range list {
+		set[e] = true
+	}
+	return set
+}
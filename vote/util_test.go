@@ -0,0 +1,116 @@
+package vote
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-relayer/bls"
+	"github.com/bnb-chain/greenfield-relayer/config"
+	"github.com/bnb-chain/greenfield-relayer/db/model"
+	"github.com/bnb-chain/greenfield-relayer/types"
+	"github.com/bnb-chain/greenfield-relayer/util"
+)
+
+func TestVerifyAggregatedSignature(t *testing.T) {
+	eventHash, err := GreenfieldEventHash(config.EventHashVersionV1, []byte("payload"))
+	require.NoError(t, err)
+
+	var validators []types.Validator
+	var blsKeys [][]byte
+	var votes []*model.Vote
+	for i := 0; i < 4; i++ {
+		privKey, err := bls.RandKey()
+		require.NoError(t, err)
+		pubKey := privKey.PublicKey().Marshal()
+		validators = append(validators, types.Validator{BlsPublicKey: pubKey})
+		blsKeys = append(blsKeys, pubKey)
+		// validator 2 does not vote
+		if i != 2 {
+			votes = append(votes, &model.Vote{
+				PubKey:    hex.EncodeToString(pubKey),
+				Signature: hex.EncodeToString(privKey.Sign(eventHash).Marshal()),
+			})
+		}
+	}
+	signature, valBitSet, err := AggregateSignatureAndValidatorBitSet(votes, validators)
+	require.NoError(t, err)
+	bitSet := util.BitSetToBigInt(valBitSet)
+	require.NoError(t, VerifyAggregatedSignature(eventHash, signature, bitSet, blsKeys))
+
+	// marks the validator who does not vote
+	require.Error(t, VerifyAggregatedSignature(eventHash, signature, new(big.Int).SetBit(bitSet, 2, 1), blsKeys))
+	// validators of another set
+	require.Error(t, VerifyAggregatedSignature(eventHash, signature, bitSet, append(blsKeys[1:], blsKeys[0])))
+	require.Error(t, VerifyAggregatedSignature(eventHash, signature, bitSet, blsKeys[:2]))
+	require.Error(t, VerifyAggregatedSignature(eventHash, signature, big.NewInt(0), blsKeys))
+}
+
+// validator set sizes of the benchmarks, the ones of BSC and Greenfield at launch and a large one
+var benchmarkValidatorSizes = []int{21, 41, 100}
+
+// benchmarkVotes returns validators of the size all voting for the event hash
+func benchmarkVotes(b *testing.B, size int, eventHash []byte) ([]types.Validator, [][]byte, []*model.Vote) {
+	var validators []types.Validator
+	var blsKeys [][]byte
+	var votes []*model.Vote
+	for i := 0; i < size; i++ {
+		privKey, err := bls.RandKey()
+		require.NoError(b, err)
+		pubKey := privKey.PublicKey().Marshal()
+		validators = append(validators, types.Validator{BlsPublicKey: pubKey})
+		blsKeys = append(blsKeys, pubKey)
+		votes = append(votes, &model.Vote{
+			PubKey:    hex.EncodeToString(pubKey),
+			Signature: hex.EncodeToString(privKey.Sign(eventHash).Marshal()),
+			EventHash: eventHash,
+		})
+	}
+	return validators, blsKeys, votes
+}
+
+func BenchmarkAggregateSignatureAndValidatorBitSet(b *testing.B) {
+	eventHash, err := GreenfieldEventHash(config.EventHashVersionV1, []byte("payload"))
+	require.NoError(b, err)
+	for _, size := range benchmarkValidatorSizes {
+		validators, _, votes := benchmarkVotes(b, size, eventHash)
+		b.Run(fmt.Sprintf("validators=%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, _, err := AggregateSignatureAndValidatorBitSet(votes, validators)
+				require.NoError(b, err)
+			}
+		})
+	}
+}
+
+func BenchmarkVerifyAggregatedSignature(b *testing.B) {
+	eventHash, err := GreenfieldEventHash(config.EventHashVersionV1, []byte("payload"))
+	require.NoError(b, err)
+	for _, size := range benchmarkValidatorSizes {
+		validators, blsKeys, votes := benchmarkVotes(b, size, eventHash)
+		signature, valBitSet, err := AggregateSignatureAndValidatorBitSet(votes, validators)
+		require.NoError(b, err)
+		bitSet := util.BitSetToBigInt(valBitSet)
+		b.Run(fmt.Sprintf("validators=%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				require.NoError(b, VerifyAggregatedSignature(eventHash, signature, bitSet, blsKeys))
+			}
+		})
+	}
+}
+
+// BenchmarkVerifySignature verifies a single vote as done for every vote collected from the vote pool
+func BenchmarkVerifySignature(b *testing.B) {
+	eventHash, err := GreenfieldEventHash(config.EventHashVersionV1, []byte("payload"))
+	require.NoError(b, err)
+	_, _, votes := benchmarkVotes(b, 1, eventHash)
+	v, err := DtoToEntity(votes[0])
+	require.NoError(b, err)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		require.NoError(b, VerifySignature(v, eventHash))
+	}
+}
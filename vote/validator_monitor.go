@@ -0,0 +1,99 @@
+package vote
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/bnb-chain/greenfield-relayer/common"
+	"github.com/bnb-chain/greenfield-relayer/config"
+	"github.com/bnb-chain/greenfield-relayer/executor"
+	"github.com/bnb-chain/greenfield-relayer/logging"
+	"github.com/bnb-chain/greenfield-relayer/metric"
+)
+
+// broadcastTasks are the tasks broadcasting votes and claims, which are paused while the validator of this relayer is
+// not active, since they are rejected or useless, and during blackout windows
+var broadcastTasks = []string{
+	common.TaskBSCVoteBroadcast,
+	common.TaskGreenfieldVoteBroadcast,
+	common.TaskBSCAssembler,
+	common.TaskGreenfieldAssembler,
+}
+
+// ValidatorMonitor pauses vote broadcasting and claims once the validator of this relayer leaves the active validator
+// set of Greenfield, e.g. it is jailed, and resumes them once it is active again. Tasks paused by operators are left
+// untouched.
+type ValidatorMonitor struct {
+	config             *config.Config
+	greenfieldExecutor *executor.GreenfieldExecutor
+	metricService      *metric.MetricService
+	scheduler          *common.Scheduler
+	paused             []string // tasks paused by the monitor
+	inactive           bool
+}
+
+func NewValidatorMonitor(cfg *config.Config, greenfieldExecutor *executor.GreenfieldExecutor, ms *metric.MetricService) *ValidatorMonitor {
+	return &ValidatorMonitor{
+		config:             cfg,
+		greenfieldExecutor: greenfieldExecutor,
+		metricService:      ms,
+		scheduler:          common.GetScheduler(),
+	}
+}
+
+func (m *ValidatorMonitor) StartLoop() {
+	common.Schedule(common.TaskValidatorMonitor, common.ValidatorStatusCheckInterval, func() {
+		if err := m.check(); err != nil {
+			logging.Logger.Errorf("failed to check validator status, err=%s", err.Error())
+		}
+	}).Run()
+}
+
+func (m *ValidatorMonitor) check() error {
+	keys, err := m.greenfieldExecutor.GetValidatorsBlsPublicKey()
+	if err != nil {
+		return err
+	}
+	ownKey := hex.EncodeToString(m.greenfieldExecutor.BlsPubKey)
+	active := false
+	for _, k := range keys {
+		if k == ownKey {
+			active = true
+			break
+		}
+	}
+	m.metricService.SetValidatorActive(active)
+	m.observe(active)
+	return nil
+}
+
+// observe pauses or resumes tasks when the validator status changes, an alert is sent on every change
+func (m *ValidatorMonitor) observe(active bool) {
+	if active == !m.inactive {
+		return
+	}
+	var msg string
+	if !active {
+		m.inactive = true
+		for _, name := range broadcastTasks {
+			task := m.scheduler.Task(name)
+			if task == nil || task.Status().Paused {
+				continue
+			}
+			task.Pause()
+			m.paused = append(m.paused, name)
+		}
+		msg = fmt.Sprintf("validator of the relayer is not active on Greenfield, e.g. jailed, paused %s", strings.Join(m.paused, ", "))
+	} else {
+		m.inactive = false
+		for _, name := range m.paused {
+			m.scheduler.Task(name).Resume()
+		}
+		msg = fmt.Sprintf("validator of the relayer is active on Greenfield again, resumed %s", strings.Join(m.paused, ", "))
+		m.paused = nil
+	}
+	logging.Logger.Error(msg)
+	config.SendTelegramMessage(m.config.AlertConfig.Identity, m.config.AlertConfig.TelegramBotId,
+		m.config.AlertConfig.TelegramChatId, msg)
+}
@@ -0,0 +1,38 @@
+package vote
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bnb-chain/greenfield-relayer/common"
+	"github.com/bnb-chain/greenfield-relayer/config"
+)
+
+func TestValidatorMonitorObserve(t *testing.T) {
+	s := common.NewScheduler(0, nil)
+	for _, name := range broadcastTasks {
+		s.Schedule(name, time.Second, func() {})
+	}
+	// paused by an operator, it is not resumed by the monitor
+	s.Task(common.TaskBSCAssembler).Pause()
+	m := &ValidatorMonitor{config: &config.Config{}, scheduler: s}
+
+	m.observe(true)
+	require.Empty(t, m.paused)
+
+	m.observe(false)
+	require.Len(t, m.paused, 3)
+	for _, name := range broadcastTasks {
+		require.True(t, s.Task(name).Status().Paused)
+	}
+	m.observe(false)
+	require.Len(t, m.paused, 3)
+
+	m.observe(true)
+	require.Empty(t, m.paused)
+	require.True(t, s.Task(common.TaskBSCAssembler).Status().Paused)
+	require.False(t, s.Task(common.TaskGreenfieldAssembler).Status().Paused)
+	require.False(t, s.Task(common.TaskBSCVoteBroadcast).Status().Paused)
+}
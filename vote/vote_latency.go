@@ -0,0 +1,41 @@
+package vote
+
+import (
+	"time"
+
+	"github.com/bnb-chain/greenfield-relayer/db/dao"
+	"github.com/bnb-chain/greenfield-relayer/db/model"
+	"github.com/bnb-chain/greenfield-relayer/metric"
+)
+
+// recordVoteLatencies records the latencies of votes newly seen in the vote pool from the package sent at txTime, the
+// latency of a vote is accurate to the interval of vote pool queries
+func recordVoteLatencies(voteDao *dao.VoteDao, ms *metric.MetricService, direction string, txTime int64, votes []*model.Vote) error {
+	latencies := make([]*model.VoteLatency, 0, len(votes))
+	for _, v := range votes {
+		latency := v.CreatedTime - txTime
+		if latency < 0 {
+			latency = 0
+		}
+		latencies = append(latencies, &model.VoteLatency{
+			Direction:     direction,
+			ChannelId:     v.ChannelId,
+			Sequence:      v.Sequence,
+			PubKey:        v.PubKey,
+			Latency:       latency,
+			FirstSeenTime: v.CreatedTime,
+		})
+	}
+	if err := voteDao.SaveVoteLatencies(latencies); err != nil {
+		return err
+	}
+	for _, l := range latencies {
+		ms.ObserveVoteLatency(direction, l.PubKey, l.ChannelId, l.Sequence, l.Latency)
+	}
+	return nil
+}
+
+// recordVoteCollection records the time the package sent at txTime took to get enough votes
+func recordVoteCollection(ms *metric.MetricService, direction string, channelId uint8, sequence uint64, txTime int64) {
+	ms.ObserveVoteCollection(direction, channelId, sequence, time.Now().Unix()-txTime)
+}
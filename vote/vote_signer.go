@@ -1,18 +1,18 @@
 package vote
 
 import (
-	"github.com/prysmaticlabs/prysm/crypto/bls/blst"
-	blscmn "github.com/prysmaticlabs/prysm/crypto/bls/common"
 	"github.com/tendermint/tendermint/votepool"
+
+	"github.com/bnb-chain/greenfield-relayer/bls"
 )
 
 type VoteSigner struct {
-	privKey blscmn.SecretKey
-	pubKey  blscmn.PublicKey
+	privKey bls.SecretKey
+	pubKey  bls.PublicKey
 }
 
 func NewVoteSigner(pk []byte) *VoteSigner {
-	privKey, err := blst.SecretKeyFromBytes(pk)
+	privKey, err := bls.SecretKeyFromBytes(pk)
 	if err != nil {
 		panic(err)
 	}
@@ -29,3 +29,12 @@ func (signer *VoteSigner) SignVote(vote *votepool.Vote) {
 	signature := signer.privKey.Sign(vote.EventHash[:])
 	vote.Signature = append(vote.Signature, signature.Marshal()...)
 }
+
+// Sign signs an arbitrary message by relayer's private key
+func (signer *VoteSigner) Sign(msg []byte) []byte {
+	return signer.privKey.Sign(msg).Marshal()
+}
+
+func (signer *VoteSigner) PubKey() []byte {
+	return signer.pubKey.Marshal()
+}